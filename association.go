@@ -57,6 +57,8 @@ func (association *Association) Find(out interface{}, conds ...interface{}) erro
 	return association.Error
 }
 
+// Append appends values to the association, new has many values of an owner are created with one multi-row INSERT
+// split by CreateBatchSize, values with primary key are upserted in the same statement
 func (association *Association) Append(values ...interface{}) error {
 	if association.Error == nil {
 		switch association.Relationship.Type {
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"sync/atomic"
 	"time"
 
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/schema"
 	"gorm.io/gorm/utils"
 )
@@ -15,11 +17,11 @@ import (
 func initializeCallbacks(db *DB) *callbacks {
 	return &callbacks{
 		processors: map[string]*processor{
-			"create": {db: db},
-			"query":  {db: db},
-			"update": {db: db},
-			"delete": {db: db},
-			"row":    {db: db},
+			"create": {db: db, tableMode: schema.TableModeWrite},
+			"query":  {db: db, tableMode: schema.TableModeRead},
+			"update": {db: db, tableMode: schema.TableModeWrite},
+			"delete": {db: db, tableMode: schema.TableModeWrite},
+			"row":    {db: db, tableMode: schema.TableModeRead},
 			"raw":    {db: db},
 		},
 	}
@@ -31,10 +33,21 @@ type callbacks struct {
 }
 
 type processor struct {
-	db        *DB
-	Clauses   []string
-	fns       []func(*DB)
-	callbacks []*callback
+	db           *DB
+	tableMode    string // mode of schema.TablerWithMode
+	Clauses      []string
+	clauseOrders []clauseOrder
+	fns          []func(*DB)
+	callbacks    []*callback
+	sorted       []*callback // callbacks of fns
+	filtered     []*callback // callbacks filtered by Match
+}
+
+// clauseOrder position of clause declared by plugins, relative to another clause
+type clauseOrder struct {
+	name   string
+	before string
+	after  string
 }
 
 type callback struct {
@@ -46,6 +59,27 @@ type callback struct {
 	match     func(*DB) bool
 	handler   func(*DB)
 	processor *processor
+	// before, after declared when registering, before and after might be changed when sorting
+	declaredBefore string
+	declaredAfter  string
+}
+
+// CallbackInfo registered callback reported by Inspect
+type CallbackInfo struct {
+	Name     string
+	Before   string // declared by Before
+	After    string // declared by After
+	Filtered bool   // filtered by the Match predicate, it won't be executed
+}
+
+// CallbackTimingsKey instance setting key of the callback durations when TraceCallbacks enabled,
+// e.g. db.InstanceGet(gorm.CallbackTimingsKey) returns []gorm.CallbackTiming
+const CallbackTimingsKey = "gorm:callback_timings"
+
+// CallbackTiming duration of an executed callback
+type CallbackTiming struct {
+	Name     string
+	Duration time.Duration
 }
 
 func (cs *callbacks) Create() *processor {
@@ -72,7 +106,70 @@ func (cs *callbacks) Raw() *processor {
 	return cs.processors["raw"]
 }
 
+// Inspect returns callbacks of processors in execution order, filtered callbacks are listed at the end, e.g:
+//
+//	db.Callback().Inspect()["query"]
+func (cs *callbacks) Inspect() map[string][]CallbackInfo {
+	results := make(map[string][]CallbackInfo, len(cs.processors))
+	for name, p := range cs.processors {
+		results[name] = p.Inspect()
+	}
+	return results
+}
+
+// Inspect returns callbacks in execution order, filtered callbacks are listed at the end
+func (p *processor) Inspect() []CallbackInfo {
+	infos := make([]CallbackInfo, 0, len(p.sorted)+len(p.filtered))
+	for _, c := range p.sorted {
+		infos = append(infos, CallbackInfo{Name: c.name, Before: c.declaredBefore, After: c.declaredAfter})
+	}
+
+	for _, c := range p.filtered {
+		infos = append(infos, CallbackInfo{Name: c.name, Before: c.declaredBefore, After: c.declaredAfter, Filtered: true})
+	}
+	return infos
+}
+
+// executePartitions executes the statement for records of each table, in a transaction unless SkipDefaultTransaction
+func (p *processor) executePartitions(db *DB, groups []reflect.Value) {
+	execute := func(tx *DB) error {
+		for _, group := range groups {
+			stmt := tx.Statement.clone()
+			partitionDB := &DB{Config: tx.Config, Statement: stmt}
+			stmt.DB = partitionDB
+			stmt.Dest, stmt.Model = group.Interface(), group.Interface()
+			// 重新解析 model 的表名，按分组的记录选择表
+			stmt.Table, stmt.modelTable, stmt.TableSchema, stmt.TableExpr = "", "", "", nil
+
+			p.Execute(partitionDB)
+			db.RowsAffected += partitionDB.RowsAffected
+			if partitionDB.Error != nil {
+				return partitionDB.Error
+			}
+		}
+		return nil
+	}
+
+	if db.SkipDefaultTransaction || db.DryRun || db.Statement.inTransaction() {
+		db.AddError(execute(db))
+	} else {
+		db.AddError(db.Transaction(execute))
+	}
+}
+
 func (p *processor) Execute(db *DB) *DB {
+	var ownedStmt *Statement
+	if db.CheckConcurrentMisuse {
+		// 同一个 statement 同时只能有一个 finisher 执行
+		ownedStmt = db.Statement
+		if !atomic.CompareAndSwapInt32(&ownedStmt.executing, 0, 1) {
+			atomic.StoreInt32(&ownedStmt.reused, 1)
+			db.AddError(fmt.Errorf("%w: another finisher is executing on the statement", ErrConcurrentStatementReuse))
+			return db
+		}
+		defer atomic.StoreInt32(&ownedStmt.executing, 0)
+	}
+
 	// call scopes
 	for len(db.Statement.scopes) > 0 {
 		db = db.executeScopes()
@@ -85,7 +182,7 @@ func (p *processor) Execute(db *DB) *DB {
 	)
 
 	if len(stmt.BuildClauses) == 0 {
-		stmt.BuildClauses = p.Clauses // stmt 没有定义 BuildClauses ，使用默认的
+		stmt.BuildClauses = p.BuildClauses() // stmt 没有定义 BuildClauses ，使用默认的
 		resetBuildClauses = true
 	}
 
@@ -101,6 +198,7 @@ func (p *processor) Execute(db *DB) *DB {
 	}
 
 	// parse model values
+	tableSpecified := (stmt.Table != "" || stmt.TableExpr != nil) && stmt.Table != stmt.modelTable
 	if stmt.Model != nil {
 		if err := stmt.Parse(stmt.Model); err != nil && (!errors.Is(err, schema.ErrUnsupportedDataType) || (stmt.Table == "" && stmt.TableExpr == nil && stmt.SQL.Len() == 0)) {
 			if errors.Is(err, schema.ErrUnsupportedDataType) && stmt.Table == "" && stmt.TableExpr == nil {
@@ -111,6 +209,11 @@ func (p *processor) Execute(db *DB) *DB {
 		}
 	}
 
+	// 读写分表时，没有指定表名的语句按模式选择表
+	if p.tableMode != "" && !tableSpecified && stmt.Schema != nil {
+		stmt.useTableOfMode(p.tableMode)
+	}
+
 	// assign stmt.ReflectValue
 	if stmt.Dest != nil {
 		stmt.ReflectValue = reflect.ValueOf(stmt.Dest)
@@ -126,8 +229,43 @@ func (p *processor) Execute(db *DB) *DB {
 		}
 	}
 
-	for _, f := range p.fns {
-		f(db)
+	// 跳过预编译时使用底层连接执行，包括默认事务
+	if stmt.SkipPrepare {
+		connPool := stmt.ConnPool
+		stmt.ConnPool = unpreparedConnPool(connPool)
+		defer func() {
+			stmt.ConnPool = connPool
+		}()
+	}
+
+	// 分表写入时按记录选择表，记录属于多个表时按表分组执行
+	var groups []reflect.Value
+	if p.tableMode == schema.TableModeWrite && !tableSpecified && stmt.Schema != nil && db.Error == nil {
+		groups = stmt.partitionRecords()
+	}
+
+	if len(groups) > 0 {
+		p.executePartitions(db, groups)
+	} else if db.RetryPolicy != nil && db.Error == nil && !db.DryRun && !stmt.inTransaction() {
+		// 事务外执行的语句遇到可重试的错误时，恢复 statement 后重新执行回调
+		snapshot := newStatementSnapshot(stmt)
+		for attempt := 1; ; attempt++ {
+			p.run(db)
+			if db.Error == nil || db.RowsAffected > 0 || !db.retryAfter(attempt, db.Error) {
+				break
+			}
+
+			snapshot.restore(stmt)
+			db.Error = nil
+		}
+	} else {
+		p.run(db)
+	}
+
+	stmt.SkipQuery = false
+
+	if ownedStmt != nil && atomic.SwapInt32(&ownedStmt.reused, 0) == 1 {
+		db.AddError(fmt.Errorf("%w: the statement of table %q was reused while executing %q", ErrConcurrentStatementReuse, stmt.Table, stmt.SQL.String()))
 	}
 
 	if stmt.SQL.Len() > 0 {
@@ -152,6 +290,130 @@ func (p *processor) Execute(db *DB) *DB {
 	return db
 }
 
+// run runs the callbacks, scopes added by a callback are executed before the next callback
+func (p *processor) run(db *DB) {
+	if db.TraceCallbacks {
+		timings := make([]CallbackTiming, 0, len(p.fns))
+		for idx, f := range p.fns {
+			for len(db.Statement.scopes) > 0 {
+				db = db.executeScopes()
+			}
+			begin := time.Now()
+			f(db)
+			timings = append(timings, CallbackTiming{Name: p.sorted[idx].name, Duration: time.Since(begin)})
+		}
+		db.InstanceSet(CallbackTimingsKey, timings)
+	} else {
+		for _, f := range p.fns {
+			for len(db.Statement.scopes) > 0 {
+				db = db.executeScopes()
+			}
+			f(db)
+		}
+	}
+}
+
+// statementSnapshot states of the statement modified by callbacks, restored before retrying
+type statementSnapshot struct {
+	sql     string
+	vars    []interface{}
+	clauses map[string]clause.Clause
+	joins   []join
+	// hooks and callbacks might change dest, e.g. BeforeCreate sets fields, primary keys assigned after creating
+	dest     reflect.Value
+	destCopy reflect.Value
+	elems    [][2]reflect.Value // 切片元素的副本，指针元素保存指向的值
+}
+
+func newStatementSnapshot(stmt *Statement) *statementSnapshot {
+	snapshot := &statementSnapshot{
+		sql:     stmt.SQL.String(),
+		vars:    append([]interface{}(nil), stmt.Vars...),
+		clauses: make(map[string]clause.Clause, len(stmt.Clauses)),
+		joins:   stmt.Joins,
+	}
+	for k, c := range stmt.Clauses {
+		snapshot.clauses[k] = c
+	}
+
+	if dest := stmt.ReflectValue; dest.IsValid() && dest.CanSet() {
+		snapshot.dest = dest
+		snapshot.destCopy = reflect.New(dest.Type()).Elem()
+		snapshot.destCopy.Set(dest)
+
+		switch dest.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < dest.Len(); i++ {
+				elem := dest.Index(i)
+				for elem.Kind() == reflect.Ptr && !elem.IsNil() {
+					elem = elem.Elem()
+				}
+
+				if elem.CanSet() {
+					elemCopy := reflect.New(elem.Type()).Elem()
+					elemCopy.Set(elem)
+					snapshot.elems = append(snapshot.elems, [2]reflect.Value{elem, elemCopy})
+				}
+			}
+		}
+	}
+	return snapshot
+}
+
+func (snapshot *statementSnapshot) restore(stmt *Statement) {
+	stmt.SQL.Reset()
+	stmt.SQL.WriteString(snapshot.sql)
+	stmt.Vars = append(stmt.Vars[:0], snapshot.vars...)
+	stmt.Clauses = make(map[string]clause.Clause, len(snapshot.clauses))
+	for k, c := range snapshot.clauses {
+		stmt.Clauses[k] = c
+	}
+	stmt.Joins = snapshot.joins
+
+	if snapshot.dest.IsValid() {
+		snapshot.dest.Set(snapshot.destCopy)
+		for _, elem := range snapshot.elems {
+			elem[0].Set(elem[1])
+		}
+		stmt.ReflectValue = snapshot.dest
+	}
+}
+
+// retryAfter reports whether to retry after the attempt-th attempt failed with err, waits for the backoff before retrying
+func (db *DB) retryAfter(attempt int, err error) bool {
+	policy := db.RetryPolicy
+	if policy == nil || attempt >= policy.MaxAttempts {
+		return false
+	}
+
+	if policy.RetryableErr != nil {
+		if !policy.RetryableErr(err) {
+			return false
+		}
+	} else if translator, ok := db.Dialector.(RetryableErrorTranslator); !ok || !translator.IsRetryableError(err) {
+		return false
+	}
+
+	if policy.Backoff != nil {
+		if wait := policy.Backoff(attempt); wait > 0 {
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+
+			ctx := db.Statement.Context
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				return false
+			}
+		}
+	}
+	return true
+}
+
 func (p *processor) Get(name string) func(*DB) {
 	for i := len(p.callbacks) - 1; i >= 0; i-- {
 		if v := p.callbacks[i]; v.name == name && !v.remove {
@@ -185,18 +447,76 @@ func (p *processor) Replace(name string, fn func(*DB)) error {
 	return (&callback{processor: p}).Replace(name, fn)
 }
 
+// ClauseBefore declares clause name placed before clause `before` when building SQL,
+// it is kept even if Clauses is reset, e.g. by RegisterDefaultCallbacks
+func (p *processor) ClauseBefore(before, name string) {
+	p.clauseOrders = append(p.clauseOrders, clauseOrder{name: name, before: before})
+}
+
+// ClauseAfter declares clause name placed after clause `after` when building SQL, E.g:
+//
+//	db.Callback().Query().ClauseAfter("WHERE", "QUALIFY")
+func (p *processor) ClauseAfter(after, name string) {
+	p.clauseOrders = append(p.clauseOrders, clauseOrder{name: name, after: after})
+}
+
+// RegisterClause declares clause name placed after clause `after` with its builder, the builder is optional
+func (p *processor) RegisterClause(name, after string, builder clause.ClauseBuilder) {
+	if builder != nil {
+		p.db.ClauseBuilders[name] = builder
+	}
+	p.ClauseAfter(after, name)
+}
+
+// BuildClauses returns clauses to build statements, Clauses with declared clauses inserted
+func (p *processor) BuildClauses() []string {
+	if len(p.clauseOrders) == 0 {
+		return p.Clauses
+	}
+
+	clauses := make([]string, len(p.Clauses), len(p.Clauses)+len(p.clauseOrders))
+	copy(clauses, p.Clauses)
+	for _, order := range p.clauseOrders {
+		if utils.Contains(clauses, order.name) {
+			continue
+		}
+
+		// 找不到相对的子句时，放在最后
+		idx := len(clauses)
+		if order.before != "" {
+			if i := getRIndex(clauses, order.before); i != -1 {
+				idx = i
+			}
+		} else if i := getRIndex(clauses, order.after); i != -1 {
+			idx = i + 1
+		}
+
+		clauses = append(clauses, "")
+		copy(clauses[idx+1:], clauses[idx:])
+		clauses[idx] = order.name
+	}
+	return clauses
+}
+
 func (p *processor) compile() (err error) {
 	var callbacks []*callback
 	for _, callback := range p.callbacks {
 		if callback.match == nil || callback.match(p.db) {
 			callbacks = append(callbacks, callback)
+		} else {
+			p.filtered = append(p.filtered, callback)
 		}
 	}
 	p.callbacks = callbacks
 
-	if p.fns, err = sortCallbacks(p.callbacks); err != nil {
+	if p.sorted, err = sortCallbacks(p.callbacks); err != nil {
 		p.db.Logger.Error(context.Background(), "Got error when compile callbacks, got %v", err)
 	}
+
+	p.fns = make([]func(*DB), 0, len(p.sorted))
+	for _, c := range p.sorted {
+		p.fns = append(p.fns, c.handler)
+	}
 	return
 }
 
@@ -213,16 +533,14 @@ func (c *callback) After(name string) *callback {
 func (c *callback) Register(name string, fn func(*DB)) error {
 	c.name = name
 	c.handler = fn
-	c.processor.callbacks = append(c.processor.callbacks, c)
-	return c.processor.compile()
+	return c.add()
 }
 
 func (c *callback) Remove(name string) error {
 	c.processor.db.Logger.Warn(context.Background(), "removing callback `%s` from %s\n", name, utils.FileWithLineNum())
 	c.name = name
 	c.remove = true
-	c.processor.callbacks = append(c.processor.callbacks, c)
-	return c.processor.compile()
+	return c.add()
 }
 
 func (c *callback) Replace(name string, fn func(*DB)) error {
@@ -230,6 +548,12 @@ func (c *callback) Replace(name string, fn func(*DB)) error {
 	c.name = name
 	c.handler = fn
 	c.replace = true
+	return c.add()
+}
+
+// add adds the callback to processor and compiles callbacks again
+func (c *callback) add() error {
+	c.declaredBefore, c.declaredAfter = c.before, c.after
 	c.processor.callbacks = append(c.processor.callbacks, c)
 	return c.processor.compile()
 }
@@ -244,7 +568,7 @@ func getRIndex(strs []string, str string) int {
 	return -1
 }
 
-func sortCallbacks(cs []*callback) (fns []func(*DB), err error) {
+func sortCallbacks(cs []*callback) (sortedCallbacks []*callback, err error) {
 	var (
 		names, sorted []string
 		sortCallback  func(*callback) error
@@ -333,7 +657,7 @@ func sortCallbacks(cs []*callback) (fns []func(*DB), err error) {
 
 	for _, name := range sorted {
 		if idx := getRIndex(names, name); !cs[idx].remove {
-			fns = append(fns, cs[idx].handler)
+			sortedCallbacks = append(sortedCallbacks, cs[idx])
 		}
 	}
 
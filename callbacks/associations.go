@@ -1,6 +1,7 @@
 package callbacks
 
 import (
+	"fmt"
 	"reflect"
 	"strings"
 
@@ -10,11 +11,37 @@ import (
 	"gorm.io/gorm/utils"
 )
 
+// AssociationResultsKey instance setting key of the per-association results collected while saving associations,
+// e.g. db.InstanceGet(callbacks.AssociationResultsKey) returns []callbacks.AssociationResult
+const AssociationResultsKey = "gorm:association_results"
+
+// AssociationResult result of a statement executed when saving an association
+type AssociationResult struct {
+	Relation     string
+	RowsAffected int64
+	Error        error
+}
+
+func appendAssociationResult(db *gorm.DB, relation string, tx *gorm.DB) {
+	var results []AssociationResult
+	if v, ok := db.InstanceGet(AssociationResultsKey); ok {
+		results, _ = v.([]AssociationResult)
+	}
+
+	db.InstanceSet(AssociationResultsKey, append(results, AssociationResult{
+		Relation:     relation,
+		RowsAffected: tx.RowsAffected,
+		Error:        tx.Error,
+	}))
+}
+
 // create: 是否是 create 的回调
 func SaveBeforeAssociations(create bool) func(db *gorm.DB) {
 	return func(db *gorm.DB) {
 		if db.Error == nil && db.Statement.Schema != nil {
 			selectColumns, restricted := db.Statement.SelectAndOmitColumns(create, !create)
+			// 更新时只保存 Select 指定的关联
+			restricted = restricted || (!create && db.DisableAssociationSaveOnUpdate && !db.FullSaveAssociations)
 
 			// Save Belongs To associations
 			for _, rel := range db.Statement.Schema.Relationships.BelongsTo {
@@ -112,6 +139,8 @@ func SaveAfterAssociations(create bool) func(db *gorm.DB) {
 	return func(db *gorm.DB) {
 		if db.Error == nil && db.Statement.Schema != nil {
 			selectColumns, restricted := db.Statement.SelectAndOmitColumns(create, !create)
+			// 更新时只保存 Select 指定的关联
+			restricted = restricted || (!create && db.DisableAssociationSaveOnUpdate && !db.FullSaveAssociations)
 
 			// Save Has One associations
 			for _, rel := range db.Statement.Schema.Relationships.HasOne {
@@ -348,10 +377,12 @@ func SaveAfterAssociations(create bool) func(db *gorm.DB) {
 				}
 
 				if joins.Len() > 0 {
-					db.AddError(db.Session(&gorm.Session{NewDB: true}).Clauses(clause.OnConflict{DoNothing: true}).Session(&gorm.Session{
+					tx := db.Session(&gorm.Session{NewDB: true}).Clauses(clause.OnConflict{DoNothing: true}).Session(&gorm.Session{
 						SkipHooks:                db.Statement.SkipHooks,
 						DisableNestedTransaction: true,
-					}).Create(joins.Interface()).Error)
+					}).Create(joins.Interface())
+					appendAssociationResult(db, rel.JoinTable.Name, tx)
+					db.AddError(tx.Error)
 				}
 			}
 		}
@@ -389,6 +420,26 @@ func saveAssociations(db *gorm.DB, rel *schema.Relationship, rValues reflect.Val
 		values         = rValues.Interface()
 	)
 
+	// foreign keys referencing the owner must be saved to keep the association, omitting them like Omit("Pets.UserID") is an error
+	var referenceFields []*schema.Field
+	for _, ref := range rel.References {
+		if ref.ForeignKey.Schema == rel.FieldSchema {
+			referenceFields = append(referenceFields, ref.ForeignKey)
+		}
+	}
+
+	isReferenceColumn := func(name string) bool {
+		if field := rel.FieldSchema.LookUpField(name); field != nil {
+			for _, referenceField := range referenceFields {
+				if field == referenceField {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	// nested columns like `Pets.Toy.Name` are passed down as `Toy.Name`
 	for name, ok := range selectColumns {
 		columnName := ""
 		if strings.HasPrefix(name, refName) {
@@ -398,12 +449,20 @@ func saveAssociations(db *gorm.DB, rel *schema.Relationship, rValues reflect.Val
 		if columnName != "" {
 			if ok {
 				selects = append(selects, columnName)
+			} else if isReferenceColumn(columnName) {
+				return db.AddError(fmt.Errorf("%w: foreign key %s of association %s can't be omitted", gorm.ErrInvalidField, columnName, rel.Name))
 			} else {
 				omits = append(omits, columnName)
 			}
 		}
 	}
 
+	if len(selects) > 0 {
+		for _, referenceField := range referenceFields {
+			selects = append(selects, referenceField.Name)
+		}
+	}
+
 	tx := db.Session(&gorm.Session{NewDB: true}).Clauses(onConflict).Session(&gorm.Session{
 		FullSaveAssociations:     db.FullSaveAssociations,
 		SkipHooks:                db.Statement.SkipHooks,
@@ -429,7 +488,9 @@ func saveAssociations(db *gorm.DB, rel *schema.Relationship, rValues reflect.Val
 		tx = tx.Omit(omits...)
 	}
 
-	return db.AddError(tx.Create(values).Error)
+	tx = tx.Create(values)
+	appendAssociationResult(db, rel.Name, tx)
+	return db.AddError(tx.Error)
 }
 
 // check association values has been saved
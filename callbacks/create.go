@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -49,13 +50,27 @@ func Create(config *Config) func(db *gorm.DB) {
 				}
 			}
 
-			if supportReturning && len(db.Statement.Schema.FieldsWithDefaultDBValue) > 0 { // 如果支持 Returning， 并且 model 存在有默认值的属性
+			if supportReturning { // 如果支持 Returning
 				if _, ok := db.Statement.Clauses["RETURNING"]; !ok { // 没有 returning clause, 默认取所有有默认值的属性构建一个 Returning Clause
 					fromColumns := make([]clause.Column, 0, len(db.Statement.Schema.FieldsWithDefaultDBValue))
 					for _, field := range db.Statement.Schema.FieldsWithDefaultDBValue {
-						fromColumns = append(fromColumns, clause.Column{Name: field.DBName})
+						if !fillNowInApp(db.Statement, field) {
+							fromColumns = append(fromColumns, clause.Column{Name: field.DBName})
+						}
+					}
+
+					// 数据库生成的自动时间、生成列，通过 RETURNING 回填
+					supportGeneratedColumn := db.Statement.SupportGeneratedColumn()
+					for _, field := range db.Statement.Schema.Fields {
+						if isDefaultDBValue := field.HasDefaultValue && field.DefaultValueInterface == nil; field.DBTime && field.DBName != "" && (!isDefaultDBValue || fillNowInApp(db.Statement, field)) {
+							fromColumns = append(fromColumns, clause.Column{Name: field.DBName})
+						} else if field.Generated != "" && field.DBName != "" && supportGeneratedColumn {
+							fromColumns = append(fromColumns, clause.Column{Name: field.DBName})
+						}
+					}
+					if len(fromColumns) > 0 {
+						db.Statement.AddClause(clause.Returning{Columns: fromColumns})
 					}
-					db.Statement.AddClause(clause.Returning{Columns: fromColumns})
 				}
 			}
 		}
@@ -63,10 +78,11 @@ func Create(config *Config) func(db *gorm.DB) {
 		if db.Statement.SQL.Len() == 0 {
 			db.Statement.SQL.Grow(180)
 			db.Statement.AddClauseIfNotExists(clause.Insert{}) // 没有 Insert 加个默认的
-			db.Statement.AddClause(ConvertToCreateValues(db.Statement))
+			db.Statement.AddClause(convertToCreateValues(db.Statement, true))
 
 			db.Statement.Build(db.Statement.BuildClauses...)
 		}
+		db.Statement.RewriteSQL()
 
 		isDryRun := !db.DryRun && db.Error == nil
 		if !isDryRun {
@@ -106,6 +122,14 @@ func Create(config *Config) func(db *gorm.DB) {
 		if db.RowsAffected != 0 && db.Statement.Schema != nil &&
 			db.Statement.Schema.PrioritizedPrimaryField != nil &&
 			db.Statement.Schema.PrioritizedPrimaryField.HasDefaultValue {
+			// 非整数主键（如 uuid）的默认值由数据库生成，LastInsertId 没有意义
+			if dataType := db.Statement.Schema.PrioritizedPrimaryField.GORMDataType; dataType != schema.Int && dataType != schema.Uint {
+				if db.FetchGeneratedKeys {
+					fetchGeneratedKeys(db)
+				}
+				return
+			}
+
 			insertID, err := result.LastInsertId()
 			insertOk := err == nil && insertID > 0
 			if !insertOk {
@@ -151,6 +175,60 @@ func Create(config *Config) func(db *gorm.DB) {
 	}
 }
 
+// fetchGeneratedKeys fills primary keys generated by database, queries created records with unique fields,
+// composite unique indexes are used if no unique field has value
+func fetchGeneratedKeys(db *gorm.DB) {
+	var (
+		stmt         = db.Statement
+		primaryField = stmt.Schema.PrioritizedPrimaryField
+		uniqueFields = stmt.Schema.UniqueFields
+	)
+
+	fetch := func(rv reflect.Value) {
+		if _, isZero := primaryField.ValueOf(stmt.Context, rv); !isZero {
+			return
+		}
+
+		for _, fields := range uniqueFields {
+			exprs := make([]clause.Expression, 0, len(fields))
+			for _, field := range fields {
+				value, isZero := field.ValueOf(stmt.Context, rv)
+				if isZero {
+					exprs = nil
+					break
+				}
+				exprs = append(exprs, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: value})
+			}
+
+			if len(exprs) == 0 {
+				continue
+			}
+
+			var key interface{}
+			tx := db.Session(&gorm.Session{NewDB: true}).Table(stmt.Table).Select(primaryField.DBName).Where(clause.Where{Exprs: exprs}).Limit(1)
+			if err := tx.Row().Scan(&key); err != nil {
+				db.AddError(fmt.Errorf("failed to fetch generated primary key of %s, got error: %w", stmt.Schema.Name, err))
+			} else {
+				db.AddError(primaryField.Set(stmt.Context, rv, key))
+			}
+			return
+		}
+
+		db.AddError(fmt.Errorf("failed to fetch generated primary key of %s, no unique fields have value", stmt.Schema.Name))
+	}
+
+	switch stmt.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < stmt.ReflectValue.Len() && db.Error == nil; i++ {
+			if rv := reflect.Indirect(stmt.ReflectValue.Index(i)); rv.Kind() == reflect.Struct {
+				fetch(rv)
+			}
+		}
+	case reflect.Struct:
+		fetch(stmt.ReflectValue)
+	}
+}
+
 // AfterCreate after create hooks
 func AfterCreate(db *gorm.DB) {
 	if db.Error == nil && db.Statement.Schema != nil && !db.Statement.SkipHooks && (db.Statement.Schema.AfterSave || db.Statement.Schema.AfterCreate) {
@@ -173,8 +251,27 @@ func AfterCreate(db *gorm.DB) {
 	}
 }
 
+// currentDBTime the current time expression of the database for DBTime fields, see gorm.CurrentTimestamper
+func currentDBTime(stmt *gorm.Statement) clause.Expression {
+	if timestamper, ok := stmt.Dialector.(gorm.CurrentTimestamper); ok {
+		return timestamper.CurrentTimestamp()
+	}
+	return clause.Expr{SQL: "CURRENT_TIMESTAMP"}
+}
+
+// fillNowInApp the current time default value of field is filled by NowFunc instead of the database, see Config.FillTimeDefaultsInApp
+func fillNowInApp(stmt *gorm.Statement, field *schema.Field) bool {
+	return field.DefaultNow && stmt.DB.FillTimeDefaultsInApp
+}
+
 // ConvertToCreateValues convert to create values 从 dest 里面转换出 Values
 func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
+	return convertToCreateValues(stmt, false)
+}
+
+// convertToCreateValues converts dest to values, values of slices are provided by Rows lazily
+// if streamable and StreamedInsert is enabled, see Config.StreamedInsert
+func convertToCreateValues(stmt *gorm.Statement, streamable bool) (values clause.Values) {
 	curTime := stmt.DB.NowFunc()
 
 	switch value := stmt.Dest.(type) {
@@ -190,7 +287,6 @@ func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 		var (
 			selectColumns, restricted = stmt.SelectAndOmitColumns(true, false)
 			_, updateTrackTime        = stmt.Get("gorm:update_track_time")
-			isZero                    bool
 		)
 		stmt.Settings.Delete("gorm:update_track_time")
 
@@ -198,8 +294,8 @@ func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 
 		for _, db := range stmt.Schema.DBNames {
 			// 如果该字段没有默认值，或者是有默认值但是显式定义了默认值，不是空或者是函数
-			if field := stmt.Schema.FieldsByDBName[db]; !field.HasDefaultValue || field.DefaultValueInterface != nil {
-				if v, ok := selectColumns[db]; (ok && v) || (!ok && (!restricted || field.AutoCreateTime > 0 || field.AutoUpdateTime > 0)) {
+			if field := stmt.Schema.FieldsByDBName[db]; !field.HasDefaultValue || field.DefaultValueInterface != nil || fillNowInApp(stmt, field) {
+				if v, ok := selectColumns[db]; (ok && v) || (!ok && (!restricted || field.AutoCreateTime > 0 || field.AutoUpdateTime > 0 || fillNowInApp(stmt, field))) {
 					// 如果通过 select 显式指定，加到 values 里面
 					// 如果没有指定，以下情况也加进去
 					// 1. 非严格模式，(严格模式：不带 * ，并且指定了 select)
@@ -220,6 +316,12 @@ func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 
 			stmt.SQL.Grow(rValLen * 18)
 			stmt.Vars = make([]interface{}, 0, rValLen*len(values.Columns))
+
+			if streamable && (stmt.DB.StreamedInsert || (stmt.DB.StreamedInsertThreshold > 0 && rValLen > stmt.DB.StreamedInsertThreshold)) {
+				values = streamCreateValues(stmt, values.Columns, selectColumns, restricted, curTime, updateTrackTime)
+				break
+			}
+
 			values.Values = make([][]interface{}, rValLen)
 
 			defaultValueFieldsHavingValue := map[*schema.Field][]interface{}{}
@@ -232,23 +334,11 @@ func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 
 				values.Values[i] = make([]interface{}, len(values.Columns))
 				for idx, column := range values.Columns {
-					field := stmt.Schema.FieldsByDBName[column.Name]
-					if values.Values[i][idx], isZero = field.ValueOf(stmt.Context, rv); isZero {
-						if field.DefaultValueInterface != nil {
-							values.Values[i][idx] = field.DefaultValueInterface
-							stmt.AddError(field.Set(stmt.Context, rv, field.DefaultValueInterface))
-						} else if field.AutoCreateTime > 0 || field.AutoUpdateTime > 0 {
-							stmt.AddError(field.Set(stmt.Context, rv, curTime))
-							values.Values[i][idx], _ = field.ValueOf(stmt.Context, rv)
-						}
-					} else if field.AutoUpdateTime > 0 && updateTrackTime {
-						stmt.AddError(field.Set(stmt.Context, rv, curTime))
-						values.Values[i][idx], _ = field.ValueOf(stmt.Context, rv)
-					}
+					values.Values[i][idx] = createValueOf(stmt, stmt.Schema.FieldsByDBName[column.Name], rv, curTime, updateTrackTime)
 				}
 
 				for _, field := range stmt.Schema.FieldsWithDefaultDBValue {
-					if v, ok := selectColumns[field.DBName]; (ok && v) || (!ok && !restricted) {
+					if v, ok := selectColumns[field.DBName]; ((ok && v) || (!ok && !restricted)) && !fillNowInApp(stmt, field) {
 						if rvOfvalue, isZero := field.ValueOf(stmt.Context, rv); !isZero {
 							if len(defaultValueFieldsHavingValue[field]) == 0 {
 								defaultValueFieldsHavingValue[field] = make([]interface{}, rValLen)
@@ -272,23 +362,11 @@ func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 		case reflect.Struct:
 			values.Values = [][]interface{}{make([]interface{}, len(values.Columns))}
 			for idx, column := range values.Columns {
-				field := stmt.Schema.FieldsByDBName[column.Name]
-				if values.Values[0][idx], isZero = field.ValueOf(stmt.Context, stmt.ReflectValue); isZero { // 如果选中的字段是空值
-					if field.DefaultValueInterface != nil { // 带了显式的默认值
-						values.Values[0][idx] = field.DefaultValueInterface
-						stmt.AddError(field.Set(stmt.Context, stmt.ReflectValue, field.DefaultValueInterface))
-					} else if field.AutoCreateTime > 0 || field.AutoUpdateTime > 0 { // 如果是设置了 AutoCreateTime 或者 AutoUpdateTime
-						stmt.AddError(field.Set(stmt.Context, stmt.ReflectValue, curTime)) // 设置为当前时间
-						values.Values[0][idx], _ = field.ValueOf(stmt.Context, stmt.ReflectValue)
-					}
-				} else if field.AutoUpdateTime > 0 && updateTrackTime {
-					stmt.AddError(field.Set(stmt.Context, stmt.ReflectValue, curTime))
-					values.Values[0][idx], _ = field.ValueOf(stmt.Context, stmt.ReflectValue)
-				}
+				values.Values[0][idx] = createValueOf(stmt, stmt.Schema.FieldsByDBName[column.Name], stmt.ReflectValue, curTime, updateTrackTime)
 			}
 
 			for _, field := range stmt.Schema.FieldsWithDefaultDBValue {
-				if v, ok := selectColumns[field.DBName]; (ok && v) || (!ok && !restricted) {
+				if v, ok := selectColumns[field.DBName]; ((ok && v) || (!ok && !restricted)) && !fillNowInApp(stmt, field) {
 					if rvOfvalue, isZero := field.ValueOf(stmt.Context, stmt.ReflectValue); !isZero {
 						values.Columns = append(values.Columns, clause.Column{Name: field.DBName})
 						values.Values[0] = append(values.Values[0], rvOfvalue)
@@ -313,12 +391,14 @@ func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 								strings.EqualFold(field.DefaultValue, "NULL")) && field.AutoCreateTime == 0 {
 								if field.AutoUpdateTime > 0 {
 									assignment := clause.Assignment{Column: clause.Column{Name: field.DBName}, Value: curTime}
-									switch field.AutoUpdateTime {
-									case schema.UnixNanosecond:
+									switch {
+									case field.DBTime:
+										assignment.Value = currentDBTime(stmt)
+									case field.AutoUpdateTime == schema.UnixNanosecond:
 										assignment.Value = curTime.UnixNano()
-									case schema.UnixMillisecond:
+									case field.AutoUpdateTime == schema.UnixMillisecond:
 										assignment.Value = curTime.UnixNano() / 1e6
-									case schema.UnixSecond:
+									case field.AutoUpdateTime == schema.UnixSecond:
 										assignment.Value = curTime.Unix()
 									}
 
@@ -337,7 +417,7 @@ func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 				}
 
 				// use primary fields as default OnConflict columns
-				if len(onConflict.Columns) == 0 {
+				if len(onConflict.Columns) == 0 && onConflict.OnConstraint == "" {
 					for _, field := range stmt.Schema.PrimaryFields {
 						onConflict.Columns = append(onConflict.Columns, clause.Column{Name: field.DBName})
 					}
@@ -349,3 +429,101 @@ func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 
 	return values
 }
+
+// createValueOf returns the value of field in rv to create, zero values are filled with the default value
+// or the current time, which are also set back to rv
+func createValueOf(stmt *gorm.Statement, field *schema.Field, rv reflect.Value, curTime time.Time, updateTrackTime bool) interface{} {
+	value, isZero := field.ValueOf(stmt.Context, rv)
+	if isZero { // 如果选中的字段是空值
+		if field.DefaultValueInterface != nil { // 带了显式的默认值
+			value = field.DefaultValueInterface
+			stmt.AddError(field.Set(stmt.Context, rv, field.DefaultValueInterface))
+		} else if field.DefaultValueFunc != nil {
+			stmt.AddError(field.Set(stmt.Context, rv, field.DefaultValueFunc(stmt.Context)))
+			value, _ = field.ValueOf(stmt.Context, rv)
+		} else if field.DBTime { // 使用数据库的当前时间，不设置字段
+			return currentDBTime(stmt)
+		} else if field.AutoCreateTime > 0 || field.AutoUpdateTime > 0 || fillNowInApp(stmt, field) { // 如果是设置了 AutoCreateTime 或者 AutoUpdateTime
+			stmt.AddError(field.Set(stmt.Context, rv, curTime)) // 设置为当前时间
+			value, _ = field.ValueOf(stmt.Context, rv)
+		}
+	} else if field.AutoUpdateTime > 0 && updateTrackTime {
+		if field.DBTime {
+			return currentDBTime(stmt)
+		}
+		stmt.AddError(field.Set(stmt.Context, rv, curTime))
+		value, _ = field.ValueOf(stmt.Context, rv)
+	}
+	return value
+}
+
+// streamCreateValues returns values of the slice to create whose rows are provided lazily by createRows,
+// fields with default database values are added to columns if any row has value
+func streamCreateValues(stmt *gorm.Statement, columns []clause.Column, selectColumns map[string]bool, restricted bool, curTime time.Time, updateTrackTime bool) clause.Values {
+	rows := &createRows{stmt: stmt, fields: make([]*schema.Field, 0, len(columns)), curTime: curTime, updateTrackTime: updateTrackTime}
+	for _, column := range columns {
+		rows.fields = append(rows.fields, stmt.Schema.FieldsByDBName[column.Name])
+	}
+
+	// 预先遍历一次，校验数据并找出有值的数据库默认值字段，不保存任何值
+	var defaultFields []*schema.Field
+	for _, field := range stmt.Schema.FieldsWithDefaultDBValue {
+		if v, ok := selectColumns[field.DBName]; ((ok && v) || (!ok && !restricted)) && !fillNowInApp(stmt, field) {
+			defaultFields = append(defaultFields, field)
+		}
+	}
+
+	havingValue := make([]bool, len(defaultFields))
+	for i := 0; i < stmt.ReflectValue.Len(); i++ {
+		rv := reflect.Indirect(stmt.ReflectValue.Index(i))
+		if !rv.IsValid() {
+			stmt.AddError(fmt.Errorf("slice data #%v is invalid: %w", i, gorm.ErrInvalidData))
+			return clause.Values{}
+		}
+
+		for idx, field := range defaultFields {
+			if !havingValue[idx] {
+				_, isZero := field.ValueOf(stmt.Context, rv)
+				havingValue[idx] = !isZero
+			}
+		}
+	}
+
+	for idx, field := range defaultFields {
+		if havingValue[idx] {
+			rows.defaultFields = append(rows.defaultFields, field)
+			columns = append(columns, clause.Column{Name: field.DBName})
+		}
+	}
+
+	return clause.Values{Columns: columns, Rows: rows}
+}
+
+// createRows provides values of rows of the slice to create when building VALUES, see Config.StreamedInsert
+type createRows struct {
+	stmt            *gorm.Statement
+	fields          []*schema.Field
+	defaultFields   []*schema.Field // fields with default database values, which have value in some rows
+	curTime         time.Time
+	updateTrackTime bool
+}
+
+func (rows *createRows) Len() int {
+	return rows.stmt.ReflectValue.Len()
+}
+
+func (rows *createRows) Row(dst []interface{}, idx int) []interface{} {
+	rv := reflect.Indirect(rows.stmt.ReflectValue.Index(idx))
+	for _, field := range rows.fields {
+		dst = append(dst, createValueOf(rows.stmt, field, rv, rows.curTime, rows.updateTrackTime))
+	}
+
+	for _, field := range rows.defaultFields {
+		if value, isZero := field.ValueOf(rows.stmt.Context, rv); isZero {
+			dst = append(dst, rows.stmt.Dialector.DefaultValueOf(field))
+		} else {
+			dst = append(dst, value)
+		}
+	}
+	return dst
+}
@@ -23,20 +23,32 @@ func BeforeDelete(db *gorm.DB) {
 	}
 }
 
+// cascadeDeleteVisitedKey records deleted records when deleting associations recursively
+const cascadeDeleteVisitedKey = "gorm:cascade_delete_visited"
+
 func DeleteBeforeAssociations(db *gorm.DB) {
 	if db.Error == nil && db.Statement.Schema != nil {
 		selectColumns, restricted := db.Statement.SelectAndOmitColumns(true, false)
-		if !restricted {
+		if !restricted && !db.CascadeDelete {
 			return
 		}
 
-		for column, v := range selectColumns {
-			if !v {
+		// 记录已删除的数据，避免自关联的数据无限递归
+		visited, _ := db.Get(cascadeDeleteVisitedKey)
+		visitedKeys, ok := visited.(map[string]bool)
+		if !ok {
+			visitedKeys = map[string]bool{}
+			markCascadeDeleteVisited(db, db.Statement.Schema, db.Statement.ReflectValue, visitedKeys)
+		}
+
+		for column, rel := range db.Statement.Schema.Relationships.Relations {
+			// 跳过 has one, has many 在关联 schema 上记录的反向关系
+			if rel.Schema != db.Statement.Schema {
 				continue
 			}
 
-			rel, ok := db.Statement.Schema.Relationships.Relations[column]
-			if !ok {
+			// 级联删除时，除非被 Omit，所有关联都会删除
+			if v, ok := selectColumns[column]; (ok && !v) || (!ok && !db.CascadeDelete) {
 				continue
 			}
 
@@ -50,19 +62,18 @@ func DeleteBeforeAssociations(db *gorm.DB) {
 					tx = tx.Unscoped()
 				}
 
+				var selects []string
 				if len(db.Statement.Selects) > 0 {
-					selects := make([]string, 0, len(db.Statement.Selects))
 					for _, s := range db.Statement.Selects {
+						// 只有级联删除时，才会删除关联的所有关联
 						if s == clause.Associations {
-							selects = append(selects, s)
+							if db.CascadeDelete {
+								selects = append(selects, s)
+							}
 						} else if columnPrefix := column + "."; strings.HasPrefix(s, columnPrefix) {
 							selects = append(selects, strings.TrimPrefix(s, columnPrefix))
 						}
 					}
-
-					if len(selects) > 0 {
-						tx = tx.Select(selects)
-					}
 				}
 
 				for _, cond := range queryConds {
@@ -72,7 +83,37 @@ func DeleteBeforeAssociations(db *gorm.DB) {
 					}
 				}
 
-				if !withoutConditions && db.AddError(tx.Clauses(clause.Where{Exprs: queryConds}).Delete(modelValue).Error) != nil {
+				if withoutConditions {
+					continue
+				}
+
+				if len(selects) == 0 && !db.CascadeDelete {
+					if db.AddError(tx.Clauses(clause.Where{Exprs: queryConds}).Delete(modelValue).Error) != nil {
+						return
+					}
+					continue
+				}
+
+				// 需要递归删除时，先查出关联数据，再以其主键删除，子级会先于父级删除
+				children := reflect.New(reflect.SliceOf(reflect.PtrTo(rel.FieldSchema.ModelType)))
+				if db.AddError(tx.Clauses(clause.Where{Exprs: queryConds}).Find(children.Interface()).Error) != nil {
+					return
+				}
+
+				if children = filterCascadeDeleteVisited(db, rel.FieldSchema, children.Elem(), visitedKeys); children.Elem().Len() == 0 {
+					continue
+				}
+
+				deleteTx := db.Session(&gorm.Session{NewDB: true}).Set(cascadeDeleteVisitedKey, visitedKeys)
+				if db.Statement.Unscoped {
+					deleteTx = deleteTx.Unscoped()
+				}
+
+				if len(selects) > 0 {
+					deleteTx = deleteTx.Select(selects)
+				}
+
+				if db.AddError(deleteTx.Delete(children.Interface()).Error) != nil {
 					return
 				}
 			case schema.Many2Many:
@@ -110,8 +151,60 @@ func DeleteBeforeAssociations(db *gorm.DB) {
 	}
 }
 
+// markCascadeDeleteVisited marks records of reflectValue as deleted
+func markCascadeDeleteVisited(db *gorm.DB, s *schema.Schema, reflectValue reflect.Value, visitedKeys map[string]bool) {
+	switch reflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < reflectValue.Len(); i++ {
+			if key, ok := cascadeDeleteKey(db, s, reflectValue.Index(i)); ok {
+				visitedKeys[key] = true
+			}
+		}
+	case reflect.Struct:
+		if key, ok := cascadeDeleteKey(db, s, reflectValue); ok {
+			visitedKeys[key] = true
+		}
+	}
+}
+
+// filterCascadeDeleteVisited removes deleted records from children, and marks the rest as deleted
+func filterCascadeDeleteVisited(db *gorm.DB, s *schema.Schema, children reflect.Value, visitedKeys map[string]bool) reflect.Value {
+	results := reflect.New(children.Type())
+	for i := 0; i < children.Len(); i++ {
+		elem := children.Index(i)
+		if key, ok := cascadeDeleteKey(db, s, elem); ok {
+			if visitedKeys[key] {
+				continue
+			}
+			visitedKeys[key] = true
+		}
+		results.Elem().Set(reflect.Append(results.Elem(), elem))
+	}
+	return results
+}
+
+// cascadeDeleteKey returns the identity key of record, returns false if its primary key is zero
+func cascadeDeleteKey(db *gorm.DB, s *schema.Schema, reflectValue reflect.Value) (string, bool) {
+	reflectValue = reflect.Indirect(reflectValue)
+	if !reflectValue.IsValid() || len(s.PrimaryFields) == 0 {
+		return "", false
+	}
+
+	values := make([]interface{}, 0, len(s.PrimaryFields)+1)
+	values = append(values, s.Table)
+	for _, field := range s.PrimaryFields {
+		value, isZero := field.ValueOf(db.Statement.Context, reflectValue)
+		if isZero {
+			return "", false
+		}
+		values = append(values, value)
+	}
+	return utils.ToStringKey(values...), true
+}
+
 func Delete(config *Config) func(db *gorm.DB) {
 	supportReturning := utils.Contains(config.DeleteClauses, "RETURNING")
+	supportUpdateReturning := utils.Contains(config.UpdateClauses, "RETURNING")
 
 	return func(db *gorm.DB) {
 		if db.Error != nil {
@@ -152,9 +245,24 @@ func Delete(config *Config) func(db *gorm.DB) {
 		}
 
 		checkMissingWhereConditions(db)
+		db.Statement.RewriteSQL()
 
 		if !db.DryRun && db.Error == nil {
-			ok, mode := hasReturning(db, supportReturning)
+			// 在同一事务中先查询删除前的旧值
+			if db.Statement.PreviousDest != nil {
+				defer selectPrevious(db)()
+				if db.Error != nil {
+					return
+				}
+			}
+
+			// 软删除会构建为 UPDATE 语句，是否支持 RETURNING 取决于更新语句
+			returning := supportReturning
+			if _, ok := db.Statement.Clauses["UPDATE"]; ok {
+				returning = supportUpdateReturning
+			}
+
+			ok, mode := hasReturning(db, returning)
 			if !ok {
 				result, err := db.Statement.ConnPool.ExecContext(db.Statement.Context, db.Statement.SQL.String(), db.Statement.Vars...)
 				if db.AddError(err) == nil {
@@ -1,14 +1,17 @@
 package callbacks
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
 	"sort"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
 )
 
-// ConvertMapToValuesForCreate convert map to values
+// ConvertMapToValuesForCreate convert map to values, columns are sorted by their db names
 func ConvertMapToValuesForCreate(stmt *gorm.Statement, mapValue map[string]interface{}) (values clause.Values) {
 	values.Columns = make([]clause.Column, 0, len(mapValue))
 	selectColumns, restricted := stmt.SelectAndOmitColumns(true, false)
@@ -19,6 +22,9 @@ func ConvertMapToValuesForCreate(stmt *gorm.Statement, mapValue map[string]inter
 	}
 	sort.Strings(keys)
 
+	// 先转换为列名再排序，字段名和列名混用时顺序也是确定的
+	result := make(map[string]interface{}, len(keys))
+	columns := make([]string, 0, len(keys))
 	for _, k := range keys {
 		value := mapValue[k]
 		if stmt.Schema != nil {
@@ -28,18 +34,26 @@ func ConvertMapToValuesForCreate(stmt *gorm.Statement, mapValue map[string]inter
 		}
 
 		if v, ok := selectColumns[k]; (ok && v) || (!ok && !restricted) {
-			values.Columns = append(values.Columns, clause.Column{Name: k})
-			if len(values.Values) == 0 {
-				values.Values = [][]interface{}{{}}
+			if _, ok := result[k]; !ok {
+				columns = append(columns, k)
 			}
+			result[k] = value
+		}
+	}
+	sort.Strings(columns)
 
-			values.Values[0] = append(values.Values[0], value)
+	for _, column := range columns {
+		values.Columns = append(values.Columns, clause.Column{Name: column})
+		if len(values.Values) == 0 {
+			values.Values = [][]interface{}{{}}
 		}
+
+		values.Values[0] = append(values.Values[0], result[column])
 	}
 	return
 }
 
-// ConvertSliceOfMapToValuesForCreate convert slice of map to values
+// ConvertSliceOfMapToValuesForCreate convert slice of map to values, all maps should have the same columns
 func ConvertSliceOfMapToValuesForCreate(stmt *gorm.Statement, mapValues []map[string]interface{}) (values clause.Values) {
 	columns := make([]string, 0, len(mapValues))
 
@@ -52,6 +66,7 @@ func ConvertSliceOfMapToValuesForCreate(stmt *gorm.Statement, mapValues []map[st
 
 	var (
 		result                    = make(map[string][]interface{}, len(mapValues))
+		assigned                  = make(map[string][]bool, len(mapValues))
 		selectColumns, restricted = stmt.SelectAndOmitColumns(true, false)
 	)
 
@@ -66,6 +81,7 @@ func ConvertSliceOfMapToValuesForCreate(stmt *gorm.Statement, mapValues []map[st
 			if _, ok := result[k]; !ok {
 				if v, ok := selectColumns[k]; (ok && v) || (!ok && !restricted) {
 					result[k] = make([]interface{}, len(mapValues))
+					assigned[k] = make([]bool, len(mapValues))
 					columns = append(columns, k)
 				} else {
 					continue
@@ -73,10 +89,20 @@ func ConvertSliceOfMapToValuesForCreate(stmt *gorm.Statement, mapValues []map[st
 			}
 
 			result[k][idx] = v
+			assigned[k][idx] = true
 		}
 	}
 
 	sort.Strings(columns)
+	for _, column := range columns {
+		for idx, ok := range assigned[column] {
+			if !ok {
+				stmt.AddError(fmt.Errorf("%w: map at index %d has no value for column %s", gorm.ErrInconsistentMapKeys, idx, column))
+				return
+			}
+		}
+	}
+
 	values.Values = make([][]interface{}, len(mapValues))
 	values.Columns = make([]clause.Column, len(columns))
 	for idx, column := range columns {
@@ -150,3 +176,96 @@ func loadOrStoreVisitMap(visitMap *visitMap, v reflect.Value) (loaded bool) {
 
 	return
 }
+
+// previousColumns returns the columns to capture the previous values, field names are converted to column names
+func previousColumns(stmt *gorm.Statement) []string {
+	columns := make([]string, 0, len(stmt.PreviousColumns))
+	for _, column := range stmt.PreviousColumns {
+		if stmt.Schema != nil {
+			if field := stmt.Schema.LookUpField(column); field != nil && field.DBName != "" {
+				column = field.DBName
+			}
+		}
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+// addPreviousReturning returns the previous values with RETURNING if the dialector supports it and the statement doesn't use RETURNING
+func addPreviousReturning(db *gorm.DB, supportReturning bool) bool {
+	if db.Statement.PreviousDest == nil || len(db.Statement.PreviousColumns) == 0 || !supportReturning {
+		return false
+	}
+
+	returner, ok := db.Dialector.(gorm.PreviousValuesReturner)
+	if !ok {
+		return false
+	}
+
+	if _, ok := db.Statement.Clauses["RETURNING"]; ok {
+		return false
+	}
+
+	var returning clause.Returning
+	for _, column := range previousColumns(db.Statement) {
+		returning.Columns = append(returning.Columns, returner.PreviousValueColumn(column))
+	}
+	db.Statement.AddClause(returning)
+	return true
+}
+
+// scanPrevious scans the previous values returned by RETURNING into PreviousDest
+func scanPrevious(db *gorm.DB, rows gorm.Rows) {
+	tx := db.Session(&gorm.Session{NewDB: true})
+	tx.Statement.Dest = db.Statement.PreviousDest
+	if err := tx.Statement.Parse(tx.Statement.Dest); err != nil && !errors.Is(err, schema.ErrUnsupportedDataType) {
+		db.AddError(err)
+		return
+	}
+
+	tx.Statement.ReflectValue = reflect.ValueOf(tx.Statement.Dest)
+	for tx.Statement.ReflectValue.Kind() == reflect.Ptr {
+		tx.Statement.ReflectValue = tx.Statement.ReflectValue.Elem()
+	}
+
+	gorm.Scan(rows, tx, 0)
+	db.RowsAffected = tx.RowsAffected
+	db.AddError(tx.Error)
+}
+
+// selectPrevious selects the previous values into PreviousDest with the conditions of the statement FOR UPDATE,
+// begins a transaction if the statement isn't executed in a transaction, call finish after executing the statement
+// to commit or rollback it
+func selectPrevious(db *gorm.DB) (finish func()) {
+	finish = func() {}
+	if committer, ok := db.Statement.ConnPool.(gorm.TxCommitter); !ok || committer == nil {
+		if tx := db.Begin(); tx.Error == nil {
+			connPool := db.Statement.ConnPool
+			db.Statement.ConnPool = tx.Statement.ConnPool
+			finish = func() {
+				if db.Error != nil {
+					tx.Rollback()
+				} else {
+					db.AddError(tx.Commit().Error)
+				}
+				db.Statement.ConnPool = connPool
+			}
+		} else if tx.Error != gorm.ErrInvalidTransaction {
+			db.AddError(tx.Error)
+			return
+		}
+	}
+
+	selectTx := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Unscoped()
+	selectTx.Statement.Table, selectTx.Statement.TableExpr = db.Statement.Table, db.Statement.TableExpr
+	if where, ok := db.Statement.Clauses["WHERE"]; ok {
+		selectTx = selectTx.Clauses(where.Expression)
+	}
+
+	if columns := previousColumns(db.Statement); len(columns) > 0 {
+		selectTx = selectTx.Select(columns)
+	}
+
+	db.AddError(selectTx.Clauses(clause.Locking{Strength: "UPDATE"}).Find(db.Statement.PreviousDest).Error)
+	return
+}
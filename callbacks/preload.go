@@ -1,8 +1,10 @@
 package callbacks
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 
 	"gorm.io/gorm"
@@ -87,7 +89,13 @@ func preloadEmbedded(tx *gorm.DB, relationships *schema.Relationships, s *schema
 		return nil
 	}
 	preloadMap := parsePreloadMap(s, preloads)
+	names := make([]string, 0, len(preloadMap))
 	for name := range preloadMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
 		if embeddedRelations := relationships.EmbeddedRelations[name]; embeddedRelations != nil {
 			if err := preloadEmbedded(tx, embeddedRelations, s, preloadMap[name], as); err != nil {
 				return err
@@ -105,22 +113,19 @@ func preloadEmbedded(tx *gorm.DB, relationships *schema.Relationships, s *schema
 
 func preload(tx *gorm.DB, rel *schema.Relationship, conds []interface{}, preloads map[string][]interface{}) error {
 	var (
-		reflectValue     = tx.Statement.ReflectValue
-		relForeignKeys   []string
-		relForeignFields []*schema.Field
-		foreignFields    []*schema.Field
-		foreignValues    [][]interface{}
-		identityMap      = map[string][]reflect.Value{}
-		inlineConds      []interface{}
+		reflectValue         = tx.Statement.ReflectValue
+		relForeignKeys       []string
+		relForeignFields     []*schema.Field
+		foreignFields        []*schema.Field
+		foreignValues        [][]interface{}
+		identityMap          = map[string][]reflect.Value{}
+		inlineConds          []interface{}
+		joinForeignFields    []*schema.Field
+		joinRelForeignFields []*schema.Field
+		joinForeignKeys      []string
 	)
 
 	if rel.JoinTable != nil {
-		var (
-			joinForeignFields    = make([]*schema.Field, 0, len(rel.References))
-			joinRelForeignFields = make([]*schema.Field, 0, len(rel.References))
-			joinForeignKeys      = make([]string, 0, len(rel.References))
-		)
-
 		for _, ref := range rel.References {
 			if ref.OwnPrimaryKey {
 				joinForeignKeys = append(joinForeignKeys, ref.ForeignKey.DBName)
@@ -134,38 +139,6 @@ func preload(tx *gorm.DB, rel *schema.Relationship, conds []interface{}, preload
 				relForeignFields = append(relForeignFields, ref.PrimaryKey)
 			}
 		}
-
-		joinIdentityMap, joinForeignValues := schema.GetIdentityFieldValuesMap(tx.Statement.Context, reflectValue, foreignFields)
-		if len(joinForeignValues) == 0 {
-			return nil
-		}
-
-		joinResults := rel.JoinTable.MakeSlice().Elem()
-		column, values := schema.ToQueryValues(clause.CurrentTable, joinForeignKeys, joinForeignValues)
-		if err := tx.Where(clause.IN{Column: column, Values: values}).Find(joinResults.Addr().Interface()).Error; err != nil {
-			return err
-		}
-
-		// convert join identity map to relation identity map
-		fieldValues := make([]interface{}, len(joinForeignFields))
-		joinFieldValues := make([]interface{}, len(joinRelForeignFields))
-		for i := 0; i < joinResults.Len(); i++ {
-			joinIndexValue := joinResults.Index(i)
-			for idx, field := range joinForeignFields {
-				fieldValues[idx], _ = field.ValueOf(tx.Statement.Context, joinIndexValue)
-			}
-
-			for idx, field := range joinRelForeignFields {
-				joinFieldValues[idx], _ = field.ValueOf(tx.Statement.Context, joinIndexValue)
-			}
-
-			if results, ok := joinIdentityMap[utils.ToStringKey(fieldValues...)]; ok {
-				joinKey := utils.ToStringKey(joinFieldValues...)
-				identityMap[joinKey] = append(identityMap[joinKey], results...)
-			}
-		}
-
-		_, foreignValues = schema.GetIdentityFieldValuesMap(tx.Statement.Context, joinResults, joinRelForeignFields)
 	} else {
 		for _, ref := range rel.References {
 			if ref.OwnPrimaryKey {
@@ -180,36 +153,88 @@ func preload(tx *gorm.DB, rel *schema.Relationship, conds []interface{}, preload
 				foreignFields = append(foreignFields, ref.ForeignKey)
 			}
 		}
+	}
 
-		identityMap, foreignValues = schema.GetIdentityFieldValuesMap(tx.Statement.Context, reflectValue, foreignFields)
-		if len(foreignValues) == 0 {
-			return nil
-		}
+	// identity map and foreign values of the parents, the foreign values are distinct
+	identityMap, foreignValues = schema.GetIdentityFieldValuesMap(tx.Statement.Context, reflectValue, foreignFields)
+	if len(foreignValues) == 0 {
+		return nil
 	}
 
+	// share the conditions between batches
+	tx = tx.Session(&gorm.Session{})
+	queryTx := tx
+
 	// nested preload
 	for p, pvs := range preloads {
-		tx = tx.Preload(p, pvs...)
+		queryTx = queryTx.Preload(p, pvs...)
 	}
 
-	reflectResults := rel.FieldSchema.MakeSlice().Elem()
-	column, values := schema.ToQueryValues(clause.CurrentTable, relForeignKeys, foreignValues)
+	var (
+		countOption     *gorm.PreloadCountOption
+		joinTableOption *gorm.PreloadJoinTableOption
+		preloadOptions  *gorm.PreloadOptions
+		hasClosure      bool
+	)
+	for _, cond := range conds {
+		if fc, ok := cond.(func(*gorm.DB) *gorm.DB); ok {
+			queryTx = fc(queryTx)
+			hasClosure = true
+		} else if option, ok := cond.(gorm.PreloadCountOption); ok {
+			countOption = &option
+		} else if option, ok := cond.(gorm.PreloadJoinTableOption); ok {
+			joinTableOption = &option
+		} else if options, ok := cond.(gorm.PreloadOptions); ok {
+			preloadOptions = &options
+		} else {
+			inlineConds = append(inlineConds, cond)
+		}
+	}
 
-	if len(values) != 0 {
-		for _, cond := range conds {
-			if fc, ok := cond.(func(*gorm.DB) *gorm.DB); ok {
-				tx = fc(tx)
-			} else {
-				inlineConds = append(inlineConds, cond)
-			}
+	if preloadOptions != nil {
+		if hasClosure || len(inlineConds) > 0 {
+			return fmt.Errorf("%s: %w", rel.Name, gorm.ErrPreloadOptionsWithConds)
 		}
 
-		if err := tx.Where(clause.IN{Column: column, Values: values}).Find(reflectResults.Addr().Interface(), inlineConds...).Error; err != nil {
-			return err
+		if len(preloadOptions.Where) > 0 {
+			queryTx = queryTx.Clauses(clause.Where{Exprs: preloadOptions.Where})
 		}
 	}
+	queryTx = queryTx.Session(&gorm.Session{})
+
+	// 只统计关联数量，不加载关联
+	if countOption != nil {
+		return preloadCount(tx, queryTx, rel, countOption.Field, inlineConds, identityMap, foreignValues)
+	}
+
+	if preloadOptions != nil {
+		queryTx = applyPreloadOptions(queryTx, rel, relForeignKeys, preloadOptions)
+	}
 
-	fieldValues := make([]interface{}, len(relForeignFields))
+	// 中间表的条件及保存中间表记录的字段
+	var (
+		joinTx    = tx
+		joinField *schema.Field
+	)
+	if joinTableOption != nil {
+		if rel.JoinTable == nil {
+			return fmt.Errorf("%s: %w for preloading join table", rel.Name, gorm.ErrUnsupportedRelation)
+		}
+
+		if len(joinTableOption.Conds) > 0 {
+			joinTx = joinTx.Where(joinTableOption.Conds[0], joinTableOption.Conds[1:]...).Session(&gorm.Session{})
+		}
+
+		if joinTableOption.Field != "" {
+			if joinField = rel.Schema.LookUpField(joinTableOption.Field); joinField == nil || joinField.IndirectFieldType.Kind() != reflect.Slice {
+				return fmt.Errorf("%w: %s for join table of %s", gorm.ErrInvalidField, joinTableOption.Field, rel.Name)
+			}
+
+			if elemType := joinField.IndirectFieldType.Elem(); elemType != rel.JoinTable.ModelType && elemType != reflect.PtrTo(rel.JoinTable.ModelType) {
+				return fmt.Errorf("%w: %s should be a slice of %s for join table of %s", gorm.ErrInvalidField, joinTableOption.Field, rel.JoinTable.ModelType, rel.Name)
+			}
+		}
+	}
 
 	// clean up old values before preloading
 	switch reflectValue.Kind() {
@@ -220,6 +245,9 @@ func preload(tx *gorm.DB, rel *schema.Relationship, conds []interface{}, preload
 		default:
 			tx.AddError(rel.Field.Set(tx.Statement.Context, reflectValue, reflect.New(rel.Field.FieldType).Interface()))
 		}
+		if joinField != nil {
+			tx.AddError(joinField.Set(tx.Statement.Context, reflectValue, reflect.MakeSlice(joinField.IndirectFieldType, 0, 10).Interface()))
+		}
 	case reflect.Slice, reflect.Array:
 		for i := 0; i < reflectValue.Len(); i++ {
 			switch rel.Type {
@@ -228,9 +256,271 @@ func preload(tx *gorm.DB, rel *schema.Relationship, conds []interface{}, preload
 			default:
 				tx.AddError(rel.Field.Set(tx.Statement.Context, reflectValue.Index(i), reflect.New(rel.Field.FieldType).Interface()))
 			}
+			if joinField != nil {
+				tx.AddError(joinField.Set(tx.Statement.Context, reflectValue.Index(i), reflect.MakeSlice(joinField.IndirectFieldType, 0, 10).Interface()))
+			}
+		}
+	}
+
+	batchSize := tx.PreloadBatchSize
+	if batchSize <= 0 {
+		batchSize = len(foreignValues)
+	}
+
+	for start := 0; start < len(foreignValues); start += batchSize {
+		end := start + batchSize
+		if end > len(foreignValues) {
+			end = len(foreignValues)
+		}
+
+		batchIdentityMap, batchForeignValues := identityMap, foreignValues[start:end]
+		if rel.JoinTable != nil {
+			joinResults := rel.JoinTable.MakeSlice().Elem()
+			column, values := schema.ToQueryValues(clause.CurrentTable, joinForeignKeys, batchForeignValues)
+			if err := joinTx.Where(clause.IN{Column: column, Values: values}).Find(joinResults.Addr().Interface()).Error; err != nil {
+				return err
+			}
+
+			// convert join identity map to relation identity map
+			batchIdentityMap = map[string][]reflect.Value{}
+			fieldValues := make([]interface{}, len(joinForeignFields))
+			joinFieldValues := make([]interface{}, len(joinRelForeignFields))
+			for i := 0; i < joinResults.Len(); i++ {
+				joinIndexValue := joinResults.Index(i)
+				for idx, field := range joinForeignFields {
+					fieldValues[idx], _ = field.ValueOf(tx.Statement.Context, joinIndexValue)
+				}
+
+				for idx, field := range joinRelForeignFields {
+					joinFieldValues[idx], _ = field.ValueOf(tx.Statement.Context, joinIndexValue)
+				}
+
+				if results, ok := identityMap[utils.ToStringKey(fieldValues...)]; ok {
+					joinKey := utils.ToStringKey(joinFieldValues...)
+					batchIdentityMap[joinKey] = append(batchIdentityMap[joinKey], results...)
+
+					if joinField != nil {
+						for _, result := range results {
+							tx.AddError(appendJoinRow(tx.Statement.Context, joinField, result, joinIndexValue))
+						}
+					}
+				}
+			}
+
+			_, batchForeignValues = schema.GetIdentityFieldValuesMap(tx.Statement.Context, joinResults, joinRelForeignFields)
+		}
+
+		if len(batchForeignValues) == 0 {
+			continue
+		}
+
+		reflectResults := rel.FieldSchema.MakeSlice().Elem()
+		column, values := schema.ToQueryValues(clause.CurrentTable, relForeignKeys, batchForeignValues)
+		findTx := queryTx.Where(clause.IN{Column: column, Values: values}).Find(reflectResults.Addr().Interface(), inlineConds...)
+		if findTx.Error != nil {
+			return findTx.Error
+		}
+
+		if tx.StrictPreload {
+			if names := unloadedFields(findTx.Statement, relForeignFields); len(names) > 0 {
+				return fmt.Errorf("%s: %w, fields %s of %s are not loaded", rel.Name, gorm.ErrPreloadReferencesNotLoaded, strings.Join(names, ", "), rel.FieldSchema.Name)
+			}
+		}
+
+		if err := assignPreloadResults(tx, rel, relForeignFields, batchIdentityMap, reflectResults); err != nil {
+			return err
+		}
+	}
+
+	// 限制每个 parent 的关联数量
+	if preloadOptions != nil && preloadOptions.Limit > 0 && (rel.Type == schema.HasMany || rel.Type == schema.Many2Many) {
+		for _, datas := range identityMap {
+			for _, data := range datas {
+				if values := reflect.Indirect(rel.Field.ReflectValueOf(tx.Statement.Context, data)); values.Len() > preloadOptions.Limit {
+					tx.AddError(rel.Field.Set(tx.Statement.Context, data, values.Slice(0, preloadOptions.Limit).Interface()))
+				}
+			}
+		}
+	}
+
+	return tx.Error
+}
+
+// applyPreloadOptions applies select, omit and order of preload options, the foreign keys are always selected
+func applyPreloadOptions(queryTx *gorm.DB, rel *schema.Relationship, relForeignKeys []string, options *gorm.PreloadOptions) *gorm.DB {
+	dbName := func(name string) string {
+		if field := rel.FieldSchema.LookUpField(name); field != nil {
+			return field.DBName
 		}
+		return name
 	}
 
+	if len(options.Select) > 0 {
+		selects := append([]string{}, options.Select...)
+		for _, key := range relForeignKeys {
+			var selected bool
+			for _, name := range options.Select {
+				if dbName(name) == key {
+					selected = true
+					break
+				}
+			}
+
+			if !selected {
+				selects = append(selects, key)
+			}
+		}
+		queryTx = queryTx.Select(selects)
+	}
+
+	if len(options.Omit) > 0 {
+		omits := make([]string, 0, len(options.Omit))
+		for _, name := range options.Omit {
+			if !utils.Contains(relForeignKeys, dbName(name)) {
+				omits = append(omits, name)
+			}
+		}
+		queryTx = queryTx.Omit(omits...)
+	}
+
+	if len(options.Order) > 0 {
+		queryTx = queryTx.Clauses(clause.OrderBy{Columns: options.Order})
+	}
+	return queryTx.Session(&gorm.Session{})
+}
+
+// appendJoinRow appends the join row (pointer of the join model) to the join field of parent
+func appendJoinRow(ctx context.Context, joinField *schema.Field, parent, joinRow reflect.Value) error {
+	joinRows := reflect.Indirect(joinField.ReflectValueOf(ctx, parent))
+	if joinRows.Type().Elem().Kind() != reflect.Ptr {
+		joinRow = joinRow.Elem()
+	}
+	return joinField.Set(ctx, parent, reflect.Append(joinRows, joinRow).Interface())
+}
+
+// preloadCount counts has many or many2many associations grouped by foreign keys, and assigns the counts to the count field of parents
+func preloadCount(tx, queryTx *gorm.DB, rel *schema.Relationship, fieldName string, conds []interface{}, identityMap map[string][]reflect.Value, foreignValues [][]interface{}) error {
+	countField := rel.Schema.LookUpField(fieldName)
+	if countField == nil {
+		return fmt.Errorf("%w: %s for counting %s", gorm.ErrInvalidField, fieldName, rel.Name)
+	}
+
+	var (
+		ctx        = tx.Statement.Context
+		keyTable   = rel.FieldSchema.Table
+		keyFields  []*schema.Field
+		keyColumns []string
+	)
+
+	switch rel.Type {
+	case schema.HasMany:
+		for _, ref := range rel.References {
+			if ref.OwnPrimaryKey {
+				keyFields = append(keyFields, ref.ForeignKey)
+				keyColumns = append(keyColumns, ref.ForeignKey.DBName)
+			}
+		}
+	case schema.Many2Many:
+		// 通过中间表统计，关联表的条件依然生效
+		var exprs []clause.Expression
+		keyTable = rel.JoinTable.Table
+		for _, ref := range rel.References {
+			if ref.OwnPrimaryKey {
+				keyFields = append(keyFields, ref.ForeignKey)
+				keyColumns = append(keyColumns, ref.ForeignKey.DBName)
+			} else if ref.PrimaryValue == "" {
+				exprs = append(exprs, clause.Eq{
+					Column: clause.Column{Table: rel.JoinTable.Table, Name: ref.ForeignKey.DBName},
+					Value:  clause.Column{Table: rel.FieldSchema.Table, Name: ref.PrimaryKey.DBName},
+				})
+			}
+		}
+		queryTx = queryTx.Joins("INNER JOIN ? ON ?", clause.Table{Name: rel.JoinTable.Table}, clause.And(exprs...))
+	default:
+		return fmt.Errorf("%s: %w for counting", rel.Name, gorm.ErrUnsupportedRelation)
+	}
+
+	// reset counts of parents, the associations are untouched
+	switch reflectValue := tx.Statement.ReflectValue; reflectValue.Kind() {
+	case reflect.Struct:
+		tx.AddError(countField.Set(ctx, reflectValue, 0))
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < reflectValue.Len(); i++ {
+			tx.AddError(countField.Set(ctx, reflectValue.Index(i), 0))
+		}
+	}
+
+	groupColumns := make([]clause.Column, len(keyColumns))
+	selectColumns := make([]clause.Column, 0, len(keyColumns)+1)
+	for idx, column := range keyColumns {
+		groupColumns[idx] = clause.Column{Table: keyTable, Name: column}
+		selectColumns = append(selectColumns, groupColumns[idx])
+	}
+	selectColumns = append(selectColumns, clause.Column{Name: "COUNT(*)", Raw: true})
+
+	batchSize := tx.PreloadBatchSize
+	if batchSize <= 0 {
+		batchSize = len(foreignValues)
+	}
+
+	for start := 0; start < len(foreignValues); start += batchSize {
+		end := start + batchSize
+		if end > len(foreignValues) {
+			end = len(foreignValues)
+		}
+
+		column, values := schema.ToQueryValues(keyTable, keyColumns, foreignValues[start:end])
+		countTx := queryTx.Model(reflect.New(rel.FieldSchema.ModelType).Interface()).Clauses(
+			clause.Select{Columns: selectColumns}, clause.GroupBy{Columns: groupColumns},
+		).Where(clause.IN{Column: column, Values: values})
+		if len(conds) > 0 {
+			countTx = countTx.Where(conds[0], conds[1:]...)
+		}
+
+		rows, err := countTx.Rows()
+		if err != nil {
+			return err
+		}
+
+		keyValues := make([]interface{}, len(keyFields))
+		for rows.Next() {
+			var (
+				count int64
+				dests = make([]interface{}, 0, len(keyFields)+1)
+			)
+			for _, field := range keyFields {
+				dests = append(dests, reflect.New(field.FieldType).Interface())
+			}
+
+			if err := rows.Scan(append(dests, &count)...); err != nil {
+				rows.Close()
+				return err
+			}
+
+			for idx, dest := range dests {
+				keyValues[idx] = reflect.ValueOf(dest).Elem().Interface()
+			}
+
+			for _, data := range identityMap[utils.ToStringKey(keyValues...)] {
+				tx.AddError(countField.Set(ctx, data, count))
+			}
+		}
+
+		if err := rows.Close(); err != nil {
+			return err
+		}
+	}
+
+	return tx.Error
+}
+
+// assignPreloadResults assign preloaded results to the matched values of identity map
+func assignPreloadResults(tx *gorm.DB, rel *schema.Relationship, relForeignFields []*schema.Field, identityMap map[string][]reflect.Value, reflectResults reflect.Value) error {
+	var (
+		fieldValues = make([]interface{}, len(relForeignFields))
+		orphans     int
+	)
+
 	for i := 0; i < reflectResults.Len(); i++ {
 		elem := reflectResults.Index(i)
 		for idx, field := range relForeignFields {
@@ -239,6 +529,11 @@ func preload(tx *gorm.DB, rel *schema.Relationship, conds []interface{}, preload
 
 		datas, ok := identityMap[utils.ToStringKey(fieldValues...)]
 		if !ok {
+			if tx.StrictPreload {
+				// 统计所有无法匹配的记录
+				orphans++
+				continue
+			}
 			return fmt.Errorf("failed to assign association %#v, make sure foreign fields exists", elem.Interface())
 		}
 
@@ -262,5 +557,36 @@ func preload(tx *gorm.DB, rel *schema.Relationship, conds []interface{}, preload
 		}
 	}
 
-	return tx.Error
+	if orphans > 0 {
+		return fmt.Errorf("%s: %w, %d of %d records", rel.Name, gorm.ErrPreloadOrphanRecords, orphans, reflectResults.Len())
+	}
+	return nil
+}
+
+// preloadParentFields returns fields of the parent referenced by the preloading relation
+func preloadParentFields(rel *schema.Relationship) (fields []*schema.Field) {
+	for _, ref := range rel.References {
+		if ref.OwnPrimaryKey {
+			fields = append(fields, ref.PrimaryKey)
+		} else if ref.PrimaryValue == "" && rel.JoinTable == nil {
+			fields = append(fields, ref.ForeignKey)
+		}
+	}
+	return
+}
+
+// unloadedFields returns names of fields whose columns are not scanned by the last query of stmt,
+// e.g. foreign keys not selected or renamed in the database
+func unloadedFields(stmt *gorm.Statement, fields []*schema.Field) (names []string) {
+	loadedColumns := stmt.LoadedColumns()
+	if loadedColumns == nil {
+		return
+	}
+
+	for _, field := range fields {
+		if !utils.Contains(loadedColumns, field.DBName) {
+			names = append(names, field.Name)
+		}
+	}
+	return
 }
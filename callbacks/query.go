@@ -14,10 +14,14 @@ import (
 
 // Query 生成 query 阶段的回调
 func Query(db *gorm.DB) {
+	_, built := db.Statement.Settings.LoadAndDelete(queryBuiltKey)
 	if db.Error == nil {
-		BuildQuerySQL(db)
+		if !built {
+			BuildQuerySQL(db)
+			db.Statement.RewriteSQL()
+		}
 
-		if !db.DryRun && db.Error == nil {
+		if !db.DryRun && !db.Statement.SkipQuery && db.Error == nil {
 			rows, err := db.Statement.ConnPool.QueryContext(db.Statement.Context, db.Statement.SQL.String(), db.Statement.Vars...)
 			if err != nil {
 				db.AddError(err)
@@ -31,6 +35,95 @@ func Query(db *gorm.DB) {
 	}
 }
 
+// BuildQueryCallback name of BuildQuery, which isn't registered by default as callbacks registered before gorm:query
+// might change the statement after building SQL, query caches register it before their callbacks, e.g:
+//
+//	db.Callback().Query().Before("gorm:query").Register(callbacks.BuildQueryCallback, callbacks.BuildQuery)
+//	db.Callback().Query().After(callbacks.BuildQueryCallback).Before("gorm:query").Register("cache:load", func(tx *gorm.DB) {
+//		if value, ok := cache.Load(tx.Statement.CacheKey()); ok && !tx.Statement.NoCache {
+//			// fill tx.Statement.Dest and tx.RowsAffected with value
+//			tx.Statement.SkipQuery = true
+//		}
+//	})
+const BuildQueryCallback = "gorm:build_query"
+
+const queryBuiltKey = "gorm:query_built"
+
+// BuildQuery builds the SQL of the query before gorm:query, which executes the SQL unless Statement.SkipQuery
+func BuildQuery(db *gorm.DB) {
+	if db.Error == nil {
+		BuildQuerySQL(db)
+		db.Statement.RewriteSQL()
+		db.Statement.Settings.Store(queryBuiltKey, true)
+	}
+}
+
+// splitJoinedRelationColumns splits columns prefixed with joined relations like `Company.name` and `Manager.Company.name`
+// from selects and omits of stmt, returns the remaining columns and the split columns by relation path like `Manager.Company`
+func splitJoinedRelationColumns(stmt *gorm.Statement) (selects, omits []string, relationSelects, relationOmits map[string][]string) {
+	selects, omits = stmt.Selects, stmt.Omits
+	if stmt.Schema == nil || len(stmt.Joins) == 0 || (len(selects) == 0 && len(omits) == 0) {
+		return
+	}
+
+	// 已 join 的关联路径，如 Joins("Manager.Company") 包括 Manager 与 Manager.Company
+	joinedPaths := map[string]bool{}
+	for _, join := range stmt.Joins {
+		if join.Clause != nil {
+			continue
+		}
+
+		relations := stmt.Schema.Relationships.Relations
+		names := strings.Split(join.Name, ".")
+		for idx, name := range names {
+			rel, ok := relations[name]
+			if !ok {
+				break
+			}
+			joinedPaths[strings.Join(names[:idx+1], ".")] = true
+			relations = rel.FieldSchema.Relationships.Relations
+		}
+	}
+
+	split := func(columns []string) (remaining []string, relationColumns map[string][]string) {
+		for _, column := range columns {
+			if idx := strings.LastIndexByte(column, '.'); idx > 0 && joinedPaths[column[:idx]] {
+				if relationColumns == nil {
+					relationColumns = map[string][]string{}
+				}
+				relationColumns[column[:idx]] = append(relationColumns[column[:idx]], column[idx+1:])
+			} else {
+				remaining = append(remaining, column)
+			}
+		}
+		return
+	}
+
+	if len(joinedPaths) > 0 {
+		selects, relationSelects = split(selects)
+		omits, relationOmits = split(omits)
+	}
+	return
+}
+
+// selectColumn returns the column of field to select, or (expression) AS column if the field has a select expression
+func selectColumn(table string, field *schema.Field) clause.Column {
+	if field.SelectExpr != "" {
+		return clause.Column{Name: "(" + field.SelectExpr + ")", Raw: true, Alias: field.DBName}
+	}
+	return clause.Column{Table: table, Name: field.DBName}
+}
+
+// hasSelectExpr returns true if any field of s has a select expression
+func hasSelectExpr(s *schema.Schema) bool {
+	for _, field := range s.Fields {
+		if field.SelectExpr != "" && field.DBName != "" {
+			return true
+		}
+	}
+	return false
+}
+
 // BuildQuerySQL 查询前 生成 SQL
 func BuildQuerySQL(db *gorm.DB) {
 	if db.Statement.Schema != nil {
@@ -60,27 +153,35 @@ func BuildQuerySQL(db *gorm.DB) {
 			}
 		}
 
-		if len(db.Statement.Selects) > 0 {
-			clauseSelect.Columns = make([]clause.Column, len(db.Statement.Selects))
-			for idx, name := range db.Statement.Selects {
+		// Select("Company.name")、Omit("Company.created_at") 等指定关联列的，在生成 join 时处理
+		selects, omits, relationSelects, relationOmits := splitJoinedRelationColumns(db.Statement)
+
+		if len(selects) > 0 {
+			clauseSelect.Columns = make([]clause.Column, len(selects))
+			for idx, name := range selects {
 				if db.Statement.Schema == nil {
 					clauseSelect.Columns[idx] = clause.Column{Name: name, Raw: true}
 				} else if f := db.Statement.Schema.LookUpField(name); f != nil {
 					clauseSelect.Columns[idx] = clause.Column{Name: f.DBName}
+					if f.SelectExpr != "" {
+						clauseSelect.Columns[idx] = selectColumn(db.Statement.Table, f)
+					}
 				} else {
 					clauseSelect.Columns[idx] = clause.Column{Name: name, Raw: true}
 				}
 			}
-		} else if db.Statement.Schema != nil && len(db.Statement.Omits) > 0 {
+		} else if db.Statement.Schema != nil && len(omits) > 0 {
 			selectColumns, _ := db.Statement.SelectAndOmitColumns(false, false)
 			clauseSelect.Columns = make([]clause.Column, 0, len(db.Statement.Schema.DBNames))
 			for _, dbName := range db.Statement.Schema.DBNames {
 				if v, ok := selectColumns[dbName]; (ok && v) || !ok {
-					clauseSelect.Columns = append(clauseSelect.Columns, clause.Column{Table: db.Statement.Table, Name: dbName})
+					clauseSelect.Columns = append(clauseSelect.Columns, selectColumn(db.Statement.Table, db.Statement.Schema.FieldsByDBName[dbName]))
 				}
 			}
 		} else if db.Statement.Schema != nil && db.Statement.ReflectValue.IsValid() {
-			queryFields := db.QueryFields
+			// 有查询表达式字段时需要选出所有字段
+			selectExpr := hasSelectExpr(db.Statement.Schema)
+			queryFields := db.QueryFields || selectExpr
 			if !queryFields {
 				switch db.Statement.ReflectValue.Kind() {
 				case reflect.Struct:
@@ -93,11 +194,15 @@ func BuildQuerySQL(db *gorm.DB) {
 			if queryFields {
 				stmt := gorm.Statement{DB: db}
 				// smaller struct
-				if err := stmt.Parse(db.Statement.Dest); err == nil && (db.QueryFields || stmt.Schema.ModelType != db.Statement.Schema.ModelType) {
+				if err := stmt.Parse(db.Statement.Dest); err == nil && (db.QueryFields || selectExpr || stmt.Schema.ModelType != db.Statement.Schema.ModelType) {
 					clauseSelect.Columns = make([]clause.Column, len(stmt.Schema.DBNames))
 
 					for idx, dbName := range stmt.Schema.DBNames {
-						clauseSelect.Columns[idx] = clause.Column{Table: db.Statement.Table, Name: dbName}
+						field := stmt.Schema.FieldsByDBName[dbName]
+						if f := db.Statement.Schema.FieldsByDBName[dbName]; f != nil && f.SelectExpr != "" {
+							field = f // smaller struct uses the select expression of the model
+						}
+						clauseSelect.Columns[idx] = selectColumn(db.Statement.Table, field)
 					}
 				}
 			}
@@ -110,16 +215,18 @@ func BuildQuerySQL(db *gorm.DB) {
 		}
 
 		if len(db.Statement.Joins) != 0 || len(fromClause.Joins) != 0 {
-			if len(db.Statement.Selects) == 0 && len(db.Statement.Omits) == 0 && db.Statement.Schema != nil {
+			if len(selects) == 0 && len(omits) == 0 && db.Statement.Schema != nil {
 				clauseSelect.Columns = make([]clause.Column, len(db.Statement.Schema.DBNames))
 				for idx, dbName := range db.Statement.Schema.DBNames {
-					clauseSelect.Columns[idx] = clause.Column{Table: db.Statement.Table, Name: dbName}
+					clauseSelect.Columns[idx] = selectColumn(db.Statement.Table, db.Statement.Schema.FieldsByDBName[dbName])
 				}
 			}
 
 			specifiedRelationsName := make(map[string]interface{})
 			for _, join := range db.Statement.Joins {
-				if db.Statement.Schema != nil {
+				if join.Clause != nil {
+					fromClause.Joins = appendJoin(fromClause.Joins, *join.Clause)
+				} else if db.Statement.Schema != nil {
 					var isRelations bool // is relations or raw sql
 					var relations []*schema.Relationship
 					relation, ok := db.Statement.Schema.Relationships.Relations[join.Name]
@@ -152,7 +259,7 @@ func BuildQuerySQL(db *gorm.DB) {
 					}
 
 					if isRelations {
-						genJoinClause := func(joinType clause.JoinType, parentTableName string, relation *schema.Relationship) clause.Join {
+						genJoinClause := func(joinType clause.JoinType, parentTableName string, relation *schema.Relationship, relationPath string) clause.Join {
 							tableAliasName := relation.Name
 							if parentTableName != clause.CurrentTable {
 								tableAliasName = utils.NestedRelationName(parentTableName, tableAliasName)
@@ -160,11 +267,29 @@ func BuildQuerySQL(db *gorm.DB) {
 
 							columnStmt := gorm.Statement{
 								Table: tableAliasName, DB: db, Schema: relation.FieldSchema,
-								Selects: join.Selects, Omits: join.Omits,
+								Selects: append(append([]string{}, join.Selects...), relationSelects[relationPath]...),
+								Omits:   append(append([]string{}, join.Omits...), relationOmits[relationPath]...),
 							}
 
 							selectColumns, restricted := columnStmt.SelectAndOmitColumns(false, false)
+							// 关联的主键以及关联表中的外键总是选出，用于填充关联的结构体
+							for _, field := range relation.FieldSchema.PrimaryFields {
+								selectColumns[field.DBName] = true
+							}
+							for _, ref := range relation.References {
+								if ref.OwnPrimaryKey || ref.PrimaryValue != "" {
+									selectColumns[ref.ForeignKey.DBName] = true
+								} else {
+									selectColumns[ref.PrimaryKey.DBName] = true
+								}
+							}
+
 							for _, s := range relation.FieldSchema.DBNames {
+								// 查询表达式无法限定关联的表，不选出
+								if field := relation.FieldSchema.FieldsByDBName[s]; field != nil && field.SelectExpr != "" {
+									continue
+								}
+
 								if v, ok := selectColumns[s]; (ok && v) || (!ok && !restricted) {
 									clauseSelect.Columns = append(clauseSelect.Columns, clause.Column{
 										Table: tableAliasName,
@@ -233,11 +358,16 @@ func BuildQuerySQL(db *gorm.DB) {
 						}
 
 						parentTableName := clause.CurrentTable
-						for _, rel := range relations {
+						for idx, rel := range relations {
 							// joins table alias like "Manager, Company, Manager__Company"
 							nestedAlias := utils.NestedRelationName(parentTableName, rel.Name)
 							if _, ok := specifiedRelationsName[nestedAlias]; !ok {
-								fromClause.Joins = append(fromClause.Joins, genJoinClause(join.JoinType, parentTableName, rel))
+								relationPath := strings.Join(strings.Split(join.Name, ".")[:idx+1], ".")
+								joinClause := genJoinClause(join.JoinType, parentTableName, rel, relationPath)
+								if idx == len(relations)-1 {
+									joinClause.IndexHints = join.IndexHints
+								}
+								fromClause.Joins = appendJoin(fromClause.Joins, joinClause)
 								specifiedRelationsName[nestedAlias] = nil
 							}
 
@@ -248,13 +378,13 @@ func BuildQuerySQL(db *gorm.DB) {
 							}
 						}
 					} else {
-						fromClause.Joins = append(fromClause.Joins, clause.Join{
+						fromClause.Joins = appendJoin(fromClause.Joins, clause.Join{
 							Expression: clause.NamedExpr{SQL: join.Name, Vars: join.Conds},
 						})
 					}
 				} else {
 					// raw SQL 模式
-					fromClause.Joins = append(fromClause.Joins, clause.Join{
+					fromClause.Joins = appendJoin(fromClause.Joins, clause.Join{
 						Expression: clause.NamedExpr{SQL: join.Name, Vars: join.Conds},
 					})
 				}
@@ -268,10 +398,24 @@ func BuildQuerySQL(db *gorm.DB) {
 
 		db.Statement.AddClauseIfNotExists(clauseSelect) // 如果没有指定 select clause, 使用这个
 
+		if db.StrictGroupBy {
+			checkGroupBy(db)
+		}
+
 		db.Statement.Build(db.Statement.BuildClauses...)
 	}
 }
 
+// appendJoin appends join unless an identical join exists, e.g. the same joins added by different scopes
+func appendJoin(joins []clause.Join, join clause.Join) []clause.Join {
+	for _, j := range joins {
+		if reflect.DeepEqual(j, join) {
+			return joins
+		}
+	}
+	return append(joins, join)
+}
+
 func Preload(db *gorm.DB) {
 	if db.Error == nil && len(db.Statement.Preloads) > 0 {
 		if db.Statement.Schema == nil {
@@ -302,6 +446,12 @@ func Preload(db *gorm.DB) {
 			if relations := preloadDB.Statement.Schema.Relationships.EmbeddedRelations[name]; relations != nil {
 				db.AddError(preloadEmbedded(preloadDB.Table("").Session(&gorm.Session{Context: db.Statement.Context, SkipHooks: db.Statement.SkipHooks}), relations, db.Statement.Schema, preloadMap[name], db.Statement.Preloads[clause.Associations]))
 			} else if rel := preloadDB.Statement.Schema.Relationships.Relations[name]; rel != nil {
+				if db.StrictPreload {
+					if names := unloadedFields(db.Statement, preloadParentFields(rel)); len(names) > 0 {
+						db.AddError(fmt.Errorf("%s: %w, fields %s are not loaded", name, gorm.ErrPreloadReferencesNotLoaded, strings.Join(names, ", ")))
+						continue
+					}
+				}
 				db.AddError(preload(preloadDB.Table("").Session(&gorm.Session{Context: db.Statement.Context, SkipHooks: db.Statement.SkipHooks}), rel, append(db.Statement.Preloads[name], db.Statement.Preloads[clause.Associations]...), preloadMap[name]))
 			} else {
 				db.AddError(fmt.Errorf("%s: %w for schema %s", name, gorm.ErrUnsupportedRelation, db.Statement.Schema.Name))
@@ -321,3 +471,129 @@ func AfterQuery(db *gorm.DB) {
 		})
 	}
 }
+
+// aggregateFuncs aggregate functions of MySQL, PostgreSQL, SQLite and SQL Server
+var aggregateFuncs = map[string]bool{
+	"count": true, "sum": true, "avg": true, "min": true, "max": true, "any_value": true,
+	"group_concat": true, "string_agg": true, "array_agg": true, "json_agg": true, "jsonb_agg": true,
+	"json_arrayagg": true, "json_objectagg": true, "json_group_array": true, "json_group_object": true,
+	"bool_and": true, "bool_or": true, "every": true, "bit_and": true, "bit_or": true, "bit_xor": true,
+	"stddev": true, "stddev_pop": true, "stddev_samp": true, "variance": true, "var_pop": true, "var_samp": true,
+	"total": true, "listagg": true,
+}
+
+// checkGroupBy 检查 Select 指定的列是否都已聚合或者在 GROUP BY 中，没有 Select 时不检查
+func checkGroupBy(db *gorm.DB) {
+	c, ok := db.Statement.Clauses["GROUP BY"]
+	if !ok || len(db.Statement.Selects) == 0 {
+		return
+	}
+
+	groupBy, ok := c.Expression.(clause.GroupBy)
+	if !ok || len(groupBy.Columns)+len(groupBy.Expressions) == 0 {
+		return
+	}
+
+	grouped := map[string]bool{}
+	addGrouped := func(name string) {
+		name = normalizeGroupByItem(db.Statement, name)
+		grouped[name] = true
+		grouped[name[strings.LastIndexByte(name, '.')+1:]] = true
+	}
+
+	for _, column := range groupBy.Columns {
+		switch {
+		case column.Raw:
+			for _, name := range utils.SplitSelectItems(column.Name) {
+				addGrouped(name)
+			}
+		case column.Name == clause.PrimaryKey:
+			if db.Statement.Schema != nil && db.Statement.Schema.PrioritizedPrimaryField != nil {
+				addGrouped(db.Statement.Schema.PrioritizedPrimaryField.DBName)
+			}
+		case column.Table != "" && column.Table != clause.CurrentTable:
+			addGrouped(column.Table + "." + column.Name)
+		default:
+			addGrouped(column.Name)
+		}
+	}
+
+	for _, expr := range groupBy.Expressions {
+		if e, ok := expr.(clause.Expr); ok && len(e.Vars) == 0 {
+			addGrouped(e.SQL)
+		}
+	}
+
+	var columns []string
+	for _, selected := range db.Statement.Selects {
+		for _, item := range utils.SplitSelectItems(selected) {
+			expr := stripSelectAlias(item)
+			if isAggregatedOrLiteral(expr) {
+				continue
+			}
+
+			name := normalizeGroupByItem(db.Statement, expr)
+			if !grouped[name] && !grouped[name[strings.LastIndexByte(name, '.')+1:]] {
+				columns = append(columns, expr)
+			}
+		}
+	}
+
+	if len(columns) > 0 {
+		db.AddError(fmt.Errorf("%w: %s", gorm.ErrNonAggregatedColumn, strings.Join(columns, ", ")))
+	}
+}
+
+// stripSelectAlias removes the alias of select item, e.g: sum(age) AS total
+func stripSelectAlias(item string) string {
+	if idx := strings.LastIndex(strings.ToLower(item), " as "); idx > 0 && strings.Count(item[idx:], "(") == strings.Count(item[idx:], ")") {
+		return strings.TrimSpace(item[:idx])
+	}
+	return item
+}
+
+// isAggregatedOrLiteral whether the select item calls aggregate functions or is a literal value
+func isAggregatedOrLiteral(expr string) bool {
+	lower := strings.ToLower(expr)
+	switch {
+	case lower == "null" || lower == "true" || lower == "false" || lower == "?":
+		return true
+	case strings.HasPrefix(lower, "'"):
+		return true
+	case strings.Trim(lower, "0123456789.-+") == "":
+		return true
+	}
+
+	for idx := strings.IndexByte(lower, '('); idx > 0; {
+		name := strings.TrimSpace(lower[:idx])
+		if pos := strings.LastIndexFunc(name, func(r rune) bool { return utils.IsValidDBNameChar(r) || r == '.' }); pos >= 0 {
+			name = name[pos+1:]
+		}
+
+		if aggregateFuncs[name] {
+			return true
+		}
+
+		next := strings.IndexByte(lower[idx+1:], '(')
+		if next < 0 {
+			break
+		}
+		idx += next + 1
+	}
+	return false
+}
+
+// normalizeGroupByItem unquotes the expression and converts field names to column names for comparing
+func normalizeGroupByItem(stmt *gorm.Statement, expr string) string {
+	expr = strings.NewReplacer("`", "", `"`, "", "[", "", "]", "").Replace(strings.TrimSpace(expr))
+	if stmt.Schema != nil {
+		if field := stmt.Schema.LookUpField(expr); field != nil && field.DBName != "" {
+			expr = field.DBName
+		} else if strings.HasPrefix(expr, stmt.Table+".") {
+			if field := stmt.Schema.LookUpField(strings.TrimPrefix(expr, stmt.Table+".")); field != nil && field.DBName != "" {
+				expr = field.DBName
+			}
+		}
+	}
+	return strings.ToLower(strings.Join(strings.Fields(expr), ""))
+}
@@ -5,6 +5,8 @@ import (
 )
 
 func RawExec(db *gorm.DB) {
+	db.Statement.RewriteSQL()
+
 	if db.Error == nil && !db.DryRun {
 		result, err := db.Statement.ConnPool.ExecContext(db.Statement.Context, db.Statement.SQL.String(), db.Statement.Vars...)
 		if err != nil {
@@ -13,5 +15,10 @@ func RawExec(db *gorm.DB) {
 		}
 
 		db.RowsAffected, _ = result.RowsAffected()
+
+		// 保留执行结果，通过 ExecResult 或 InstanceGet 获取 LastInsertID
+		execResult := gorm.ExecResult{RowsAffected: db.RowsAffected}
+		execResult.LastInsertID, execResult.LastInsertIDError = result.LastInsertId()
+		db.InstanceSet(gorm.ExecResultKey, execResult)
 	}
 }
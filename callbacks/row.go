@@ -7,6 +7,7 @@ import (
 func RowQuery(db *gorm.DB) {
 	if db.Error == nil {
 		BuildQuerySQL(db)
+		db.Statement.RewriteSQL()
 		if db.DryRun || db.Error != nil {
 			return
 		}
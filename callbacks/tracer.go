@@ -0,0 +1,106 @@
+package callbacks
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// TracerInterface starts a span for each statement, the returned func finishes the span with the statement's error
+type TracerInterface interface {
+	Start(ctx context.Context, op string, stmt *gorm.Statement) (context.Context, func(err error))
+}
+
+const (
+	tracerStartCallback  = "gorm:tracer_start"
+	tracerFinishCallback = "gorm:tracer_finish"
+	tracerSpansKey       = "gorm:tracer_spans"
+)
+
+type tracerSpan struct {
+	ctx    context.Context // statement context before starting the span
+	finish func(err error)
+}
+
+type tracerCallback interface {
+	Register(name string, fn func(*gorm.DB)) error
+	Replace(name string, fn func(*gorm.DB)) error
+}
+
+// RegisterTracer wraps create, query, update, delete, row and raw processors with tracer's spans, E.g:
+//
+//	callbacks.RegisterTracer(db, tracer)
+//
+// the span's context is propagated into Statement.Context during the statement,
+// the span is finished even if previous callbacks failed, registering again replaces the tracer
+func RegisterTracer(db *gorm.DB, tracer TracerInterface) error {
+	callback := db.Callback()
+	// 开始回调在所有回调之前，结束回调在所有回调之后
+	processors := []struct {
+		op     string
+		get    func(name string) func(*gorm.DB)
+		start  tracerCallback
+		finish tracerCallback
+	}{
+		{"create", callback.Create().Get, callback.Create().Before("*"), callback.Create().After("*")},
+		{"query", callback.Query().Get, callback.Query().Before("*"), callback.Query().After("*")},
+		{"update", callback.Update().Get, callback.Update().Before("*"), callback.Update().After("*")},
+		{"delete", callback.Delete().Get, callback.Delete().Before("*"), callback.Delete().After("*")},
+		{"row", callback.Row().Get, callback.Row().Before("*"), callback.Row().After("*")},
+		{"raw", callback.Raw().Get, callback.Raw().Before("*"), callback.Raw().After("*")},
+	}
+
+	for _, p := range processors {
+		register := p.start.Register
+		if p.get(tracerStartCallback) != nil {
+			register = p.start.Replace
+		}
+		if err := register(tracerStartCallback, startTracerSpan(tracer, p.op)); err != nil {
+			return err
+		}
+
+		register = p.finish.Register
+		if p.get(tracerFinishCallback) != nil {
+			register = p.finish.Replace
+		}
+		if err := register(tracerFinishCallback, finishTracerSpan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startTracerSpan(tracer TracerInterface, op string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx, finish := tracer.Start(db.Statement.Context, op, db.Statement)
+
+		var spans []tracerSpan
+		if v, ok := db.InstanceGet(tracerSpansKey); ok {
+			spans, _ = v.([]tracerSpan)
+		}
+		db.InstanceSet(tracerSpansKey, append(spans, tracerSpan{ctx: db.Statement.Context, finish: finish}))
+
+		if ctx != nil {
+			db.Statement.Context = ctx
+		}
+	}
+}
+
+func finishTracerSpan(db *gorm.DB) {
+	v, ok := db.InstanceGet(tracerSpansKey)
+	if !ok {
+		return
+	}
+
+	spans, _ := v.([]tracerSpan)
+	if len(spans) == 0 {
+		return
+	}
+
+	span := spans[len(spans)-1]
+	db.InstanceSet(tracerSpansKey, spans[:len(spans)-1])
+	db.Statement.Context = span.ctx
+	if span.finish != nil {
+		span.finish(db.Error)
+	}
+}
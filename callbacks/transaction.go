@@ -5,11 +5,20 @@ import (
 )
 
 func BeginTransaction(db *gorm.DB) {
+	if db.Statement.TxOptions != nil && db.Statement.TxOptions.ReadOnly {
+		db.AddError(gorm.ErrReadOnlyTransaction)
+		return
+	}
+
 	// 如果没有配置跳过事务，并且没错误
 	if !db.Config.SkipDefaultTransaction && db.Error == nil {
 		if tx := db.Begin(); tx.Error == nil { // 开始一个事务
 			db.Statement.ConnPool = tx.Statement.ConnPool
+			db.Statement.TxOptions = tx.Statement.TxOptions
 			db.InstanceSet("gorm:started_transaction", true)
+			if tx.Statement.TxOptions != nil && tx.Statement.TxOptions.ReadOnly {
+				db.AddError(gorm.ErrReadOnlyTransaction)
+			}
 		} else if tx.Error == gorm.ErrInvalidTransaction {
 			tx.Error = nil
 		} else {
@@ -28,6 +37,7 @@ func CommitOrRollbackTransaction(db *gorm.DB) {
 			}
 
 			db.Statement.ConnPool = db.ConnPool
+			db.Statement.TxOptions = nil
 		}
 	}
 }
@@ -1,6 +1,7 @@
 package callbacks
 
 import (
+	"fmt"
 	"reflect"
 	"sort"
 
@@ -32,7 +33,7 @@ func SetupUpdateReflectValue(db *gorm.DB) {
 // BeforeUpdate before update hooks
 func BeforeUpdate(db *gorm.DB) {
 	if db.Error == nil && db.Statement.Schema != nil && !db.Statement.SkipHooks && (db.Statement.Schema.BeforeSave || db.Statement.Schema.BeforeUpdate) {
-		callMethod(db, func(value interface{}, tx *gorm.DB) (called bool) {
+		callHooks := func(value interface{}, tx *gorm.DB) (called bool) {
 			if db.Statement.Schema.BeforeSave {
 				if i, ok := value.(BeforeSaveInterface); ok {
 					called = true
@@ -48,8 +49,67 @@ func BeforeUpdate(db *gorm.DB) {
 			}
 
 			return called
-		})
+		}
+
+		// 使用 map 更新时，hooks 接收应用了更新值的 model 副本，以便校验更新后的值
+		if model, applied, ok := updatingModel(db); ok {
+			callHooks(model.Interface(), db.Session(&gorm.Session{NewDB: true}))
+
+			// 同步 hooks 对 model 的修改，更新的值在更新成功后才会赋值给 model
+			for _, field := range applied {
+				field.ReflectValueOf(db.Statement.Context, model.Elem()).Set(field.ReflectValueOf(db.Statement.Context, db.Statement.ReflectValue))
+			}
+			db.Statement.ReflectValue.Set(model.Elem())
+		} else {
+			callMethod(db, callHooks)
+		}
+	}
+}
+
+// updatingModel returns a copy of the model with the updating map applied if updating a struct model with map,
+// and the fields applied
+func updatingModel(db *gorm.DB) (model reflect.Value, applied []*schema.Field, ok bool) {
+	var values map[string]interface{}
+	switch dest := db.Statement.Dest.(type) {
+	case map[string]interface{}:
+		values = dest
+	case *map[string]interface{}:
+		values = *dest
+	default:
+		return
+	}
+
+	reflectValue := db.Statement.ReflectValue
+	if reflectValue.Kind() != reflect.Struct || !reflectValue.CanAddr() {
+		return
+	}
+
+	selectColumns, restricted := db.Statement.SelectAndOmitColumns(false, true)
+	model = reflect.New(reflectValue.Type())
+	model.Elem().Set(reflectValue)
+	for key, value := range values {
+		field := db.Statement.Schema.LookUpField(key)
+		if field == nil {
+			continue
+		}
+
+		switch value.(type) {
+		case clause.Expression, *gorm.DB:
+			continue // SQL 表达式的值在执行前未知，保留原值
+		}
+
+		name := field.DBName
+		if name == "" {
+			name = field.Name
+		}
+
+		if v, ok := selectColumns[name]; (ok && v) || (!ok && !restricted) {
+			// 无法赋值给字段的值保留原值，由数据库处理
+			_ = field.Set(db.Statement.Context, model.Elem(), value)
+			applied = append(applied, field)
+		}
 	}
+	return model, applied, true
 }
 
 // Update update hook
@@ -67,6 +127,7 @@ func Update(config *Config) func(db *gorm.DB) {
 			}
 		}
 
+		var previousReturning bool
 		if db.Statement.SQL.Len() == 0 {
 			db.Statement.SQL.Grow(180)
 			db.Statement.AddClauseIfNotExists(clause.Update{})
@@ -78,13 +139,28 @@ func Update(config *Config) func(db *gorm.DB) {
 				}
 			}
 
+			previousReturning = addPreviousReturning(db, supportReturning)
 			db.Statement.Build(db.Statement.BuildClauses...)
 		}
 
 		checkMissingWhereConditions(db)
+		db.Statement.RewriteSQL()
 
 		if !db.DryRun && db.Error == nil {
-			if ok, mode := hasReturning(db, supportReturning); ok {
+			// 不支持通过 RETURNING 返回旧值时，在同一事务中先查询旧值
+			if db.Statement.PreviousDest != nil && !previousReturning {
+				defer selectPrevious(db)()
+				if db.Error != nil {
+					return
+				}
+			}
+
+			if previousReturning {
+				if rows, err := db.Statement.ConnPool.QueryContext(db.Statement.Context, db.Statement.SQL.String(), db.Statement.Vars...); db.AddError(err) == nil {
+					scanPrevious(db, rows)
+					db.AddError(rows.Close())
+				}
+			} else if ok, mode := hasReturning(db, supportReturning); ok {
 				if rows, err := db.Statement.ConnPool.QueryContext(db.Statement.Context, db.Statement.SQL.String(), db.Statement.Vars...); db.AddError(err) == nil {
 					dest := db.Statement.Dest
 					db.Statement.Dest = db.Statement.ReflectValue.Addr().Interface()
@@ -99,6 +175,22 @@ func Update(config *Config) func(db *gorm.DB) {
 					db.RowsAffected, _ = result.RowsAffected()
 				}
 			}
+
+			checkVersionConflict(db)
+		}
+	}
+}
+
+// checkVersionConflict returns ErrOptimisticLockConflict if nothing updated but the locked record exists
+func checkVersionConflict(db *gorm.DB) {
+	if db.Error != nil || db.RowsAffected != 0 {
+		return
+	}
+
+	if c, ok := db.Statement.Clauses["version_lock_enabled"]; ok && c.Expression != nil {
+		var count int64
+		if db.AddError(db.Session(&gorm.Session{NewDB: true}).Table(db.Statement.Table).Where(c.Expression).Count(&count).Error) == nil && count > 0 {
+			db.AddError(gorm.ErrOptimisticLockConflict)
 		}
 	}
 }
@@ -153,6 +245,16 @@ func ConvertToAssignments(stmt *gorm.Statement) (set clause.Set) {
 		}
 	}
 
+	// 显式 select 不可变字段时报错，而不是静默忽略
+	if stmt.Schema != nil {
+		for _, column := range stmt.Selects {
+			if field := stmt.Schema.LookUpField(column); field != nil && field.Immutable {
+				stmt.AddError(fmt.Errorf("%w: %s", gorm.ErrImmutableColumn, field.DBName))
+				return
+			}
+		}
+	}
+
 	updatingValue := reflect.ValueOf(stmt.Dest)
 	for updatingValue.Kind() == reflect.Ptr {
 		updatingValue = updatingValue.Elem()
@@ -205,6 +307,11 @@ func ConvertToAssignments(stmt *gorm.Statement) (set clause.Set) {
 
 			if stmt.Schema != nil {
 				if field := stmt.Schema.LookUpField(k); field != nil {
+					if field.Immutable {
+						stmt.AddError(fmt.Errorf("%w: %s", gorm.ErrImmutableColumn, field.DBName))
+						return
+					}
+
 					if field.DBName != "" {
 						if v, ok := selectColumns[field.DBName]; (ok && v) || (!ok && !restricted) {
 							set = append(set, clause.Assignment{Column: clause.Column{Name: field.DBName}, Value: kv})
@@ -227,6 +334,11 @@ func ConvertToAssignments(stmt *gorm.Statement) (set clause.Set) {
 				field := stmt.Schema.LookUpField(dbName)
 				if field.AutoUpdateTime > 0 && value[field.Name] == nil && value[field.DBName] == nil {
 					if v, ok := selectColumns[field.DBName]; (ok && v) || !ok {
+						if field.DBTime {
+							set = append(set, clause.Assignment{Column: clause.Column{Name: field.DBName}, Value: currentDBTime(stmt)})
+							continue
+						}
+
 						now := stmt.DB.NowFunc()
 						assignValue(field, now)
 
@@ -263,8 +375,11 @@ func ConvertToAssignments(stmt *gorm.Statement) (set clause.Set) {
 					if !field.PrimaryKey || !updatingValue.CanAddr() || stmt.Dest != stmt.Model {
 						if v, ok := selectColumns[field.DBName]; (ok && v) || (!ok && (!restricted || (!stmt.SkipHooks && field.AutoUpdateTime > 0))) {
 							value, isZero := field.ValueOf(stmt.Context, updatingValue)
+							isDBTime := !stmt.SkipHooks && field.AutoUpdateTime > 0 && field.DBTime
 							if !stmt.SkipHooks && field.AutoUpdateTime > 0 {
-								if field.AutoUpdateTime == schema.UnixNanosecond {
+								if isDBTime {
+									value = currentDBTime(stmt)
+								} else if field.AutoUpdateTime == schema.UnixNanosecond {
 									value = stmt.DB.NowFunc().UnixNano()
 								} else if field.AutoUpdateTime == schema.UnixMillisecond {
 									value = stmt.DB.NowFunc().UnixNano() / 1e6
@@ -284,7 +399,9 @@ func ConvertToAssignments(stmt *gorm.Statement) (set clause.Set) {
 										assignField = originField
 									}
 								}
-								assignValue(assignField, value)
+								if !isDBTime { // 数据库的当前时间不设置到字段
+									assignValue(assignField, value)
+								}
 							}
 						}
 					} else {
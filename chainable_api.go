@@ -55,6 +55,36 @@ func (db *DB) Clauses(conds ...clause.Expression) (tx *DB) {
 	return
 }
 
+// Hint add comments or hints injected into the clauses of the statement when building, works for query, create, update and delete
+//
+//	// add sqlcommenter style comment before SELECT
+//	db.Hint(clause.CommentBefore("SELECT", "app='api',route='/users'")).Find(&users)
+//	// /* app='api',route='/users' */ SELECT * FROM `users`
+//	// add optimizer hints after UPDATE
+//	db.Hint(clause.OptimizerHints("UPDATE", "MAX_EXECUTION_TIME(1000)")).Model(&user).Update("name", "jinzhu")
+//	// UPDATE /*+ MAX_EXECUTION_TIME(1000) */ `users` SET `name`='jinzhu' WHERE `id` = 1
+//	// add index hint after FROM
+//	db.Hint(clause.UseIndex("idx_user_name")).Find(&users)
+//	// SELECT * FROM `users` USE INDEX (`idx_user_name`)
+func (db *DB) Hint(hints ...clause.Hint) (tx *DB) {
+	tx = db.getInstance()
+	tx.Statement.Hints = append(tx.Statement.Hints, hints...)
+	return
+}
+
+// CapturePrevious captures the previous values of columns of the rows to update or delete into dest (a struct, slice or map),
+// uses RETURNING if the dialector implements PreviousValuesReturner, otherwise selects the rows with the conditions of
+// the statement FOR UPDATE before updating or deleting, in the same transaction
+//
+//	var prev User
+//	db.Model(&user).CapturePrevious(&prev, "status", "updated_at").Updates(map[string]interface{}{"status": "archived"})
+func (db *DB) CapturePrevious(dest interface{}, columns ...string) (tx *DB) {
+	tx = db.getInstance()
+	tx.Statement.PreviousDest = dest
+	tx.Statement.PreviousColumns = columns
+	return
+}
+
 var tableRegexp = regexp.MustCompile(`(?i)(?:.+? AS (\w+)\s*(?:$|,)|^\w+\s+(\w+)$)`)
 
 // Table specify the table you would like to run db operations
@@ -66,6 +96,8 @@ var tableRegexp = regexp.MustCompile(`(?i)(?:.+? AS (\w+)\s*(?:$|,)|^\w+\s+(\w+)
 // args 可以填写子查询
 func (db *DB) Table(name string, args ...interface{}) (tx *DB) {
 	tx = db.getInstance()
+	tx.Statement.TableSchema = ""
+	tx.Statement.modelTable = "" // 指定的表名不再按读写模式选择
 	if strings.Contains(name, " ") || strings.Contains(name, "`") || len(args) > 0 {
 		tx.Statement.TableExpr = &clause.Expr{SQL: name, Vars: args}
 		// 匹配以下表达式里面的 table name
@@ -81,6 +113,7 @@ func (db *DB) Table(name string, args ...interface{}) (tx *DB) {
 		}
 	} else if tables := strings.Split(name, "."); len(tables) == 2 { // 含有 db 名的 case
 		tx.Statement.TableExpr = &clause.Expr{SQL: tx.Statement.Quote(name)}
+		tx.Statement.TableSchema = tables[0]
 		tx.Statement.Table = tables[1]
 	} else if name != "" { // 直接填写表名
 		tx.Statement.TableExpr = &clause.Expr{SQL: tx.Statement.Quote(name)}
@@ -181,7 +214,23 @@ func (db *DB) Select(query interface{}, args ...interface{}) (tx *DB) {
 	return
 }
 
+// NoCache marks the statement not to use query caches, plugins caching query results should read Statement.NoCache, e.g:
+//
+//	db.NoCache().First(&user)
+func (db *DB) NoCache() (tx *DB) {
+	tx = db.getInstance()
+	tx.Statement.NoCache = true
+	return
+}
+
 // Omit specify fields that you want to ignore when creating, updating and querying
+//
+//	// skip saving the Pets association
+//	db.Omit("Pets").Create(&user)
+//	// save the Pets association without the name of pets and toys
+//	db.Omit("Pets.Name", "Pets.Toy.Name").Create(&user)
+//
+// foreign keys of associations referencing the owner like `Pets.UserID` can't be omitted, ErrInvalidField is returned
 func (db *DB) Omit(columns ...string) (tx *DB) {
 	tx = db.getInstance()
 
@@ -246,26 +295,69 @@ func (db *DB) Or(query interface{}, args ...interface{}) (tx *DB) {
 //	db.Joins("Account").Find(&user)
 //	db.Joins("JOIN emails ON emails.user_id = users.id AND emails.email = ?", "jinzhu@example.org").Find(&user)
 //	db.Joins("Account", DB.Select("id").Where("user_id = users.id AND name = ?", "someName").Model(&Account{}))
-func (db *DB) Joins(query string, args ...interface{}) (tx *DB) {
+//	db.Joins("Company", func(tx *gorm.DB) *gorm.DB { return tx.Select("name") }).Find(&user)
+//	db.Joins(clause.Join{Type: clause.LeftJoin, Table: clause.Table{Name: "emails"}, ON: clause.Where{Exprs: exprs}}).Find(&user)
+//	db.Joins("Company", clause.IndexHint{Type: clause.ForceIndexHint, Keys: []string{"idx_companies_name"}}).Find(&user)
+//
+// columns of joined relations could also be specified with relation prefixed names, e.g:
+//
+//	db.Joins("Company").Select("Company.name").Find(&user)
+//	db.Joins("Manager.Company").Omit("Manager.Company.created_at").Find(&user)
+//
+// keys of relations are always selected, identical joins are only joined once, e.g. the same joins added by different scopes,
+// index hints are written after the table of the joined relation, they are ignored for raw SQL joins
+func (db *DB) Joins(query interface{}, args ...interface{}) (tx *DB) {
 	return joins(db, clause.LeftJoin, query, args...)
 }
 
 // InnerJoins specify inner joins conditions
 // db.InnerJoins("Account").Find(&user)
-func (db *DB) InnerJoins(query string, args ...interface{}) (tx *DB) {
+func (db *DB) InnerJoins(query interface{}, args ...interface{}) (tx *DB) {
 	return joins(db, clause.InnerJoin, query, args...)
 }
 
-func joins(db *DB, joinType clause.JoinType, query string, args ...interface{}) (tx *DB) {
+func joins(db *DB, joinType clause.JoinType, query interface{}, args ...interface{}) (tx *DB) {
 	tx = db.getInstance()
 
+	var name string
+	switch v := query.(type) {
+	case string:
+		name = v
+	case clause.Join: // 直接使用构建好的 join，类型以其 Type 为准
+		tx.Statement.Joins = append(tx.Statement.Joins, join{Clause: &v, JoinType: v.Type})
+		return
+	case *clause.Join:
+		tx.Statement.Joins = append(tx.Statement.Joins, join{Clause: v, JoinType: v.Type})
+		return
+	default:
+		tx.AddError(fmt.Errorf("unsupported joins type %T", query))
+		return
+	}
+
+	// 关联的索引提示，如 Joins("Company", clause.IndexHint{Type: clause.UseIndexHint, Keys: []string{"idx_name"}})
+	var indexHints []clause.IndexHint
+	for idx := 0; idx < len(args); idx++ {
+		if hint, ok := args[idx].(clause.IndexHint); ok {
+			indexHints = append(indexHints, hint)
+			args = append(args[:idx:idx], args[idx+1:]...)
+			idx--
+		}
+	}
+
 	if len(args) == 1 {
-		if db, ok := args[0].(*DB); ok {
+		joinDB, ok := args[0].(*DB)
+		if fc, isFunc := args[0].(func(*DB) *DB); isFunc {
+			// 函数在新的会话上指定关联的 Select、Omit 及 ON 条件
+			joinDB, ok = fc(db.Session(&Session{NewDB: true})), true
+			args = []interface{}{joinDB}
+		}
+
+		if ok && joinDB != nil {
 			j := join{
-				Name: query, Conds: args, Selects: db.Statement.Selects,
-				Omits: db.Statement.Omits, JoinType: joinType,
+				Name: name, Conds: args, Selects: joinDB.Statement.Selects,
+				Omits: joinDB.Statement.Omits, JoinType: joinType, IndexHints: indexHints,
 			}
-			if where, ok := db.Statement.Clauses["WHERE"].Expression.(clause.Where); ok {
+			if where, ok := joinDB.Statement.Clauses["WHERE"].Expression.(clause.Where); ok {
 				j.On = &where
 			}
 			tx.Statement.Joins = append(tx.Statement.Joins, j)
@@ -273,7 +365,7 @@ func joins(db *DB, joinType clause.JoinType, query string, args ...interface{})
 		}
 	}
 
-	tx.Statement.Joins = append(tx.Statement.Joins, join{Name: query, Conds: args, JoinType: joinType})
+	tx.Statement.Joins = append(tx.Statement.Joins, join{Name: name, Conds: args, JoinType: joinType, IndexHints: indexHints})
 	return
 }
 
@@ -281,13 +373,24 @@ func joins(db *DB, joinType clause.JoinType, query string, args ...interface{})
 //
 //	// Select the sum age of users with given names
 //	db.Model(&User{}).Select("name, sum(age) as total").Group("name").Find(&results)
-func (db *DB) Group(name string) (tx *DB) {
+//	db.Model(&User{}).Select("company_id, count(*)").Group(clause.Column{Name: "company_id"}).Find(&results)
+//	db.Model(&User{}).Select("DATE(created_at), count(*)").Group(clause.Expr{SQL: "DATE(created_at)"}).Find(&results)
+func (db *DB) Group(name interface{}) (tx *DB) {
 	tx = db.getInstance()
 
-	fields := strings.FieldsFunc(name, utils.IsValidDBNameChar)
-	tx.Statement.AddClause(clause.GroupBy{
-		Columns: []clause.Column{{Name: name, Raw: len(fields) != 1}},
-	})
+	switch v := name.(type) {
+	case string:
+		fields := strings.FieldsFunc(v, utils.IsValidDBNameChar)
+		tx.Statement.AddClause(clause.GroupBy{
+			Columns: []clause.Column{{Name: v, Raw: len(fields) != 1}},
+		})
+	case clause.Column:
+		tx.Statement.AddClause(clause.GroupBy{Columns: []clause.Column{v}})
+	case clause.Expression:
+		tx.Statement.AddClause(clause.GroupBy{Expressions: []clause.Expression{v}})
+	default:
+		tx.AddError(fmt.Errorf("unsupported group args %v", name))
+	}
 	return
 }
 
@@ -295,6 +398,7 @@ func (db *DB) Group(name string) (tx *DB) {
 //
 //	// Select the sum age of users with name jinzhu
 //	db.Model(&User{}).Select("name, sum(age) as total").Group("name").Having("name = ?", "jinzhu").Find(&result)
+//	db.Model(&User{}).Select("company_id, count(*)").Group("company_id").Having(clause.Gt{Column: clause.Expr{SQL: "count(*)"}, Value: 5}).Find(&result)
 func (db *DB) Having(query interface{}, args ...interface{}) (tx *DB) {
 	tx = db.getInstance()
 	tx.Statement.AddClause(clause.GroupBy{
@@ -307,6 +411,8 @@ func (db *DB) Having(query interface{}, args ...interface{}) (tx *DB) {
 //
 //	db.Order("name DESC")
 //	db.Order(clause.OrderByColumn{Column: clause.Column{Name: "name"}, Desc: true})
+//	db.Order(clause.OrderByValues{Column: clause.Column{Name: "id"}, Values: []interface{}{3, 1, 2}})
+//	db.Order(clause.JSONQuery{Column: "attributes", Path: []string{"age"}, Desc: true})
 func (db *DB) Order(value interface{}) (tx *DB) {
 	tx = db.getInstance()
 
@@ -315,6 +421,14 @@ func (db *DB) Order(value interface{}) (tx *DB) {
 		tx.Statement.AddClause(clause.OrderBy{
 			Columns: []clause.OrderByColumn{v},
 		})
+	case clause.OrderByValues:
+		if len(v.Values) > 0 {
+			tx.Statement.AddClause(clause.OrderBy{
+				Columns: []clause.OrderByColumn{{Expression: v}},
+			})
+		}
+	case clause.JSONQuery:
+		tx.Statement.AddClause(clause.OrderBy{Expression: v})
 	case string:
 		if v != "" {
 			tx.Statement.AddClause(clause.OrderBy{
@@ -371,9 +485,53 @@ func (db *DB) Offset(offset int) (tx *DB) {
 func (db *DB) Scopes(funcs ...func(*DB) *DB) (tx *DB) {
 	tx = db.getInstance()
 	tx.Statement.scopes = append(tx.Statement.scopes, funcs...)
+	tx.Statement.scopeNames = append(tx.Statement.scopeNames, make([]string, len(funcs))...)
+	return tx
+}
+
+// ScopeNamed add a scope with name, the named scope could be removed with WithoutScope later,
+// it is skipped if the name has been removed by WithoutScope, e.g. added by a callback of plugins
+//
+//	tenantDB := db.ScopeNamed("tenant", func(db *gorm.DB) *gorm.DB {
+//	    return db.Where("tenant_id = ?", tenantID)
+//	})
+//	tenantDB.WithoutScope("tenant").Find(&users)
+func (db *DB) ScopeNamed(name string, fc func(*DB) *DB) (tx *DB) {
+	tx = db.getInstance()
+	if utils.Contains(tx.Statement.withoutScopes, name) {
+		return tx
+	}
+	tx.Statement.scopes = append(tx.Statement.scopes, fc)
+	tx.Statement.scopeNames = append(tx.Statement.scopeNames, name)
 	return tx
 }
 
+// WithoutScope remove named scopes that haven't been executed, and skip the named scopes added later
+func (db *DB) WithoutScope(names ...string) (tx *DB) {
+	tx = db.getInstance()
+	tx.Statement.withoutScopes = append(tx.Statement.withoutScopes, names...)
+	scopes := make([]func(*DB) *DB, 0, len(tx.Statement.scopes))
+	scopeNames := make([]string, 0, len(tx.Statement.scopeNames))
+	for idx, scope := range tx.Statement.scopes {
+		if name := tx.Statement.scopeNames[idx]; name == "" || !utils.Contains(names, name) {
+			scopes = append(scopes, scope)
+			scopeNames = append(scopeNames, name)
+		}
+	}
+	tx.Statement.scopes, tx.Statement.scopeNames = scopes, scopeNames
+	return tx
+}
+
+// HasScope returns true if the named scope exists and hasn't been executed
+func (db *DB) HasScope(name string) bool {
+	for _, scopeName := range db.Statement.scopeNames {
+		if scopeName == name {
+			return true
+		}
+	}
+	return false
+}
+
 func (db *DB) executeScopes() (tx *DB) {
 	tx = db.getInstance()
 	scopes := db.Statement.scopes
@@ -381,6 +539,7 @@ func (db *DB) executeScopes() (tx *DB) {
 		return tx
 	}
 	tx.Statement.scopes = nil
+	tx.Statement.scopeNames = nil
 
 	conditions := make([]clause.Interface, 0, 4)
 	if cs, ok := tx.Statement.Clauses["WHERE"]; ok && cs.Expression != nil {
@@ -404,10 +563,32 @@ func (db *DB) executeScopes() (tx *DB) {
 	return tx
 }
 
+// MapColumns translates column names of query results before matching them to fields when scanning,
+// joined columns like `Company__legacy_name` are translated by the last segment.
+// column keys of maps are translated as well if mapKeys is true
+//
+//	// scan legacy_name into User.Name
+//	db.MapColumns(map[string]string{"legacy_name": "name"}).Raw("SELECT id, legacy_name FROM legacy_users").Scan(&users)
+func (db *DB) MapColumns(mapping map[string]string, mapKeys ...bool) (tx *DB) {
+	tx = db.getInstance()
+	columnMapping := make(map[string]string, len(tx.Statement.ColumnMapping)+len(mapping))
+	for k, v := range tx.Statement.ColumnMapping {
+		columnMapping[k] = v
+	}
+	for k, v := range mapping {
+		columnMapping[k] = v
+	}
+	tx.Statement.ColumnMapping = columnMapping
+	tx.Statement.MapColumnKeys = len(mapKeys) > 0 && mapKeys[0]
+	return
+}
+
 // Preload preload associations with given conditions
 //
 //	// get all users, and preload all non-cancelled orders
 //	db.Preload("Orders", "state NOT IN (?)", "cancelled").Find(&users)
+//
+// relations are preloaded in order of names, nested relations are preloaded after their parents loaded
 func (db *DB) Preload(query string, args ...interface{}) (tx *DB) {
 	tx = db.getInstance()
 	if tx.Statement.Preloads == nil {
@@ -417,6 +598,73 @@ func (db *DB) Preload(query string, args ...interface{}) (tx *DB) {
 	return
 }
 
+// PreloadCountOption preload option counting associations instead of loading them, see [PreloadCount]
+type PreloadCountOption struct {
+	Field string
+}
+
+// PreloadCount counts has many or many2many associations into the given field of parents, associations are not loaded
+//
+//	type User struct {
+//		Pets      []Pet
+//		PetsCount int `gorm:"->;-:migration"`
+//	}
+//
+//	// count users' pets, pets are not loaded
+//	db.Preload("Pets", gorm.PreloadCount("PetsCount")).Find(&users)
+//	// count users' pets with conditions
+//	db.Preload("Pets", gorm.PreloadCount("PetsCount"), "name <> ?", "cat").Find(&users)
+func PreloadCount(field string) PreloadCountOption {
+	return PreloadCountOption{Field: field}
+}
+
+// PreloadJoinTableOption preload option for the join table of many2many associations, see [PreloadJoinTable]
+type PreloadJoinTableOption struct {
+	Field string
+	Conds []interface{}
+}
+
+// PreloadJoinTable filters the join rows of many2many associations with conds, and assigns the join rows into the given field of parents,
+// the field should be a slice of the join model set up by [DB.SetupJoinTable], leave it blank to filter only,
+// the option is ignored when counting associations with [PreloadCount]
+//
+//	type User struct {
+//		ID             uint
+//		Following      []User   `gorm:"many2many:follows;joinForeignKey:UserID;joinReferences:FollowingID"`
+//		FollowingJoins []Follow `gorm:"-"`
+//	}
+//
+//	type Follow struct {
+//		UserID      uint `gorm:"primaryKey"`
+//		FollowingID uint `gorm:"primaryKey"`
+//		CreatedAt   time.Time
+//		State       string
+//	}
+//
+//	db.SetupJoinTable(&User{}, "Following", &Follow{})
+//	// preload accepted followings, and their join rows into FollowingJoins
+//	db.Preload("Following", gorm.PreloadJoinTable("FollowingJoins", "state = ?", "accepted")).Find(&users)
+func PreloadJoinTable(field string, conds ...interface{}) PreloadJoinTableOption {
+	return PreloadJoinTableOption{Field: field, Conds: conds}
+}
+
+// PreloadOptions structured conditions of preloading, could be built from configuration instead of closures,
+// it can't be used with conditions or closures of the same preload
+//
+//	db.Preload("Orders", gorm.PreloadOptions{
+//		Select: []string{"ID", "Amount"},
+//		Where:  []clause.Expression{clause.Neq{Column: "state", Value: "cancelled"}},
+//		Order:  []clause.OrderByColumn{{Column: clause.Column{Name: "created_at"}, Desc: true}},
+//		Limit:  10,
+//	}).Find(&users)
+type PreloadOptions struct {
+	Order  []clause.OrderByColumn
+	Limit  int                 // limit associations of each parent, associations are limited after loaded
+	Where  []clause.Expression // only Where is used when counting associations with [PreloadCount]
+	Omit   []string
+	Select []string // foreign keys are always selected
+}
+
 // Attrs provide attributes used in [FirstOrCreate] or [FirstOrInit]
 //
 // Attrs only adds attributes if the record is not found.
@@ -465,6 +713,16 @@ func (db *DB) Unscoped() (tx *DB) {
 	return
 }
 
+// SkipPrepare executes the statement with the underlying conn pool instead of cached prepared statements when PrepareStmt is enabled,
+// it is useful for one-off or dynamically built queries that would pollute the statements cache
+//
+//	db.SkipPrepare().Where("id IN ?", ids).Find(&users)
+func (db *DB) SkipPrepare() (tx *DB) {
+	tx = db.getInstance()
+	tx.Statement.SkipPrepare = true
+	return
+}
+
 func (db *DB) Raw(sql string, values ...interface{}) (tx *DB) {
 	tx = db.getInstance()
 	tx.Statement.SQL = strings.Builder{}
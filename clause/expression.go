@@ -5,6 +5,7 @@ import (
 	"database/sql/driver"
 	"go/ast"
 	"reflect"
+	"strings"
 )
 
 // Expression expression interface
@@ -26,17 +27,60 @@ type Expr struct {
 	WithoutParentheses bool
 }
 
+// BackslashEscaper builders of databases treating backslash as the escape char in quoted strings implement it, e.g. MySQL
+type BackslashEscaper interface {
+	BackslashEscape() bool
+}
+
+// quoteState tracks quoted strings and identifiers of raw sql, placeholders inside them are kept as they are
+type quoteState struct {
+	quote     byte // 当前所在的引号，0 表示不在引号内
+	escaped   bool // 上一个字符是否是转义符 \
+	backslash bool // 引号内的 \ 是否是转义符，标准 SQL 只使用两个引号转义
+}
+
+func newQuoteState(builder Builder) quoteState {
+	if escaper, ok := builder.(BackslashEscaper); ok {
+		return quoteState{backslash: escaper.BackslashEscape()}
+	}
+	return quoteState{}
+}
+
+// step consumes the byte, returns true if it is inside a quoted run or closes it
+func (s *quoteState) step(v byte) bool {
+	if s.quote == 0 {
+		if v == '\'' || v == '"' || v == '`' {
+			s.quote = v
+		}
+		return false
+	}
+
+	if s.escaped {
+		s.escaped = false
+	} else if v == '\\' && s.backslash && s.quote != '`' {
+		s.escaped = true
+	} else if v == s.quote {
+		s.quote = 0
+	}
+	return true
+}
+
 // Build build raw expression
 func (expr Expr) Build(builder Builder) {
 	var (
 		afterParenthesis bool // 标记原始 sql 里面，当前字符的上一个字符是否是 (
 		idx              int  // sql 里面有 ? 时，当前 vars 匹配到了第几个
+		quotes           = newQuoteState(builder)
 	)
 
 	for _, v := range []byte(expr.SQL) {
 		c := string([]byte{v})
-		_ = c                                 // 只用于方便调试
-		if v == '?' && len(expr.Vars) > idx { // 遇到 ? 并且 vars 还没用完
+		_ = c // 只用于方便调试
+		// 引号内的 ? 是普通字符
+		if quotes.step(v) {
+			afterParenthesis = false
+			builder.WriteByte(v)
+		} else if v == '?' && len(expr.Vars) > idx { // 遇到 ? 并且 vars 还没用完
 			if afterParenthesis || expr.WithoutParentheses {
 				if _, ok := expr.Vars[idx].(driver.Valuer); ok {
 					builder.AddVar(builder, expr.Vars[idx])
@@ -91,6 +135,7 @@ func (expr NamedExpr) Build(builder Builder) {
 	var (
 		idx              int // sql 里面有 ? 时，当前 vars 匹配到了第几个
 		inName           bool
+		quotes           = newQuoteState(builder)
 		afterParenthesis bool                                           // 标记原始 sql 里面，当前字符的上一个字符是否是 (
 		namedMap         = make(map[string]interface{}, len(expr.Vars)) // 命名参数以及对应的值
 	)
@@ -137,10 +182,18 @@ func (expr NamedExpr) Build(builder Builder) {
 	for _, v := range []byte(expr.SQL) {
 		c := string([]byte{v})
 		_ = c // 只用于方便调试
-		if v == '@' && !inName {
-			// @ 表示命名参数开始，多个 @ 会将后面的 @ 当做名字的一部分
+		if quotes.step(v) {
+			// 引号内的 @ 和 ? 是普通字符，如 'user@example.com'
+			afterParenthesis = false
+			builder.WriteByte(v)
+		} else if v == '@' && !inName {
+			// @ 表示命名参数开始
 			inName = true // 开始读取一个命名参数
 			name = []byte{}
+		} else if v == '@' && inName && len(name) == 0 {
+			// @@ 是转义的 @，不会作为命名参数，如 MySQL 的 @@session.sql_mode
+			inName = false
+			builder.WriteString("@@")
 		} else if v == ' ' || v == ',' || v == ')' || v == '"' || v == '\'' || v == '`' || v == '\r' || v == '\n' || v == ';' {
 			// 这些特殊字符作为变量的分隔符
 			if inName { // 如果刚刚读取完成的是一个命名参数
@@ -209,6 +262,18 @@ func (expr NamedExpr) Build(builder Builder) {
 	}
 }
 
+// ColumnValueBinder builders implement it to convert the value of column before binding, e.g. serialize the value
+type ColumnValueBinder interface {
+	BindColumnValue(column interface{}, value interface{}) interface{}
+}
+
+func bindColumnValue(builder Builder, column interface{}, value interface{}) interface{} {
+	if binder, ok := builder.(ColumnValueBinder); ok {
+		return binder.BindColumnValue(column, value)
+	}
+	return value
+}
+
 // IN Whether a value is within a set of values
 type IN struct {
 	Column interface{}
@@ -224,14 +289,14 @@ func (in IN) Build(builder Builder) {
 	case 1:
 		if _, ok := in.Values[0].([]interface{}); !ok {
 			builder.WriteString(" = ")
-			builder.AddVar(builder, in.Values[0])
+			builder.AddVar(builder, bindColumnValue(builder, in.Column, in.Values[0]))
 			break
 		}
 
 		fallthrough
 	default:
 		builder.WriteString(" IN (")
-		builder.AddVar(builder, in.Values...)
+		builder.AddVar(builder, in.bindValues(builder)...)
 		builder.WriteByte(')')
 	}
 }
@@ -244,18 +309,34 @@ func (in IN) NegationBuild(builder Builder) {
 	case 1:
 		if _, ok := in.Values[0].([]interface{}); !ok {
 			builder.WriteString(" <> ")
-			builder.AddVar(builder, in.Values[0])
+			builder.AddVar(builder, bindColumnValue(builder, in.Column, in.Values[0]))
 			break
 		}
 
 		fallthrough
 	default:
 		builder.WriteString(" NOT IN (")
-		builder.AddVar(builder, in.Values...)
+		builder.AddVar(builder, in.bindValues(builder)...)
 		builder.WriteByte(')')
 	}
 }
 
+func (in IN) bindValues(builder Builder) []interface{} {
+	if _, ok := builder.(ColumnValueBinder); !ok {
+		return in.Values
+	}
+
+	values := make([]interface{}, len(in.Values))
+	for idx, value := range in.Values {
+		if _, ok := value.([]interface{}); ok {
+			values[idx] = value
+		} else {
+			values[idx] = bindColumnValue(builder, in.Column, value)
+		}
+	}
+	return values
+}
+
 // Eq equal to for where
 type Eq struct {
 	Column interface{} // 行号
@@ -273,7 +354,7 @@ func (eq Eq) Build(builder Builder) {
 			if i > 0 {
 				builder.WriteByte(',')
 			}
-			builder.AddVar(builder, rv.Index(i).Interface())
+			builder.AddVar(builder, bindColumnValue(builder, eq.Column, rv.Index(i).Interface()))
 		}
 		builder.WriteByte(')')
 	default: // 非列表值
@@ -281,7 +362,7 @@ func (eq Eq) Build(builder Builder) {
 			builder.WriteString(" IS NULL") // value 是 nil, 使用 is null
 		} else { // 其他情况用 =
 			builder.WriteString(" = ")
-			builder.AddVar(builder, eq.Value)
+			builder.AddVar(builder, bindColumnValue(builder, eq.Column, eq.Value))
 		}
 	}
 }
@@ -304,7 +385,7 @@ func (neq Neq) Build(builder Builder) {
 			if i > 0 {
 				builder.WriteByte(',')
 			}
-			builder.AddVar(builder, rv.Index(i).Interface())
+			builder.AddVar(builder, bindColumnValue(builder, neq.Column, rv.Index(i).Interface()))
 		}
 		builder.WriteByte(')')
 	default:
@@ -312,7 +393,7 @@ func (neq Neq) Build(builder Builder) {
 			builder.WriteString(" IS NOT NULL")
 		} else {
 			builder.WriteString(" <> ")
-			builder.AddVar(builder, neq.Value)
+			builder.AddVar(builder, bindColumnValue(builder, neq.Column, neq.Value))
 		}
 	}
 }
@@ -388,6 +469,104 @@ func (like Like) NegationBuild(builder Builder) {
 	builder.AddVar(builder, like.Value)
 }
 
+// Exists whether the subquery returns any rows, Query is a subquery *gorm.DB or an Expression, e.g:
+//
+//	db.Where(clause.Exists{Query: db.Model(&Pet{}).Select("1").Where("pets.user_id = users.id")})
+//	db.Where(clause.Not(clause.Exists{Query: subQuery})) // NOT EXISTS (...)
+type Exists struct {
+	Query interface{}
+}
+
+func (exists Exists) Build(builder Builder) {
+	builder.WriteString("EXISTS (")
+	builder.AddVar(builder, exists.Query)
+	builder.WriteByte(')')
+}
+
+func (exists Exists) NegationBuild(builder Builder) {
+	builder.WriteString("NOT ")
+	exists.Build(builder)
+}
+
+// DefaultLikeEscape the ESCAPE clause for patterns escaped by EscapeLike
+const DefaultLikeEscape = ` ESCAPE '\'`
+
+// BackslashLikeEscape the ESCAPE clause for databases treating backslash as the escape char in string literals, e.g. MySQL
+const BackslashLikeEscape = ` ESCAPE '\\'`
+
+// LikeEscaper returns the ESCAPE clause for patterns escaped by EscapeLike,
+// dialectors of engines using a different syntax to specify the escape char could implement it,
+// BackslashLikeEscape is used for BackslashEscaper if not implemented
+type LikeEscaper interface {
+	LikeEscape() string
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// EscapeLike escapes the wildcards of LIKE patterns with backslash, E.g:
+//
+//	EscapeLike("50%_off") // 50\%\_off
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// Contains column contains value, wildcards in value are escaped,
+// an empty value matches all non-NULL values, and never matches with Not
+type Contains struct {
+	Column interface{}
+	Value  string
+}
+
+func (contains Contains) Build(builder Builder) {
+	buildLike(builder, contains.Column, "%"+EscapeLike(contains.Value)+"%", false)
+}
+
+func (contains Contains) NegationBuild(builder Builder) {
+	buildLike(builder, contains.Column, "%"+EscapeLike(contains.Value)+"%", true)
+}
+
+// HasPrefix column starts with value, wildcards in value are escaped,
+// an empty value matches all non-NULL values, and never matches with Not
+type HasPrefix Contains
+
+func (prefix HasPrefix) Build(builder Builder) {
+	buildLike(builder, prefix.Column, EscapeLike(prefix.Value)+"%", false)
+}
+
+func (prefix HasPrefix) NegationBuild(builder Builder) {
+	buildLike(builder, prefix.Column, EscapeLike(prefix.Value)+"%", true)
+}
+
+// HasSuffix column ends with value, wildcards in value are escaped,
+// an empty value matches all non-NULL values, and never matches with Not
+type HasSuffix Contains
+
+func (suffix HasSuffix) Build(builder Builder) {
+	buildLike(builder, suffix.Column, "%"+EscapeLike(suffix.Value), false)
+}
+
+func (suffix HasSuffix) NegationBuild(builder Builder) {
+	buildLike(builder, suffix.Column, "%"+EscapeLike(suffix.Value), true)
+}
+
+func buildLike(builder Builder, column interface{}, pattern string, not bool) {
+	builder.WriteQuoted(column)
+	if not {
+		builder.WriteString(" NOT LIKE ")
+	} else {
+		builder.WriteString(" LIKE ")
+	}
+	builder.AddVar(builder, pattern)
+
+	if escaper, ok := builder.(LikeEscaper); ok {
+		builder.WriteString(escaper.LikeEscape())
+	} else if escaper, ok := builder.(BackslashEscaper); ok && escaper.BackslashEscape() {
+		builder.WriteString(BackslashLikeEscape)
+	} else {
+		builder.WriteString(DefaultLikeEscape)
+	}
+}
+
 // value 是否是 nil
 func eqNil(value interface{}) bool {
 	if valuer, ok := value.(driver.Valuer); ok && !eqNilReflect(valuer) {
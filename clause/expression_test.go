@@ -15,13 +15,29 @@ import (
 
 func TestExpr(t *testing.T) {
 	results := []struct {
-		SQL    string
-		Result string
-		Vars   []interface{}
+		SQL          string
+		Result       string
+		Vars         []interface{}
+		ExpectedVars []interface{}
 	}{{
 		SQL:    "create table ? (? ?, ? ?)",
 		Vars:   []interface{}{clause.Table{Name: "users"}, clause.Column{Name: "id"}, clause.Expr{SQL: "int"}, clause.Column{Name: "name"}, clause.Expr{SQL: "text"}},
 		Result: "create table `users` (`id` int, `name` text)",
+	}, {
+		SQL:          "name = '?' AND age = ?",
+		Vars:         []interface{}{18},
+		Result:       "name = '?' AND age = ?",
+		ExpectedVars: []interface{}{18},
+	}, {
+		SQL:          `name = "what?" AND ` + "`col?` = ? AND note = 'it''s ?' AND age = ?",
+		Vars:         []interface{}{"jinzhu", 18},
+		Result:       `name = "what?" AND ` + "`col?` = ? AND note = 'it''s ?' AND age = ?",
+		ExpectedVars: []interface{}{"jinzhu", 18},
+	}, {
+		SQL:          `note = 'it\'s ?' AND age = ?`,
+		Vars:         []interface{}{18},
+		Result:       `note = 'it\'s ?' AND age = ?`,
+		ExpectedVars: []interface{}{18},
 	}}
 
 	for idx, result := range results {
@@ -32,6 +48,10 @@ func TestExpr(t *testing.T) {
 			if stmt.SQL.String() != result.Result {
 				t.Errorf("generated SQL is not equal, expects %v, but got %v", result.Result, stmt.SQL.String())
 			}
+
+			if result.ExpectedVars != nil && !reflect.DeepEqual(result.ExpectedVars, stmt.Vars) {
+				t.Errorf("generated vars is not equal, expects %v, but got %v", result.ExpectedVars, stmt.Vars)
+			}
 		})
 	}
 }
@@ -132,6 +152,31 @@ func TestNamedExpr(t *testing.T) {
 		SQL:    "?",
 		Vars:   []interface{}{clause.Table{Name: "table", Alias: "alias", Raw: true}},
 		Result: "table alias",
+	}, {
+		SQL:          "email = 'user@example.com' AND name = @name",
+		Vars:         []interface{}{map[string]interface{}{"name": "jinzhu", "example": "example"}},
+		Result:       "email = 'user@example.com' AND name = ?",
+		ExpectedVars: []interface{}{"jinzhu"},
+	}, {
+		SQL:          `name1 = '@name' AND name2 = "@name" AND ` + "`@name` = @name AND name3 = 'it''s @name'",
+		Vars:         []interface{}{sql.Named("name", "jinzhu")},
+		Result:       `name1 = '@name' AND name2 = "@name" AND ` + "`@name` = ? AND name3 = 'it''s @name'",
+		ExpectedVars: []interface{}{"jinzhu"},
+	}, {
+		SQL:          `path = 'C:\' AND name = @name`,
+		Vars:         []interface{}{sql.Named("name", "jinzhu")},
+		Result:       `path = 'C:\' AND name = ?`,
+		ExpectedVars: []interface{}{"jinzhu"},
+	}, {
+		SQL:          "SELECT @@session.sql_mode, @@name, @name",
+		Vars:         []interface{}{sql.Named("name", "jinzhu"), sql.Named("@name", "escaped")},
+		Result:       "SELECT @@session.sql_mode, @@name, ?",
+		ExpectedVars: []interface{}{"jinzhu"},
+	}, {
+		SQL:          "age = ? AND name = @name AND note = '?@name'",
+		Vars:         []interface{}{18, sql.Named("name", "jinzhu")},
+		Result:       "age = ? AND name = ? AND note = '?@name'",
+		ExpectedVars: []interface{}{18, "jinzhu"},
 	}}
 
 	for idx, result := range results {
@@ -150,6 +195,20 @@ func TestNamedExpr(t *testing.T) {
 	}
 }
 
+func TestNamedExprBackslashEscape(t *testing.T) {
+	escapeDB, _ := gorm.Open(tests.DummyDialector{BackslashEscapes: true}, nil)
+	stmt := &gorm.Statement{DB: escapeDB, Clauses: map[string]clause.Clause{}}
+	clause.NamedExpr{SQL: `name1 = 'it\'s @name' AND name2 = @name AND note = 'C:\\'`, Vars: []interface{}{sql.Named("name", "jinzhu")}}.Build(stmt)
+
+	if expects := `name1 = 'it\'s @name' AND name2 = ? AND note = 'C:\\'`; stmt.SQL.String() != expects {
+		t.Errorf("generated SQL is not equal, expects %v, but got %v", expects, stmt.SQL.String())
+	}
+
+	if !reflect.DeepEqual([]interface{}{"jinzhu"}, stmt.Vars) {
+		t.Errorf("generated vars is not equal, expects %v, but got %v", []interface{}{"jinzhu"}, stmt.Vars)
+	}
+}
+
 func TestExpression(t *testing.T) {
 	column := "column-name"
 	results := []struct {
@@ -211,6 +270,18 @@ func TestExpression(t *testing.T) {
 		},
 		ExpectedVars: []interface{}{100},
 		Result:       "SUM(`users`.`id`) >= ?",
+	}, {
+		Expressions: []clause.Expression{
+			clause.Exists{Query: clause.Expr{SQL: "SELECT 1 FROM pets WHERE pets.user_id = users.id AND name = ?", Vars: []interface{}{"pet"}}},
+		},
+		ExpectedVars: []interface{}{"pet"},
+		Result:       "EXISTS (SELECT 1 FROM pets WHERE pets.user_id = users.id AND name = ?)",
+	}, {
+		Expressions: []clause.Expression{
+			clause.Not(clause.Exists{Query: clause.Expr{SQL: "SELECT 1 FROM pets WHERE pets.user_id = users.id AND name = ?", Vars: []interface{}{"pet"}}}),
+		},
+		ExpectedVars: []interface{}{"pet"},
+		Result:       "NOT EXISTS (SELECT 1 FROM pets WHERE pets.user_id = users.id AND name = ?)",
 	}}
 
 	for idx, result := range results {
@@ -230,3 +301,84 @@ func TestExpression(t *testing.T) {
 		}
 	}
 }
+
+func TestEscapeLike(t *testing.T) {
+	results := map[string]string{
+		"50%_off":   `50\%\_off`,
+		`C:\temp\_`: `C:\\temp\\\_`,
+		"plain":     "plain",
+		"":          "",
+	}
+
+	for value, expected := range results {
+		if escaped := clause.EscapeLike(value); escaped != expected {
+			t.Errorf("escaped %v should be %v, but got %v", value, expected, escaped)
+		}
+	}
+}
+
+func TestLikeExpressions(t *testing.T) {
+	column := "column-name"
+	results := []struct {
+		Expression   clause.Expression
+		Negation     bool
+		ExpectedVars []interface{}
+		Result       string
+	}{{
+		Expression:   clause.Contains{Column: column, Value: "50%_off"},
+		ExpectedVars: []interface{}{`%50\%\_off%`},
+		Result:       "`column-name` LIKE ? ESCAPE '\\'",
+	}, {
+		Expression:   clause.HasPrefix{Column: column, Value: "50%_off"},
+		ExpectedVars: []interface{}{`50\%\_off%`},
+		Result:       "`column-name` LIKE ? ESCAPE '\\'",
+	}, {
+		Expression:   clause.HasSuffix{Column: clause.Column{Table: "users", Name: "name"}, Value: "50%_off"},
+		ExpectedVars: []interface{}{`%50\%\_off`},
+		Result:       "`users`.`name` LIKE ? ESCAPE '\\'",
+	}, {
+		Expression:   clause.Contains{Column: column, Value: "50%_off"},
+		Negation:     true,
+		ExpectedVars: []interface{}{`%50\%\_off%`},
+		Result:       "`column-name` NOT LIKE ? ESCAPE '\\'",
+	}, {
+		// empty value matches all non-NULL values
+		Expression:   clause.HasPrefix{Column: column, Value: ""},
+		ExpectedVars: []interface{}{"%"},
+		Result:       "`column-name` LIKE ? ESCAPE '\\'",
+	}, {
+		// and never matches with Not
+		Expression:   clause.HasSuffix{Column: column, Value: ""},
+		Negation:     true,
+		ExpectedVars: []interface{}{"%"},
+		Result:       "`column-name` NOT LIKE ? ESCAPE '\\'",
+	}}
+
+	for idx, result := range results {
+		t.Run(fmt.Sprintf("case #%v", idx), func(t *testing.T) {
+			user, _ := schema.Parse(&tests.User{}, &sync.Map{}, db.NamingStrategy)
+			stmt := &gorm.Statement{DB: db, Table: user.Table, Schema: user, Clauses: map[string]clause.Clause{}}
+			if result.Negation {
+				result.Expression.(clause.NegationExpressionBuilder).NegationBuild(stmt)
+			} else {
+				result.Expression.Build(stmt)
+			}
+
+			if stmt.SQL.String() != result.Result {
+				t.Errorf("generated SQL is not equal, expects %v, but got %v", result.Result, stmt.SQL.String())
+			}
+
+			if !reflect.DeepEqual(result.ExpectedVars, stmt.Vars) {
+				t.Errorf("generated vars is not equal, expects %v, but got %v", result.ExpectedVars, stmt.Vars)
+			}
+		})
+	}
+
+	// 反斜杠在字符串中是转义符的数据库，ESCAPE 需要写成 '\\'
+	escapeDB, _ := gorm.Open(tests.DummyDialector{BackslashEscapes: true}, nil)
+	stmt := &gorm.Statement{DB: escapeDB, Clauses: map[string]clause.Clause{}}
+	clause.Contains{Column: column, Value: "50%_off"}.Build(stmt)
+	if expects := "`column-name` LIKE ? ESCAPE '\\\\'"; stmt.SQL.String() != expects {
+		t.Errorf("generated SQL is not equal, expects %v, but got %v", expects, stmt.SQL.String())
+	}
+}
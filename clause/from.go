@@ -6,6 +6,8 @@ type From struct {
 	Tables []Table
 	// 嵌套的 Join 子句
 	Joins []Join
+	// 主表的索引提示，写在主表（及其别名）之后
+	IndexHints []IndexHint
 }
 
 // Name from clause name
@@ -22,9 +24,13 @@ func (from From) Build(builder Builder) {
 			}
 
 			builder.WriteQuoted(table)
+			if idx == 0 {
+				buildIndexHints(builder, from.IndexHints)
+			}
 		}
 	} else {
 		builder.WriteQuoted(currentTable) // 默认情况下，写入当前表占位符
+		buildIndexHints(builder, from.IndexHints)
 	}
 
 	for _, join := range from.Joins { // from 带 join
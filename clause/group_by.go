@@ -3,6 +3,8 @@ package clause
 // GroupBy group by clause
 type GroupBy struct {
 	Columns []Column
+	// Expressions grouped by after the columns, e.g: DATE(created_at)
+	Expressions []Expression
 	// Having 嵌套的 Having 表达式
 	Having []Expression
 }
@@ -22,6 +24,14 @@ func (groupBy GroupBy) Build(builder Builder) {
 		builder.WriteQuoted(column)
 	}
 
+	for idx, expr := range groupBy.Expressions {
+		if idx > 0 || len(groupBy.Columns) > 0 {
+			builder.WriteByte(',')
+		}
+
+		expr.Build(builder)
+	}
+
 	if len(groupBy.Having) > 0 {
 		builder.WriteString(" HAVING ")
 		Where{Exprs: groupBy.Having}.Build(builder)
@@ -35,13 +45,17 @@ func (groupBy GroupBy) MergeClause(clause *Clause) {
 		copy(copiedColumns, v.Columns)
 		groupBy.Columns = append(copiedColumns, groupBy.Columns...)
 
+		copiedExpressions := make([]Expression, len(v.Expressions))
+		copy(copiedExpressions, v.Expressions)
+		groupBy.Expressions = append(copiedExpressions, groupBy.Expressions...)
+
 		copiedHaving := make([]Expression, len(v.Having))
 		copy(copiedHaving, v.Having)
 		groupBy.Having = append(copiedHaving, groupBy.Having...)
 	}
 	clause.Expression = groupBy
 
-	if len(groupBy.Columns) == 0 {
+	if len(groupBy.Columns) == 0 && len(groupBy.Expressions) == 0 {
 		clause.Name = ""
 	} else {
 		clause.Name = groupBy.Name()
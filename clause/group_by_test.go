@@ -32,6 +32,17 @@ func TestGroupBy(t *testing.T) {
 			"SELECT * FROM `users` GROUP BY `role`,`gender` HAVING `role` = ? AND `gender` <> ?",
 			[]interface{}{"admin", "U"},
 		},
+		{
+			[]clause.Interface{clause.Select{}, clause.From{}, clause.GroupBy{
+				Columns:     []clause.Column{{Name: "role"}},
+				Expressions: []clause.Expression{clause.Expr{SQL: "DATE(?)", Vars: []interface{}{clause.Column{Name: "created_at"}}}},
+			}, clause.GroupBy{
+				Expressions: []clause.Expression{clause.Expr{SQL: "LEFT(name, ?)", Vars: []interface{}{1}}},
+				Having:      []clause.Expression{clause.Gt{Column: clause.Expr{SQL: "count(*)"}, Value: 5}},
+			}},
+			"SELECT * FROM `users` GROUP BY `role`,DATE(`created_at`),LEFT(name, ?) HAVING count(*) > ?",
+			[]interface{}{1, 5},
+		},
 	}
 
 	for idx, result := range results {
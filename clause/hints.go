@@ -0,0 +1,138 @@
+package clause
+
+import "strings"
+
+// HintPosition position of the hint in the clause
+type HintPosition int
+
+const (
+	HintBeforeClause HintPosition = iota // before the clause, e.g: /* comment */ SELECT
+	HintAfterName                        // immediately after the verb of the clause, e.g: SELECT /*+ hint */
+	HintAfterClause                      // after the clause, e.g: FROM `users` USE INDEX (`idx_name`)
+)
+
+// Hint expression injected into the clause named Clause when building the statement, e.g:
+//
+//	db.Hint(clause.CommentBefore("SELECT", "app='api'")).Find(&users)
+//	// /* app='api' */ SELECT * FROM `users`
+//
+//	db.Hint(clause.OptimizerHints("SELECT", "MAX_EXECUTION_TIME(1000)")).Find(&users)
+//	// SELECT /*+ MAX_EXECUTION_TIME(1000) */ * FROM `users`
+//
+// the hint is ignored if the statement doesn't have the clause, the expression of HintAfterName should not add vars
+type Hint struct {
+	Clause     string // name of the clause, e.g: SELECT, INSERT, UPDATE, DELETE, FROM
+	Position   HintPosition
+	Expression Expression
+}
+
+// CommentBefore comment before the clause, e.g: /* comment */ UPDATE
+func CommentBefore(clause string, comment string) Hint {
+	return Hint{Clause: clause, Position: HintBeforeClause, Expression: Comment{Text: comment}}
+}
+
+// CommentAfter comment immediately after the verb of the clause, e.g: DELETE /* comment */ FROM
+func CommentAfter(clause string, comment string) Hint {
+	return Hint{Clause: clause, Position: HintAfterName, Expression: Comment{Text: comment}}
+}
+
+// OptimizerHints optimizer hints immediately after the verb of the clause, e.g: SELECT /*+ SET_VAR(sort_buffer_size = 16M) BKA(users) */
+func OptimizerHints(clause string, hints ...string) Hint {
+	return Hint{Clause: clause, Position: HintAfterName, Expression: Comment{Text: strings.Join(hints, " "), Optimizer: true}}
+}
+
+// UseIndex index hint of the main table, e.g: FROM `users` USE INDEX (`idx_name`)
+func UseIndex(keys ...string) Hint {
+	return Hint{Clause: "FROM", Position: HintAfterClause, Expression: IndexHint{Type: UseIndexHint, Keys: keys}}
+}
+
+// ForceIndex index hint of the main table, e.g: FROM `users` FORCE INDEX (`idx_name`)
+func ForceIndex(keys ...string) Hint {
+	return Hint{Clause: "FROM", Position: HintAfterClause, Expression: IndexHint{Type: ForceIndexHint, Keys: keys}}
+}
+
+// IgnoreIndex index hint of the main table, e.g: FROM `users` IGNORE INDEX (`idx_name`)
+func IgnoreIndex(keys ...string) Hint {
+	return Hint{Clause: "FROM", Position: HintAfterClause, Expression: IndexHint{Type: IgnoreIndexHint, Keys: keys}}
+}
+
+// Comment SQL comment /* text */, or optimizer hints /*+ text */ if Optimizer,
+// the text is escaped so that it can't end the comment
+type Comment struct {
+	Text      string
+	Optimizer bool
+}
+
+// Build build comment
+func (comment Comment) Build(builder Builder) {
+	if comment.Optimizer {
+		builder.WriteString("/*+ ")
+	} else {
+		builder.WriteString("/* ")
+	}
+	builder.WriteString(escapeComment(comment.Text))
+	builder.WriteString(" */")
+}
+
+var commentReplacer = strings.NewReplacer("*/", "* /", "/*", "/ *")
+
+// escapeComment breaks */ and /* in text, replaces until none left as a replacement could make a new one, e.g: */*
+func escapeComment(text string) string {
+	for strings.Contains(text, "*/") || strings.Contains(text, "/*") {
+		text = commentReplacer.Replace(text)
+	}
+	return text
+}
+
+// types of index hints
+const (
+	UseIndexHint    = "USE INDEX"
+	ForceIndexHint  = "FORCE INDEX"
+	IgnoreIndexHint = "IGNORE INDEX"
+)
+
+// IndexHint MySQL index hint, e.g: USE INDEX FOR JOIN (`idx_name`), written immediately after the table (and its alias)
+// of FROM and JOIN, skipped when the builder implements IndexHintSupporter and doesn't support it
+type IndexHint struct {
+	Type string // UseIndexHint, ForceIndexHint or IgnoreIndexHint
+	For  string // JOIN, ORDER BY or GROUP BY, optional
+	Keys []string
+}
+
+// IndexHintSupporter reports whether index hints are supported, e.g. the statement of dialects without index hints
+type IndexHintSupporter interface {
+	SupportIndexHint() bool
+}
+
+// buildIndexHints writes index hints after the table
+func buildIndexHints(builder Builder, hints []IndexHint) {
+	if len(hints) == 0 {
+		return
+	}
+
+	if supporter, ok := builder.(IndexHintSupporter); ok && !supporter.SupportIndexHint() {
+		return
+	}
+
+	for _, hint := range hints {
+		builder.WriteByte(' ')
+		hint.Build(builder)
+	}
+}
+
+// Build build index hint
+func (hint IndexHint) Build(builder Builder) {
+	builder.WriteString(hint.Type)
+	if hint.For != "" {
+		builder.WriteString(" FOR ")
+		builder.WriteString(hint.For)
+	}
+	builder.WriteString(" (")
+	for idx, key := range hint.Keys {
+		if idx > 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteQuoted(key)
+	}
+	builder.WriteByte(')')
+}
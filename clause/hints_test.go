@@ -0,0 +1,159 @@
+package clause_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"gorm.io/gorm/utils/tests"
+)
+
+func TestHints(t *testing.T) {
+	results := []struct {
+		Clauses []clause.Interface
+		Hints   []clause.Hint
+		Result  string
+	}{
+		{
+			[]clause.Interface{clause.Select{}, clause.From{}},
+			[]clause.Hint{clause.CommentBefore("SELECT", "app='api',route='/users'")},
+			"/* app='api',route='/users' */ SELECT * FROM `users`",
+		},
+		{
+			[]clause.Interface{clause.Select{}, clause.From{}},
+			[]clause.Hint{clause.OptimizerHints("select", "MAX_EXECUTION_TIME(1000)", "BKA(users)"), clause.CommentAfter("SELECT", "app='api'")},
+			"SELECT /*+ MAX_EXECUTION_TIME(1000) BKA(users) */ /* app='api' */ * FROM `users`",
+		},
+		{
+			[]clause.Interface{clause.Select{}, clause.From{}, clause.Where{Exprs: []clause.Expression{clause.Eq{Column: "name", Value: "jinzhu"}}}},
+			[]clause.Hint{clause.UseIndex("idx_name", "idx_age"), clause.CommentBefore("UPDATE", "ignored")},
+			"SELECT * FROM `users` USE INDEX (`idx_name`,`idx_age`) WHERE `name` = ?",
+		},
+		{
+			[]clause.Interface{clause.Select{}, clause.From{}},
+			[]clause.Hint{{Clause: "FROM", Position: clause.HintAfterClause, Expression: clause.IndexHint{Type: "FORCE INDEX", For: "ORDER BY", Keys: []string{"idx_age"}}}},
+			"SELECT * FROM `users` FORCE INDEX FOR ORDER BY (`idx_age`)",
+		},
+		{
+			[]clause.Interface{clause.Update{}, clause.Set{{Column: clause.Column{Name: "name"}, Value: "jinzhu"}}},
+			[]clause.Hint{clause.OptimizerHints("UPDATE", "NO_INDEX_MERGE(users)")},
+			"UPDATE /*+ NO_INDEX_MERGE(users) */ `users` SET `name`=?",
+		},
+		{
+			[]clause.Interface{clause.Delete{}, clause.From{}},
+			[]clause.Hint{clause.CommentBefore("DELETE", "job='cleanup'"), clause.CommentAfter("DELETE", "batch")},
+			"/* job='cleanup' */ DELETE /* batch */ FROM `users`",
+		},
+		{
+			[]clause.Interface{clause.Insert{}, clause.Values{Columns: []clause.Column{{Name: "name"}}, Values: [][]interface{}{{"jinzhu"}}}},
+			[]clause.Hint{clause.CommentAfter("INSERT", "app='api'")},
+			"INSERT /* app='api' */ INTO `users` (`name`) VALUES (?)",
+		},
+	}
+
+	for idx, result := range results {
+		t.Run(fmt.Sprintf("case #%v", idx), func(t *testing.T) {
+			if sql := buildWithHints(t, result.Clauses, result.Hints); sql != result.Result {
+				t.Errorf("SQL expects %v got %v", result.Result, sql)
+			}
+		})
+	}
+}
+
+func TestHintsEscapeComment(t *testing.T) {
+	results := []struct {
+		Comment string
+		Result  string
+	}{
+		{"app='api' */ DROP TABLE users; /*", "/* app='api' * / DROP TABLE users; / * */ SELECT * FROM `users`"},
+		{"*/*/", "/* * / * / */ SELECT * FROM `users`"},
+		{"/**/", "/* / ** / */ SELECT * FROM `users`"},
+	}
+
+	for _, result := range results {
+		sql := buildWithHints(t, []clause.Interface{clause.Select{}, clause.From{}}, []clause.Hint{clause.CommentBefore("SELECT", result.Comment)})
+		if sql != result.Result {
+			t.Errorf("SQL expects %v got %v", result.Result, sql)
+		}
+
+		if comment := sql[2:strings.Index(sql, "*/")]; strings.Contains(comment, "/*") {
+			t.Errorf("comment should not contain /*, got %v", sql)
+		}
+	}
+
+	sql := buildWithHints(t, []clause.Interface{clause.Select{}, clause.From{}}, []clause.Hint{clause.OptimizerHints("SELECT", "BKA(users) */ DROP TABLE users")})
+	if expects := "SELECT /*+ BKA(users) * / DROP TABLE users */ * FROM `users`"; sql != expects {
+		t.Errorf("SQL expects %v got %v", expects, sql)
+	}
+}
+
+func TestIndexHints(t *testing.T) {
+	joins := []clause.Join{{
+		Type:       clause.LeftJoin,
+		Table:      clause.Table{Name: "companies", Alias: "Company"},
+		ON:         clause.Where{Exprs: []clause.Expression{clause.Eq{Column: clause.Column{Table: "Company", Name: "id"}, Value: clause.Column{Table: clause.CurrentTable, Name: "company_id"}}}},
+		IndexHints: []clause.IndexHint{{Type: clause.IgnoreIndexHint, For: "JOIN", Keys: []string{"idx_companies_name"}}},
+	}}
+
+	results := []struct {
+		Clauses []clause.Interface
+		Hints   []clause.Hint
+		Result  string
+	}{
+		{
+			[]clause.Interface{clause.Select{}, clause.From{Joins: joins}},
+			[]clause.Hint{clause.UseIndex("idx_users_name")},
+			"SELECT * FROM `users` USE INDEX (`idx_users_name`) LEFT JOIN `companies` `Company` IGNORE INDEX FOR JOIN (`idx_companies_name`) ON `Company`.`id` = `users`.`company_id`",
+		},
+		{
+			[]clause.Interface{clause.Select{}, clause.From{
+				Tables:     []clause.Table{{Name: "users", Alias: "u"}, {Name: "pets"}},
+				IndexHints: []clause.IndexHint{{Type: clause.ForceIndexHint, Keys: []string{"idx_users_age"}}},
+			}},
+			[]clause.Hint{clause.IgnoreIndex("idx_users_name")},
+			"SELECT * FROM `users` `u` FORCE INDEX (`idx_users_age`) IGNORE INDEX (`idx_users_name`),`pets`",
+		},
+	}
+
+	for idx, result := range results {
+		t.Run(fmt.Sprintf("case #%v", idx), func(t *testing.T) {
+			if sql := buildWithHints(t, result.Clauses, result.Hints); sql != result.Result {
+				t.Errorf("SQL expects %v got %v", result.Result, sql)
+			}
+		})
+	}
+
+	// index hints are skipped when the dialector doesn't support them
+	noIndexHintDB, _ := gorm.Open(tests.DummyDialector{DisableIndexHints: true}, nil)
+	user, _ := schema.Parse(&tests.User{}, &sync.Map{}, noIndexHintDB.NamingStrategy)
+	stmt := gorm.Statement{DB: noIndexHintDB, Table: user.Table, Schema: user, Clauses: map[string]clause.Clause{}, Hints: []clause.Hint{clause.UseIndex("idx_users_name")}}
+	stmt.AddClause(clause.Select{})
+	stmt.AddClause(clause.From{Joins: joins})
+	stmt.Build("SELECT", "FROM")
+	if sql, expects := stmt.SQL.String(), "SELECT * FROM `users` LEFT JOIN `companies` `Company` ON `Company`.`id` = `users`.`company_id`"; sql != expects {
+		t.Errorf("SQL expects %v got %v", expects, sql)
+	}
+}
+
+func buildWithHints(t *testing.T, clauses []clause.Interface, hints []clause.Hint) string {
+	var (
+		buildNames []string
+		user, _    = schema.Parse(&tests.User{}, &sync.Map{}, db.NamingStrategy)
+		stmt       = gorm.Statement{DB: db, Table: user.Table, Schema: user, Clauses: map[string]clause.Clause{}, Hints: hints}
+	)
+
+	for _, c := range clauses {
+		buildNames = append(buildNames, c.Name())
+		stmt.AddClause(c)
+	}
+
+	stmt.Build(buildNames...)
+	if stmt.Error != nil {
+		t.Fatalf("failed to build SQL, got error %v", stmt.Error)
+	}
+	return strings.TrimSpace(stmt.SQL.String())
+}
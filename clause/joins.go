@@ -17,6 +17,8 @@ type Join struct {
 	ON Where
 	// join 的 using 选项
 	Using []string
+	// 连接表的索引提示，写在表（及其别名）之后
+	IndexHints []IndexHint
 
 	// Expression 嵌套的其他 Expression， 比如 raw SQL 模式的 NamedExpr
 	Expression Expression
@@ -33,6 +35,7 @@ func (join Join) Build(builder Builder) {
 
 		builder.WriteString("JOIN ")
 		builder.WriteQuoted(join.Table)
+		buildIndexHints(builder, join.IndexHints)
 
 		if len(join.ON.Exprs) > 0 {
 			// 指定了 join on 条件
@@ -0,0 +1,148 @@
+package clause
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	JSONQueryStylePostgres = "postgres" // column #>> '{a,b}'
+	JSONQueryStyleMySQL    = "mysql"    // JSON_UNQUOTE(JSON_EXTRACT(column, '$.a.b'))
+	JSONQueryStyleSQLite   = "sqlite"   // json_extract(column, '$.a.b')
+)
+
+// JSONQueryStyler builders (or the dialector of the builder) implement it to choose how to build JSONQuery,
+// the value is one of JSONQueryStylePostgres, JSONQueryStyleMySQL and JSONQueryStyleSQLite
+type JSONQueryStyler interface {
+	JSONQueryStyle() string
+}
+
+// JSONQuery query the value of the JSON column at Path, e.g:
+//
+//	db.Where(clause.JSONQuery{Column: "attributes", Path: []string{"role", "name"}, Equals: "admin"})
+//	// PostgreSQL: WHERE "attributes" #>> '{role,name}' = 'admin'
+//	// MySQL: WHERE JSON_UNQUOTE(JSON_EXTRACT(`attributes`,'$.role.name')) = 'admin'
+//	// SQLite: WHERE json_extract(`attributes`,'$.role.name') = 'admin'
+//
+//	db.Where(clause.JSONQuery{Column: "attributes", Path: []string{"role"}, HasKey: true})
+//	db.Order(clause.JSONQuery{Column: "attributes", Path: []string{"age"}, Desc: true})
+//
+// without Equals and HasKey, it builds the value at Path, which could be used in ORDER BY, values are compared as text on PostgreSQL
+type JSONQuery struct {
+	Column interface{} // column name or Column
+	Path   []string    // keys of objects or indexes of arrays
+	Equals interface{}
+	HasKey bool
+	Desc   bool // order by the value descending
+}
+
+// Build build JSON query expression
+func (query JSONQuery) Build(builder Builder) {
+	var style string
+	if styler, ok := builder.(JSONQueryStyler); ok {
+		style = styler.JSONQueryStyle()
+	}
+
+	switch style {
+	case JSONQueryStylePostgres:
+		if query.HasKey {
+			builder.WriteByte('(')
+			builder.WriteQuoted(query.Column)
+			builder.WriteString(" #> ")
+			builder.AddVar(builder, query.postgresPath())
+			builder.WriteString(") IS NOT NULL")
+			return
+		}
+
+		builder.WriteQuoted(query.Column)
+		builder.WriteString(" #>> ")
+		builder.AddVar(builder, query.postgresPath())
+	case JSONQueryStyleMySQL:
+		if query.HasKey {
+			builder.WriteString("JSON_EXTRACT(")
+			builder.WriteQuoted(query.Column)
+			builder.WriteByte(',')
+			builder.AddVar(builder, query.jsonPath())
+			builder.WriteString(") IS NOT NULL")
+			return
+		}
+
+		builder.WriteString("JSON_UNQUOTE(JSON_EXTRACT(")
+		builder.WriteQuoted(query.Column)
+		builder.WriteByte(',')
+		builder.AddVar(builder, query.jsonPath())
+		builder.WriteString("))")
+	case JSONQueryStyleSQLite:
+		// json_extract returns NULL for JSON null, json_type returns 'null'
+		if query.HasKey {
+			builder.WriteString("json_type(")
+			builder.WriteQuoted(query.Column)
+			builder.WriteByte(',')
+			builder.AddVar(builder, query.jsonPath())
+			builder.WriteString(") IS NOT NULL")
+			return
+		}
+
+		builder.WriteString("json_extract(")
+		builder.WriteQuoted(query.Column)
+		builder.WriteByte(',')
+		builder.AddVar(builder, query.jsonPath())
+		builder.WriteByte(')')
+	default:
+		builder.AddError(fmt.Errorf("JSON query is not supported by dialect %q", style))
+		return
+	}
+
+	if query.Equals != nil {
+		builder.WriteString(" = ")
+		builder.AddVar(builder, query.Equals)
+	} else if query.Desc {
+		builder.WriteString(" DESC")
+	}
+}
+
+// jsonPath path like $.a.b[0]."c.d" used by MySQL and SQLite
+func (query JSONQuery) jsonPath() string {
+	var path strings.Builder
+	path.WriteByte('$')
+	for _, key := range query.Path {
+		if isJSONArrayIndex(key) {
+			path.WriteString("[" + key + "]")
+		} else if isJSONIdentifier(key) {
+			path.WriteString("." + key)
+		} else {
+			path.WriteString(`."` + strings.ReplaceAll(key, `"`, `\"`) + `"`)
+		}
+	}
+	return path.String()
+}
+
+// postgresPath path like {a,b,0,"c,d"} used by PostgreSQL
+func (query JSONQuery) postgresPath() string {
+	keys := make([]string, 0, len(query.Path))
+	for _, key := range query.Path {
+		if key == "" || strings.ContainsAny(key, `{},"\ `) {
+			key = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(key) + `"`
+		}
+		keys = append(keys, key)
+	}
+	return "{" + strings.Join(keys, ",") + "}"
+}
+
+func isJSONArrayIndex(key string) bool {
+	for _, c := range key {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return key != ""
+}
+
+func isJSONIdentifier(key string) bool {
+	for idx, c := range key {
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (idx > 0 && c >= '0' && c <= '9')) {
+			return false
+		}
+	}
+	return key != ""
+}
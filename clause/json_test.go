@@ -0,0 +1,109 @@
+package clause_test
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"gorm.io/gorm/utils/tests"
+)
+
+type jsonQueryDialector struct {
+	tests.DummyDialector
+	style string
+}
+
+func (dialector jsonQueryDialector) JSONQueryStyle() string {
+	return dialector.style
+}
+
+func TestJSONQuery(t *testing.T) {
+	results := []struct {
+		Style  string
+		Query  clause.JSONQuery
+		Result string
+		Vars   []interface{}
+	}{
+		{
+			clause.JSONQueryStyleMySQL,
+			clause.JSONQuery{Column: "attributes", Path: []string{"role", "name"}, Equals: "admin"},
+			"WHERE JSON_UNQUOTE(JSON_EXTRACT(`attributes`,?)) = ?",
+			[]interface{}{"$.role.name", "admin"},
+		},
+		{
+			clause.JSONQueryStyleMySQL,
+			clause.JSONQuery{Column: clause.Column{Table: clause.CurrentTable, Name: "attributes"}, Path: []string{"tags", "0", "first name"}, HasKey: true},
+			"WHERE JSON_EXTRACT(`users`.`attributes`,?) IS NOT NULL",
+			[]interface{}{`$.tags[0]."first name"`},
+		},
+		{
+			clause.JSONQueryStylePostgres,
+			clause.JSONQuery{Column: "attributes", Path: []string{"role", "name"}, Equals: "admin"},
+			"WHERE `attributes` #>> ? = ?",
+			[]interface{}{"{role,name}", "admin"},
+		},
+		{
+			clause.JSONQueryStylePostgres,
+			clause.JSONQuery{Column: "attributes", Path: []string{"tags", "0", "a,b"}, HasKey: true},
+			"WHERE (`attributes` #> ?) IS NOT NULL",
+			[]interface{}{`{tags,0,"a,b"}`},
+		},
+		{
+			clause.JSONQueryStyleSQLite,
+			clause.JSONQuery{Column: "attributes", Path: []string{"age"}, Equals: 18},
+			"WHERE json_extract(`attributes`,?) = ?",
+			[]interface{}{"$.age", 18},
+		},
+		{
+			clause.JSONQueryStyleSQLite,
+			clause.JSONQuery{Column: "attributes", Path: []string{"role"}, HasKey: true},
+			"WHERE json_type(`attributes`,?) IS NOT NULL",
+			[]interface{}{"$.role"},
+		},
+	}
+
+	for idx, result := range results {
+		t.Run(fmt.Sprintf("case #%v", idx), func(t *testing.T) {
+			jsonDB, _ := gorm.Open(jsonQueryDialector{style: result.Style}, nil)
+			user, _ := schema.Parse(&tests.User{}, &sync.Map{}, jsonDB.NamingStrategy)
+			stmt := gorm.Statement{DB: jsonDB, Table: user.Table, Schema: user, Clauses: map[string]clause.Clause{}}
+
+			stmt.AddClause(clause.Where{Exprs: []clause.Expression{result.Query}})
+			stmt.Build("WHERE")
+
+			if sql := stmt.SQL.String(); sql != result.Result {
+				t.Errorf("SQL expects %v got %v", result.Result, sql)
+			}
+
+			if !reflect.DeepEqual(stmt.Vars, result.Vars) {
+				t.Errorf("Vars expects %+v got %v", result.Vars, stmt.Vars)
+			}
+		})
+	}
+}
+
+func TestJSONQueryOrder(t *testing.T) {
+	jsonDB, _ := gorm.Open(jsonQueryDialector{style: clause.JSONQueryStyleMySQL}, nil)
+
+	sql := jsonDB.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&tests.User{}).Order(clause.JSONQuery{Column: "attributes", Path: []string{"age"}, Desc: true}).Find(&[]tests.User{})
+	})
+
+	if expects := "SELECT * FROM `users` WHERE `users`.`deleted_at` IS NULL ORDER BY JSON_UNQUOTE(JSON_EXTRACT(`attributes`,\"$.age\")) DESC"; sql != expects {
+		t.Errorf("SQL expects %v got %v", expects, sql)
+	}
+}
+
+func TestJSONQueryUnsupported(t *testing.T) {
+	dummyDB, _ := gorm.Open(tests.DummyDialector{}, nil)
+	stmt := gorm.Statement{DB: dummyDB, Clauses: map[string]clause.Clause{}}
+	clause.JSONQuery{Column: "attributes", Path: []string{"age"}}.Build(&stmt)
+
+	if stmt.DB.Error == nil {
+		t.Errorf("should return error for unsupported dialect")
+	}
+}
@@ -1,10 +1,14 @@
 package clause
 
+import "fmt"
+
+// OnConflict on conflict clause, the conflict target is one of Columns (with TargetWhere for partial unique indexes) and OnConstraint,
+// Where is the condition of DO UPDATE
 type OnConflict struct {
 	Columns      []Column
 	Where        Where
-	TargetWhere  Where
-	OnConstraint string
+	TargetWhere  Where  // predicate of partial unique index
+	OnConstraint string // name of the unique constraint, can't be used with Columns and TargetWhere
 	DoNothing    bool
 	DoUpdates    Set
 	UpdateAll    bool
@@ -17,6 +21,12 @@ func (OnConflict) Name() string {
 // Build build onConflict clause
 func (onConflict OnConflict) Build(builder Builder) {
 	if onConflict.OnConstraint != "" {
+		// 约束名和冲突列（以及部分唯一索引的条件）只能指定一种
+		if len(onConflict.Columns) > 0 || len(onConflict.TargetWhere.Exprs) > 0 {
+			builder.AddError(fmt.Errorf("on conflict constraint %v can't be used with columns or target where", onConflict.OnConstraint))
+			return
+		}
+
 		builder.WriteString("ON CONSTRAINT ")
 		builder.WriteString(onConflict.OnConstraint)
 		builder.WriteByte(' ')
@@ -32,8 +42,9 @@ func (onConflict OnConflict) Build(builder Builder) {
 			builder.WriteString(`) `)
 		}
 
+		// the predicate of partial unique index, e.g. ON CONFLICT (email) WHERE deleted_at IS NULL
 		if len(onConflict.TargetWhere.Exprs) > 0 {
-			builder.WriteString(" WHERE ")
+			builder.WriteString("WHERE ")
 			onConflict.TargetWhere.Build(builder)
 			builder.WriteByte(' ')
 		}
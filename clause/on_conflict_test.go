@@ -0,0 +1,57 @@
+package clause_test
+
+import (
+	"fmt"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/utils/tests"
+)
+
+func TestOnConflict(t *testing.T) {
+	results := []struct {
+		Clauses []clause.Interface
+		Result  string
+		Vars    []interface{}
+	}{
+		{
+			[]clause.Interface{clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}},
+			"ON CONFLICT (`name`) DO NOTHING", nil,
+		},
+		{
+			[]clause.Interface{clause.OnConflict{OnConstraint: "users_name_key", DoUpdates: clause.AssignmentColumns([]string{"age"})}},
+			"ON CONFLICT ON CONSTRAINT users_name_key DO UPDATE SET `age`=`excluded`.`age`", nil,
+		},
+		{
+			[]clause.Interface{clause.OnConflict{
+				Columns:     []clause.Column{{Name: "name"}},
+				TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Eq{Column: "deleted_at", Value: nil}}},
+				DoUpdates:   clause.AssignmentColumns([]string{"age"}),
+				Where:       clause.Where{Exprs: []clause.Expression{clause.Gt{Column: "age", Value: 18}}},
+			}},
+			"ON CONFLICT (`name`) WHERE `deleted_at` IS NULL DO UPDATE SET `age`=`excluded`.`age` WHERE `age` > ?", []interface{}{18},
+		},
+	}
+
+	for idx, result := range results {
+		t.Run(fmt.Sprintf("case #%v", idx), func(t *testing.T) {
+			checkBuildClauses(t, result.Clauses, result.Result, result.Vars)
+		})
+	}
+}
+
+func TestOnConflictConstraintWithColumns(t *testing.T) {
+	for _, onConflict := range []clause.OnConflict{
+		{OnConstraint: "users_name_key", Columns: []clause.Column{{Name: "name"}}, DoNothing: true},
+		{OnConstraint: "users_name_key", TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Eq{Column: "deleted_at", Value: nil}}}, DoNothing: true},
+	} {
+		dummyDB, _ := gorm.Open(tests.DummyDialector{}, nil)
+		stmt := gorm.Statement{DB: dummyDB, Clauses: map[string]clause.Clause{}}
+		onConflict.Build(&stmt)
+
+		if stmt.DB.Error == nil {
+			t.Errorf("should return error when using constraint with columns or target where, got SQL %v", stmt.SQL.String())
+		}
+	}
+}
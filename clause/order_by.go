@@ -1,9 +1,12 @@
 package clause
 
+import "strconv"
+
 type OrderByColumn struct {
-	Column  Column
-	Desc    bool
-	Reorder bool
+	Column     Column
+	Expression Expression // order by the expression instead of Column, e.g. OrderByValues
+	Desc       bool
+	Reorder    bool
 }
 
 type OrderBy struct {
@@ -26,7 +29,11 @@ func (orderBy OrderBy) Build(builder Builder) {
 				builder.WriteByte(',')
 			}
 
-			builder.WriteQuoted(column.Column)
+			if column.Expression != nil {
+				column.Expression.Build(builder)
+			} else {
+				builder.WriteQuoted(column.Column)
+			}
 			if column.Desc {
 				builder.WriteString(" DESC")
 			}
@@ -52,3 +59,73 @@ func (orderBy OrderBy) MergeClause(clause *Clause) {
 
 	clause.Expression = orderBy
 }
+
+// OrderByFieldSupporter builders (or the dialector of the builder) implement it to render OrderByValues with FIELD(), e.g. MySQL
+type OrderByFieldSupporter interface {
+	SupportOrderByField() bool
+}
+
+// orderByValuesChunkSize max WHEN branches of a single CASE expression
+const orderByValuesChunkSize = 1000
+
+// OrderByValues order by the position of the column value in Values, e.g:
+//
+//	db.Order(clause.OrderByValues{Column: clause.Column{Name: "id"}, Values: []interface{}{3, 1, 2}})
+//	// MySQL: ORDER BY FIELD(`id`,3,1,2)
+//	// others: ORDER BY CASE `id` WHEN 3 THEN 0 WHEN 1 THEN 1 WHEN 2 THEN 2 END
+type OrderByValues struct {
+	Column Column
+	Values []interface{}
+}
+
+// Build build order by values expression
+func (orderByValues OrderByValues) Build(builder Builder) {
+	if len(orderByValues.Values) == 0 {
+		return
+	}
+
+	if supporter, ok := builder.(OrderByFieldSupporter); ok && supporter.SupportOrderByField() {
+		builder.WriteString("FIELD(")
+		builder.WriteQuoted(orderByValues.Column)
+		for _, value := range orderByValues.Values {
+			builder.WriteByte(',')
+			builder.AddVar(builder, value)
+		}
+		builder.WriteByte(')')
+		return
+	}
+
+	if len(orderByValues.Values) <= orderByValuesChunkSize {
+		orderByValues.buildCase(builder, 0, orderByValues.Values)
+		return
+	}
+
+	// nest CASE expressions to keep the WHEN branches of each CASE bounded
+	builder.WriteString("CASE")
+	for offset := 0; offset < len(orderByValues.Values); offset += orderByValuesChunkSize {
+		end := offset + orderByValuesChunkSize
+		if end > len(orderByValues.Values) {
+			end = len(orderByValues.Values)
+		}
+
+		builder.WriteString(" WHEN ")
+		builder.WriteQuoted(orderByValues.Column)
+		builder.WriteString(" IN (")
+		builder.AddVar(builder, orderByValues.Values[offset:end]...)
+		builder.WriteString(") THEN ")
+		orderByValues.buildCase(builder, offset, orderByValues.Values[offset:end])
+	}
+	builder.WriteString(" END")
+}
+
+func (orderByValues OrderByValues) buildCase(builder Builder, offset int, values []interface{}) {
+	builder.WriteString("CASE ")
+	builder.WriteQuoted(orderByValues.Column)
+	for idx, value := range values {
+		builder.WriteString(" WHEN ")
+		builder.AddVar(builder, value)
+		builder.WriteString(" THEN ")
+		builder.WriteString(strconv.Itoa(offset + idx))
+	}
+	builder.WriteString(" END")
+}
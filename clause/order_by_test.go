@@ -2,9 +2,14 @@ package clause_test
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
 
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"gorm.io/gorm/utils/tests"
 )
 
 func TestOrderBy(t *testing.T) {
@@ -48,6 +53,26 @@ func TestOrderBy(t *testing.T) {
 			"SELECT * FROM `users` ORDER BY FIELD(id, ?,?,?)",
 			[]interface{}{1, 2, 3},
 		},
+		{
+			[]clause.Interface{
+				clause.Select{}, clause.From{}, clause.OrderBy{
+					Expression: clause.OrderByValues{Column: clause.Column{Name: "id"}, Values: []interface{}{3, 1, 2}},
+				},
+			},
+			"SELECT * FROM `users` ORDER BY CASE `id` WHEN ? THEN 0 WHEN ? THEN 1 WHEN ? THEN 2 END",
+			[]interface{}{3, 1, 2},
+		},
+		{
+			[]clause.Interface{
+				clause.Select{}, clause.From{}, clause.OrderBy{
+					Columns: []clause.OrderByColumn{{Expression: clause.OrderByValues{Column: clause.Column{Name: "age"}, Values: []interface{}{18, 20}}}},
+				}, clause.OrderBy{
+					Columns: []clause.OrderByColumn{{Column: clause.PrimaryColumn, Desc: true}},
+				},
+			},
+			"SELECT * FROM `users` ORDER BY CASE `age` WHEN ? THEN 0 WHEN ? THEN 1 END,`users`.`id` DESC",
+			[]interface{}{18, 20},
+		},
 	}
 
 	for idx, result := range results {
@@ -56,3 +81,49 @@ func TestOrderBy(t *testing.T) {
 		})
 	}
 }
+
+type orderByFieldDialector struct {
+	tests.DummyDialector
+}
+
+func (orderByFieldDialector) SupportOrderByField() bool {
+	return true
+}
+
+func TestOrderByValuesWithField(t *testing.T) {
+	fieldDB, _ := gorm.Open(orderByFieldDialector{}, nil)
+	user, _ := schema.Parse(&tests.User{}, &sync.Map{}, fieldDB.NamingStrategy)
+	stmt := gorm.Statement{DB: fieldDB, Table: user.Table, Schema: user, Clauses: map[string]clause.Clause{}}
+
+	stmt.AddClause(clause.OrderBy{
+		Expression: clause.OrderByValues{Column: clause.Column{Table: clause.CurrentTable, Name: "id"}, Values: []interface{}{3, 1, 2}},
+	})
+	stmt.Build("ORDER BY")
+
+	if sql := stmt.SQL.String(); sql != "ORDER BY FIELD(`users`.`id`,?,?,?)" {
+		t.Errorf("SQL expects FIELD() rendering, got %v", sql)
+	}
+}
+
+func TestOrderByValuesChunked(t *testing.T) {
+	values := make([]interface{}, 2500)
+	for i := range values {
+		values[i] = i
+	}
+
+	stmt := gorm.Statement{DB: db, Clauses: map[string]clause.Clause{}}
+	clause.OrderByValues{Column: clause.Column{Name: "id"}, Values: values}.Build(&stmt)
+
+	sql := stmt.SQL.String()
+	if !strings.HasPrefix(sql, "CASE WHEN `id` IN (") || strings.Count(sql, "CASE") != 4 {
+		t.Errorf("large values should be chunked into nested CASE, got %v", sql[:100])
+	}
+
+	if !strings.Contains(sql, " THEN 2499 END END") {
+		t.Errorf("last value should keep its position, got %v", sql[len(sql)-100:])
+	}
+
+	if len(stmt.Vars) != 5000 {
+		t.Errorf("vars expects 5000, got %v", len(stmt.Vars))
+	}
+}
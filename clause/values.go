@@ -3,6 +3,14 @@ package clause
 type Values struct {
 	Columns []Column
 	Values  [][]interface{}
+	// Rows provides values of rows lazily when building instead of Values, e.g. large batch inserts
+	Rows RowsValuer
+}
+
+// RowsValuer provides values of rows lazily, Row appends values of the row at idx to dst and returns it
+type RowsValuer interface {
+	Len() int
+	Row(dst []interface{}, idx int) []interface{}
 }
 
 // Name from clause name
@@ -24,6 +32,22 @@ func (values Values) Build(builder Builder) {
 
 		builder.WriteString(" VALUES ")
 
+		if values.Rows != nil {
+			// 逐行取值并写入，复用同一个切片，不持有所有行的值
+			var row []interface{}
+			for idx := 0; idx < values.Rows.Len(); idx++ {
+				if idx > 0 {
+					builder.WriteByte(',')
+				}
+
+				row = values.Rows.Row(row[:0], idx)
+				builder.WriteByte('(')
+				builder.AddVar(builder, row...)
+				builder.WriteByte(')')
+			}
+			return
+		}
+
 		for idx, value := range values.Values {
 			if idx > 0 {
 				builder.WriteByte(',')
@@ -24,6 +24,17 @@ func TestValues(t *testing.T) {
 			"INSERT INTO `users` (`name`,`age`) VALUES (?,?),(?,?)",
 			[]interface{}{"jinzhu", 18, "josh", 1},
 		},
+		{
+			[]clause.Interface{
+				clause.Insert{},
+				clause.Values{
+					Columns: []clause.Column{{Name: "name"}, {Name: "age"}},
+					Rows:    rowsValuer{{"jinzhu", 18}, {"josh", 1}},
+				},
+			},
+			"INSERT INTO `users` (`name`,`age`) VALUES (?,?),(?,?)",
+			[]interface{}{"jinzhu", 18, "josh", 1},
+		},
 	}
 
 	for idx, result := range results {
@@ -32,3 +43,13 @@ func TestValues(t *testing.T) {
 		})
 	}
 }
+
+type rowsValuer [][]interface{}
+
+func (rows rowsValuer) Len() int {
+	return len(rows)
+}
+
+func (rows rowsValuer) Row(dst []interface{}, idx int) []interface{} {
+	return append(dst, rows[idx]...)
+}
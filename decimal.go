@@ -0,0 +1,127 @@
+package gorm
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+
+	"gorm.io/gorm/schema"
+)
+
+// decimalDefaultScale default scale of big.Rat values that can't be represented exactly as decimals
+const decimalDefaultScale = 10
+
+// big.Int, big.Rat 作为 decimal 处理，以字符串绑定和扫描，避免经过 float64 丢失精度，
+// 列的类型由方言的 DataTypeOf 根据 schema.Decimal 及 precision、scale 决定
+func init() {
+	RegisterDataType(reflect.TypeOf(big.Int{}), DataTypeSpec{
+		GORMType: schema.Decimal,
+		Bind: func(v interface{}) (driver.Value, error) {
+			i := v.(big.Int)
+			return i.String(), nil
+		},
+		Scan: func(dst reflect.Value, src interface{}) error {
+			r, ok := decimalRatOf(src)
+			if !ok {
+				return fmt.Errorf("unsupported decimal value %#v", src)
+			}
+			if !r.IsInt() {
+				return fmt.Errorf("decimal value %v is not an integer", src)
+			}
+			dst.Addr().Interface().(*big.Int).Set(r.Num())
+			return nil
+		},
+	})
+
+	RegisterDataType(reflect.TypeOf(big.Rat{}), DataTypeSpec{
+		GORMType: schema.Decimal,
+		Bind: func(v interface{}) (driver.Value, error) {
+			r := v.(big.Rat)
+			return decimalString(&r), nil
+		},
+		Scan: func(dst reflect.Value, src interface{}) error {
+			r, ok := decimalRatOf(src)
+			if !ok {
+				return fmt.Errorf("unsupported decimal value %#v", src)
+			}
+			dst.Addr().Interface().(*big.Rat).Set(r)
+			return nil
+		},
+	})
+}
+
+// decimalRatOf converts database values and decimal numbers to big.Rat
+func decimalRatOf(v interface{}) (*big.Rat, bool) {
+	switch v := v.(type) {
+	case string:
+		return new(big.Rat).SetString(v)
+	case []byte:
+		return new(big.Rat).SetString(string(v))
+	case int64:
+		return new(big.Rat).SetInt64(v), true
+	case int:
+		return new(big.Rat).SetInt64(int64(v)), true
+	case int32:
+		return new(big.Rat).SetInt64(int64(v)), true
+	case uint64:
+		return new(big.Rat).SetUint64(v), true
+	case uint:
+		return new(big.Rat).SetUint64(uint64(v)), true
+	case uint32:
+		return new(big.Rat).SetUint64(uint64(v)), true
+	case float64:
+		// 使用最短的十进制表示，避免 0.1 被转换为二进制近似值
+		return new(big.Rat).SetString(strconv.FormatFloat(v, 'g', -1, 64))
+	case float32:
+		return new(big.Rat).SetString(strconv.FormatFloat(float64(v), 'g', -1, 32))
+	case big.Int:
+		return new(big.Rat).SetInt(&v), true
+	case *big.Int:
+		if v != nil {
+			return new(big.Rat).SetInt(v), true
+		}
+	case big.Rat:
+		return new(big.Rat).Set(&v), true
+	case *big.Rat:
+		if v != nil {
+			return new(big.Rat).Set(v), true
+		}
+	}
+	return nil, false
+}
+
+// decimalString returns the exact decimal representation of r, rounded to decimalDefaultScale digits when r can't be represented exactly
+func decimalString(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+
+	// 分母只包含因子 2 和 5 时可以精确表示为有限小数
+	var (
+		denom        = new(big.Int).Set(r.Denom())
+		twos, fives  int
+		five, remain = big.NewInt(5), new(big.Int)
+	)
+	for denom.Bit(0) == 0 {
+		denom.Rsh(denom, 1)
+		twos++
+	}
+	for {
+		quo, _ := new(big.Int).QuoRem(denom, five, remain)
+		if remain.Sign() != 0 {
+			break
+		}
+		denom = quo
+		fives++
+	}
+
+	if denom.Cmp(big.NewInt(1)) != 0 {
+		return r.FloatString(decimalDefaultScale)
+	}
+	if twos > fives {
+		return r.FloatString(twos)
+	}
+	return r.FloatString(fives)
+}
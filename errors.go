@@ -2,8 +2,11 @@ package gorm
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
 )
 
 var (
@@ -35,6 +38,8 @@ var (
 	ErrInvalidField = errors.New("invalid field")
 	// ErrEmptySlice empty slice found
 	ErrEmptySlice = errors.New("empty slice found")
+	// ErrInconsistentMapKeys maps to create in batches have different keys
+	ErrInconsistentMapKeys = errors.New("maps have inconsistent keys")
 	// ErrDryRunModeUnsupported dry run mode unsupported
 	ErrDryRunModeUnsupported = errors.New("dry run mode unsupported")
 	// ErrInvalidDB invalid db
@@ -47,4 +52,146 @@ var (
 	ErrPreloadNotAllowed = errors.New("preload is not allowed when count is used")
 	// ErrDuplicatedKey occurs when there is a unique key constraint violation
 	ErrDuplicatedKey = errors.New("duplicated key not allowed")
+	// ErrCheckConstraintViolated occurs when there is a check constraint violation
+	ErrCheckConstraintViolated = errors.New("violates check constraint")
+	// ErrReadOnlyTransaction occurs when writing in a read-only transaction
+	ErrReadOnlyTransaction = errors.New("write operation in read-only transaction")
+	// ErrImmutableColumn occurs when updating an immutable column explicitly
+	ErrImmutableColumn = errors.New("immutable column can't be updated")
+	// ErrNestedTransactionOptions occurs when a nested transaction requires different options
+	ErrNestedTransactionOptions = errors.New("nested transaction can't change transaction options")
+	// ErrOptimisticLockConflict occurs when the record to update exists but its version has been changed
+	ErrOptimisticLockConflict = errors.New("optimistic lock conflict, the record has been modified")
+	// ErrInvalidPreparedStmt occurs when the prepared statement is invalid, e.g. database restarted, translate driver errors into it to re-prepare
+	ErrInvalidPreparedStmt = errors.New("invalid prepared statement")
+	// ErrNullValue occurs when scanning NULL into a non-pointer field with NullScanError policy
+	ErrNullValue = errors.New("can't scan NULL into non-pointer field")
+	// ErrPreloadOptionsWithConds occurs when PreloadOptions is used with conditions or closures of the same preload
+	ErrPreloadOptionsWithConds = errors.New("preload options can't be used with conditions")
+	// ErrPreloadReferencesNotLoaded occurs when the reference fields of a preloading relation are not loaded with StrictPreload
+	ErrPreloadReferencesNotLoaded = errors.New("references of preloading relation not loaded")
+	// ErrPreloadOrphanRecords occurs when preloaded records can't be matched to any parent with StrictPreload
+	ErrPreloadOrphanRecords = errors.New("preloaded records can't be matched to parents")
+	// ErrConcurrentStatementReuse occurs when finishers execute on the same statement concurrently with CheckConcurrentMisuse
+	ErrConcurrentStatementReuse = errors.New("statement reused concurrently, use a new chain or Session for each goroutine")
+	// ErrUniqueIndexNotFound occurs when the unique index of OnConflictForIndex is not found
+	ErrUniqueIndexNotFound = errors.New("unique index not found")
+	// ErrNonAggregatedColumn occurs when selected columns are neither aggregated nor in GROUP BY with StrictGroupBy
+	ErrNonAggregatedColumn = errors.New("selected columns must be aggregated or in GROUP BY")
+	// ErrAmbiguousColumn occurs when a duplicated column can't be matched to the model or joined relations, e.g: raw SQL selecting users.*, companies.*
+	ErrAmbiguousColumn = errors.New("ambiguous column")
+	// ErrExplainWriteStatement explain write statements without ExplainOptions.AllowWrites
+	ErrExplainWriteStatement = errors.New("explaining write statements requires AllowWrites")
 )
+
+// DuplicatedKeyError duplicated key error with the violated constraint, it matches ErrDuplicatedKey with errors.Is
+type DuplicatedKeyError struct {
+	Constraint string
+	Columns    []string
+	Err        error
+}
+
+func (e *DuplicatedKeyError) Error() string {
+	if e.Constraint != "" {
+		return fmt.Sprintf("%v: constraint %s", ErrDuplicatedKey, e.Constraint)
+	} else if len(e.Columns) > 0 {
+		return fmt.Sprintf("%v: columns %s", ErrDuplicatedKey, strings.Join(e.Columns, ", "))
+	}
+	return ErrDuplicatedKey.Error()
+}
+
+func (e *DuplicatedKeyError) Is(target error) bool {
+	return target == ErrDuplicatedKey
+}
+
+func (e *DuplicatedKeyError) Unwrap() error {
+	return e.Err
+}
+
+// CheckConstraintError check constraint error with the violated constraint, it matches ErrCheckConstraintViolated with errors.Is
+//
+// Check is the parsed check constraint of the statement's schema with the same name, nil if not found
+type CheckConstraintError struct {
+	Constraint string
+	Check      *schema.CheckConstraint
+	Err        error
+}
+
+func (e *CheckConstraintError) Error() string {
+	if e.Constraint != "" {
+		return fmt.Sprintf("%v: constraint %s", ErrCheckConstraintViolated, e.Constraint)
+	}
+	return ErrCheckConstraintViolated.Error()
+}
+
+func (e *CheckConstraintError) Is(target error) bool {
+	return target == ErrCheckConstraintViolated
+}
+
+func (e *CheckConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError the batch failed when creating in batches with PerBatchTransaction, batches before it are committed
+type BatchError struct {
+	BatchIndex int64
+	Err        error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("failed to create batch %d: %v", e.BatchIndex, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// newDuplicatedKeyError fill the violated constraint and columns of err reported by the dialector's DuplicatedKeyTranslator,
+// the constraint and columns are completed with the unique indexes of the statement's schema
+func newDuplicatedKeyError(db *DB, err error) *DuplicatedKeyError {
+	dupErr := &DuplicatedKeyError{Err: err}
+
+	if translator, ok := db.Dialector.(DuplicatedKeyTranslator); ok {
+		if constraint, columns, ok := translator.TranslateDuplicatedKey(err); ok {
+			dupErr.Constraint, dupErr.Columns = constraint, columns
+		}
+	}
+
+	if db.Statement != nil && db.Statement.Schema != nil {
+		for _, idx := range db.Statement.Schema.Indexes() {
+			if idx.Class != "UNIQUE" {
+				continue
+			}
+
+			columns := make([]string, 0, len(idx.Fields))
+			for _, field := range idx.Fields {
+				columns = append(columns, field.DBName)
+			}
+
+			if dupErr.Constraint == idx.Name && len(dupErr.Columns) == 0 {
+				dupErr.Columns = columns
+			} else if dupErr.Constraint == "" && strings.Join(dupErr.Columns, ",") == strings.Join(columns, ",") {
+				dupErr.Constraint = idx.Name
+			}
+		}
+	}
+
+	return dupErr
+}
+
+// newCheckConstraintError fill the violated constraint of err reported by the dialector's CheckConstraintTranslator and its parsed check definition
+func newCheckConstraintError(db *DB, err error) *CheckConstraintError {
+	chkErr := &CheckConstraintError{Err: err}
+
+	if translator, ok := db.Dialector.(CheckConstraintTranslator); ok {
+		if constraint, ok := translator.TranslateCheckConstraint(err); ok {
+			chkErr.Constraint = constraint
+		}
+	}
+
+	if chkErr.Constraint != "" && db.Statement != nil && db.Statement.Schema != nil {
+		chkErr.Check = db.Statement.Schema.LookUpCheckConstraint(chkErr.Constraint)
+	}
+
+	return chkErr
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"unicode"
 
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
@@ -26,8 +27,22 @@ func (db *DB) Create(value interface{}) (tx *DB) {
 	return tx.callbacks.Create().Execute(tx)
 }
 
-// CreateInBatches inserts value in batches of batchSize
+// CreateInBatches inserts value in batches of batchSize, clauses of db like ON CONFLICT are applied to every batch
 func (db *DB) CreateInBatches(value interface{}, batchSize int) (tx *DB) {
+	return db.CreateInBatchesWithOptions(value, batchSize, CreateBatchOptions{})
+}
+
+// CreateInBatchesWithOptions inserts value in batches of batchSize like CreateInBatches with options, e.g:
+//
+//	// report progress and commit each batch separately
+//	db.CreateInBatchesWithOptions(&users, 100, gorm.CreateBatchOptions{
+//	  Progress: func(batchIndex, rowsAffected int64) error {
+//	    log.Printf("batch %d created %d rows", batchIndex, rowsAffected)
+//	    return nil
+//	  },
+//	  PerBatchTransaction: true,
+//	})
+func (db *DB) CreateInBatchesWithOptions(value interface{}, batchSize int, opt CreateBatchOptions) (tx *DB) {
 	reflectValue := reflect.Indirect(reflect.ValueOf(value))
 
 	switch reflectValue.Kind() {
@@ -38,20 +53,61 @@ func (db *DB) CreateInBatches(value interface{}, batchSize int) (tx *DB) {
 		// the reflection length judgment of the optimized value
 		reflectLen := reflectValue.Len()
 
+		// 创建一批数据，每批的 statement 都复制自 tx，带有 tx 的所有 Clauses
+		createBatch := func(tx *DB, i int) (int64, error) {
+			ends := i + batchSize
+			if ends > reflectLen {
+				ends = reflectLen
+			}
+
+			subtx := tx.getInstance()
+			subtx.Statement.Dest = reflectValue.Slice(i, ends).Interface()
+			subtx.callbacks.Create().Execute(subtx)
+			return subtx.RowsAffected, subtx.Error
+		}
+
+		progress := func(batchIndex, affected int64) error {
+			if opt.Progress != nil {
+				return opt.Progress(batchIndex, affected)
+			}
+			return nil
+		}
+
+		if opt.PerBatchTransaction {
+			// 每批单独提交，出错时之前的批次已经提交，返回出错的批次
+			for i, batchIndex := 0, int64(0); i < reflectLen; i, batchIndex = i+batchSize, batchIndex+1 {
+				var affected int64
+				err := tx.Transaction(func(tx *DB) (err error) {
+					affected, err = createBatch(tx, i)
+					return err
+				})
+
+				if err == nil {
+					rowsAffected += affected
+					err = progress(batchIndex, affected)
+				}
+
+				if err != nil {
+					tx.AddError(&BatchError{BatchIndex: batchIndex, Err: err})
+					break
+				}
+			}
+
+			tx.RowsAffected = rowsAffected
+			return
+		}
+
 		callFc := func(tx *DB) error {
-			for i := 0; i < reflectLen; i += batchSize {
-				ends := i + batchSize
-				if ends > reflectLen {
-					ends = reflectLen
+			for i, batchIndex := 0, int64(0); i < reflectLen; i, batchIndex = i+batchSize, batchIndex+1 {
+				affected, err := createBatch(tx, i)
+				if err != nil {
+					return err
 				}
+				rowsAffected += affected
 
-				subtx := tx.getInstance()
-				subtx.Statement.Dest = reflectValue.Slice(i, ends).Interface()
-				subtx.callbacks.Create().Execute(subtx)
-				if subtx.Error != nil {
-					return subtx.Error
+				if err := progress(batchIndex, affected); err != nil {
+					return err
 				}
-				rowsAffected += subtx.RowsAffected
 			}
 			return nil
 		}
@@ -119,57 +175,141 @@ func (db *DB) Save(value interface{}) (tx *DB) {
 
 // First finds the first record ordered by primary key, matching given conditions conds
 func (db *DB) First(dest interface{}, conds ...interface{}) (tx *DB) {
-	tx = db.Limit(1).Order(clause.OrderByColumn{
-		Column: clause.Column{Table: clause.CurrentTable, Name: clause.PrimaryKey},
-	})
+	tx = db.Limit(1).orderByPrimaryKey(dest, false)
+	tx.Statement.Dest = dest
 	if len(conds) > 0 {
 		if exprs := tx.Statement.BuildCondition(conds[0], conds[1:]...); len(exprs) > 0 {
 			tx.Statement.AddClause(clause.Where{Exprs: exprs})
 		}
 	}
 	tx.Statement.RaiseErrorOnNotFound = true
-	tx.Statement.Dest = dest
 	return tx.callbacks.Query().Execute(tx)
 }
 
 // Take finds the first record returned by the database in no specified order, matching given conditions conds
 func (db *DB) Take(dest interface{}, conds ...interface{}) (tx *DB) {
 	tx = db.Limit(1)
+	tx.Statement.Dest = dest
 	if len(conds) > 0 {
 		if exprs := tx.Statement.BuildCondition(conds[0], conds[1:]...); len(exprs) > 0 {
 			tx.Statement.AddClause(clause.Where{Exprs: exprs})
 		}
 	}
 	tx.Statement.RaiseErrorOnNotFound = true
-	tx.Statement.Dest = dest
 	return tx.callbacks.Query().Execute(tx)
 }
 
+// TakeOptional finds the first record like Take, but reports absence through found instead of ErrRecordNotFound
+//
+//	found, err := db.Where("name = ?", "jinzhu").TakeOptional(&user)
+func (db *DB) TakeOptional(dest interface{}, conds ...interface{}) (found bool, err error) {
+	tx := db.Limit(1)
+	tx.Statement.Dest = dest
+	if len(conds) > 0 {
+		if exprs := tx.Statement.BuildCondition(conds[0], conds[1:]...); len(exprs) > 0 {
+			tx.Statement.AddClause(clause.Where{Exprs: exprs})
+		}
+	}
+	tx = tx.callbacks.Query().Execute(tx)
+
+	return tx.RowsAffected > 0, tx.Error
+}
+
 // Last finds the last record ordered by primary key, matching given conditions conds
 func (db *DB) Last(dest interface{}, conds ...interface{}) (tx *DB) {
-	tx = db.Limit(1).Order(clause.OrderByColumn{
-		Column: clause.Column{Table: clause.CurrentTable, Name: clause.PrimaryKey},
-		Desc:   true,
-	})
+	tx = db.Limit(1).orderByPrimaryKey(dest, true)
+	tx.Statement.Dest = dest
 	if len(conds) > 0 {
 		if exprs := tx.Statement.BuildCondition(conds[0], conds[1:]...); len(exprs) > 0 {
 			tx.Statement.AddClause(clause.Where{Exprs: exprs})
 		}
 	}
 	tx.Statement.RaiseErrorOnNotFound = true
+	return tx.callbacks.Query().Execute(tx)
+}
+
+// FirstBy finds the first record ordered by column instead of primary key, matching given conditions conds
+//
+//	// SELECT * FROM users ORDER BY users.created_at LIMIT 1
+//	db.FirstBy(&user, "CreatedAt")
+func (db *DB) FirstBy(dest interface{}, column string, conds ...interface{}) (tx *DB) {
+	tx = db.Limit(1)
+	tx = tx.Order(clause.OrderByColumn{Column: tx.orderColumn(dest, column)})
 	tx.Statement.Dest = dest
+	if len(conds) > 0 {
+		if exprs := tx.Statement.BuildCondition(conds[0], conds[1:]...); len(exprs) > 0 {
+			tx.Statement.AddClause(clause.Where{Exprs: exprs})
+		}
+	}
+	tx.Statement.RaiseErrorOnNotFound = true
 	return tx.callbacks.Query().Execute(tx)
 }
 
+// LastBy finds the last record ordered by column instead of primary key, matching given conditions conds
+func (db *DB) LastBy(dest interface{}, column string, conds ...interface{}) (tx *DB) {
+	tx = db.Limit(1)
+	tx = tx.Order(clause.OrderByColumn{Column: tx.orderColumn(dest, column), Desc: true})
+	tx.Statement.Dest = dest
+	if len(conds) > 0 {
+		if exprs := tx.Statement.BuildCondition(conds[0], conds[1:]...); len(exprs) > 0 {
+			tx.Statement.AddClause(clause.Where{Exprs: exprs})
+		}
+	}
+	tx.Statement.RaiseErrorOnNotFound = true
+	return tx.callbacks.Query().Execute(tx)
+}
+
+// orderByPrimaryKey orders by primary keys for First and Last, all columns of composite primary keys are used in declared order,
+// it is skipped when ORDER BY exists unless ForceStableOrder
+func (db *DB) orderByPrimaryKey(dest interface{}, desc bool) *DB {
+	if _, ok := db.Statement.Clauses["ORDER BY"]; ok && !db.ForceStableOrder {
+		return db
+	}
+
+	model := db.Statement.Model
+	if model == nil {
+		model = dest
+	}
+
+	if s, err := schema.Parse(model, db.cacheStore, db.NamingStrategy); err == nil && len(s.PrimaryFields) > 1 {
+		columns := make([]clause.OrderByColumn, 0, len(s.PrimaryFields))
+		for _, field := range s.PrimaryFields {
+			columns = append(columns, clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Desc: desc})
+		}
+		db.Statement.AddClause(clause.OrderBy{Columns: columns})
+		return db
+	}
+
+	return db.Order(clause.OrderByColumn{
+		Column: clause.Column{Table: clause.CurrentTable, Name: clause.PrimaryKey},
+		Desc:   desc,
+	})
+}
+
+// orderColumn 字段名或列名能在模型的 schema 里找到时使用 当前表.列名，否则按原样引用
+func (db *DB) orderColumn(dest interface{}, column string) clause.Column {
+	model := db.Statement.Model
+	if model == nil {
+		model = dest
+	}
+
+	if s, err := schema.Parse(model, db.cacheStore, db.NamingStrategy); err == nil {
+		if field := s.LookUpField(column); field != nil && field.DBName != "" {
+			return clause.Column{Table: clause.CurrentTable, Name: field.DBName}
+		}
+	}
+	return clause.Column{Name: column}
+}
+
 // Find finds all records matching given conditions conds
 func (db *DB) Find(dest interface{}, conds ...interface{}) (tx *DB) {
 	tx = db.getInstance()
+	tx.Statement.Dest = dest
 	if len(conds) > 0 {
 		if exprs := tx.Statement.BuildCondition(conds[0], conds[1:]...); len(exprs) > 0 {
 			tx.Statement.AddClause(clause.Where{Exprs: exprs})
 		}
 	}
-	tx.Statement.Dest = dest
 	return tx.callbacks.Query().Execute(tx)
 }
 
@@ -246,6 +386,104 @@ func (db *DB) FindInBatches(dest interface{}, batchSize int, fc func(tx *DB, bat
 	return tx
 }
 
+// FindToChannel finds records matching conditions and sends them to ch (chan T or chan *T, T is a struct) one by one
+// while scanning rows, AfterFind hooks are called for each record before sending it. ch is closed when finished,
+// sending stops when the context of the statement is done, e.g:
+//
+//	users := make(chan User, 10)
+//	go func() {
+//		err = db.WithContext(ctx).Where("age > ?", 18).FindToChannel(users).Error
+//	}()
+//	for user := range users {
+//		// ...
+//	}
+func (db *DB) FindToChannel(ch interface{}) (tx *DB) {
+	tx = db.getInstance()
+
+	chValue := reflect.ValueOf(ch)
+	if chValue.Kind() != reflect.Chan || chValue.IsNil() || chValue.Type().ChanDir()&reflect.SendDir == 0 {
+		tx.AddError(ErrInvalidValue)
+		return
+	}
+	defer chValue.Close()
+
+	elemType := chValue.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+
+	if elemType.Kind() != reflect.Struct {
+		tx.AddError(ErrInvalidValue)
+		return
+	}
+
+	if tx.Statement.Model == nil {
+		tx.Statement.Model = reflect.New(elemType).Interface()
+	}
+
+	tx = tx.Set("rows", true)
+	tx.callbacks.Row().Execute(tx)
+	rows, ok := tx.Statement.Dest.(*sql.Rows)
+	if !ok || rows == nil {
+		if tx.Error == nil {
+			tx.AddError(ErrDryRunModeUnsupported)
+		}
+		return
+	}
+	defer func() {
+		tx.AddError(rows.Close())
+	}()
+
+	var (
+		ctx       = tx.Statement.Context
+		scanTx    = tx.Session(&Session{NewDB: true})
+		afterFind = !tx.Statement.SkipHooks
+		sent      int64
+	)
+	if err := scanTx.Statement.Parse(reflect.New(elemType).Interface()); err != nil {
+		tx.AddError(err)
+		return
+	}
+
+	for rows.Next() {
+		record := reflect.New(elemType)
+		scanTx.Statement.Dest = record.Interface()
+		scanTx.Statement.ReflectValue = record.Elem()
+		Scan(rows, scanTx, ScanInitialized)
+		if scanTx.Error != nil {
+			tx.AddError(scanTx.Error)
+			return
+		}
+
+		if i, ok := record.Interface().(interface{ AfterFind(*DB) error }); ok && afterFind {
+			if tx.AddError(i.AfterFind(tx.Session(&Session{NewDB: true}))) != nil {
+				return
+			}
+		}
+
+		value := record
+		if !isPtr {
+			value = record.Elem()
+		}
+
+		// 通道阻塞时等待消费，或者 context 结束
+		if chosen, _, _ := reflect.Select([]reflect.SelectCase{
+			{Dir: reflect.SelectSend, Chan: chValue, Send: value},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+		}); chosen == 1 {
+			tx.AddError(ctx.Err())
+			return
+		}
+
+		sent++
+		tx.RowsAffected = sent
+	}
+
+	tx.AddError(rows.Err())
+	return
+}
+
 func (db *DB) assignInterfacesToValue(values ...interface{}) {
 	for _, value := range values {
 		switch v := value.(type) {
@@ -346,6 +584,11 @@ func (db *DB) FirstOrInit(dest interface{}, conds ...interface{}) (tx *DB) {
 //	result := db.Where(User{Name: "jinzhu"}).Assign(User{Email: "fake@fake.org"}).FirstOrCreate(&user)
 //	// user -> User{Name: "jinzhu", Age: 20, Email: "fake@fake.org"}
 //	// result.RowsAffected -> 1
+//
+// To be safe with concurrent creating, use it with ON CONFLICT DO NOTHING clause or TranslateError,
+// the record will be queried again if it is created by others, E.g:
+//
+//	db.Clauses(clause.OnConflict{DoNothing: true}).Where(User{Name: "jinzhu"}).FirstOrCreate(&user)
 func (db *DB) FirstOrCreate(dest interface{}, conds ...interface{}) (tx *DB) {
 	tx = db.getInstance()
 	queryTx := db.Session(&Session{}).Limit(1).Order(clause.OrderByColumn{
@@ -375,8 +618,34 @@ func (db *DB) FirstOrCreate(dest interface{}, conds ...interface{}) (tx *DB) {
 			result.assignInterfacesToValue(db.Statement.assigns...)
 		}
 
-		return tx.Create(dest)
-	} else if len(db.Statement.assigns) > 0 {
+		if !tx.firstOrCreateOnConflict() {
+			return tx.Create(dest)
+		}
+
+		// 并发创建时记录可能已被其他连接创建，冲突后重新查询该记录
+		if tx = tx.Create(dest); tx.Error != nil && !errors.Is(tx.Error, ErrDuplicatedKey) {
+			return tx
+		} else if tx.Error == nil && tx.RowsAffected > 0 {
+			return tx
+		}
+
+		createErr := tx.Error
+		tx.Error = nil
+		if result = queryTx.Find(dest, conds...); result.Error != nil {
+			tx.AddError(result.Error)
+			return tx
+		} else if result.RowsAffected == 0 {
+			// 冲突发生在其他唯一键上时查询不到记录，返回原来的冲突错误
+			if createErr != nil {
+				tx.Error = createErr
+			} else {
+				tx.AddError(ErrRecordNotFound)
+			}
+			return tx
+		}
+	}
+
+	if len(db.Statement.assigns) > 0 {
 		exprs := tx.Statement.BuildCondition(db.Statement.assigns[0], db.Statement.assigns[1:]...)
 		assigns := map[string]interface{}{}
 		for _, expr := range exprs {
@@ -396,6 +665,17 @@ func (db *DB) FirstOrCreate(dest interface{}, conds ...interface{}) (tx *DB) {
 	return tx
 }
 
+// firstOrCreateOnConflict returns true if FirstOrCreate should tolerate conflicts on creating,
+// it is enabled by ON CONFLICT DO NOTHING clause or error translation
+func (db *DB) firstOrCreateOnConflict() bool {
+	if c, ok := db.Statement.Clauses["ON CONFLICT"]; ok {
+		if onConflict, ok := c.Expression.(clause.OnConflict); ok && onConflict.DoNothing {
+			return true
+		}
+	}
+	return db.TranslateError
+}
+
 // Update updates column with value using callbacks. Reference: https://gorm.io/docs/update.html#Update-Changed-Fields
 func (db *DB) Update(column string, value interface{}) (tx *DB) {
 	tx = db.getInstance()
@@ -429,15 +709,64 @@ func (db *DB) UpdateColumns(values interface{}) (tx *DB) {
 // time if null.
 func (db *DB) Delete(value interface{}, conds ...interface{}) (tx *DB) {
 	tx = db.getInstance()
+	tx.Statement.Dest = value
 	if len(conds) > 0 {
 		if exprs := tx.Statement.BuildCondition(conds[0], conds[1:]...); len(exprs) > 0 {
 			tx.Statement.AddClause(clause.Where{Exprs: exprs})
 		}
 	}
-	tx.Statement.Dest = value
 	return tx.callbacks.Delete().Execute(tx)
 }
 
+// DeleteInBatches deletes value of slice by primary keys in batches of batchSize, one DELETE for each batch,
+// or UPDATE for soft delete models, hooks of records are called with their batch, clauses of db are applied to every batch.
+// it stops at the first error, RowsAffected is the rows deleted by the finished batches, or 0 if they are rolled back
+//
+//	db.DeleteInBatches(&users, 1000)
+func (db *DB) DeleteInBatches(value interface{}, batchSize int) (tx *DB) {
+	reflectValue := reflect.Indirect(reflect.ValueOf(value))
+	if (reflectValue.Kind() != reflect.Slice && reflectValue.Kind() != reflect.Array) || batchSize <= 0 {
+		return db.Delete(value)
+	}
+
+	var (
+		rowsAffected int64
+		reflectLen   = reflectValue.Len()
+	)
+	tx = db.getInstance()
+
+	callFc := func(tx *DB) error {
+		for i := 0; i < reflectLen; i += batchSize {
+			ends := i + batchSize
+			if ends > reflectLen {
+				ends = reflectLen
+			}
+
+			subtx := tx.getInstance()
+			subtx.Statement.Dest = reflectValue.Slice(i, ends).Interface()
+			subtx.callbacks.Delete().Execute(subtx)
+			if subtx.Error != nil {
+				return subtx.Error
+			}
+			rowsAffected += subtx.RowsAffected
+		}
+		return nil
+	}
+
+	if tx.SkipDefaultTransaction || reflectLen <= batchSize {
+		tx.AddError(callFc(tx.Session(&Session{})))
+	} else if err := tx.Transaction(callFc); err != nil {
+		tx.AddError(err)
+		// 事务回滚时已完成批次的删除也被撤销，关闭嵌套事务时不会回滚到保存点
+		if !tx.Statement.inTransaction() || !tx.DisableNestedTransaction {
+			rowsAffected = 0
+		}
+	}
+
+	tx.RowsAffected = rowsAffected
+	return
+}
+
 func (db *DB) Count(count *int64) (tx *DB) {
 	tx = db.getInstance()
 	if tx.Statement.Model == nil {
@@ -490,6 +819,30 @@ func (db *DB) Count(count *int64) (tx *DB) {
 		}
 	}
 
+	// 预加载不影响计数，仅用于预加载的 LEFT JOIN 也可以去掉，没有 schema 时保留预加载以返回错误
+	if preloads := tx.Statement.Preloads; len(preloads) > 0 && tx.Statement.Parse(tx.Statement.Model) == nil {
+		tx.Statement.Preloads = nil
+		defer func() {
+			tx.Statement.Preloads = preloads
+		}()
+	}
+
+	if joins := tx.Statement.Joins; len(joins) > 0 {
+		fromClause, hasFrom := db.Statement.Clauses["FROM"]
+		defer func() {
+			tx.Statement.Joins = joins
+			if hasFrom {
+				tx.Statement.Clauses["FROM"] = fromClause
+			} else {
+				delete(tx.Statement.Clauses, "FROM")
+			}
+		}()
+
+		if _, ok := db.Statement.Clauses["GROUP BY"]; !ok && !tx.Statement.Distinct {
+			tx.Statement.Joins = tx.countJoins()
+		}
+	}
+
 	tx.Statement.Dest = count
 	tx = tx.callbacks.Query().Execute(tx)
 
@@ -500,6 +853,219 @@ func (db *DB) Count(count *int64) (tx *DB) {
 	return
 }
 
+// Exists checks whether any record matches the conditions with SELECT 1 ... LIMIT 1, ORDER BY and preloads are ignored
+//
+//	exists, err := db.Model(&User{}).Where("name = ?", "jinzhu").Exists()
+func (db *DB) Exists() (exists bool, err error) {
+	tx := db.getInstance()
+	if tx.Statement.Model == nil {
+		tx.Statement.Model = tx.Statement.Dest
+		defer func() {
+			tx.Statement.Model = nil
+		}()
+	}
+
+	// 查询完成后恢复被替换的子句，不影响 db 后续的使用
+	for _, name := range []string{"SELECT", "ORDER BY", "LIMIT"} {
+		if c, ok := tx.Statement.Clauses[name]; ok {
+			defer func(name string, c clause.Clause) {
+				tx.Statement.Clauses[name] = c
+			}(name, c)
+		} else {
+			defer delete(tx.Statement.Clauses, name)
+		}
+	}
+
+	limit := 1
+	delete(tx.Statement.Clauses, "ORDER BY")
+	tx.Statement.AddClause(clause.Select{Expression: clause.Expr{SQL: "1"}})
+	tx.Statement.AddClause(clause.Limit{Limit: &limit})
+
+	if preloads := tx.Statement.Preloads; len(preloads) > 0 && tx.Statement.Parse(tx.Statement.Model) == nil {
+		tx.Statement.Preloads = nil
+		defer func() {
+			tx.Statement.Preloads = preloads
+		}()
+	}
+
+	if joins := tx.Statement.Joins; len(joins) > 0 {
+		fromClause, hasFrom := tx.Statement.Clauses["FROM"]
+		defer func() {
+			tx.Statement.Joins = joins
+			if hasFrom {
+				tx.Statement.Clauses["FROM"] = fromClause
+			} else {
+				delete(tx.Statement.Clauses, "FROM")
+			}
+		}()
+
+		if _, ok := tx.Statement.Clauses["GROUP BY"]; !ok {
+			tx.Statement.Joins = tx.countJoins()
+		}
+	}
+
+	var result int
+	tx.Statement.Dest = &result
+	tx = tx.callbacks.Query().Execute(tx)
+	return tx.RowsAffected > 0, tx.Error
+}
+
+// countJoins returns joins required when counting, LEFT JOINs of belongs to or has one associations
+// are only used for eager loading, they are removed unless referenced by selects, conditions or other joins
+func (db *DB) countJoins() []join {
+	stmt := db.Statement
+	if stmt.Parse(stmt.Model) != nil {
+		return stmt.Joins
+	}
+
+	// 收集 select、where、having 以及 raw join 中的 SQL，判断关联是否被引用
+	referenced := strings.Builder{}
+	for _, s := range stmt.Selects {
+		referenced.WriteString(s)
+	}
+	for _, name := range []string{"WHERE", "HAVING"} {
+		if c, ok := stmt.Clauses[name]; ok {
+			referencedStmt := &Statement{DB: db, Table: stmt.Table, Clauses: map[string]clause.Clause{}}
+			c.Build(referencedStmt)
+			referenced.WriteString(referencedStmt.SQL.String())
+		}
+	}
+
+	relationsOf := func(j join) []*schema.Relationship {
+		var relations []*schema.Relationship
+		currentRelations := stmt.Schema.Relationships.Relations
+		for _, name := range strings.Split(j.Name, ".") {
+			relation, ok := currentRelations[name]
+			if !ok {
+				return nil
+			}
+			relations = append(relations, relation)
+			currentRelations = relation.FieldSchema.Relationships.Relations
+		}
+		return relations
+	}
+
+	for _, j := range stmt.Joins {
+		if j.Clause != nil {
+			referencedStmt := &Statement{DB: db, Table: stmt.Table, Schema: stmt.Schema, Clauses: map[string]clause.Clause{}}
+			j.Clause.Build(referencedStmt)
+			referenced.WriteString(referencedStmt.SQL.String())
+		} else if relationsOf(j) == nil {
+			referenced.WriteString(j.Name)
+		}
+	}
+
+	// 按完整的标识符匹配关联名，避免 CompanyName 之类的列名被当做引用了 Company
+	identifiers := strings.FieldsFunc(referenced.String(), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '_' && r != '$'
+	})
+	isReferenced := func(name string) bool {
+		for _, identifier := range identifiers {
+			// 嵌套关联的别名以第一层关联名开头，如 Manager__Company
+			if identifier == name || strings.HasPrefix(identifier, name+"__") {
+				return true
+			}
+		}
+		return false
+	}
+
+	joins := make([]join, 0, len(stmt.Joins))
+	for _, j := range stmt.Joins {
+		relations := relationsOf(j)
+		eagerLoading := j.JoinType == clause.LeftJoin && len(relations) > 0
+		for _, relation := range relations {
+			if relation.Type != schema.BelongsTo && relation.Type != schema.HasOne {
+				eagerLoading = false
+			}
+		}
+
+		if !eagerLoading || isReferenced(relations[0].Name) {
+			joins = append(joins, j)
+		}
+	}
+	return joins
+}
+
+// ExplainQuery scans the plan of the query which Find would run into dest, with its real bind vars, e.g:
+//
+//	var plan []map[string]interface{}
+//	db.Model(&User{}).Where("name = ?", "jinzhu").ExplainQuery(&plan)
+//	// EXPLAIN SELECT * FROM `users` WHERE name = "jinzhu" AND `users`.`deleted_at` IS NULL
+//
+// the query itself isn't executed and hooks aren't called, the keyword is decided by QueryPlanExplainer,
+// write statements built by Raw are refused with ErrExplainWriteStatement unless AllowWrites
+func (db *DB) ExplainQuery(dest interface{}, opts ...*ExplainOptions) (tx *DB) {
+	var opt ExplainOptions
+	if len(opts) > 0 && opts[0] != nil {
+		opt = *opts[0]
+	}
+
+	// 以 DryRun 构建 Find 将执行的 SQL，不影响原有的 Dest
+	model := db.Statement.Model
+	if model == nil {
+		model = &[]map[string]interface{}{}
+	}
+	stmt := db.Session(&Session{DryRun: true, SkipHooks: true, SkipDefaultTransaction: true}).Find(model)
+	if stmt.Error != nil {
+		return stmt
+	}
+
+	sql := stmt.Statement.SQL.String()
+	if !opt.AllowWrites && !isReadStatement(sql) {
+		tx = db.getInstance()
+		tx.AddError(ErrExplainWriteStatement)
+		return tx
+	}
+
+	keyword := "EXPLAIN"
+	if explainer, ok := db.Dialector.(QueryPlanExplainer); ok {
+		keyword = explainer.ExplainKeyword()
+	}
+
+	// 直接写入已构建的 SQL 及参数，避免 Raw 再次解析占位符
+	tx = db.Session(&Session{NewDB: true}).getInstance()
+	tx.Statement.SQL.WriteString(keyword + " " + sql)
+	tx.Statement.Vars = stmt.Statement.Vars
+	return tx.Scan(dest)
+}
+
+// isReadStatement reports whether sql is a SELECT, or a WITH query without INSERT, UPDATE, DELETE or MERGE
+func isReadStatement(sql string) bool {
+	// 跳过前置的注释和括号
+	for {
+		sql = strings.TrimLeft(sql, " \t\r\n(")
+		if !strings.HasPrefix(sql, "/*") {
+			break
+		}
+		if idx := strings.Index(sql, "*/"); idx >= 0 {
+			sql = sql[idx+2:]
+		} else {
+			return false
+		}
+	}
+
+	words := strings.FieldsFunc(strings.ToUpper(sql), func(r rune) bool {
+		return !(r == '_' || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'))
+	})
+	if len(words) == 0 {
+		return false
+	}
+
+	switch words[0] {
+	case "SELECT":
+		return true
+	case "WITH":
+		for _, word := range words {
+			switch word {
+			case "INSERT", "UPDATE", "DELETE", "MERGE":
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
 func (db *DB) Row() *sql.Row {
 	tx := db.getInstance().Set("rows", false)
 	tx = tx.callbacks.Row().Execute(tx)
@@ -520,7 +1086,7 @@ func (db *DB) Rows() (*sql.Rows, error) {
 	return rows, tx.Error
 }
 
-// Scan scans selected value to the struct dest
+// Scan scans selected value to the struct dest, runs preloads and AfterFind hooks of dest when RunHooksOnRaw is enabled
 func (db *DB) Scan(dest interface{}) (tx *DB) {
 	config := *db.Config
 	currentLogger, newLogger := config.Logger, logger.Recorder.New()
@@ -542,6 +1108,15 @@ func (db *DB) Scan(dest interface{}) (tx *DB) {
 		return newLogger.SQL, tx.RowsAffected
 	}, tx.Error)
 	tx.Logger = currentLogger
+
+	// 像 Find 一样执行预加载和 AfterFind 钩子
+	if tx.RunHooksOnRaw && tx.Error == nil && tx.RowsAffected > 0 && tx.Statement.Schema != nil {
+		for _, name := range []string{"gorm:preload", "gorm:after_query"} {
+			if fc := tx.Callback().Query().Get(name); fc != nil {
+				fc(tx)
+			}
+		}
+	}
 	return
 }
 
@@ -549,6 +1124,11 @@ func (db *DB) Scan(dest interface{}) (tx *DB) {
 //
 //	var ages []int64
 //	db.Model(&users).Pluck("age", &ages)
+//
+// multiple columns can be plucked into a slice of structs, E.g.:
+//
+//	var results []struct{ ID uint; Name string }
+//	db.Model(&users).Pluck("id, name", &results)
 func (db *DB) Pluck(column string, dest interface{}) (tx *DB) {
 	tx = db.getInstance()
 	if tx.Statement.Model != nil {
@@ -559,7 +1139,17 @@ func (db *DB) Pluck(column string, dest interface{}) (tx *DB) {
 		}
 	}
 
-	if len(tx.Statement.Selects) != 1 {
+	if columns := tx.pluckColumns(column); len(columns) > 1 {
+		// 多列 pluck，dest 必须是结构体切片
+		if !isPluckStructSlice(dest) {
+			tx.AddError(ErrInvalidValue)
+			return
+		}
+
+		if len(tx.Statement.Selects) == 0 {
+			tx.Statement.AddClauseIfNotExists(clause.Select{Distinct: tx.Statement.Distinct, Columns: columns})
+		}
+	} else if len(tx.Statement.Selects) != 1 {
 		fields := strings.FieldsFunc(column, utils.IsValidDBNameChar)
 		tx.Statement.AddClauseIfNotExists(clause.Select{
 			Distinct: tx.Statement.Distinct,
@@ -570,6 +1160,100 @@ func (db *DB) Pluck(column string, dest interface{}) (tx *DB) {
 	return tx.callbacks.Query().Execute(tx)
 }
 
+// PluckMap queries two columns from a model, returning in the map dest keyed by keyColumn. E.g.:
+//
+//	var names map[uint]string
+//	db.Model(&User{}).PluckMap("id", "name", &names)
+func (db *DB) PluckMap(keyColumn, valueColumn string, dest interface{}) (tx *DB) {
+	tx = db.getInstance()
+	reflectValue := reflect.ValueOf(dest)
+	if reflectValue.Kind() == reflect.Ptr {
+		reflectValue = reflectValue.Elem()
+	}
+
+	if reflectValue.Kind() != reflect.Map || (reflectValue.IsNil() && !reflectValue.CanSet()) {
+		tx.AddError(ErrInvalidValue)
+		return
+	}
+
+	if tx.Statement.Model != nil && tx.Statement.Parse(tx.Statement.Model) == nil {
+		if f := tx.Statement.Schema.LookUpField(keyColumn); f != nil {
+			keyColumn = f.DBName
+		}
+		if f := tx.Statement.Schema.LookUpField(valueColumn); f != nil {
+			valueColumn = f.DBName
+		}
+	}
+
+	if len(tx.Statement.Selects) == 0 {
+		tx.Statement.AddClauseIfNotExists(clause.Select{
+			Distinct: tx.Statement.Distinct,
+			Columns: []clause.Column{
+				{Name: keyColumn, Raw: len(strings.FieldsFunc(keyColumn, utils.IsValidDBNameChar)) != 1},
+				{Name: valueColumn, Raw: len(strings.FieldsFunc(valueColumn, utils.IsValidDBNameChar)) != 1},
+			},
+		})
+	}
+
+	// 单独扫描到 map，不经过通用的 Scan
+	rows, err := tx.Rows()
+	if err != nil {
+		return
+	}
+	defer func() {
+		tx.AddError(rows.Close())
+	}()
+
+	tx.scanIntoPluckMap(rows, reflectValue)
+	return
+}
+
+// pluckColumns splits a comma separated column list, returns nil if any of them is not a plain column name
+func (db *DB) pluckColumns(column string) []clause.Column {
+	names := strings.Split(column, ",")
+	if len(names) < 2 {
+		return nil
+	}
+
+	columns := make([]clause.Column, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if fields := strings.FieldsFunc(name, utils.IsValidDBNameChar); len(fields) != 1 || fields[0] != name {
+			return nil
+		}
+
+		if db.Statement.Schema != nil {
+			if f := db.Statement.Schema.LookUpField(name); f != nil {
+				name = f.DBName
+			}
+		}
+		columns = append(columns, clause.Column{Name: name})
+	}
+	return columns
+}
+
+// isPluckStructSlice checks whether dest is a pointer to a slice of structs
+func isPluckStructSlice(dest interface{}) bool {
+	destType := reflect.TypeOf(dest)
+	if destType == nil || destType.Kind() != reflect.Ptr {
+		return false
+	}
+
+	if destType = destType.Elem(); destType.Kind() != reflect.Slice && destType.Kind() != reflect.Array {
+		return false
+	}
+
+	elemType := destType.Elem()
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	if _, ok := reflect.New(elemType).Interface().(sql.Scanner); ok {
+		return false
+	}
+	return elemType.Kind() == reflect.Struct && !elemType.ConvertibleTo(schema.TimeReflectType)
+}
+
 func (db *DB) ScanRows(rows *sql.Rows, dest interface{}) error {
 	tx := db.getInstance()
 	if err := tx.Statement.Parse(dest); !errors.Is(err, schema.ErrUnsupportedDataType) {
@@ -608,6 +1292,10 @@ func (db *DB) Connection(fc func(tx *DB) error) (err error) {
 	}
 
 	defer conn.Close()
+	if err = tx.initConn(conn); err != nil {
+		return
+	}
+
 	tx.Statement.ConnPool = conn
 	return fc(tx)
 }
@@ -623,12 +1311,36 @@ var (
 
 // Transaction start a transaction as a block, return error will rollback, otherwise to commit. Transaction executes an
 // arbitrary number of commands in fc within a transaction. On success the changes are committed; if an error occurs
-// they are rolled back.
+// they are rolled back. With RetryPolicy, fc may be called again on retryable errors.
 func (db *DB) Transaction(fc func(tx *DB) error, opts ...*sql.TxOptions) (err error) {
+	if db.RetryPolicy == nil || db.Statement.inTransaction() {
+		return db.transaction(fc, opts...)
+	}
+
+	// 遇到可重试的错误时重新执行整个事务，嵌套事务不单独重试
+	for attempt := 1; ; attempt++ {
+		if err = db.transaction(fc, opts...); err == nil || !db.retryAfter(attempt, err) {
+			return err
+		}
+	}
+}
+
+func (db *DB) transaction(fc func(tx *DB) error, opts ...*sql.TxOptions) (err error) {
 	panicked := true
 
-	if committer, ok := db.Statement.ConnPool.(TxCommitter); ok && committer != nil {
-		// nested transaction
+	if db.Statement.inTransaction() {
+		// nested transaction shares the options of the outer transaction
+		if len(opts) > 0 && opts[0] != nil {
+			var current sql.TxOptions
+			if db.Statement.TxOptions != nil {
+				current = *db.Statement.TxOptions
+			}
+
+			if *opts[0] != current {
+				return ErrNestedTransactionOptions
+			}
+		}
+
 		if !db.DisableNestedTransaction {
 			poolName := savepointNamePool.Get()
 			defer savepointNamePool.Put(poolName)
@@ -679,6 +1391,8 @@ func (db *DB) Begin(opts ...*sql.TxOptions) *DB {
 
 	if len(opts) > 0 {
 		opt = opts[0]
+	} else {
+		opt = db.DefaultTransactionOptions
 	}
 
 	switch beginner := tx.Statement.ConnPool.(type) {
@@ -690,13 +1404,31 @@ func (db *DB) Begin(opts ...*sql.TxOptions) *DB {
 		err = ErrInvalidTransaction
 	}
 
+	if err == nil {
+		if err = tx.initConn(tx.Statement.ConnPool); err != nil {
+			tx.Rollback()
+		}
+	}
+
 	if err != nil {
 		tx.AddError(err)
+	} else {
+		tx.Statement.TxOptions = opt
 	}
 
 	return tx
 }
 
+// initConn executes ConnInitSQL on the connection
+func (db *DB) initConn(conn ConnPool) error {
+	for _, sql := range db.ConnInitSQL {
+		if _, err := conn.ExecContext(db.Statement.Context, sql); err != nil {
+			return fmt.Errorf("failed to execute conn init sql %q: %w", sql, err)
+		}
+	}
+	return nil
+}
+
 // Commit commits the changes in a transaction
 func (db *DB) Commit() *DB {
 	if committer, ok := db.Statement.ConnPool.(TxCommitter); ok && committer != nil && !reflect.ValueOf(committer).IsNil() {
@@ -737,6 +1469,30 @@ func (db *DB) RollbackTo(name string) *DB {
 	return db
 }
 
+// ExecResultKey instance setting key of the result of Exec, e.g. db.Exec(sql).InstanceGet(gorm.ExecResultKey) returns gorm.ExecResult
+const ExecResultKey = "gorm:exec_result"
+
+// ExecResult result of executing raw sql, LastInsertIDError is the error of getting LastInsertID as some drivers don't support it
+type ExecResult struct {
+	RowsAffected      int64
+	LastInsertID      int64
+	LastInsertIDError error
+}
+
+// ExecResult executes raw sql, returns the result including LastInsertID
+//
+//	result, err := db.ExecResult("INSERT INTO users (name) VALUES (?)", "jinzhu")
+//	// result.LastInsertID
+func (db *DB) ExecResult(sql string, values ...interface{}) (ExecResult, error) {
+	tx := db.Exec(sql, values...)
+	if v, ok := tx.InstanceGet(ExecResultKey); ok {
+		if result, ok := v.(ExecResult); ok {
+			return result, tx.Error
+		}
+	}
+	return ExecResult{RowsAffected: tx.RowsAffected}, tx.Error
+}
+
 // Exec executes raw sql
 func (db *DB) Exec(sql string, values ...interface{}) (tx *DB) {
 	tx = db.getInstance()
@@ -3,7 +3,9 @@ package gorm
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"reflect"
 	"sort"
 	"sync"
 	"time"
@@ -30,6 +32,13 @@ type Config struct {
 	NamingStrategy schema.Namer
 	// FullSaveAssociations full save associations
 	FullSaveAssociations bool
+	// DisableAssociationSaveOnUpdate skips saving associations when updating (e.g. Save records with primary keys),
+	// unless they are selected like Select("Company") or FullSaveAssociations is enabled, creating is not affected
+	// 更新时不保存关联，除非通过 Select 指定或启用 FullSaveAssociations，创建时不受影响
+	DisableAssociationSaveOnUpdate bool
+	// CascadeDelete delete has one, has many associations recursively and many2many join records when deleting
+	// 没有数据库外键约束时，模拟级联删除
+	CascadeDelete bool
 	// Logger 自定义 log
 	Logger logger.Interface
 	// NowFunc the function to be used when creating a new timestamp
@@ -54,10 +63,71 @@ type Config struct {
 	AllowGlobalUpdate bool
 	// QueryFields executes the SQL query with all fields of the table
 	QueryFields bool
+	// DedupBindVars reuses the placeholder of an equal var, only works when the dialector implements BindVarIndexer
+	// 相同的参数复用同一个占位符，需要方言支持编号或命名占位符
+	DedupBindVars bool
 	// CreateBatchSize default create batch size 分批创建的时候，每批大小
 	CreateBatchSize int
+	// PreloadBatchSize max parent keys of a single preload query, preload in batches when exceeded
+	PreloadBatchSize int
+	// StrictPreload reports ErrPreloadReferencesNotLoaded when the reference fields of preloading relations are not loaded by the query,
+	// and ErrPreloadOrphanRecords with the count of preloaded records that can't be matched to any parent
+	// 预加载时检查关联的外键是否被查询，以及查询到的关联记录是否都能匹配到父记录
+	StrictPreload bool
+	// ConnInitSQL statements executed on the connection before using it in Connection and transactions,
+	// e.g. SET search_path, SET time_zone, statements executed outside them may run on other connections of the pool,
+	// use Connection to run them on the same initialized connection
+	// 在 Connection 和事务使用连接前执行，用于设置连接级别的会话变量
+	ConnInitSQL []string
+	// RunHooksOnRaw runs preloads and AfterFind hooks after scanning Raw queries into models with Scan, like Find does
+	// 使用 Scan 扫描原生 SQL 的结果到模型后，执行预加载和 AfterFind 钩子
+	RunHooksOnRaw bool
+	// DefaultTransactionOptions default options when beginning transactions
+	DefaultTransactionOptions *sql.TxOptions
 	// TranslateError enabling error translation
 	TranslateError bool
+	// QueryRewriter rewrites SQL just before executing
+	// 执行前改写 sql，比如添加注释、hint
+	QueryRewriter QueryRewriter
+	// TraceCallbacks records the duration of each callback, retrieve them with db.InstanceGet(CallbackTimingsKey)
+	// 记录每个回调的耗时，用于调试
+	TraceCallbacks bool
+	// NullScanPolicy how to scan NULL into non-pointer bool, numeric and string fields, leaves these fields untouched by default
+	// 查询结果为 NULL 而字段不是指针时的处理方式
+	NullScanPolicy NullScanPolicy
+	// FetchGeneratedKeys queries the primary keys generated by database with unique fields after creating,
+	// used when the primary key is not an integer (e.g. uuid) and the database doesn't support RETURNING
+	// 不支持 RETURNING 时，创建后通过唯一字段查询数据库生成的非整数主键
+	FetchGeneratedKeys bool
+	// ForceStableOrder First and Last order by primary keys even if ORDER BY is specified
+	// First、Last 已指定排序时，仍然追加主键排序
+	ForceStableOrder bool
+	// PropagateUnscoped shows soft deleted records in the new statements created by NewDB sessions (e.g. saving associations) of an unscoped db,
+	// deletes of them are still soft deletes, preload and joins always follow Unscoped of the statement
+	// Unscoped 传递到 NewDB 创建的新 statement（如保存关联），只影响软删除数据的可见性，不会变为永久删除
+	PropagateUnscoped bool
+	// StrictGroupBy reports ErrNonAggregatedColumn when selected columns are neither aggregated nor in GROUP BY,
+	// which fails on MySQL with ONLY_FULL_GROUP_BY and PostgreSQL, only columns specified with Select are checked
+	// 查询时检查 Select 指定的列是否都已聚合或者在 GROUP BY 中
+	StrictGroupBy bool
+	// RetryPolicy retries transactions and statements executed outside transactions on retryable errors, e.g. deadlocks
+	// 遇到可重试的错误（如死锁）时，自动重试事务以及事务外执行的语句
+	RetryPolicy *RetryPolicy
+	// CheckConcurrentMisuse reports ErrConcurrentStatementReuse when finishers execute on the same statement concurrently,
+	// e.g. a chained *DB shared by goroutines, for debugging, it doesn't check anything when disabled
+	// 调试用，检测多个协程同时在同一个 statement 上执行（如共享带条件的 *DB）
+	CheckConcurrentMisuse bool
+	// FillTimeDefaultsInApp fills zero time fields whose default value is the current time (e.g. now(), current_timestamp)
+	// with NowFunc when creating, instead of leaving them to the database, the default value is still migrated
+	// 创建时用 NowFunc 填充默认值为当前时间函数的零值时间字段，不依赖数据库默认值
+	FillTimeDefaultsInApp bool
+	// StreamedInsert builds VALUES of creating slices row by row instead of converting values of all rows first,
+	// which reduces memory of large batch inserts, batches are still split by CreateBatchSize
+	// 批量创建时逐行生成 VALUES，不再预先转换出所有行的值，减少大批量插入的内存占用
+	StreamedInsert bool
+	// StreamedInsertThreshold enables StreamedInsert for creating slices longer than it, 0 means never
+	// 批量创建的行数超过该值时启用 StreamedInsert
+	StreamedInsertThreshold int
 
 	// ClauseBuilders clause builder
 	// 子句构建器，可以覆盖子句默认实现
@@ -132,11 +202,67 @@ type Session struct {
 	// 允许没有 where 条件的全表更新
 	AllowGlobalUpdate    bool
 	FullSaveAssociations bool
+	CascadeDelete        bool
 	QueryFields          bool
+	DedupBindVars        bool
 	Context              context.Context
 	Logger               logger.Interface
 	NowFunc              func() time.Time
 	CreateBatchSize      int
+	PreloadBatchSize     int
+	TraceCallbacks       bool
+	NullScanPolicy       NullScanPolicy
+	FetchGeneratedKeys   bool
+	ForceStableOrder     bool
+	PropagateUnscoped    bool
+	StrictGroupBy        bool
+	StreamedInsert       bool
+	StrictPreload        bool
+	RunHooksOnRaw        bool
+	// DisableAssociationSaveOnUpdate overrides Config.DisableAssociationSaveOnUpdate when not nil,
+	// e.g. set it to false to save associations when updating in a session
+	DisableAssociationSaveOnUpdate *bool
+	// TablePrefix, TableSuffix are added to table names when building SQL, e.g. tables sharded by tenant
+	// 生成 SQL 时给表名（包括关联表和多对多的连接表）添加前后缀，原生 SQL 和 Table 指定的表名不受影响
+	TablePrefix string
+	TableSuffix string
+}
+
+// NullScanPolicy policy of scanning NULL into non-pointer bool, numeric and string fields
+type NullScanPolicy int
+
+const (
+	// NullScanError reports ErrNullValue and leaves the field untouched
+	NullScanError NullScanPolicy = iota + 1
+	// NullScanZeroValue sets the field to its zero value
+	NullScanZeroValue
+	// NullScanSkipField keeps the previous value of the field
+	NullScanSkipField
+)
+
+// RetryPolicy policy of retrying on retryable errors, e.g. MySQL deadlocks (1213) and PostgreSQL serialization failures (40001),
+// Transaction reruns the function, statements executed outside transactions rerun their callbacks including hooks,
+// statements failed after rows affected (e.g. RETURNING scanned into Dest) are not retried
+type RetryPolicy struct {
+	MaxAttempts  int                             // max attempts including the first one
+	Backoff      func(attempt int) time.Duration // wait before retrying after the attempt-th attempt failed, retry immediately if nil
+	RetryableErr func(error) bool                // reports retryable errors, uses the dialector's RetryableErrorTranslator if nil
+}
+
+// CreateBatchOptions options for CreateInBatchesWithOptions
+type CreateBatchOptions struct {
+	// Progress is called after each batch created with the batch index starting from 0 and rows affected by the batch,
+	// returning an error stops creating the remaining batches
+	Progress func(batchIndex, rowsAffected int64) error
+	// PerBatchTransaction commits each batch in its own transaction instead of holding one transaction for all batches,
+	// batches committed before a failure are kept, the failed batch is reported by *BatchError
+	PerBatchTransaction bool
+}
+
+// ExplainOptions options for ExplainQuery
+type ExplainOptions struct {
+	// AllowWrites explains statements other than SELECT, be careful as EXPLAIN ANALYZE executes them
+	AllowWrites bool
 }
 
 // Open initialize db session based on dialector
@@ -224,6 +350,9 @@ func Open(dialector Dialector, opts ...Option) (db *DB, err error) {
 		Mux:         &sync.RWMutex{},
 		PreparedSQL: make([]string, 0, 100),
 	}
+	if translator, ok := config.Dialector.(ErrorTranslator); ok {
+		preparedStmt.translator = translator
+	}
 	db.cacheStore.Store(preparedStmtDBKey, preparedStmt)
 
 	if config.PrepareStmt {
@@ -265,6 +394,46 @@ func (db *DB) Session(config *Session) *DB {
 		tx.Config.CreateBatchSize = config.CreateBatchSize
 	}
 
+	if config.PreloadBatchSize > 0 {
+		tx.Config.PreloadBatchSize = config.PreloadBatchSize
+	}
+
+	if config.TraceCallbacks {
+		tx.Config.TraceCallbacks = true
+	}
+
+	if config.NullScanPolicy != 0 {
+		tx.Config.NullScanPolicy = config.NullScanPolicy
+	}
+
+	if config.FetchGeneratedKeys {
+		tx.Config.FetchGeneratedKeys = true
+	}
+
+	if config.ForceStableOrder {
+		tx.Config.ForceStableOrder = true
+	}
+
+	if config.PropagateUnscoped {
+		tx.Config.PropagateUnscoped = true
+	}
+
+	if config.StrictGroupBy {
+		tx.Config.StrictGroupBy = true
+	}
+
+	if config.StreamedInsert {
+		tx.Config.StreamedInsert = true
+	}
+
+	if config.StrictPreload {
+		tx.Config.StrictPreload = true
+	}
+
+	if config.RunHooksOnRaw {
+		tx.Config.RunHooksOnRaw = true
+	}
+
 	if config.SkipDefaultTransaction {
 		tx.Config.SkipDefaultTransaction = true
 	}
@@ -277,7 +446,15 @@ func (db *DB) Session(config *Session) *DB {
 		txConfig.FullSaveAssociations = true
 	}
 
-	if config.Context != nil || config.PrepareStmt || config.SkipHooks {
+	if config.CascadeDelete {
+		txConfig.CascadeDelete = true
+	}
+
+	if config.DisableAssociationSaveOnUpdate != nil {
+		txConfig.DisableAssociationSaveOnUpdate = *config.DisableAssociationSaveOnUpdate
+	}
+
+	if config.Context != nil || config.PrepareStmt || config.SkipHooks || config.TablePrefix != "" || config.TableSuffix != "" {
 		tx.Statement = tx.Statement.clone()
 		tx.Statement.DB = tx
 	}
@@ -297,9 +474,10 @@ func (db *DB) Session(config *Session) *DB {
 				}
 			default:
 				tx.Statement.ConnPool = &PreparedStmtDB{
-					ConnPool: db.Config.ConnPool,
-					Mux:      preparedStmt.Mux,
-					Stmts:    preparedStmt.Stmts,
+					ConnPool:   db.Config.ConnPool,
+					Mux:        preparedStmt.Mux,
+					Stmts:      preparedStmt.Stmts,
+					translator: preparedStmt.translator,
 				}
 			}
 			txConfig.ConnPool = tx.Statement.ConnPool
@@ -311,6 +489,14 @@ func (db *DB) Session(config *Session) *DB {
 		tx.Statement.SkipHooks = true
 	}
 
+	if config.TablePrefix != "" {
+		tx.Statement.TablePrefix = config.TablePrefix
+	}
+
+	if config.TableSuffix != "" {
+		tx.Statement.TableSuffix = config.TableSuffix
+	}
+
 	if config.DisableNestedTransaction {
 		txConfig.DisableNestedTransaction = true
 	}
@@ -327,6 +513,10 @@ func (db *DB) Session(config *Session) *DB {
 		tx.Config.QueryFields = true
 	}
 
+	if config.DedupBindVars {
+		tx.Config.DedupBindVars = true
+	}
+
 	if config.Logger != nil {
 		tx.Config.Logger = config.Logger
 	}
@@ -389,7 +579,16 @@ func (db *DB) AddError(err error) error {
 	if err != nil {
 		if db.Config.TranslateError {
 			if errTranslator, ok := db.Dialector.(ErrorTranslator); ok {
-				err = errTranslator.Translate(err)
+				translatedErr := errTranslator.Translate(err)
+
+				var dupErr *DuplicatedKeyError
+				var chkErr *CheckConstraintError
+				if errors.Is(translatedErr, ErrDuplicatedKey) && !errors.As(translatedErr, &dupErr) {
+					translatedErr = newDuplicatedKeyError(db, err)
+				} else if errors.Is(translatedErr, ErrCheckConstraintViolated) && !errors.As(translatedErr, &chkErr) {
+					translatedErr = newCheckConstraintError(db, err)
+				}
+				err = translatedErr
 			}
 		}
 
@@ -402,6 +601,18 @@ func (db *DB) AddError(err error) error {
 	return db.Error
 }
 
+// PreparedStmts returns the prepared statements manager of db, e.g. for metrics of prepared statements,
+// returns false if db is not running with PrepareStmt
+func (db *DB) PreparedStmts() (*PreparedStmtDB, bool) {
+	switch connPool := db.Statement.ConnPool.(type) {
+	case *PreparedStmtDB:
+		return connPool, true
+	case *PreparedStmtTX:
+		return connPool.PreparedStmtDB, true
+	}
+	return nil, false
+}
+
 // DB returns `*sql.DB`
 func (db *DB) DB() (*sql.DB, error) {
 	connPool := db.ConnPool
@@ -417,6 +628,15 @@ func (db *DB) DB() (*sql.DB, error) {
 	return nil, ErrInvalidDB
 }
 
+// Ping verifies the connection to the database is still alive
+func (db *DB) Ping(ctx context.Context) error {
+	sqldb, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqldb.PingContext(ctx)
+}
+
 // 处理 db 的
 func (db *DB) getInstance() *DB {
 	if db.clone > 0 {
@@ -426,11 +646,17 @@ func (db *DB) getInstance() *DB {
 			// clone with new statement
 			// statement 用全新的，只继承一些必要数据
 			tx.Statement = &Statement{
-				DB:       tx,
-				ConnPool: db.Statement.ConnPool,
-				Context:  db.Statement.Context,
-				Clauses:  map[string]clause.Clause{},
-				Vars:     make([]interface{}, 0, 8),
+				DB:        tx,
+				ConnPool:  db.Statement.ConnPool,
+				Context:   db.Statement.Context,
+				TxOptions: db.Statement.TxOptions,
+				Clauses:   map[string]clause.Clause{},
+				Vars:      make([]interface{}, 0, 8),
+			}
+			tx.Statement.TablePrefix = db.Statement.TablePrefix
+			tx.Statement.TableSuffix = db.Statement.TableSuffix
+			if db.PropagateUnscoped {
+				tx.Statement.unscopedQuery = db.Statement.Unscoped || db.Statement.unscopedQuery
 			}
 		} else {
 			// 继承之前的 Statement 副本
@@ -450,6 +676,38 @@ func Expr(expr string, args ...interface{}) clause.Expr {
 	return clause.Expr{SQL: expr, Vars: args}
 }
 
+// RegisterDefaultValuer register default valuer used by fields with tag `default:fn:name` when creating them with zero value, e.g:
+//
+//	gorm.RegisterDefaultValuer("uuid", func(ctx context.Context) interface{} { return uuid.NewString() })
+//
+//	type User struct {
+//	  ID string `gorm:"primaryKey;default:fn:uuid"`
+//	}
+func RegisterDefaultValuer(name string, valuer func(ctx context.Context) interface{}) {
+	schema.RegisterDefaultValuer(name, valuer)
+}
+
+// DataTypeSpec spec of custom data type registered by RegisterDataType
+type DataTypeSpec = schema.DataTypeSpec
+
+// RegisterDataType register data type for types can't implement Scanner/Valuer and GormDataTypeInterface, e.g. types of third-party packages,
+// should be called before parsing models having the type, e.g:
+//
+//	gorm.RegisterDataType(reflect.TypeOf(decimal.Decimal{}), gorm.DataTypeSpec{
+//	  GORMType: schema.String,
+//	  DBType: func(dialect string, field *schema.Field) string {
+//	    if dialect == "postgres" {
+//	      return "numeric"
+//	    }
+//	    return "decimal(20,8)"
+//	  },
+//	  Bind: func(v interface{}) (driver.Value, error) { return v.(decimal.Decimal).String(), nil },
+//	  Scan: func(dst reflect.Value, src interface{}) error { ... },
+//	})
+func RegisterDataType(t reflect.Type, spec DataTypeSpec) {
+	schema.RegisterDataType(t, spec)
+}
+
 // SetupJoinTable setup join table schema
 func (db *DB) SetupJoinTable(model interface{}, field string, joinTable interface{}) error {
 	var (
@@ -501,6 +759,73 @@ func (db *DB) SetupJoinTable(model interface{}, field string, joinTable interfac
 	return nil
 }
 
+// SchemaOf returns the parsed schema of model without issuing queries, the schema is parsed with db's naming strategy
+// and shares the cache with statements, E.g:
+//
+//	s, err := db.SchemaOf(&User{})
+//	for _, column := range s.Columns() {
+//		fmt.Println(column.DBName, column.DataType)
+//	}
+func (db *DB) SchemaOf(model interface{}) (*schema.Schema, error) {
+	return schema.Parse(model, db.cacheStore, db.NamingStrategy)
+}
+
+// OnConflictForIndex returns OnConflict with the columns of the unique index name of model, and TargetWhere if it is
+// a partial index, so the conflict target follows the uniqueIndex tags of the model, e.g:
+//
+//	onConflict, err := gorm.OnConflictForIndex(db, &User{}, "idx_users_email")
+//	onConflict.UpdateAll = true
+//	db.Clauses(onConflict).Create(&users)
+func OnConflictForIndex(db *DB, model interface{}, name string) (clause.OnConflict, error) {
+	s, err := db.SchemaOf(model)
+	if err != nil {
+		return clause.OnConflict{}, err
+	}
+
+	index, ok := s.Indexes()[name]
+	if !ok || index.Class != "UNIQUE" {
+		return clause.OnConflict{}, fmt.Errorf("%w: %s of %s", ErrUniqueIndexNotFound, name, s.Name)
+	}
+
+	onConflict := clause.OnConflict{Columns: make([]clause.Column, 0, len(index.Fields))}
+	for _, field := range index.Fields {
+		if field.Expression != "" {
+			onConflict.Columns = append(onConflict.Columns, clause.Column{Name: field.Expression, Raw: true})
+		} else {
+			onConflict.Columns = append(onConflict.Columns, clause.Column{Name: field.DBName})
+		}
+	}
+
+	if index.Where != "" {
+		onConflict.TargetWhere = clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: index.Where}}}
+	}
+	return onConflict, nil
+}
+
+// OnConflictForPrimaryKey returns OnConflict with the primary key columns of model
+func OnConflictForPrimaryKey(db *DB, model interface{}) (clause.OnConflict, error) {
+	s, err := db.SchemaOf(model)
+	if err != nil {
+		return clause.OnConflict{}, err
+	}
+
+	if len(s.PrimaryFieldDBNames) == 0 {
+		return clause.OnConflict{}, fmt.Errorf("%w: %s", ErrPrimaryKeyRequired, s.Name)
+	}
+
+	onConflict := clause.OnConflict{Columns: make([]clause.Column, 0, len(s.PrimaryFieldDBNames))}
+	for _, name := range s.PrimaryFieldDBNames {
+		onConflict.Columns = append(onConflict.Columns, clause.Column{Name: name})
+	}
+	return onConflict, nil
+}
+
+// FlushSchemaCache removes all parsed schemas, models will be parsed again when using them,
+// it is useful when models are rebuilt at runtime
+func (db *DB) FlushSchemaCache() {
+	schema.FlushCache(db.cacheStore)
+}
+
 // Use use plugin
 func (db *DB) Use(plugin Plugin) error {
 	name := plugin.Name()
@@ -30,6 +30,24 @@ type Dialector interface {
 	Explain(sql string, vars ...interface{}) string
 }
 
+// BindVarIndexer dialector with numbered or named placeholders, writes the placeholder of the existing var at idx of stmt.Vars
+// 用于 DedupBindVars 复用已有参数的占位符
+type BindVarIndexer interface {
+	BindVarIndexTo(writer clause.Writer, stmt *Statement, idx int)
+}
+
+// AliasWriter dialector could implement it to decide the keyword written before aliases of tables and columns,
+// e.g. Oracle disallows AS for table aliases, defaults to " " for tables and " AS " for columns
+type AliasWriter interface {
+	AliasKeyword(table bool) string
+}
+
+// QueryPlanExplainer dialector could implement it to decide the keyword prefixed to queries by ExplainQuery,
+// e.g. EXPLAIN QUERY PLAN for sqlite, EXPLAIN (ANALYZE, FORMAT JSON) for postgres, defaults to EXPLAIN
+type QueryPlanExplainer interface {
+	ExplainKeyword() string
+}
+
 // Plugin GORM plugin interface
 type Plugin interface {
 	Name() string
@@ -41,6 +59,11 @@ type ParamsFilter interface {
 	ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{})
 }
 
+// QueryRewriter rewrite sql and vars just before executing
+type QueryRewriter interface {
+	Rewrite(ctx context.Context, sql string, vars []interface{}) (string, []interface{})
+}
+
 // ConnPool db conns pool interface
 type ConnPool interface {
 	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
@@ -101,3 +124,36 @@ type Rows interface {
 type ErrorTranslator interface {
 	Translate(err error) error
 }
+
+// DuplicatedKeyTranslator dialector could implement it to report the violated constraint of a duplicated key error
+type DuplicatedKeyTranslator interface {
+	TranslateDuplicatedKey(err error) (constraint string, columns []string, ok bool)
+}
+
+// CheckConstraintTranslator dialector could implement it to report the violated constraint of a check constraint error
+type CheckConstraintTranslator interface {
+	TranslateCheckConstraint(err error) (constraint string, ok bool)
+}
+
+// GeneratedColumnSupporter dialector could implement it to report generated columns are supported,
+// generated columns are returned after creating only if they are supported
+type GeneratedColumnSupporter interface {
+	SupportGeneratedColumn() bool
+}
+
+// CurrentTimestamper dialector could implement it to decide the expression of the database's current time for DBTime fields,
+// CURRENT_TIMESTAMP by default
+type CurrentTimestamper interface {
+	CurrentTimestamp() clause.Expression
+}
+
+// PreviousValuesReturner dialector could implement it if UPDATE ... RETURNING supports the previous values of columns,
+// e.g. clause.Column{Table: "old", Name: column, Alias: column} for PostgreSQL 18
+type PreviousValuesReturner interface {
+	PreviousValueColumn(column string) clause.Column
+}
+
+// RetryableErrorTranslator dialector could implement it to report retryable errors for RetryPolicy, e.g. deadlocks
+type RetryableErrorTranslator interface {
+	IsRetryableError(err error) bool
+}
@@ -31,6 +31,37 @@ type ViewOption struct {
 	Query       *DB    // required subquery.
 }
 
+// SyncOptions options for ColumnsSyncer.SyncColumns, nothing will be dropped unless it is enabled explicitly
+type SyncOptions struct {
+	DropExtraColumns bool     // drop columns that don't exist in the model
+	DropExtraIndexes bool     // drop indexes that don't exist in the model
+	DryRun           bool     // only returns the planned statements without executing them
+	IgnoreColumns    []string // columns or indexes never dropped, supports LIKE patterns, e.g. `legacy_%`
+}
+
+// SyncResult result of ColumnsSyncer.SyncColumns
+type SyncResult struct {
+	Columns    []string // extra columns dropped, or to be dropped in dry run mode
+	Indexes    []string // extra indexes dropped, or to be dropped in dry run mode
+	Statements []string // DROP statements
+	Warnings   []string // extra columns can't be dropped, e.g. primary keys, columns referenced by foreign keys
+}
+
+// ColumnsSyncer migrators dropping columns and indexes that don't exist in the model implement it, E.g:
+//
+//	db.Migrator().(gorm.ColumnsSyncer).SyncColumns(&User{}, gorm.SyncOptions{DropExtraColumns: true, DryRun: true})
+type ColumnsSyncer interface {
+	SyncColumns(dst interface{}, opts SyncOptions) (*SyncResult, error)
+}
+
+// CommentReader migrators reading table and column comments implement it, E.g:
+//
+//	db.Migrator().(gorm.CommentReader).ColumnComment(&User{}, "Name")
+type CommentReader interface {
+	TableComment(dst interface{}) (string, error)
+	ColumnComment(dst interface{}, field string) (string, error)
+}
+
 // ColumnType column type interface
 type ColumnType interface {
 	Name() string
@@ -47,6 +78,15 @@ type ColumnType interface {
 	DefaultValue() (value string, ok bool)
 }
 
+// ColumnCollationReader column types reading the collation of columns implement it, E.g:
+//
+//	if reader, ok := columnType.(gorm.ColumnCollationReader); ok {
+//		collation, ok := reader.Collation()
+//	}
+type ColumnCollationReader interface {
+	Collation() (value string, ok bool)
+}
+
 type Index interface {
 	Table() string
 	Name() string
@@ -21,6 +21,7 @@ type ColumnType struct {
 	ScanTypeValue      reflect.Type
 	CommentValue       sql.NullString
 	DefaultValueValue  sql.NullString
+	CollationValue     sql.NullString
 }
 
 // Name returns the name or alias of the column.
@@ -105,3 +106,8 @@ func (ct ColumnType) Comment() (value string, ok bool) {
 func (ct ColumnType) DefaultValue() (value string, ok bool) {
 	return ct.DefaultValueValue.String, ct.DefaultValueValue.Valid
 }
+
+// Collation returns the collation of current column.
+func (ct ColumnType) Collation() (value string, ok bool) {
+	return ct.CollationValue.String, ct.CollationValue.Valid
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,6 +20,9 @@ import (
 
 var regFullDataType = regexp.MustCompile(`\D*(\d+)\D?`)
 
+// regFillfactor 匹配索引选项中的 fillfactor 存储参数
+var regFillfactor = regexp.MustCompile(`(?i)^\s*fillfactor\s*=\s*(.*?)\s*$`)
+
 // Migrator m struct
 type Migrator struct {
 	Config
@@ -26,10 +31,27 @@ type Migrator struct {
 // Config schema config
 type Config struct {
 	CreateIndexAfterCreateTable bool
-	DB                          *gorm.DB
+	// SupportIndexWhere partial index is supported, e.g. CREATE INDEX ... WHERE deleted_at IS NULL
+	SupportIndexWhere bool
+	// SupportIndexInclude covering index is supported, e.g. CREATE INDEX ... INCLUDE (col_a, col_b)
+	SupportIndexInclude bool
+	// SupportCommentOn table and column comments are supported, e.g. COMMENT ON TABLE users IS 'users'
+	SupportCommentOn bool
+	// SupportCollate column charset and collation are supported, e.g. VARCHAR(100) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci
+	SupportCollate bool
+	// SupportGeneratedColumn generated columns are supported, e.g. GENERATED ALWAYS AS (price * quantity) STORED,
+	// generated columns are not created when it is unsupported
+	SupportGeneratedColumn bool
+	DB                     *gorm.DB
 	gorm.Dialector
 }
 
+// SyncOptions options of SyncColumns
+type SyncOptions = gorm.SyncOptions
+
+// SyncResult result of SyncColumns
+type SyncResult = gorm.SyncResult
+
 type printSQLLogger struct {
 	logger.Interface
 }
@@ -51,10 +73,15 @@ func (m Migrator) RunWithValue(value interface{}, fc func(*gorm.Statement) error
 	if m.DB.Statement != nil {
 		stmt.Table = m.DB.Statement.Table
 		stmt.TableExpr = m.DB.Statement.TableExpr
+		stmt.TableSchema = m.DB.Statement.TableSchema
+		stmt.TablePrefix = m.DB.Statement.TablePrefix
+		stmt.TableSuffix = m.DB.Statement.TableSuffix
 	}
 
 	if table, ok := value.(string); ok {
 		stmt.Table = table
+		// 直接传入的表名也添加 session 指定的前后缀，解析 model 得到的表名已经添加过了
+		stmt.AffixTable()
 	} else if err := stmt.ParseWithSpecialTableName(value, stmt.Table); err != nil {
 		return err
 	}
@@ -64,6 +91,13 @@ func (m Migrator) RunWithValue(value interface{}, fc func(*gorm.Statement) error
 
 // DataTypeOf return field's db data type
 func (m Migrator) DataTypeOf(field *schema.Field) string {
+	// 通过 gorm.RegisterDataType 注册的类型
+	if spec, ok := schema.LookUpDataType(field.IndirectFieldType); ok && spec.DBType != nil {
+		if dataType := spec.DBType(m.Dialector.Name(), field); dataType != "" {
+			return dataType
+		}
+	}
+
 	fieldValue := reflect.New(field.IndirectFieldType)
 	if dataTyper, ok := fieldValue.Interface().(GormDataTypeInterface); ok {
 		if dataType := dataTyper.GormDBDataType(m.DB, field); dataType != "" {
@@ -74,10 +108,40 @@ func (m Migrator) DataTypeOf(field *schema.Field) string {
 	return m.Dialector.DataTypeOf(field)
 }
 
+// findColumnType returns the column type named name
+func findColumnType(columnTypes []gorm.ColumnType, name string) gorm.ColumnType {
+	for _, columnType := range columnTypes {
+		if columnType.Name() == name {
+			return columnType
+		}
+	}
+	return nil
+}
+
 // FullDataTypeOf returns field's db full data type
 func (m Migrator) FullDataTypeOf(field *schema.Field) (expr clause.Expr) {
 	expr.SQL = m.DataTypeOf(field)
 
+	// 不支持的数据库忽略字符集和排序规则
+	if m.SupportCollate {
+		if field.Charset != "" {
+			expr.SQL += " CHARACTER SET " + field.Charset
+		}
+
+		if field.Collation != "" {
+			expr.SQL += " COLLATE " + field.Collation
+		}
+	}
+
+	if field.Generated != "" && m.SupportGeneratedColumn {
+		expr.SQL += " GENERATED ALWAYS AS (" + field.Generated + ")"
+		if field.GeneratedStored {
+			expr.SQL += " STORED"
+		} else {
+			expr.SQL += " VIRTUAL"
+		}
+	}
+
 	if field.NotNull {
 		expr.SQL += " NOT NULL"
 	}
@@ -86,7 +150,7 @@ func (m Migrator) FullDataTypeOf(field *schema.Field) (expr clause.Expr) {
 		expr.SQL += " UNIQUE"
 	}
 
-	if field.HasDefaultValue && (field.DefaultValueInterface != nil || field.DefaultValue != "") {
+	if field.Generated == "" && field.HasDefaultValue && (field.DefaultValueInterface != nil || field.DefaultValue != "") {
 		if field.DefaultValueInterface != nil {
 			defaultStmt := &gorm.Statement{Vars: []interface{}{field.DefaultValueInterface}}
 			m.Dialector.BindVarTo(defaultStmt, defaultStmt, field.DefaultValueInterface)
@@ -123,16 +187,38 @@ func (m Migrator) AutoMigrate(values ...interface{}) error {
 					parseCheckConstraints = stmt.Schema.ParseCheckConstraints()
 				)
 				for _, dbName := range stmt.Schema.DBNames {
-					var foundColumn gorm.ColumnType
+					var (
+						foundColumn    = findColumnType(columnTypes, dbName)
+						previousColumn gorm.ColumnType
+					)
+
+					// 按顺序查找 previousColumn 注解里的旧列名
+					for _, name := range strings.Split(stmt.Schema.FieldsByDBName[dbName].TagSettings["PREVIOUSCOLUMN"], ",") {
+						if name = strings.TrimSpace(name); name != "" {
+							if previousColumn = findColumnType(columnTypes, name); previousColumn != nil {
+								break
+							}
+						}
+					}
+
+					if previousColumn != nil {
+						if foundColumn != nil {
+							return fmt.Errorf("both column %s and its previous column %s exist in table %s", dbName, previousColumn.Name(), stmt.Table)
+						}
 
-					for _, columnType := range columnTypes {
-						if columnType.Name() == dbName {
-							foundColumn = columnType
-							break
+						// 旧列存在时重命名，而不是新增列
+						if err = execTx.Migrator().RenameColumn(value, previousColumn.Name(), dbName); err != nil {
+							return err
 						}
+						foundColumn = previousColumn
 					}
 
 					if foundColumn == nil {
+						// 不支持生成列时不创建该列
+						if field := stmt.Schema.FieldsByDBName[dbName]; field.Generated != "" && !m.SupportGeneratedColumn {
+							continue
+						}
+
 						// not found, add column
 						if err = execTx.Migrator().AddColumn(value, dbName); err != nil {
 							return err
@@ -206,7 +292,7 @@ func (m Migrator) CreateTable(values ...interface{}) error {
 
 			for _, dbName := range stmt.Schema.DBNames {
 				field := stmt.Schema.FieldsByDBName[dbName]
-				if !field.IgnoreMigration {
+				if !field.IgnoreMigration && (field.Generated == "" || m.SupportGeneratedColumn) {
 					createTableSQL += "? ?"
 					hasPrimaryKeyInDataType = hasPrimaryKeyInDataType || strings.Contains(strings.ToUpper(string(field.DataType)), "PRIMARY KEY")
 					values = append(values, clause.Column{Name: dbName}, m.DB.Migrator().FullDataTypeOf(field))
@@ -242,9 +328,16 @@ func (m Migrator) CreateTable(values ...interface{}) error {
 					}
 
 					if idx.Option != "" {
+						if err = checkIndexOption(idx); err != nil {
+							return err
+						}
 						createTableSQL += " " + idx.Option
 					}
 
+					if idx.Where != "" || len(idx.Include) > 0 {
+						m.DB.Logger.Warn(context.Background(), "index %s: WHERE and INCLUDE are not supported when creating index with table, skipped", idx.Name)
+					}
+
 					createTableSQL += ","
 					values = append(values, clause.Column{Name: idx.Name}, tx.Migrator().(BuildIndexOptionsInterface).BuildIndexOptions(idx.Fields, stmt))
 				}
@@ -278,7 +371,13 @@ func (m Migrator) CreateTable(values ...interface{}) error {
 				createTableSQL += fmt.Sprint(tableOption)
 			}
 
-			err = tx.Exec(createTableSQL, values...).Error
+			if err = tx.Exec(createTableSQL, values...).Error; err == nil && stmt.Schema.Comment != "" {
+				if m.SupportCommentOn {
+					err = tx.Exec("COMMENT ON TABLE ? IS ?", m.CurrentTable(stmt), stmt.Schema.Comment).Error
+				} else {
+					m.DB.Logger.Warn(context.Background(), "table %s: table comment is not supported, skipped", stmt.Table)
+				}
+			}
 			return err
 		}); err != nil {
 			return err
@@ -306,8 +405,8 @@ func (m Migrator) HasTable(value interface{}) bool {
 	var count int64
 
 	m.RunWithValue(value, func(stmt *gorm.Statement) error {
-		currentDatabase := m.DB.Migrator().CurrentDatabase()
-		return m.DB.Raw("SELECT count(*) FROM information_schema.tables WHERE table_schema = ? AND table_name = ? AND table_type = ?", currentDatabase, stmt.Table, "BASE TABLE").Row().Scan(&count)
+		currentSchema, table := m.CurrentSchema(stmt, stmt.Table)
+		return m.DB.Raw("SELECT count(*) FROM information_schema.tables WHERE table_schema = ? AND table_name = ? AND table_type = ?", currentSchema, table, "BASE TABLE").Row().Scan(&count)
 	})
 
 	return count > 0
@@ -379,29 +478,243 @@ func (m Migrator) AlterColumn(value interface{}, field string) error {
 	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
 		if field := stmt.Schema.LookUpField(field); field != nil {
 			fileType := m.FullDataTypeOf(field)
-			return m.DB.Exec(
+			if err := m.DB.Exec(
 				"ALTER TABLE ? ALTER COLUMN ? TYPE ?",
 				m.CurrentTable(stmt), clause.Column{Name: field.DBName}, fileType,
-			).Error
+			).Error; err != nil {
+				return err
+			}
 
+			if m.SupportCommentOn {
+				return m.DB.Exec(
+					"COMMENT ON COLUMN ?.? IS ?",
+					m.CurrentTable(stmt), clause.Column{Name: field.DBName}, field.Comment,
+				).Error
+			}
+			return nil
 		}
 		return fmt.Errorf("failed to look up field with name: %s", field)
 	})
 }
 
+// ColumnComment returns the comment of column `field` for value
+func (m Migrator) ColumnComment(value interface{}, field string) (comment string, err error) {
+	err = m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		name := field
+		if field := stmt.Schema.LookUpField(field); field != nil {
+			name = field.DBName
+		}
+
+		columnTypes, err := m.DB.Migrator().ColumnTypes(value)
+		if err != nil {
+			return err
+		}
+
+		for _, columnType := range columnTypes {
+			if columnType.Name() == name {
+				comment, _ = columnType.Comment()
+				return nil
+			}
+		}
+		return fmt.Errorf("failed to look up column with name: %s", field)
+	})
+	return
+}
+
+// ReferencedColumnsInterface returns the columns of the table referenced by foreign keys, SyncColumns never drops them,
+// migrators of dialects should implement it to look up the foreign keys of the database, or no column is dropped
+type ReferencedColumnsInterface interface {
+	ReferencedColumns(dst interface{}) ([]string, error)
+}
+
+type collectSQLLogger struct {
+	logger.Interface
+	sqls []string
+}
+
+func (l *collectSQLLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	sql, _ := fc()
+	l.sqls = append(l.sqls, sql)
+}
+
+// SyncColumns drops columns and indexes that don't exist in the model for value,
+// primary keys and columns referenced by foreign keys are never dropped, see ReferencedColumnsInterface
+func (m Migrator) SyncColumns(value interface{}, opts gorm.SyncOptions) (*gorm.SyncResult, error) {
+	result := &gorm.SyncResult{}
+	err := m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		columnTypes, err := m.DB.Migrator().ColumnTypes(value)
+		if err != nil {
+			return err
+		}
+
+		ignored := func(name string) bool {
+			for _, pattern := range opts.IgnoreColumns {
+				if matchLikePattern(pattern, name) {
+					return true
+				}
+			}
+			return false
+		}
+
+		// 模型中的列，包括 previousColumn 注解里尚未重命名的旧列
+		modelColumns := map[string]bool{}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			modelColumns[field.DBName] = true
+			for _, name := range strings.Split(field.TagSettings["PREVIOUSCOLUMN"], ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					modelColumns[name] = true
+				}
+			}
+		}
+
+		// 无法确定哪些列被外键引用时，不删除任何列
+		var (
+			referencedColumns = map[string]bool{}
+			columns           []string
+			referencedErr     error
+		)
+		if opts.DropExtraColumns {
+			if referencer, ok := m.DB.Migrator().(ReferencedColumnsInterface); ok {
+				columns, referencedErr = referencer.ReferencedColumns(value)
+			} else {
+				referencedErr = fmt.Errorf("%T doesn't implement ReferencedColumnsInterface", m.DB.Migrator())
+			}
+			for _, column := range columns {
+				referencedColumns[column] = true
+			}
+		}
+
+		droppedColumns := map[string]bool{}
+		if opts.DropExtraColumns {
+			for _, columnType := range columnTypes {
+				name := columnType.Name()
+				if modelColumns[name] || ignored(name) {
+					continue
+				}
+
+				if isPrimaryKey, _ := columnType.PrimaryKey(); isPrimaryKey {
+					result.Warnings = append(result.Warnings, fmt.Sprintf("column %s of table %s is a primary key, skipped", name, stmt.Table))
+				} else if referencedColumns[name] {
+					result.Warnings = append(result.Warnings, fmt.Sprintf("column %s of table %s is referenced by foreign keys, skipped", name, stmt.Table))
+				} else if referencedErr != nil {
+					result.Warnings = append(result.Warnings, fmt.Sprintf("column %s of table %s skipped, failed to look up foreign keys referencing it: %v", name, stmt.Table, referencedErr))
+				} else {
+					droppedColumns[name] = true
+					result.Columns = append(result.Columns, name)
+				}
+			}
+		}
+
+		if opts.DropExtraIndexes {
+			indexes, err := m.DB.Migrator().GetIndexes(value)
+			if err != nil {
+				return err
+			}
+
+			parsedIndexes := stmt.Schema.ParseIndexes()
+		indexLoop:
+			for _, idx := range indexes {
+				if _, ok := parsedIndexes[idx.Name()]; ok || ignored(idx.Name()) {
+					continue
+				}
+				if isPrimaryKey, _ := idx.PrimaryKey(); isPrimaryKey {
+					continue
+				}
+
+				// 索引的列全部被删除时，索引随列一起删除；包含忽略的列或唯一字段的索引不删除
+				coveredByDroppedColumns := len(idx.Columns()) > 0
+				for _, column := range idx.Columns() {
+					if ignored(column) {
+						continue indexLoop
+					}
+					if field := stmt.Schema.LookUpField(column); field != nil && field.Unique && len(idx.Columns()) == 1 {
+						continue indexLoop
+					}
+					coveredByDroppedColumns = coveredByDroppedColumns && droppedColumns[column]
+				}
+				if coveredByDroppedColumns {
+					continue
+				}
+
+				result.Indexes = append(result.Indexes, idx.Name())
+			}
+			sort.Strings(result.Indexes)
+		}
+
+		// 先删除索引再删除列
+		collector := &collectSQLLogger{Interface: m.DB.Logger}
+		dryRunTx := m.DB.Session(&gorm.Session{DryRun: true, Logger: collector})
+		for _, name := range result.Indexes {
+			if err := dryRunTx.Migrator().DropIndex(value, name); err != nil {
+				return err
+			}
+		}
+		// SQLite 删除列时会重建表，无法在 DryRun 模式下执行，统一使用标准的 ALTER TABLE 语句
+		for _, name := range result.Columns {
+			if err := (Migrator{Config: Config{DB: dryRunTx, Dialector: m.Dialector}}).DropColumn(value, name); err != nil {
+				return err
+			}
+		}
+		result.Statements = collector.sqls
+
+		if opts.DryRun {
+			return nil
+		}
+
+		for _, name := range result.Indexes {
+			if err := m.DB.Migrator().DropIndex(value, name); err != nil {
+				return err
+			}
+		}
+		for _, name := range result.Columns {
+			if err := m.DB.Migrator().DropColumn(value, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// matchLikePattern reports whether name matches the LIKE pattern, `%` matches any characters and `_` matches a single character
+func matchLikePattern(pattern, name string) bool {
+	var expr strings.Builder
+	expr.WriteString("(?i)^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			expr.WriteString(".*")
+		case '_':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+
+	matched, _ := regexp.MatchString(expr.String(), name)
+	return matched
+}
+
 // HasColumn check has column `field` for value or not
 func (m Migrator) HasColumn(value interface{}, field string) bool {
 	var count int64
 	m.RunWithValue(value, func(stmt *gorm.Statement) error {
-		currentDatabase := m.DB.Migrator().CurrentDatabase()
+		currentSchema, table := m.CurrentSchema(stmt, stmt.Table)
 		name := field
-		if field := stmt.Schema.LookUpField(field); field != nil {
-			name = field.DBName
+		if stmt.Schema != nil {
+			if field := stmt.Schema.LookUpField(field); field != nil {
+				name = field.DBName
+			}
 		}
 
 		return m.DB.Raw(
 			"SELECT count(*) FROM INFORMATION_SCHEMA.columns WHERE table_schema = ? AND table_name = ? AND column_name = ?",
-			currentDatabase, stmt.Table, name,
+			currentSchema, table, name,
 		).Row().Scan(&count)
 	})
 
@@ -495,8 +808,8 @@ func (m Migrator) MigrateColumn(value interface{}, field *schema.Field, columnTy
 		}
 	}
 
-	// check default value
-	if !field.PrimaryKey {
+	// check default value, generated columns have no default value
+	if !field.PrimaryKey && field.Generated == "" {
 		currentDefaultNotNull := field.HasDefaultValue && (field.DefaultValueInterface != nil || !strings.EqualFold(field.DefaultValue, "NULL"))
 		dv, dvNotNull := columnType.DefaultValue()
 		if dvNotNull && !currentDefaultNotNull {
@@ -523,6 +836,13 @@ func (m Migrator) MigrateColumn(value interface{}, field *schema.Field, columnTy
 		}
 	}
 
+	// check collation
+	if reader, ok := columnType.(gorm.ColumnCollationReader); ok && field.Collation != "" {
+		if collation, ok := reader.Collation(); ok && !strings.EqualFold(collation, field.Collation) {
+			alterColumn = true
+		}
+	}
+
 	if alterColumn && !field.IgnoreMigration {
 		return m.DB.Migrator().AlterColumn(value, field.DBName)
 	}
@@ -622,7 +942,7 @@ func buildConstraint(constraint *schema.Constraint) (sql string, results []inter
 }
 
 // GuessConstraintAndTable guess statement's constraint and it's table based on name
-func (m Migrator) GuessConstraintAndTable(stmt *gorm.Statement, name string) (_ *schema.Constraint, _ *schema.Check, table string) {
+func (m Migrator) GuessConstraintAndTable(stmt *gorm.Statement, name string) (_ *schema.Constraint, _ *schema.CheckConstraint, table string) {
 	if stmt.Schema == nil {
 		return nil, nil, stmt.Table
 	}
@@ -707,7 +1027,6 @@ func (m Migrator) DropConstraint(value interface{}, name string) error {
 func (m Migrator) HasConstraint(value interface{}, name string) bool {
 	var count int64
 	m.RunWithValue(value, func(stmt *gorm.Statement) error {
-		currentDatabase := m.DB.Migrator().CurrentDatabase()
 		constraint, chk, table := m.GuessConstraintAndTable(stmt, name)
 		if constraint != nil {
 			name = constraint.Name
@@ -715,9 +1034,10 @@ func (m Migrator) HasConstraint(value interface{}, name string) bool {
 			name = chk.Name
 		}
 
+		currentSchema, curTable := m.CurrentSchema(stmt, table)
 		return m.DB.Raw(
 			"SELECT count(*) FROM INFORMATION_SCHEMA.table_constraints WHERE constraint_schema = ? AND table_name = ? AND constraint_name = ?",
-			currentDatabase, table, name,
+			currentSchema, curTable, name,
 		).Row().Scan(&count)
 	})
 
@@ -729,7 +1049,11 @@ func (m Migrator) BuildIndexOptions(opts []schema.IndexOption, stmt *gorm.Statem
 	for _, opt := range opts {
 		str := stmt.Quote(opt.DBName)
 		if opt.Expression != "" {
+			// 表达式原样输出，用括号包起来，如 MySQL 的函数索引要求 ((lower(email)))
 			str = opt.Expression
+			if !inParentheses(str) {
+				str = "(" + str + ")"
+			}
 		} else if opt.Length > 0 {
 			str += fmt.Sprintf("(%d)", opt.Length)
 		}
@@ -746,6 +1070,26 @@ func (m Migrator) BuildIndexOptions(opts []schema.IndexOption, stmt *gorm.Statem
 	return
 }
 
+// inParentheses whether the whole expression is enclosed by a pair of parentheses
+func inParentheses(expr string) bool {
+	if !strings.HasPrefix(expr, "(") || !strings.HasSuffix(expr, ")") {
+		return false
+	}
+
+	depth := 0
+	for i, c := range expr {
+		if c == '(' {
+			depth++
+		} else if c == ')' {
+			depth--
+			if depth == 0 && i != len(expr)-1 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
 // BuildIndexOptionsInterface build index options interface
 type BuildIndexOptionsInterface interface {
 	BuildIndexOptions([]schema.IndexOption, *gorm.Statement) []interface{}
@@ -768,14 +1112,38 @@ func (m Migrator) CreateIndex(value interface{}, name string) error {
 				createIndexSQL += " USING " + idx.Type
 			}
 
+			if len(idx.Include) > 0 {
+				if m.SupportIndexInclude {
+					include := make([]interface{}, 0, len(idx.Include))
+					for _, column := range idx.Include {
+						include = append(include, clause.Column{Name: column})
+					}
+					createIndexSQL += " INCLUDE ?"
+					values = append(values, include)
+				} else {
+					m.DB.Logger.Warn(context.Background(), "index %s: INCLUDE is not supported by %s, skipped", idx.Name, m.Dialector.Name())
+				}
+			}
+
 			if idx.Comment != "" {
 				createIndexSQL += fmt.Sprintf(" COMMENT '%s'", idx.Comment)
 			}
 
 			if idx.Option != "" {
+				if err := checkIndexOption(*idx); err != nil {
+					return err
+				}
 				createIndexSQL += " " + idx.Option
 			}
 
+			if idx.Where != "" {
+				if m.SupportIndexWhere {
+					createIndexSQL += " WHERE " + idx.Where
+				} else {
+					m.DB.Logger.Warn(context.Background(), "index %s: partial index is not supported by %s, WHERE skipped", idx.Name, m.Dialector.Name())
+				}
+			}
+
 			return m.DB.Exec(createIndexSQL, values...).Error
 		}
 
@@ -783,6 +1151,16 @@ func (m Migrator) CreateIndex(value interface{}, name string) error {
 	})
 }
 
+// checkIndexOption checks the option of index before writing it to DDL, fillfactor must be an integer between 10 and 100
+func checkIndexOption(idx schema.Index) error {
+	if matches := regFillfactor.FindStringSubmatch(idx.Option); len(matches) == 2 {
+		if fillfactor, err := strconv.Atoi(matches[1]); err != nil || fillfactor < 10 || fillfactor > 100 {
+			return fmt.Errorf("invalid fillfactor %q of index %s, should be an integer between 10 and 100", matches[1], idx.Name)
+		}
+	}
+	return nil
+}
+
 // DropIndex drop index `name`
 func (m Migrator) DropIndex(value interface{}, name string) error {
 	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
@@ -798,14 +1176,14 @@ func (m Migrator) DropIndex(value interface{}, name string) error {
 func (m Migrator) HasIndex(value interface{}, name string) bool {
 	var count int64
 	m.RunWithValue(value, func(stmt *gorm.Statement) error {
-		currentDatabase := m.DB.Migrator().CurrentDatabase()
+		currentSchema, table := m.CurrentSchema(stmt, stmt.Table)
 		if idx := stmt.Schema.LookIndex(name); idx != nil {
 			name = idx.Name
 		}
 
 		return m.DB.Raw(
 			"SELECT count(*) FROM information_schema.statistics WHERE table_schema = ? AND table_name = ? AND index_name = ?",
-			currentDatabase, stmt.Table, name,
+			currentSchema, table, name,
 		).Row().Scan(&count)
 	})
 
@@ -937,9 +1315,23 @@ func (m Migrator) CurrentTable(stmt *gorm.Statement) interface{} {
 	if stmt.TableExpr != nil {
 		return *stmt.TableExpr
 	}
+	if stmt.TableSchema != "" {
+		return clause.Table{Name: stmt.TableSchema + "." + stmt.Table}
+	}
 	return clause.Table{Name: stmt.Table}
 }
 
+// CurrentSchema returns the schema (database) and the name of table, the schema is the current database if table isn't qualified
+func (m Migrator) CurrentSchema(stmt *gorm.Statement, table string) (interface{}, interface{}) {
+	if stmt.TableSchema != "" && table == stmt.Table {
+		return stmt.TableSchema, table
+	}
+	if tables := strings.Split(table, "."); len(tables) == 2 {
+		return tables[0], tables[1]
+	}
+	return m.DB.Migrator().CurrentDatabase(), table
+}
+
 // GetIndexes return Indexes []gorm.Index and execErr error
 func (m Migrator) GetIndexes(dst interface{}) ([]gorm.Index, error) {
 	return nil, errors.New("not support")
@@ -954,3 +1346,14 @@ func (m Migrator) GetTypeAliases(databaseTypeName string) []string {
 func (m Migrator) TableType(dst interface{}) (gorm.TableType, error) {
 	return nil, errors.New("not support")
 }
+
+// TableComment returns the comment of table for value
+func (m Migrator) TableComment(dst interface{}) (string, error) {
+	tableType, err := m.DB.Migrator().TableType(dst)
+	if err != nil {
+		return "", err
+	}
+
+	comment, _ := tableType.Comment()
+	return comment, nil
+}
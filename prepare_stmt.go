@@ -2,8 +2,15 @@ package gorm
 
 import (
 	"context"
+	"crypto/sha1"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type Stmt struct {
@@ -11,6 +18,28 @@ type Stmt struct {
 	Transaction bool
 	prepared    chan struct{}
 	prepareErr  error
+	// stats 缓存的 stmt 的创建时间与使用次数，值拷贝的 Stmt 共享同一个
+	stats *stmtStats
+}
+
+type stmtStats struct {
+	createdAt time.Time
+	uses      int64
+}
+
+// PreparedStmtStats stats of cached prepared statements, e.g. for metrics
+type PreparedStmtStats struct {
+	Count int // count of prepared statements
+	Stmts []PreparedStmtStat
+}
+
+// PreparedStmtStat stat of a cached prepared statement
+type PreparedStmtStat struct {
+	SQL         string
+	Digest      string // hex encoded sha1 of SQL, could be used as a metric label
+	Transaction bool
+	CreatedAt   time.Time
+	Uses        int64 // times the statement is got from the cache, including the first prepare
 }
 
 type PreparedStmtDB struct {
@@ -19,6 +48,8 @@ type PreparedStmtDB struct {
 	Mux         *sync.RWMutex
 	// ConnPool 具体的连接池，如 sql.Open 返回的连接池
 	ConnPool
+
+	translator ErrorTranslator
 }
 
 func (db *PreparedStmtDB) GetDBConn() (*sql.DB, error) {
@@ -45,15 +76,85 @@ func (db *PreparedStmtDB) Close() {
 	}
 }
 
+// Reset closes and clears all prepared statements, statements are prepared again when used,
+// statements still preparing are closed after prepared
 func (db *PreparedStmtDB) Reset() {
+	for _, stmt := range db.clear() {
+		go closeStmt(stmt)
+	}
+}
+
+// ResetContext clears all prepared statements like Reset, but returns after they are closed,
+// statements still preparing when ctx is done are closed in background and ctx.Err() is returned
+func (db *PreparedStmtDB) ResetContext(ctx context.Context) (err error) {
+	for _, stmt := range db.clear() {
+		select {
+		case <-stmt.prepared:
+			closeStmt(stmt)
+		case <-ctx.Done():
+			err = ctx.Err()
+			go closeStmt(stmt)
+		}
+	}
+	return err
+}
+
+// clear 清空缓存的 stmt 并返回它们，由调用方负责关闭
+func (db *PreparedStmtDB) clear() map[string]*Stmt {
 	db.Mux.Lock()
 	defer db.Mux.Unlock()
 
-	for _, stmt := range db.Stmts {
-		go stmt.Close()
-	}
+	stmts := db.Stmts
 	db.PreparedSQL = make([]string, 0, 100)
 	db.Stmts = make(map[string]*Stmt)
+	return stmts
+}
+
+// closeStmt waits for the stmt prepared and closes it
+func closeStmt(stmt *Stmt) {
+	<-stmt.prepared
+	if stmt.Stmt != nil {
+		stmt.Close()
+	}
+}
+
+// Stats returns stats of prepared statements ordered by SQL, statements still preparing are excluded
+func (db *PreparedStmtDB) Stats() PreparedStmtStats {
+	db.Mux.RLock()
+	defer db.Mux.RUnlock()
+
+	stats := PreparedStmtStats{Stmts: make([]PreparedStmtStat, 0, len(db.Stmts))}
+	for query, stmt := range db.Stmts {
+		if stmt.Stmt == nil {
+			continue
+		}
+
+		digest := sha1.Sum([]byte(query))
+		stat := PreparedStmtStat{SQL: query, Digest: hex.EncodeToString(digest[:]), Transaction: stmt.Transaction}
+		if stmt.stats != nil {
+			stat.CreatedAt, stat.Uses = stmt.stats.createdAt, atomic.LoadInt64(&stmt.stats.uses)
+		}
+		stats.Stmts = append(stats.Stmts, stat)
+	}
+	stats.Count = len(stats.Stmts)
+
+	sort.Slice(stats.Stmts, func(i, j int) bool {
+		return stats.Stmts[i].SQL < stats.Stmts[j].SQL
+	})
+	return stats
+}
+
+// cachedStmt waits for the cached stmt prepared and counts its usage
+func cachedStmt(stmt *Stmt) (Stmt, error) {
+	<-stmt.prepared
+	if stmt.prepareErr != nil {
+		return Stmt{}, stmt.prepareErr
+	}
+
+	if stmt.stats != nil {
+		atomic.AddInt64(&stmt.stats.uses, 1)
+	}
+	return *stmt, nil
 }
 
 func (db *PreparedStmtDB) prepare(ctx context.Context, conn ConnPool, isTransaction bool, query string) (Stmt, error) {
@@ -61,12 +162,7 @@ func (db *PreparedStmtDB) prepare(ctx context.Context, conn ConnPool, isTransact
 	if stmt, ok := db.Stmts[query]; ok && (!stmt.Transaction || isTransaction) {
 		db.Mux.RUnlock()
 		// wait for other goroutines prepared
-		<-stmt.prepared
-		if stmt.prepareErr != nil {
-			return Stmt{}, stmt.prepareErr
-		}
-
-		return *stmt, nil
+		return cachedStmt(stmt)
 	}
 	db.Mux.RUnlock()
 
@@ -75,16 +171,11 @@ func (db *PreparedStmtDB) prepare(ctx context.Context, conn ConnPool, isTransact
 	if stmt, ok := db.Stmts[query]; ok && (!stmt.Transaction || isTransaction) {
 		db.Mux.Unlock()
 		// wait for other goroutines prepared
-		<-stmt.prepared
-		if stmt.prepareErr != nil {
-			return Stmt{}, stmt.prepareErr
-		}
-
-		return *stmt, nil
+		return cachedStmt(stmt)
 	}
 
 	// cache preparing stmt first
-	cacheStmt := Stmt{Transaction: isTransaction, prepared: make(chan struct{})}
+	cacheStmt := Stmt{Transaction: isTransaction, prepared: make(chan struct{}), stats: &stmtStats{uses: 1}}
 	db.Stmts[query] = &cacheStmt
 	db.Mux.Unlock()
 
@@ -97,6 +188,11 @@ func (db *PreparedStmtDB) prepare(ctx context.Context, conn ConnPool, isTransact
 	// 2. g2 select lock `conn.PrepareContext(ctx, query)`, now db.numOpen == db.maxOpen , wait for release.
 	// 3. g1 tx exec insert, wait for unlock `conn.PrepareContext(ctx, query)` to finish tx and release.
 	stmt, err := conn.PrepareContext(ctx, query)
+	if err != nil && db.isInvalidStmtError(err) {
+		// 连接或预编译语句失效时重新预编译一次，只重试预编译，不会重复执行语句
+		stmt, err = conn.PrepareContext(ctx, query)
+	}
+
 	if err != nil {
 		cacheStmt.prepareErr = err
 		db.Mux.Lock()
@@ -107,12 +203,23 @@ func (db *PreparedStmtDB) prepare(ctx context.Context, conn ConnPool, isTransact
 
 	db.Mux.Lock()
 	cacheStmt.Stmt = stmt
+	cacheStmt.stats.createdAt = time.Now()
 	db.PreparedSQL = append(db.PreparedSQL, query)
 	db.Mux.Unlock()
 
 	return cacheStmt, nil
 }
 
+// isInvalidStmtError returns true if err means the connection or prepared statement is invalid,
+// the dialector could translate its driver errors into ErrInvalidPreparedStmt
+func (db *PreparedStmtDB) isInvalidStmtError(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, ErrInvalidPreparedStmt) {
+		return true
+	}
+
+	return db.translator != nil && errors.Is(db.translator.Translate(err), ErrInvalidPreparedStmt)
+}
+
 func (db *PreparedStmtDB) BeginTx(ctx context.Context, opt *sql.TxOptions) (ConnPool, error) {
 	if beginner, ok := db.ConnPool.(TxBeginner); ok {
 		tx, err := beginner.BeginTx(ctx, opt)
@@ -126,10 +233,8 @@ func (db *PreparedStmtDB) ExecContext(ctx context.Context, query string, args ..
 	if err == nil {
 		result, err = stmt.ExecContext(ctx, args...)
 		if err != nil {
-			db.Mux.Lock()
-			defer db.Mux.Unlock()
-			go stmt.Close()
-			delete(db.Stmts, query)
+			db.invalidateStmt(query, stmt)
+			db.prepareAgain(ctx, query, err)
 		}
 	}
 	return result, err
@@ -140,16 +245,36 @@ func (db *PreparedStmtDB) QueryContext(ctx context.Context, query string, args .
 	if err == nil {
 		rows, err = stmt.QueryContext(ctx, args...)
 		if err != nil {
-			db.Mux.Lock()
-			defer db.Mux.Unlock()
-
-			go stmt.Close()
-			delete(db.Stmts, query)
+			db.invalidateStmt(query, stmt)
+			db.prepareAgain(ctx, query, err)
 		}
 	}
 	return rows, err
 }
 
+// prepareAgain prepares the query again if err means the statement is invalid, the failed statement is not executed again,
+// as it might have reached the database, retrying is left to the caller or RetryPolicy
+func (db *PreparedStmtDB) prepareAgain(ctx context.Context, query string, err error) {
+	if db.isInvalidStmtError(err) {
+		db.prepare(ctx, db.ConnPool, false, query)
+	}
+}
+
+// invalidateStmt closes the failed stmt and removes it from the cache unless it has been replaced
+func (db *PreparedStmtDB) invalidateStmt(query string, stmt Stmt) {
+	if stmt.Stmt == nil {
+		return
+	}
+
+	db.Mux.Lock()
+	defer db.Mux.Unlock()
+
+	go stmt.Close()
+	if cached, ok := db.Stmts[query]; ok && cached.Stmt == stmt.Stmt {
+		delete(db.Stmts, query)
+	}
+}
+
 func (db *PreparedStmtDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
 	stmt, err := db.prepare(ctx, db.ConnPool, false, query)
 	if err == nil {
@@ -158,6 +283,20 @@ func (db *PreparedStmtDB) QueryRowContext(ctx context.Context, query string, arg
 	return &sql.Row{}
 }
 
+// unpreparedConnPool returns the underlying conn pool or transaction of prepared statements conn pools
+func unpreparedConnPool(connPool ConnPool) ConnPool {
+	for {
+		switch conn := connPool.(type) {
+		case *PreparedStmtDB:
+			connPool = conn.ConnPool
+		case *PreparedStmtTX:
+			connPool = conn.Tx
+		default:
+			return connPool
+		}
+	}
+}
+
 type PreparedStmtTX struct {
 	Tx
 	PreparedStmtDB *PreparedStmtDB
@@ -3,9 +3,12 @@ package gorm
 import (
 	"database/sql"
 	"database/sql/driver"
+	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/schema"
 	"gorm.io/gorm/utils"
 )
@@ -37,7 +40,13 @@ func prepareValues(values []interface{}, db *DB, columnTypes []*sql.ColumnType,
 
 func scanIntoMap(mapValue map[string]interface{}, values []interface{}, columns []string) {
 	for idx, column := range columns {
-		if reflectValue := reflect.Indirect(reflect.Indirect(reflect.ValueOf(values[idx]))); reflectValue.IsValid() {
+		reflectValue := reflect.Indirect(reflect.Indirect(reflect.ValueOf(values[idx])))
+		// 驱动对无类型的列（如表达式、EXPLAIN 结果）可能返回 *interface{} 作为 ScanType，需要再解引用一层
+		if reflectValue.Kind() == reflect.Ptr && reflectValue.Type().Elem().Kind() == reflect.Interface {
+			reflectValue = reflect.Indirect(reflectValue)
+		}
+
+		if reflectValue.IsValid() {
 			mapValue[column] = reflectValue.Interface()
 			if valuer, ok := mapValue[column].(driver.Valuer); ok {
 				mapValue[column], _ = valuer.Value()
@@ -72,7 +81,7 @@ func (db *DB) scanIntoStruct(rows Rows, reflectValue reflect.Value, values []int
 		}
 
 		if len(joinFields) == 0 || len(joinFields[idx]) == 0 {
-			db.AddError(field.Set(db.Statement.Context, reflectValue, values[idx]))
+			db.setScannedValue(field, reflectValue, values[idx])
 		} else { // joinFields count is larger than 2 when using join
 			var isNilPtrValue bool
 			var relValue reflect.Value
@@ -101,8 +110,7 @@ func (db *DB) scanIntoStruct(rows Rows, reflectValue reflect.Value, values []int
 			}
 
 			if !isNilPtrValue { // ignore if value is nil
-				f := joinFields[idx][len(joinFields[idx])-1]
-				db.AddError(f.Set(db.Statement.Context, relValue, values[idx]))
+				db.setScannedValue(joinFields[idx][len(joinFields[idx])-1], relValue, values[idx])
 			}
 		}
 
@@ -111,6 +119,189 @@ func (db *DB) scanIntoStruct(rows Rows, reflectValue reflect.Value, values []int
 	}
 }
 
+// lookUpJoinFields resolves relation prefixed column like `Manager__Company__name` against sch,
+// returns the relation fields followed by the readable field of the column, or nil if not matched
+func lookUpJoinFields(sch *schema.Schema, column string) []*schema.Field {
+	names := utils.SplitNestedRelationName(column)
+	if len(names) < 2 {
+		return nil
+	}
+
+	relFields := make([]*schema.Field, 0, len(names))
+	for _, name := range names[:len(names)-1] {
+		rel, ok := sch.Relationships.Relations[name]
+		if !ok {
+			return nil
+		}
+		relFields = append(relFields, rel.Field)
+		sch = rel.FieldSchema
+	}
+
+	// lastest name is raw dbname
+	if field := sch.LookUpField(names[len(names)-1]); field != nil && field.Readable {
+		return append(relFields, field)
+	}
+	return nil
+}
+
+// selectedColumnOwners matches columns to the SELECT built by gorm, returns the owner of each column,
+// "" for the model and the relation name like `Manager__Company` for joined relations, e.g: SELECT users.*, Company.*
+// columns of table.* are the columns until a column name repeats, as a table can't have duplicated column names
+func selectedColumnOwners(stmt *Statement, sch *schema.Schema, columns []string) (owners []string, matched bool) {
+	c, ok := stmt.Clauses["SELECT"]
+	if !ok {
+		return nil, false
+	}
+
+	selectClause, ok := c.Expression.(clause.Select)
+	if !ok || selectClause.Expression != nil || len(selectClause.Columns) == 0 {
+		return nil, false
+	}
+
+	type selectItem struct {
+		owner    string
+		wildcard bool
+	}
+
+	ownerOf := func(table string) string {
+		table = strings.Trim(table, "`\"[]")
+		if table == "" || table == clause.CurrentTable || table == stmt.Table || table == sch.Table {
+			return ""
+		}
+
+		// joined relations like `Manager__Company`
+		relSchema := sch
+		for _, name := range utils.SplitNestedRelationName(table) {
+			rel, ok := relSchema.Relationships.Relations[name]
+			if !ok {
+				relSchema = nil
+				break
+			}
+			relSchema = rel.FieldSchema
+		}
+		if relSchema != nil {
+			return table
+		}
+
+		// joined with raw SQL, e.g: LEFT JOIN companies ON ...
+		for _, rel := range sch.Relationships.Relations {
+			if rel.FieldSchema.Table == table {
+				return rel.Name
+			}
+		}
+		return table
+	}
+
+	var items []selectItem
+	for _, column := range selectClause.Columns {
+		if !column.Raw {
+			items = append(items, selectItem{owner: ownerOf(column.Table)})
+			continue
+		}
+
+		for _, item := range utils.SplitSelectItems(column.Name) {
+			switch {
+			case item == "*":
+				return nil, false
+			case strings.HasSuffix(item, ".*"):
+				items = append(items, selectItem{owner: ownerOf(strings.TrimSuffix(item, ".*")), wildcard: true})
+			case strings.Contains(strings.ToLower(item), " as ") || !strings.Contains(item, "."):
+				items = append(items, selectItem{})
+			default:
+				items = append(items, selectItem{owner: ownerOf(item[:strings.LastIndexByte(item, '.')])})
+			}
+		}
+	}
+
+	singles := 0
+	for _, item := range items {
+		if !item.wildcard {
+			singles++
+		}
+	}
+
+	owners = make([]string, 0, len(columns))
+	for _, item := range items {
+		if !item.wildcard {
+			if len(owners) >= len(columns) {
+				return nil, false
+			}
+			owners = append(owners, item.owner)
+			singles--
+			continue
+		}
+
+		seen := map[string]bool{}
+		for len(owners) < len(columns)-singles && !seen[columns[len(owners)]] {
+			seen[columns[len(owners)]] = true
+			owners = append(owners, item.owner)
+		}
+	}
+	return owners, len(owners) == len(columns)
+}
+
+// relationWithColumn returns the relation of sch that has the column
+func relationWithColumn(sch *schema.Schema, column string) *schema.Relationship {
+	for _, rel := range sch.Relationships.Relations {
+		if field := rel.FieldSchema.LookUpField(column); field != nil && field.Readable && rel.Field.Readable {
+			return rel
+		}
+	}
+	return nil
+}
+
+// scanIntoPluckMap scan key and value columns into map for PluckMap
+func (db *DB) scanIntoPluckMap(rows Rows, mapValue reflect.Value) {
+	db.RowsAffected = 0
+	if columns, _ := rows.Columns(); len(columns) != 2 {
+		db.AddError(ErrInvalidValue)
+		return
+	}
+
+	if mapValue.IsNil() {
+		mapValue.Set(reflect.MakeMap(mapValue.Type()))
+	}
+
+	mapType := mapValue.Type()
+	for rows.Next() {
+		key, value := reflect.New(mapType.Key()), reflect.New(mapType.Elem())
+
+		db.RowsAffected++
+		if err := rows.Scan(key.Interface(), value.Interface()); err != nil {
+			db.AddError(err)
+			return
+		}
+		mapValue.SetMapIndex(key.Elem(), value.Elem())
+	}
+	db.AddError(rows.Err())
+}
+
+// setScannedValue set the scanned value to the field, the **T scanned value is nil for NULL, apply NullScanPolicy to non-pointer fields
+func (db *DB) setScannedValue(field *schema.Field, reflectValue reflect.Value, value interface{}) {
+	if db.NullScanPolicy != 0 && field.Serializer == nil && isNullScanned(value) {
+		switch field.FieldType.Kind() {
+		case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64, reflect.String:
+			switch db.NullScanPolicy {
+			case NullScanError:
+				db.AddError(fmt.Errorf("%w: %s.%s", ErrNullValue, field.Schema.Name, field.Name))
+			case NullScanZeroValue:
+				fieldValue := field.ReflectValueOf(db.Statement.Context, reflectValue)
+				fieldValue.Set(reflect.Zero(fieldValue.Type()))
+			}
+			return
+		}
+	}
+
+	db.AddError(field.Set(db.Statement.Context, reflectValue, value))
+}
+
+func isNullScanned(value interface{}) bool {
+	rv := reflect.ValueOf(value)
+	return rv.Kind() == reflect.Ptr && !rv.IsNil() && rv.Elem().Kind() == reflect.Ptr && rv.Elem().IsNil()
+}
+
 // ScanMode scan data mode
 type ScanMode uint8
 
@@ -132,9 +323,14 @@ func Scan(rows Rows, db *DB, mode ScanMode) {
 	)
 
 	db.RowsAffected = 0
+	db.Statement.loadedColumns = columns
 
 	switch dest := db.Statement.Dest.(type) { // switch 要 scan 的目标
 	case map[string]interface{}, *map[string]interface{}: // 如果是要 scan 到 map 里面
+		if db.Statement.MapColumnKeys {
+			columns = db.Statement.mapColumns(columns)
+		}
+
 		if initialized || rows.Next() {
 			columnTypes, _ := rows.ColumnTypes()
 			prepareValues(values, db, columnTypes, columns)
@@ -154,6 +350,10 @@ func Scan(rows Rows, db *DB, mode ScanMode) {
 			scanIntoMap(mapValue, values, columns)
 		}
 	case *[]map[string]interface{}: // 如果是要 scan 到 []map 里面
+		if db.Statement.MapColumnKeys {
+			columns = db.Statement.mapColumns(columns)
+		}
+
 		columnTypes, _ := rows.ColumnTypes()
 		for initialized || rows.Next() {
 			prepareValues(values, db, columnTypes, columns)
@@ -222,54 +422,91 @@ func Scan(rows Rows, db *DB, mode ScanMode) {
 			// Not Pluck
 			// 这个时候还有 schema， 说明是结构体字段接收列值，不是 pluck 那种，整个 dest 接收一个列
 			if sch != nil {
+				columns = db.Statement.mapColumns(columns) // 按映射后的列名匹配字段
 				matchedFieldCount := make(map[string]int, len(columns))
+				var (
+					owners        []string
+					ownersMatched bool
+					firstIndexes  = make(map[string]int, len(columns))
+				)
+
+				// 按 SELECT 中列所属的表，使用 model 或者 join 的关联的字段
+				useOwnerField := func(idx int) bool {
+					if owners[idx] == "" {
+						fields[idx] = sch.LookUpField(columns[idx])
+						return true
+					}
+
+					if relFields := lookUpJoinFields(sch, utils.JoinNestedRelationNames([]string{owners[idx], columns[idx]})); len(relFields) > 0 {
+						fields[idx] = relFields[len(relFields)-1]
+						if len(joinFields) == 0 {
+							joinFields = make([][]*schema.Field, len(columns))
+						}
+						joinFields[idx] = relFields
+						return true
+					}
+					return false
+				}
 				for idx, column := range columns { // 遍历 db 返回接口的所有列的名字
 					if field := sch.LookUpField(column); field != nil && field.Readable { // 如果当前字段能从 schema里面取到 Field, 并且可读
 						fields[idx] = field
 						if count, ok := matchedFieldCount[column]; ok {
 							// 如果 db 返回结果里面的某个字段之前已经匹配到了一个 field，说明 columns 里面有重复字段
 							// handle duplicate fields
+							matched := false
 							for _, selectField := range sch.Fields { // 遍历 schema 里面的所有 fields
 								if selectField.DBName == column && selectField.Readable { // 如果 dbName 精确匹配到了
 									if count == 0 {
 										matchedFieldCount[column]++
 										fields[idx] = selectField
+										matched = true
 										break // 取匹配到的第 count 个 field
 									}
 									count-- // 之前的 field 已经匹配到了，跳过
 								}
 							}
-						} else {
-							matchedFieldCount[column] = 1
-						}
-					} else if names := utils.SplitNestedRelationName(column); len(names) > 1 { // has nested relation
-						if rel, ok := sch.Relationships.Relations[names[0]]; ok {
-							subNameCount := len(names)
-							// nested relation fields
-							relFields := make([]*schema.Field, 0, subNameCount-1)
-							relFields = append(relFields, rel.Field)
-							for _, name := range names[1 : subNameCount-1] {
-								rel = rel.FieldSchema.Relationships.Relations[name]
-								relFields = append(relFields, rel.Field)
-							}
-							// lastest name is raw dbname
-							dbName := names[subNameCount-1]
-							if field := rel.FieldSchema.LookUpField(dbName); field != nil && field.Readable {
-								fields[idx] = field
 
-								if len(joinFields) == 0 {
-									joinFields = make([][]*schema.Field, len(columns))
+							// model 的字段都已匹配，重复的列可能属于 join 的关联，如 SELECT users.*, Company.*
+							if !matched {
+								if owners == nil {
+									owners, ownersMatched = selectedColumnOwners(db.Statement, sch, columns)
+								}
+
+								// 第一次出现的列也可能属于关联，如 SELECT Company.*, users.*
+								if ownersMatched && useOwnerField(firstIndexes[column]) && useOwnerField(idx) {
+									continue
+								}
+
+								// 无法确定列属于哪个表时，不猜测
+								if rel := relationWithColumn(sch, column); rel != nil {
+									fields[idx], values[idx] = nil, &sql.RawBytes{}
+									db.AddError(fmt.Errorf("%w: %s is returned more than once and may be the column of %s, select it with alias %s",
+										ErrAmbiguousColumn, column, rel.Name, utils.JoinNestedRelationNames([]string{rel.Name, column})))
 								}
-								relFields = append(relFields, field)
-								joinFields[idx] = relFields
-								continue
 							}
+						} else {
+							matchedFieldCount[column] = 1
+							firstIndexes[column] = idx
 						}
-						values[idx] = &sql.RawBytes{}
+					} else if relFields := lookUpJoinFields(sch, column); len(relFields) > 0 { // has nested relation
+						fields[idx] = relFields[len(relFields)-1]
+						if len(joinFields) == 0 {
+							joinFields = make([][]*schema.Field, len(columns))
+						}
+						joinFields[idx] = relFields
 					} else {
 						values[idx] = &sql.RawBytes{}
 					}
 				}
+
+				// 只记录主表的列，join 的列不算
+				loadedColumns := make([]string, 0, len(columns))
+				for idx, field := range fields {
+					if field != nil && (len(joinFields) == 0 || len(joinFields[idx]) == 0) {
+						loadedColumns = append(loadedColumns, field.DBName)
+					}
+				}
+				db.Statement.loadedColumns = loadedColumns
 			}
 		}
 
@@ -1,6 +1,7 @@
 package schema
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 )
@@ -8,28 +9,63 @@ import (
 // reg match english letters and midline
 var regEnLetterAndMidline = regexp.MustCompile("^[A-Za-z-_]+$")
 
-type Check struct {
+// CheckConstraint check constraint parsed from field's check tag
+type CheckConstraint struct {
 	Name       string
 	Constraint string // length(phone) >= 10
 	*Field
 }
 
+// Check is the old name of CheckConstraint
+//
+// Deprecated: use CheckConstraint instead
+type Check = CheckConstraint
+
 // ParseCheckConstraints parse schema check constraints
-func (schema *Schema) ParseCheckConstraints() map[string]Check {
-	checks := map[string]Check{}
-	for _, field := range schema.FieldsByDBName {
+func (schema *Schema) ParseCheckConstraints() map[string]CheckConstraint {
+	checks, _ := schema.parseCheckConstraints()
+	return checks
+}
+
+// LookUpCheckConstraint returns the parsed check constraint by name, e.g. the constraint of a check violation error, nil if not found
+func (schema *Schema) LookUpCheckConstraint(name string) *CheckConstraint {
+	checks, _ := schema.parseCheckConstraints()
+	if chk, ok := checks[name]; ok {
+		return &chk
+	}
+	return nil
+}
+
+// parseCheckConstraints parse check constraints, returns error if fields have checks with the same name
+//
+// explicit names of embedded fields are prefixed with the embedded prefix, e.g:
+//
+//	type Amount struct {
+//		Value int `gorm:"check:value_positive,value > 0"`
+//	}
+//
+//	Price Amount `gorm:"embedded;embeddedPrefix:price_"` // check name: price_value_positive
+func (schema *Schema) parseCheckConstraints() (map[string]CheckConstraint, error) {
+	checks := map[string]CheckConstraint{}
+	for _, dbName := range schema.DBNames {
+		field := schema.FieldsByDBName[dbName]
 		if chk := field.TagSettings["CHECK"]; chk != "" {
+			var name string
 			names := strings.Split(chk, ",")
 			if len(names) > 1 && regEnLetterAndMidline.MatchString(names[0]) {
-				checks[names[0]] = Check{Name: names[0], Constraint: strings.Join(names[1:], ","), Field: field}
+				name, chk = field.EmbeddedPrefix+names[0], strings.Join(names[1:], ",")
 			} else {
 				if names[0] == "" {
 					chk = strings.Join(names[1:], ",")
 				}
-				name := schema.namer.CheckerName(schema.Table, field.DBName)
-				checks[name] = Check{Name: name, Constraint: chk, Field: field}
+				name = schema.namer.CheckerName(schema.Table, field.DBName)
 			}
+
+			if exist, ok := checks[name]; ok {
+				return checks, fmt.Errorf("duplicated check constraint %s for struct %s's fields %s and %s", name, schema.Name, exist.Field.BindName(), field.BindName())
+			}
+			checks[name] = CheckConstraint{Name: name, Constraint: chk, Field: field}
 		}
 	}
-	return checks
+	return checks, nil
 }
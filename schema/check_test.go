@@ -2,6 +2,7 @@ package schema_test
 
 import (
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
 
@@ -53,3 +54,68 @@ func TestParseCheck(t *testing.T) {
 		}
 	}
 }
+
+type CheckAmount struct {
+	Value int `gorm:"check:value_positive,value > 0"`
+	Limit int `gorm:"check:limit > 0"`
+}
+
+type OrderCheck struct {
+	ID    uint
+	Price CheckAmount `gorm:"embedded;embeddedPrefix:price_"`
+	Cost  CheckAmount `gorm:"embedded;embeddedPrefix:cost_"`
+}
+
+func TestParseCheckWithEmbeddedPrefix(t *testing.T) {
+	order, err := schema.Parse(&OrderCheck{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse order check, got error %v", err)
+	}
+
+	checks := order.ParseCheckConstraints()
+	results := map[string]string{
+		"price_value_positive":         "price_value",
+		"cost_value_positive":          "cost_value",
+		"chk_order_checks_price_limit": "price_limit",
+		"chk_order_checks_cost_limit":  "cost_limit",
+	}
+
+	if len(checks) != len(results) {
+		t.Errorf("expects %v checks, got %+v", len(results), checks)
+	}
+
+	for name, dbName := range results {
+		if chk, ok := checks[name]; !ok {
+			t.Errorf("failed to found check %v from parsed checks %+v", name, checks)
+		} else if chk.Field.DBName != dbName {
+			t.Errorf("check %v should belong to field %v, got %v", name, dbName, chk.Field.DBName)
+		}
+	}
+}
+
+type DuplicatedCheck struct {
+	Name  string `gorm:"check:name_checker,name <> 'jinzhu'"`
+	Name2 string `gorm:"check:name_checker,name2 <> 'jinzhu'"`
+}
+
+func TestParseDuplicatedCheck(t *testing.T) {
+	_, err := schema.Parse(&DuplicatedCheck{}, &sync.Map{}, schema.NamingStrategy{})
+	if err == nil || !strings.Contains(err.Error(), "name_checker") || !strings.Contains(err.Error(), "Name2") {
+		t.Errorf("should return duplicated check error listing both fields, got %v", err)
+	}
+}
+
+func TestLookUpCheckConstraint(t *testing.T) {
+	user, err := schema.Parse(&UserCheck{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse user check, got error %v", err)
+	}
+
+	if chk := user.LookUpCheckConstraint("chk_user_checks_name2"); chk == nil || chk.Field.Name != "Name2" || chk.Constraint != "name <> 'jinzhu'" {
+		t.Errorf("failed to look up check constraint, got %+v", chk)
+	}
+
+	if chk := user.LookUpCheckConstraint("not_exists"); chk != nil {
+		t.Errorf("should not found check constraint, got %+v", chk)
+	}
+}
@@ -0,0 +1,57 @@
+package schema
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"sync"
+)
+
+// DataTypeSpec converters of registered data types, used for types can't implement Scanner/Valuer, e.g. types of third-party packages
+type DataTypeSpec struct {
+	// GORMType GORM data type of the type, defaults to the data type of its kind or string
+	GORMType DataType
+	// DBType returns the column type of the field for the dialect (name of the dialector), returns empty string to use the dialector's
+	DBType func(dialect string, field *Field) string
+	// Bind converts field value to database value, the value is not pointer
+	Bind func(v interface{}) (driver.Value, error)
+	// Scan sets database value src to dst, dst is the settable non-pointer reflect value of field, src is not nil,
+	// []byte src is only valid during the call, copy it if it is retained
+	Scan func(dst reflect.Value, src interface{}) error
+}
+
+var dataTypeMap = sync.Map{}
+
+// RegisterDataType register data type, should be called before parsing models having the type
+func RegisterDataType(t reflect.Type, spec DataTypeSpec) {
+	dataTypeMap.Store(t, spec)
+}
+
+// LookUpDataType look up registered data type
+func LookUpDataType(t reflect.Type) (spec DataTypeSpec, ok bool) {
+	v, ok := dataTypeMap.Load(t)
+	if ok {
+		spec, ok = v.(DataTypeSpec)
+	}
+	return spec, ok
+}
+
+// dataTypeScanner holds database value of registered data type when scanning
+type dataTypeScanner struct {
+	value interface{}
+}
+
+// Scan implements sql.Scanner interface
+func (s *dataTypeScanner) Scan(value interface{}) error {
+	s.value = value
+	return nil
+}
+
+// dataTypeBindError returns the bind error when executing
+type dataTypeBindError struct {
+	err error
+}
+
+// Value implements driver.Valuer interface
+func (e dataTypeBindError) Value() (driver.Value, error) {
+	return nil, e.err
+}
@@ -0,0 +1,26 @@
+package schema
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// DefaultValuer generates the default value of field when creating, e.g. uuid, snowflake id
+type DefaultValuer func(ctx context.Context) interface{}
+
+var defaultValuerMap = sync.Map{}
+
+// RegisterDefaultValuer register default valuer, fields with tag `default:fn:name` use it when their value is zero
+func RegisterDefaultValuer(name string, valuer DefaultValuer) {
+	defaultValuerMap.Store(strings.ToLower(name), valuer)
+}
+
+// GetDefaultValuer get default valuer
+func GetDefaultValuer(name string) (valuer DefaultValuer, ok bool) {
+	v, ok := defaultValuerMap.Load(strings.ToLower(name))
+	if ok {
+		valuer, ok = v.(DefaultValuer)
+	}
+	return valuer, ok
+}
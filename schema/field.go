@@ -5,7 +5,9 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
+	"math"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -18,9 +20,10 @@ import (
 
 // special types' reflect type
 var (
-	TimeReflectType    = reflect.TypeOf(time.Time{})
-	TimePtrReflectType = reflect.TypeOf(&time.Time{})
-	ByteReflectType    = reflect.TypeOf(uint8(0))
+	TimeReflectType     = reflect.TypeOf(time.Time{})
+	TimePtrReflectType  = reflect.TypeOf(&time.Time{})
+	DurationReflectType = reflect.TypeOf(time.Duration(0))
+	ByteReflectType     = reflect.TypeOf(uint8(0))
 )
 
 type (
@@ -47,6 +50,8 @@ const (
 	String DataType = "string"
 	Time   DataType = "time"
 	Bytes  DataType = "bytes"
+	// Decimal decimal numbers bound and scanned as strings without losing precision, e.g. big.Int, big.Rat
+	Decimal DataType = "decimal"
 )
 
 // Field is the representation of model schema's field
@@ -63,18 +68,31 @@ type Field struct {
 	Creatable              bool                // 创建的时候可见
 	Updatable              bool                // 更新的时候可见
 	Readable               bool                // 读取的时候可见
+	Immutable              bool                // 创建后不可更新，显式更新时报错
 	AutoCreateTime         TimeType            // 在创建的时候自动设置创建时间,及其设置形式
 	AutoUpdateTime         TimeType            // 在创建和更新的时候自动设置更新时间,及其设置形式
+	DBTime                 bool                // 自动时间使用数据库的当前时间，通过 autoCreateTime:db 注解或者 model 实现 DBTimeModel 接口指定
 	HasDefaultValue        bool                // 该字段是否有默认值，带有 default 注解，或者是自增的注解
 	DefaultValue           string              // 该字段的默认值
+	DefaultNow             bool                // 时间字段的默认值是当前时间的函数，如 now()、current_timestamp
 	DefaultValueInterface  interface{}         // 解析后的默认值，以下情况有默认值但是该字段为空：默认值包含函数 ( ), 或者是 null, ""
+	DefaultValueFunc       DefaultValuer       // default:fn:name 注解指定的默认值生成函数，创建时字段为零值则调用
 	NotNull                bool                // 是否是 NOT NULL
 	Unique                 bool                // 是否是唯一的
 	Comment                string              // 表字段注释
+	Charset                string              // 字段字符集，如 utf8mb4
+	Collation              string              // 字段排序规则，如 utf8mb4_unicode_ci
+	Generated              string              // 生成列的表达式，如 concat(first_name,' ',last_name)
+	GeneratedStored        bool                // 生成列是否存储（STORED），否则为 VIRTUAL
 	Size                   int                 // 字段的大小
 	Precision              int                 // 精度
 	Scale                  int                 // 小数位数的精度
+	TimePrecision          time.Duration       // 时间字段比较时的精度，由 precision 注解或者 type 注解推导，默认微秒
+	DurationUnit           time.Duration       // time.Duration 字段在数据库里面的单位，由 durationUnit 注解指定，默认纳秒
 	IgnoreMigration        bool                // migration 时忽略该字段
+	SelectExpr             string              // 查询时计算的表达式，如 price * quantity，查询所有字段时以 (expr) AS 列名 选出，只读且不参与 migration
+	ColumnOrder            int                 // order 注解指定的列顺序，按升序排列建表和插入的列，未指定的为 0，相同时保持声明顺序
+	EmbeddedPrefix         string              // 嵌入结构体字段的列名前缀，多层嵌套时包含所有前缀
 	FieldType              reflect.Type        // 字段的类型，可能是指针
 	IndirectFieldType      reflect.Type        // 字段的真实类型
 	StructField            reflect.StructField // 从当前字段所属结构体里面取出来的字段定义,如果是嵌套结构体，则 Index 会有多层
@@ -90,8 +108,9 @@ type Field struct {
 	// 如果当前字段定义是嵌套结构体，会用 StructField.Index 逐级查找对应的字段的 interface{} 值和是否为空
 	ValueOf func(context.Context, reflect.Value) (value interface{}, zero bool)
 	// 为 field 赋值，对于一个 reflect.Value，找到其真实嵌套位置，然后设置其值 （interface{}）
-	Set        func(context.Context, reflect.Value, interface{}) error
-	Serializer SerializerInterface // 该字段配置的序列化器
+	Set          func(context.Context, reflect.Value, interface{}) error
+	Serializer   SerializerInterface // 该字段配置的序列化器
+	DataTypeSpec *DataTypeSpec       // 通过 RegisterDataType 注册的类型转换
 	// schema.serializer 的对象池
 	NewValuePool FieldNewValuePool
 }
@@ -126,6 +145,8 @@ func (schema *Schema) ParseField(fieldStruct reflect.StructField) *Field {
 		NotNull:                utils.CheckTruth(tagSetting["NOT NULL"], tagSetting["NOTNULL"]),
 		Unique:                 utils.CheckTruth(tagSetting["UNIQUE"]),
 		Comment:                tagSetting["COMMENT"],
+		Charset:                tagSetting["CHARSET"],
+		Collation:              tagSetting["COLLATE"],
 		AutoIncrementIncrement: 1,
 	}
 
@@ -136,6 +157,11 @@ func (schema *Schema) ParseField(fieldStruct reflect.StructField) *Field {
 	fieldValue := reflect.New(field.IndirectFieldType) // 创建一个实际类型实例
 	// if field is valuer, used its value or first field as data type
 	valuer, isValuer := fieldValue.Interface().(driver.Valuer)
+	if spec, ok := LookUpDataType(field.IndirectFieldType); ok { // 注册的类型，由注册的方法转换，当做 Valuer 对待
+		field.DataTypeSpec = &spec
+		isValuer = false
+	}
+
 	if isValuer { // 如果实现了 driver.Valuer 接口
 		if _, ok := fieldValue.Interface().(GormDataTypeInterface); !ok {
 			if v, err := valuer.Value(); reflect.ValueOf(v).IsValid() && err == nil {
@@ -205,8 +231,15 @@ func (schema *Schema) ParseField(fieldStruct reflect.StructField) *Field {
 	}
 
 	if v, ok := field.TagSettings["DEFAULT"]; ok {
-		field.HasDefaultValue = true
-		field.DefaultValue = v // 配置了 DEFAULT 注解，设置默认值
+		if name := strings.TrimSpace(v); strings.HasPrefix(name, "fn:") {
+			// 默认值由注册的函数在创建时生成，不是数据库的默认值
+			if field.DefaultValueFunc, ok = GetDefaultValuer(strings.TrimPrefix(name, "fn:")); !ok {
+				schema.err = fmt.Errorf("invalid default valuer %v for field %s", strings.TrimPrefix(name, "fn:"), field.Name)
+			}
+		} else {
+			field.HasDefaultValue = true
+			field.DefaultValue = v // 配置了 DEFAULT 注解，设置默认值
+		}
 	}
 
 	if num, ok := field.TagSettings["SIZE"]; ok {
@@ -223,6 +256,10 @@ func (schema *Schema) ParseField(fieldStruct reflect.StructField) *Field {
 		field.Scale, _ = strconv.Atoi(s) // 小数位数的精度
 	}
 
+	if o, ok := field.TagSettings["ORDER"]; ok {
+		field.ColumnOrder, _ = strconv.Atoi(o) // 列顺序
+	}
+
 	// default value is function or null or blank (primary keys)
 	field.DefaultValue = strings.TrimSpace(field.DefaultValue)
 	// 如果默认值包含 ( ), 或者是 null, "" , 不解析默认值
@@ -272,27 +309,53 @@ func (schema *Schema) ParseField(fieldStruct reflect.StructField) *Field {
 		} else if fieldValue.Type().ConvertibleTo(TimePtrReflectType) {
 			field.DataType = Time
 		}
+		if field.DataType == Time {
+			field.TimePrecision = parseTimePrecision(field)
+		}
 		if field.HasDefaultValue && !skipParseDefaultValue && field.DataType == Time {
 			if t, err := now.Parse(field.DefaultValue); err == nil {
 				field.DefaultValueInterface = t
 			}
 		}
+		if field.HasDefaultValue && field.DataType == Time && field.DefaultValueInterface == nil {
+			field.DefaultNow = isNowFunc(field.DefaultValue)
+		}
 	case reflect.Array, reflect.Slice:
 		if reflect.Indirect(fieldValue).Type().Elem() == ByteReflectType && field.DataType == "" {
 			field.DataType = Bytes
 		}
 	}
 
+	// time.Duration 字段默认以纳秒整数保存，durationUnit 注解可以指定其他单位
+	if field.IndirectFieldType == DurationReflectType {
+		if field.Serializer != nil {
+			field.DataType = String
+		} else if unit, ok := field.TagSettings["DURATIONUNIT"]; ok {
+			if field.DurationUnit, ok = durationUnits[strings.ToLower(strings.TrimSpace(unit))]; !ok {
+				schema.err = fmt.Errorf("invalid duration unit %v for field %s, should be one of ns, us, ms, s", unit, field.Name)
+			}
+		}
+	}
+
 	if dataTyper, ok := fieldValue.Interface().(GormDataTypeInterface); ok {
 		field.DataType = DataType(dataTyper.GormDataType()) // 如果实现 GormDataTypeInterface ，可指定 DataType
 	}
 
+	if field.DataTypeSpec != nil && field.Serializer == nil {
+		if field.DataTypeSpec.GORMType != "" {
+			field.DataType = field.DataTypeSpec.GORMType
+		} else if field.DataType == "" {
+			field.DataType = String
+		}
+	}
+
 	// 以下情况会自动设置创建时间
 	// 1. 带有 AUTOCREATETIME 注解，
 	// 2. 属性名叫做：CreatedAt 并且类型在 (Time, Int, Uint) 里面
 	if v, ok := field.TagSettings["AUTOCREATETIME"]; (ok && utils.CheckTruth(v)) || (!ok && field.Name == "CreatedAt" && (field.DataType == Time || field.DataType == Int || field.DataType == Uint)) {
 		if field.DataType == Time {
 			field.AutoCreateTime = UnixTime
+			field.DBTime = field.DBTime || strings.ToUpper(v) == "DB"
 		} else if strings.ToUpper(v) == "NANO" {
 			field.AutoCreateTime = UnixNanosecond
 		} else if strings.ToUpper(v) == "MILLI" {
@@ -308,6 +371,7 @@ func (schema *Schema) ParseField(fieldStruct reflect.StructField) *Field {
 	if v, ok := field.TagSettings["AUTOUPDATETIME"]; (ok && utils.CheckTruth(v)) || (!ok && field.Name == "UpdatedAt" && (field.DataType == Time || field.DataType == Int || field.DataType == Uint)) {
 		if field.DataType == Time {
 			field.AutoUpdateTime = UnixTime
+			field.DBTime = field.DBTime || strings.ToUpper(v) == "DB"
 		} else if strings.ToUpper(v) == "NANO" {
 			field.AutoUpdateTime = UnixNanosecond
 		} else if strings.ToUpper(v) == "MILLI" {
@@ -390,11 +454,32 @@ func (schema *Schema) ParseField(fieldStruct reflect.StructField) *Field {
 		}
 	}
 
+	if _, ok := field.TagSettings["IMMUTABLE"]; ok { // 创建后不可更新
+		field.Immutable = true
+		field.Updatable = false
+	}
+
+	// 生成列由数据库计算，不可创建、更新，方言支持生成列时插入后由数据库返回
+	if expr, ok := field.TagSettings["GENERATED"]; ok && expr != "GENERATED" {
+		field.Generated = expr
+		field.GeneratedStored = utils.CheckTruth(field.TagSettings["STORED"])
+		field.Creatable = false
+		field.Updatable = false
+	}
+
+	// 查询时计算的列，数据库里面没有该列
+	if expr, ok := field.TagSettings["SELECTEXPR"]; ok && expr != "SELECTEXPR" {
+		field.SelectExpr = strings.TrimSpace(expr)
+		field.Creatable = false
+		field.Updatable = false
+		field.IgnoreMigration = true
+	}
+
 	// Normal anonymous field or having `EMBEDDED` tag
 	// 以下情况之一会当做 EMBEDDED model,
 	// 1. 带有 EMBEDDED 注解
 	// 2. 类型不为 (Time, Bytes), 并且没实现 driver.Valuer 接口，并且为嵌入字段，并且有(可创建，可更新，可读)权限之一)
-	if _, ok := field.TagSettings["EMBEDDED"]; ok || (field.GORMDataType != Time && field.GORMDataType != Bytes && !isValuer &&
+	if _, ok := field.TagSettings["EMBEDDED"]; ok || (field.GORMDataType != Time && field.GORMDataType != Bytes && !isValuer && field.DataTypeSpec == nil &&
 		fieldStruct.Anonymous && (field.Creatable || field.Updatable || field.Readable)) {
 		kind := reflect.Indirect(fieldValue).Kind()
 		switch kind {
@@ -425,6 +510,7 @@ func (schema *Schema) ParseField(fieldStruct reflect.StructField) *Field {
 
 				if prefix, ok := field.TagSettings["EMBEDDEDPREFIX"]; ok && ef.DBName != "" {
 					ef.DBName = prefix + ef.DBName // 如果定义了 EMBEDDEDPREFIX 注解，给 DBName 加一个前缀
+					ef.EmbeddedPrefix = prefix + ef.EmbeddedPrefix
 				}
 
 				if ef.PrimaryKey {
@@ -445,6 +531,11 @@ func (schema *Schema) ParseField(fieldStruct reflect.StructField) *Field {
 					}
 				}
 
+				// 嵌套结构体字段未指定顺序时，使用嵌套结构体的顺序
+				if _, ok := ef.TagSettings["ORDER"]; !ok {
+					ef.ColumnOrder = field.ColumnOrder
+				}
+
 				for k, v := range field.TagSettings {
 					ef.TagSettings[k] = v // 嵌套结构体字段的 tag Setting 也会收集到嵌套结构体的 TagSetting 里面
 				}
@@ -455,9 +546,147 @@ func (schema *Schema) ParseField(fieldStruct reflect.StructField) *Field {
 		}
 	}
 
+	// 不支持的类型在解析时就报错，而不是等到解析关联关系或者查询时
+	if schema.err == nil && field.DataType == "" && (field.Creatable || field.Updatable || field.Readable) && !isValuer {
+		if _, isScanner := reflect.New(field.IndirectFieldType).Interface().(sql.Scanner); !isScanner && !isSupportedFieldKind(reflect.Indirect(fieldValue).Type()) {
+			schema.err = fmt.Errorf("%w %v for struct %s's field %s: implement the Valuer/Scanner interface, use a serializer like `gorm:\"serializer:json\"`, or ignore it with `gorm:\"-\"`",
+				ErrUnsupportedDataType, field.FieldType, schema.Name, field.Name)
+		}
+	}
+
 	return field
 }
 
+// regTimePrecision match fractional seconds precision of time column type, e.g. datetime(3)
+var regTimePrecision = regexp.MustCompile(`\((\d)\)\s*$`)
+
+// parseTimePrecision returns the precision of time field, derived from precision tag or column type, defaults to microsecond
+func parseTimePrecision(field *Field) time.Duration {
+	precision := 6
+	if _, ok := field.TagSettings["PRECISION"]; ok {
+		precision = field.Precision
+	} else if matches := regTimePrecision.FindStringSubmatch(field.TagSettings["TYPE"]); len(matches) == 2 {
+		precision, _ = strconv.Atoi(matches[1])
+	}
+
+	if precision < 0 || precision > 9 {
+		return time.Nanosecond
+	}
+
+	duration := time.Second
+	for i := 0; i < precision; i++ {
+		duration /= 10
+	}
+	return duration
+}
+
+// durationUnits units of durationUnit tag
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+}
+
+// isSupportedFieldKind returns false for kinds can't be stored in a column nor used as relations
+func isSupportedFieldKind(fieldType reflect.Type) bool {
+	switch fieldType.Kind() {
+	case reflect.Map, reflect.Chan, reflect.Func, reflect.Interface, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return false
+	case reflect.Slice, reflect.Array:
+		elemType := fieldType.Elem()
+		for elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
+		}
+		// 结构体切片是 has many、many2many 关联
+		return elemType.Kind() == reflect.Struct
+	}
+	return true
+}
+
+// zeroChecker returns custom zero value checker of the field, returns nil if using reflect's IsZero
+//
+//	`gorm:"zerovalue:-1"` treats -1 as the zero value, `gorm:"zerovalue:null"` treats only nil as the zero value
+func (field *Field) zeroChecker() func(value interface{}) bool {
+	if str, ok := field.TagSettings["ZEROVALUE"]; ok {
+		if strings.ToUpper(str) == "NULL" {
+			return func(value interface{}) bool {
+				rv := reflect.ValueOf(value)
+				switch rv.Kind() {
+				case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+					return rv.IsNil()
+				}
+				return false
+			}
+		}
+
+		zeroValue := reflect.New(field.IndirectFieldType).Elem()
+		switch zeroValue.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			v, err := strconv.ParseInt(str, 10, 64)
+			if err != nil {
+				return nil
+			}
+			zeroValue.SetInt(v)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			v, err := strconv.ParseUint(str, 10, 64)
+			if err != nil {
+				return nil
+			}
+			zeroValue.SetUint(v)
+		case reflect.Float32, reflect.Float64:
+			v, err := strconv.ParseFloat(str, 64)
+			if err != nil {
+				return nil
+			}
+			zeroValue.SetFloat(v)
+		case reflect.Bool:
+			v, err := strconv.ParseBool(str)
+			if err != nil {
+				return nil
+			}
+			zeroValue.SetBool(v)
+		case reflect.String:
+			zeroValue.SetString(str)
+		default:
+			return nil
+		}
+
+		zero := zeroValue.Interface()
+		return func(value interface{}) bool {
+			rv := reflect.ValueOf(value)
+			if rv.Kind() == reflect.Ptr {
+				if rv.IsNil() {
+					return true
+				}
+				rv = rv.Elem()
+			}
+			return rv.Interface() == zero
+		}
+	}
+
+	if _, ok := reflect.New(field.IndirectFieldType).Interface().(ZeroChecker); ok {
+		return func(value interface{}) bool {
+			rv := reflect.ValueOf(value)
+			if rv.Kind() == reflect.Ptr {
+				if rv.IsNil() {
+					return true
+				}
+			} else {
+				ptr := reflect.New(rv.Type())
+				ptr.Elem().Set(rv)
+				rv = ptr
+			}
+
+			if checker, ok := rv.Interface().(ZeroChecker); ok {
+				return checker.GormIsZero()
+			}
+			return reflect.Indirect(rv).IsZero()
+		}
+	}
+	return nil
+}
+
 // create valuer, setter when parse struct
 func (field *Field) setupValuerAndSetter() {
 	// Setup NewValuePool
@@ -497,6 +726,53 @@ func (field *Field) setupValuerAndSetter() {
 		}
 	}
 
+	// 自定义零值判断，ZeroChecker 接口或者 zerovalue 注解
+	if isZero := field.zeroChecker(); isZero != nil {
+		oldValueOf := field.ValueOf
+		field.ValueOf = func(ctx context.Context, v reflect.Value) (interface{}, bool) {
+			value, zero := oldValueOf(ctx, v)
+			if value == nil {
+				return value, zero
+			}
+			return value, isZero(value)
+		}
+	}
+
+	// 转换为 durationUnit 单位的整数，不足一个单位的部分被截断
+	if field.DurationUnit > time.Nanosecond {
+		oldValueOf := field.ValueOf
+		field.ValueOf = func(ctx context.Context, v reflect.Value) (interface{}, bool) {
+			value, zero := oldValueOf(ctx, v)
+			switch d := value.(type) {
+			case time.Duration:
+				return int64(d / field.DurationUnit), zero
+			case *time.Duration:
+				if d != nil {
+					return int64(*d / field.DurationUnit), zero
+				}
+			}
+			return value, zero
+		}
+	}
+
+	// 注册的类型，由 Bind 转换为数据库的值
+	if field.DataTypeSpec != nil && field.DataTypeSpec.Bind != nil && field.Serializer == nil {
+		oldValuerOf := field.ValueOf
+		field.ValueOf = func(ctx context.Context, v reflect.Value) (interface{}, bool) {
+			value, zero := oldValuerOf(ctx, v)
+			rv := reflect.ValueOf(value)
+			if value == nil || (rv.Kind() == reflect.Ptr && rv.IsNil()) {
+				return nil, zero
+			}
+
+			dv, err := field.DataTypeSpec.Bind(reflect.Indirect(rv).Interface())
+			if err != nil { // 执行时返回错误
+				return dataTypeBindError{err: fmt.Errorf("failed to bind field %s: %w", field.Name, err)}, zero
+			}
+			return dv, zero
+		}
+	}
+
 	if field.Serializer != nil {
 		oldValuerOf := field.ValueOf
 		field.ValueOf = func(ctx context.Context, v reflect.Value) (interface{}, bool) {
@@ -965,6 +1241,72 @@ func (field *Field) setupValuerAndSetter() {
 		}
 	}
 
+	// 数据库里面的数值是 durationUnit 单位的个数，time.Duration 类型的值直接设置
+	if field.DurationUnit > time.Nanosecond {
+		oldFieldSetter := field.Set
+		field.Set = func(ctx context.Context, value reflect.Value, v interface{}) error {
+			switch data := v.(type) {
+			case time.Duration, *time.Duration:
+				return oldFieldSetter(ctx, value, v)
+			case **time.Duration:
+				if data != nil && *data != nil {
+					return oldFieldSetter(ctx, value, **data*field.DurationUnit)
+				}
+				return oldFieldSetter(ctx, value, v)
+			case []byte:
+				return field.Set(ctx, value, string(data))
+			case string:
+				if i, err := strconv.ParseInt(data, 0, 64); err == nil {
+					return oldFieldSetter(ctx, value, time.Duration(i)*field.DurationUnit)
+				} else if d, err := parseDuration(data); err == nil {
+					return oldFieldSetter(ctx, value, d)
+				}
+				return fmt.Errorf("failed to set string %v to time.Duration field %s", data, field.Name)
+			}
+
+			switch rv := reflect.ValueOf(v); rv.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				return oldFieldSetter(ctx, value, time.Duration(rv.Int())*field.DurationUnit)
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				return oldFieldSetter(ctx, value, time.Duration(rv.Uint())*field.DurationUnit)
+			case reflect.Float32, reflect.Float64:
+				return oldFieldSetter(ctx, value, time.Duration(math.Round(rv.Float()*float64(field.DurationUnit))))
+			}
+			return oldFieldSetter(ctx, value, v)
+		}
+	}
+
+	// 注册的类型，由 Scan 将数据库的值设置到字段
+	if field.DataTypeSpec != nil && field.DataTypeSpec.Scan != nil && field.Serializer == nil {
+		oldFieldSetter := field.Set
+		field.Set = func(ctx context.Context, value reflect.Value, v interface{}) error {
+			if s, ok := v.(*dataTypeScanner); ok {
+				v = s.value
+			}
+
+			if v == nil {
+				return oldFieldSetter(ctx, value, nil)
+			}
+
+			if rt := reflect.TypeOf(v); rt.AssignableTo(field.FieldType) || rt.AssignableTo(field.IndirectFieldType) ||
+				(rt.Kind() == reflect.Ptr && rt.Elem() == field.IndirectFieldType) {
+				return oldFieldSetter(ctx, value, v)
+			}
+
+			fieldValue := field.ReflectValueOf(ctx, value)
+			if fieldValue.Kind() == reflect.Ptr {
+				elem := reflect.New(field.IndirectFieldType)
+				if err := field.DataTypeSpec.Scan(elem.Elem(), v); err != nil {
+					return fmt.Errorf("failed to scan field %s: %w", field.Name, err)
+				}
+				fieldValue.Set(elem)
+			} else if err := field.DataTypeSpec.Scan(fieldValue, v); err != nil {
+				return fmt.Errorf("failed to scan field %s: %w", field.Name, err)
+			}
+			return nil
+		}
+	}
+
 	if field.Serializer != nil {
 		var (
 			oldFieldSetter = field.Set
@@ -1021,8 +1363,34 @@ func (field *Field) setupNewValuePool() {
 		}
 	}
 
+	if field.NewValuePool == nil && field.DataTypeSpec != nil && field.DataTypeSpec.Scan != nil {
+		// 注册的类型，先保存数据库原始的值，再由 Scan 转换
+		field.NewValuePool = &sync.Pool{
+			New: func() interface{} {
+				return &dataTypeScanner{}
+			},
+		}
+	}
+
 	if field.NewValuePool == nil { // 如果是不带序列化器的
 		// 从全局类型对象池 map 里面根据 IndirectFieldType 取一个
 		field.NewValuePool = poolInitializer(reflect.PtrTo(field.IndirectFieldType))
 	}
 }
+
+// isNowFunc returns true if the default value is a well-known function of the current time, e.g: now(), current_timestamp(3)
+func isNowFunc(value string) bool {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if idx := strings.IndexByte(value, '('); idx > 0 && strings.HasSuffix(value, ")") {
+		if _, err := strconv.Atoi(value[idx+1 : len(value)-1]); err != nil && idx+2 != len(value) {
+			return false
+		}
+		value = value[:idx]
+	}
+
+	switch value {
+	case "now", "current_timestamp", "localtimestamp", "getdate", "sysdatetime":
+		return true
+	}
+	return false
+}
@@ -3,6 +3,8 @@ package schema_test
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
 	"reflect"
 	"sync"
 	"testing"
@@ -228,16 +230,73 @@ func TestAdvancedDataTypeValuerAndSetter(t *testing.T) {
 	checkField(t, userSchema, reflectValue, newValues2)
 }
 
+type customZeroValue int
+
+func (v customZeroValue) GormIsZero() bool {
+	return v < 0
+}
+
+// implicitZeroValue only has IsZero, which is not used by gorm
+type implicitZeroValue int
+
+func (v implicitZeroValue) IsZero() bool {
+	return v < 0
+}
+
+type UserWithZeroValue struct {
+	ID       uint
+	Age      int     `gorm:"zerovalue:-1"`
+	Name     string  `gorm:"zerovalue:unknown"`
+	Active   bool    `gorm:"zerovalue:null"`
+	Nickname *string `gorm:"zerovalue:null"`
+	Score    customZeroValue
+	Scores   *customZeroValue
+	Level    implicitZeroValue
+}
+
+func TestFieldValuerWithZeroValue(t *testing.T) {
+	userSchema, err := schema.Parse(&UserWithZeroValue{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse user with zero value, got error %v", err)
+	}
+
+	score := customZeroValue(-1)
+	for _, c := range []struct {
+		user     UserWithZeroValue
+		field    string
+		expected bool
+	}{
+		{UserWithZeroValue{Age: 0}, "Age", false},
+		{UserWithZeroValue{Age: -1}, "Age", true},
+		{UserWithZeroValue{Name: ""}, "Name", false},
+		{UserWithZeroValue{Name: "unknown"}, "Name", true},
+		{UserWithZeroValue{Active: false}, "Active", false},
+		{UserWithZeroValue{}, "Nickname", true},
+		{UserWithZeroValue{Score: 0}, "Score", false},
+		{UserWithZeroValue{Score: -2}, "Score", true},
+		{UserWithZeroValue{}, "Scores", true},
+		{UserWithZeroValue{Scores: &score}, "Scores", true},
+		{UserWithZeroValue{Level: 0}, "Level", true},
+		{UserWithZeroValue{Level: -1}, "Level", false},
+	} {
+		if _, zero := userSchema.LookUpField(c.field).ValueOf(context.Background(), reflect.ValueOf(c.user)); zero != c.expected {
+			t.Errorf("field %v of %+v should be zero: %v, got %v", c.field, c.user, c.expected, zero)
+		}
+	}
+}
+
 type UserWithPermissionControl struct {
-	ID    uint
-	Name  string `gorm:"-"`
-	Name2 string `gorm:"->"`
-	Name3 string `gorm:"<-"`
-	Name4 string `gorm:"<-:create"`
-	Name5 string `gorm:"<-:update"`
-	Name6 string `gorm:"<-:create,update"`
-	Name7 string `gorm:"->:false;<-:create,update"`
-	Name8 string `gorm:"->;-:migration"`
+	ID     uint
+	Name   string `gorm:"-"`
+	Name2  string `gorm:"->"`
+	Name3  string `gorm:"<-"`
+	Name4  string `gorm:"<-:create"`
+	Name5  string `gorm:"<-:update"`
+	Name6  string `gorm:"<-:create,update"`
+	Name7  string `gorm:"->:false;<-:create,update"`
+	Name8  string `gorm:"->;-:migration"`
+	Name9  string `gorm:"<-:create;->"`
+	Name10 string `gorm:"immutable"`
 }
 
 func TestParseFieldWithPermission(t *testing.T) {
@@ -256,6 +315,8 @@ func TestParseFieldWithPermission(t *testing.T) {
 		{Name: "Name6", DBName: "name6", BindNames: []string{"Name6"}, DataType: schema.String, Tag: `gorm:"<-:create,update"`, Creatable: true, Updatable: true, Readable: true},
 		{Name: "Name7", DBName: "name7", BindNames: []string{"Name7"}, DataType: schema.String, Tag: `gorm:"->:false;<-:create,update"`, Creatable: true, Updatable: true, Readable: false},
 		{Name: "Name8", DBName: "name8", BindNames: []string{"Name8"}, DataType: schema.String, Tag: `gorm:"->;-:migration"`, Creatable: false, Updatable: false, Readable: true, IgnoreMigration: true},
+		{Name: "Name9", DBName: "name9", BindNames: []string{"Name9"}, DataType: schema.String, Tag: `gorm:"<-:create;->"`, Creatable: true, Updatable: false, Readable: true},
+		{Name: "Name10", DBName: "name10", BindNames: []string{"Name10"}, DataType: schema.String, Tag: `gorm:"immutable"`, Creatable: true, Updatable: false, Readable: true, Immutable: true},
 	}
 
 	for _, f := range fields {
@@ -263,6 +324,62 @@ func TestParseFieldWithPermission(t *testing.T) {
 	}
 }
 
+func TestParseFieldWithCollation(t *testing.T) {
+	type CollatedUser struct {
+		ID    uint
+		Name  string `gorm:"charset:utf8mb4;collate:utf8mb4_unicode_ci"`
+		Email string
+	}
+
+	user, err := schema.Parse(&CollatedUser{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse user with collation, got error %v", err)
+	}
+
+	if field := user.LookUpField("Name"); field.Charset != "utf8mb4" || field.Collation != "utf8mb4_unicode_ci" {
+		t.Errorf("failed to parse charset and collation, got %v, %v", field.Charset, field.Collation)
+	}
+
+	if field := user.LookUpField("Email"); field.Charset != "" || field.Collation != "" {
+		t.Errorf("charset and collation should be empty, got %v, %v", field.Charset, field.Collation)
+	}
+}
+
+func TestParseGeneratedField(t *testing.T) {
+	type GeneratedUser struct {
+		ID        uint
+		FirstName string
+		LastName  string
+		FullName  string `gorm:"->;generated:concat(first_name,' ',last_name);stored"`
+		Initials  string `gorm:"generated:upper(first_name)"`
+	}
+
+	user, err := schema.Parse(&GeneratedUser{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse user with generated fields, got error %v", err)
+	}
+
+	fullName := user.LookUpField("FullName")
+	if fullName.Generated != "concat(first_name,' ',last_name)" || !fullName.GeneratedStored {
+		t.Errorf("failed to parse generated field, got %v, stored %v", fullName.Generated, fullName.GeneratedStored)
+	}
+
+	initials := user.LookUpField("Initials")
+	if initials.Generated != "upper(first_name)" || initials.GeneratedStored {
+		t.Errorf("failed to parse virtual generated field, got %v, stored %v", initials.Generated, initials.GeneratedStored)
+	}
+
+	for _, field := range []*schema.Field{fullName, initials} {
+		if field.Creatable || field.Updatable || !field.Readable || field.HasDefaultValue {
+			t.Errorf("generated field %v should be read only without default value, got %+v", field.Name, field)
+		}
+	}
+
+	if firstName := user.LookUpField("FirstName"); firstName.Generated != "" || !firstName.Creatable {
+		t.Errorf("normal field should not be generated, got %+v", firstName)
+	}
+}
+
 type (
 	ID      int64
 	INT     int
@@ -333,3 +450,303 @@ func TestTypeAliasField(t *testing.T) {
 		checkSchemaField(t, alias, f, func(f *schema.Field) {})
 	}
 }
+
+func TestParseFieldTimePrecision(t *testing.T) {
+	type TimePrecisionUser struct {
+		ID        uint
+		Name      string
+		CreatedAt time.Time
+		UpdatedAt *time.Time `gorm:"precision:3"`
+		DeletedAt gorm.DeletedAt
+		LoginAt   time.Time `gorm:"type:datetime(0)"`
+		LogoutAt  time.Time `gorm:"precision:9"`
+	}
+
+	user, err := schema.Parse(&TimePrecisionUser{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse user with time fields, got error %v", err)
+	}
+
+	expects := map[string]time.Duration{
+		"Name":      0,
+		"CreatedAt": time.Microsecond,
+		"UpdatedAt": time.Millisecond,
+		"DeletedAt": time.Microsecond,
+		"LoginAt":   time.Second,
+		"LogoutAt":  time.Nanosecond,
+	}
+
+	for name, precision := range expects {
+		if field := user.LookUpField(name); field.TimePrecision != precision {
+			t.Errorf("time precision of field %v should be %v, got %v", name, precision, field.TimePrecision)
+		}
+	}
+}
+
+func TestParseFieldDurationUnit(t *testing.T) {
+	type DurationUser struct {
+		ID       uint
+		Timeout  time.Duration
+		Interval time.Duration  `gorm:"durationUnit:s"`
+		Delay    *time.Duration `gorm:"durationUnit:ms"`
+		Period   time.Duration  `gorm:"serializer:duration"`
+	}
+
+	user, err := schema.Parse(&DurationUser{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse user with duration fields, got error %v", err)
+	}
+
+	expects := map[string]struct {
+		unit     time.Duration
+		dataType schema.DataType
+	}{
+		"Timeout":  {0, schema.Int},
+		"Interval": {time.Second, schema.Int},
+		"Delay":    {time.Millisecond, schema.Int},
+		"Period":   {0, schema.String},
+	}
+
+	for name, expect := range expects {
+		if field := user.LookUpField(name); field.DurationUnit != expect.unit || field.DataType != expect.dataType {
+			t.Errorf("field %v should have duration unit %v, data type %v, got %v, %v", name, expect.unit, expect.dataType, field.DurationUnit, field.DataType)
+		}
+	}
+
+	delay := 1500 * time.Millisecond
+	value := reflect.ValueOf(&DurationUser{Timeout: time.Minute, Interval: 90 * time.Minute, Delay: &delay})
+	for name, expect := range map[string]interface{}{"Timeout": time.Minute, "Interval": int64(5400), "Delay": int64(1500)} {
+		if v, _ := user.LookUpField(name).ValueOf(context.Background(), value); v != expect {
+			t.Errorf("value of field %v should be %#v, got %#v", name, expect, v)
+		}
+	}
+
+	interval := 2 * time.Hour
+	for _, v := range []interface{}{int64(3600), "3600", "1h", 3600.0, time.Hour, &interval} {
+		expect := time.Hour
+		if v == &interval {
+			expect = interval
+		}
+
+		if err := user.LookUpField("Interval").Set(context.Background(), value, v); err != nil {
+			t.Fatalf("failed to set %#v to duration field, got error %v", v, err)
+		}
+
+		if got := value.Elem().FieldByName("Interval").Interface(); got != expect {
+			t.Errorf("should set %#v as %v, got %v", v, expect, got)
+		}
+	}
+
+	if _, err := schema.Parse(&struct {
+		ID      uint
+		Timeout time.Duration `gorm:"durationUnit:minute"`
+	}{}, &sync.Map{}, schema.NamingStrategy{}); err == nil {
+		t.Errorf("should return error for invalid duration unit")
+	}
+}
+
+func TestParseFieldDefaultValueFunc(t *testing.T) {
+	schema.RegisterDefaultValuer("schema_test_uuid", func(ctx context.Context) interface{} {
+		return "00000000-0000-0000-0000-000000000000"
+	})
+
+	type DefaultFuncUser struct {
+		ID   uint   `gorm:"default:fn:schema_test_uuid"`
+		UUID string `gorm:"default:fn:SCHEMA_TEST_UUID"`
+		Name string `gorm:"default:guest"`
+	}
+
+	user, err := schema.Parse(&DefaultFuncUser{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse user with default value func, got error %v", err)
+	}
+
+	for _, name := range []string{"ID", "UUID"} {
+		field := user.LookUpField(name)
+		if field.DefaultValueFunc == nil || field.HasDefaultValue || field.AutoIncrement || field.DefaultValue != "" {
+			t.Errorf("field %v should have default value func only, got %+v", name, field)
+		}
+	}
+
+	if len(user.FieldsWithDefaultDBValue) != 0 {
+		t.Errorf("fields with default value func should be filled by application, got %+v", user.FieldsWithDefaultDBValue)
+	}
+
+	if _, err := schema.Parse(&struct {
+		ID   uint
+		UUID string `gorm:"default:fn:unknown_valuer"`
+	}{}, &sync.Map{}, schema.NamingStrategy{}); err == nil {
+		t.Errorf("should return error for unknown default valuer")
+	}
+}
+
+type registeredPoint struct {
+	x, y int
+}
+
+func TestParseFieldRegisteredDataType(t *testing.T) {
+	schema.RegisterDataType(reflect.TypeOf(registeredPoint{}), schema.DataTypeSpec{
+		Bind: func(v interface{}) (driver.Value, error) {
+			p := v.(registeredPoint)
+			return fmt.Sprintf("%d,%d", p.x, p.y), nil
+		},
+		Scan: func(dst reflect.Value, src interface{}) error {
+			if b, ok := src.([]byte); ok {
+				src = string(b)
+			}
+
+			var p registeredPoint
+			if _, err := fmt.Sscanf(src.(string), "%d,%d", &p.x, &p.y); err != nil {
+				return err
+			}
+			dst.Set(reflect.ValueOf(p))
+			return nil
+		},
+	})
+
+	type Place struct {
+		ID       uint
+		Location registeredPoint
+		Entrance *registeredPoint
+	}
+
+	place, err := schema.Parse(&Place{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse place with registered data type, got error %v", err)
+	}
+
+	for _, name := range []string{"Location", "Entrance"} {
+		field := place.LookUpField(name)
+		if field == nil || field.DataType != schema.String || field.DataTypeSpec == nil {
+			t.Fatalf("field %v should be parsed as registered data type, got %+v", name, field)
+		}
+	}
+
+	ctx := context.Background()
+	value := reflect.ValueOf(&Place{})
+	location, entrance := place.LookUpField("Location"), place.LookUpField("Entrance")
+
+	if err := location.Set(ctx, value, "1,2"); err != nil {
+		t.Fatalf("failed to set location, got error %v", err)
+	}
+	if err := entrance.Set(ctx, value, []byte("3,4")); err != nil {
+		t.Fatalf("failed to set entrance, got error %v", err)
+	}
+
+	if v, _ := location.ValueOf(ctx, value); v != "1,2" {
+		t.Errorf("location should be bound to 1,2, got %#v", v)
+	}
+	if v, _ := entrance.ValueOf(ctx, value); v != "3,4" {
+		t.Errorf("entrance should be bound to 3,4, got %#v", v)
+	}
+
+	if err := entrance.Set(ctx, value, nil); err != nil {
+		t.Fatalf("failed to set entrance to nil, got error %v", err)
+	}
+	if v, _ := entrance.ValueOf(ctx, value); v != nil {
+		t.Errorf("nil entrance should be bound to nil, got %#v", v)
+	}
+
+	if err := location.Set(ctx, value, registeredPoint{x: 5, y: 6}); err != nil {
+		t.Fatalf("failed to set location with registered data type, got error %v", err)
+	}
+	if v, _ := location.ValueOf(ctx, value); v != "5,6" {
+		t.Errorf("location should be bound to 5,6, got %#v", v)
+	}
+}
+
+func TestParseFieldSelectExpr(t *testing.T) {
+	type SelectExprItem struct {
+		ID       uint
+		Price    int
+		Quantity int
+		Total    int `gorm:"->;selectExpr:price * quantity"`
+	}
+
+	item, err := schema.Parse(&SelectExprItem{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse item with select expression, got error %v", err)
+	}
+
+	total := item.LookUpField("Total")
+	if total.SelectExpr != "price * quantity" || total.Creatable || total.Updatable || !total.Readable || !total.IgnoreMigration {
+		t.Errorf("select expression field should be read only and ignored by migration, got %+v", total)
+	}
+
+	if price := item.LookUpField("Price"); price.SelectExpr != "" || price.IgnoreMigration {
+		t.Errorf("normal field shouldn't have select expression, got %+v", price)
+	}
+}
+
+func TestParseFieldDefaultNow(t *testing.T) {
+	type DefaultNowEvent struct {
+		ID          uint
+		CreatedOn   time.Time  `gorm:"default:now()"`
+		UpdatedOn   *time.Time `gorm:"default:CURRENT_TIMESTAMP(3)"`
+		ReceivedOn  time.Time  `gorm:"default:getdate()"`
+		FixedOn     time.Time  `gorm:"default:2000-01-01 00:00:00"`
+		CustomOn    time.Time  `gorm:"default:date_trunc('day', now())"`
+		Name        string     `gorm:"default:now()"`
+		Description string
+	}
+
+	event, err := schema.Parse(&DefaultNowEvent{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse event, got error %v", err)
+	}
+
+	expects := map[string]bool{
+		"CreatedOn": true, "UpdatedOn": true, "ReceivedOn": true,
+		"FixedOn": false, "CustomOn": false, "Name": false, "Description": false,
+	}
+	for name, defaultNow := range expects {
+		if field := event.LookUpField(name); field.DefaultNow != defaultNow {
+			t.Errorf("DefaultNow of field %v should be %v, got %v", name, defaultNow, field.DefaultNow)
+		}
+	}
+}
+
+type DBTimeEvent struct {
+	ID        uint
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Unix      int64 `gorm:"autoUpdateTime"`
+}
+
+func (DBTimeEvent) UseDBTime() bool {
+	return true
+}
+
+func TestParseFieldDBTime(t *testing.T) {
+	type DBTimeTagEvent struct {
+		ID        uint
+		CreatedAt time.Time `gorm:"autoCreateTime:db"`
+		UpdatedAt time.Time
+		SyncedAt  time.Time `gorm:"autoUpdateTime:DB"`
+	}
+
+	tagEvent, err := schema.Parse(&DBTimeTagEvent{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse event, got error %v", err)
+	}
+
+	event, err := schema.Parse(&DBTimeEvent{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse event, got error %v", err)
+	}
+
+	for s, expects := range map[*schema.Schema]map[string]bool{
+		tagEvent: {"CreatedAt": true, "UpdatedAt": false, "SyncedAt": true},
+		event:    {"CreatedAt": true, "UpdatedAt": true, "Unix": false},
+	} {
+		for name, dbTime := range expects {
+			if field := s.LookUpField(name); field.DBTime != dbTime {
+				t.Errorf("DBTime of %v's field %v should be %v, got %v", s.Name, name, dbTime, field.DBTime)
+			}
+		}
+	}
+
+	if field := tagEvent.LookUpField("SyncedAt"); field.AutoUpdateTime != schema.UnixTime {
+		t.Errorf("SyncedAt should be auto update time, got %v", field.AutoUpdateTime)
+	}
+}
@@ -13,7 +13,8 @@ type Index struct {
 	Type    string // btree, hash, gist, spgist, gin, and brin
 	Where   string
 	Comment string
-	Option  string // WITH PARSER parser_name
+	Option  string   // WITH PARSER parser_name
+	Include []string // INCLUDE columns of covering index
 	Fields  []IndexOption
 }
 
@@ -28,14 +29,36 @@ type IndexOption struct {
 
 // ParseIndexes parse schema indexes
 func (schema *Schema) ParseIndexes() map[string]Index {
+	indexes, err := schema.parseIndexes()
+	if err != nil {
+		schema.err = err
+	}
+	for _, index := range indexes {
+		// 表达式索引的唯一性不代表字段本身唯一
+		// 带 where 条件的部分唯一索引不保证字段唯一
+		if index.Class == "UNIQUE" && index.Where == "" && len(index.Fields) == 1 && index.Fields[0].Expression == "" {
+			index.Fields[0].Field.Unique = true
+		}
+	}
+	return indexes
+}
+
+// Indexes parse schema indexes like ParseIndexes without marking fields of single field unique indexes as unique,
+// used when the schema is shared, e.g. at query time
+func (schema *Schema) Indexes() map[string]Index {
+	indexes, _ := schema.parseIndexes()
+	return indexes
+}
+
+// parseIndexes parse schema indexes without changing fields
+func (schema *Schema) parseIndexes() (map[string]Index, error) {
 	indexes := map[string]Index{}
 
 	for _, field := range schema.Fields {
 		if field.TagSettings["INDEX"] != "" || field.TagSettings["UNIQUEINDEX"] != "" {
 			fieldIndexes, err := parseFieldIndexes(field)
 			if err != nil {
-				schema.err = err
-				break
+				return indexes, err
 			}
 			for _, index := range fieldIndexes {
 				idx := indexes[index.Name]
@@ -55,9 +78,12 @@ func (schema *Schema) ParseIndexes() map[string]Index {
 				if idx.Option == "" {
 					idx.Option = index.Option
 				}
+				if len(idx.Include) == 0 {
+					idx.Include = index.Include
+				}
 
 				idx.Fields = append(idx.Fields, index.Fields...)
-				sort.Slice(idx.Fields, func(i, j int) bool {
+				sort.SliceStable(idx.Fields, func(i, j int) bool {
 					return idx.Fields[i].priority < idx.Fields[j].priority
 				})
 
@@ -65,12 +91,53 @@ func (schema *Schema) ParseIndexes() map[string]Index {
 			}
 		}
 	}
+	return indexes, nil
+}
+
+// parseUniqueFields returns unique fields and fields of composite unique indexes, sorted by index name
+func (schema *Schema) parseUniqueFields() (uniqueFields [][]*Field) {
+	// 索引解析出错时只使用 unique 注解的字段，错误在迁移时由 ParseIndexes 报告
+	indexes, err := schema.parseIndexes()
+	if err != nil {
+		indexes = nil
+	}
+
+	singles := map[*Field]bool{}
 	for _, index := range indexes {
-		if index.Class == "UNIQUE" && len(index.Fields) == 1 {
-			index.Fields[0].Field.Unique = true
+		// 带 where 条件的部分唯一索引不保证字段唯一
+		if index.Class == "UNIQUE" && index.Where == "" && len(index.Fields) == 1 && index.Fields[0].Expression == "" {
+			singles[index.Fields[0].Field] = true
 		}
 	}
-	return indexes
+	for _, field := range schema.Fields {
+		if (field.Unique || singles[field]) && !field.PrimaryKey && field.DBName != "" {
+			uniqueFields = append(uniqueFields, []*Field{field})
+		}
+	}
+
+	indexNames := make([]string, 0, len(indexes))
+	for name := range indexes {
+		indexNames = append(indexNames, name)
+	}
+	sort.Strings(indexNames)
+
+	for _, name := range indexNames {
+		if index := indexes[name]; index.Class == "UNIQUE" && index.Where == "" && len(index.Fields) > 1 {
+			fields := make([]*Field, 0, len(index.Fields))
+			for _, option := range index.Fields {
+				if option.Expression != "" {
+					fields = nil
+					break
+				}
+				fields = append(fields, option.Field)
+			}
+
+			if len(fields) > 0 {
+				uniqueFields = append(uniqueFields, fields)
+			}
+		}
+	}
+	return uniqueFields
 }
 
 func (schema *Schema) LookIndex(name string) *Index {
@@ -93,27 +160,26 @@ func (schema *Schema) LookIndex(name string) *Index {
 }
 
 func parseFieldIndexes(field *Field) (indexes []Index, err error) {
-	for _, value := range strings.Split(field.Tag.Get("gorm"), ";") {
+	for _, value := range splitTagSetting(field.Tag.Get("gorm"), ";") {
 		if value != "" {
 			v := strings.Split(value, ":")
 			k := strings.TrimSpace(strings.ToUpper(v[0]))
 			if k == "INDEX" || k == "UNIQUEINDEX" {
 				var (
-					name       string
-					tag        = strings.Join(v[1:], ":")
-					idx        = strings.Index(tag, ",")
-					tagSetting = strings.Join(strings.Split(tag, ",")[1:], ",")
-					settings   = ParseTagSetting(tagSetting, ",")
-					length, _  = strconv.Atoi(settings["LENGTH"])
+					tags     = splitIndexTag(strings.Join(v[1:], ":"))
+					name     = tags[0]
+					settings = map[string]string{}
 				)
 
-				if idx == -1 {
-					idx = len(tag)
-				}
-
-				if idx != -1 {
-					name = tag[0:idx]
+				for _, setting := range tags[1:] {
+					values := strings.Split(setting, ":")
+					if k := strings.TrimSpace(strings.ToUpper(values[0])); len(values) >= 2 {
+						settings[k] = strings.Join(values[1:], ":")
+					} else if k != "" {
+						settings[k] = k
+					}
 				}
+				length, _ := strconv.Atoi(settings["LENGTH"])
 
 				if name == "" {
 					subName := field.Name
@@ -141,6 +207,15 @@ func parseFieldIndexes(field *Field) (indexes []Index, err error) {
 					priority = 10
 				}
 
+				var include []string
+				if settings["INCLUDE"] != "" {
+					for _, column := range strings.Split(settings["INCLUDE"], ";") {
+						if column = strings.TrimSpace(column); column != "" {
+							include = append(include, column)
+						}
+					}
+				}
+
 				indexes = append(indexes, Index{
 					Name:    name,
 					Class:   settings["CLASS"],
@@ -148,6 +223,7 @@ func parseFieldIndexes(field *Field) (indexes []Index, err error) {
 					Where:   settings["WHERE"],
 					Comment: settings["COMMENT"],
 					Option:  settings["OPTION"],
+					Include: include,
 					Fields: []IndexOption{{
 						Field:      field,
 						Expression: settings["EXPRESSION"],
@@ -164,3 +240,31 @@ func parseFieldIndexes(field *Field) (indexes []Index, err error) {
 	err = nil
 	return
 }
+
+// splitIndexTag split index tag with comma, commas inside parentheses like `expression:substr(name,1,3)` are kept,
+// the escaped comma `\,` is kept as comma
+func splitIndexTag(tag string) (results []string) {
+	var (
+		depth int
+		value strings.Builder
+	)
+
+	for i := 0; i < len(tag); i++ {
+		switch c := tag[i]; {
+		case c == '\\' && i+1 < len(tag) && tag[i+1] == ',':
+			value.WriteByte(',')
+			i++
+		case c == ',' && depth == 0:
+			results = append(results, value.String())
+			value.Reset()
+		default:
+			if c == '(' {
+				depth++
+			} else if c == ')' && depth > 0 {
+				depth--
+			}
+			value.WriteByte(c)
+		}
+	}
+	return append(results, value.String())
+}
@@ -19,6 +19,7 @@ type UserIndex struct {
 	OID          int64  `gorm:"index:idx_id;index:idx_oid,unique"`
 	MemberNumber string `gorm:"index:idx_id,priority:1"`
 	Name7        string `gorm:"index:type"`
+	Name8        string `gorm:"index:idx_name8,where:deleted_at IS NULL,include:name7\\;age,option:fillfactor=70"`
 
 	// Composite Index: Flattened structure.
 	Data0A string `gorm:"index:,composite:comp_id0"`
@@ -114,6 +115,13 @@ func TestParseIndex(t *testing.T) {
 			Type:   "",
 			Fields: []schema.IndexOption{{Field: &schema.Field{Name: "Name7"}}},
 		},
+		"idx_name8": {
+			Name:    "idx_name8",
+			Where:   "deleted_at IS NULL",
+			Option:  "fillfactor=70",
+			Include: []string{"name7", "age"},
+			Fields:  []schema.IndexOption{{Field: &schema.Field{Name: "Name8"}}},
+		},
 		"idx_user_indices_comp_id0": {
 			Name: "idx_user_indices_comp_id0",
 			Type: "",
@@ -163,6 +171,10 @@ func TestParseIndex(t *testing.T) {
 			}
 		}
 
+		if !reflect.DeepEqual(result.Include, v.Include) {
+			t.Errorf("index %v Include should equal, expects %v, got %v", k, result.Include, v.Include)
+		}
+
 		for idx, ef := range result.Fields {
 			rf := v.Fields[idx]
 			if rf.Field.Name != ef.Field.Name {
@@ -183,3 +195,96 @@ func TestParseIndex(t *testing.T) {
 		}
 	}
 }
+
+type UserExpressionIndex struct {
+	Name  string `gorm:"index:idx_expr_name,expression:substr(name\\,1\\,3);index:idx_expr_composite,expression:coalesce(name, '')"`
+	Email string `gorm:"uniqueIndex:idx_expr_email,expression:lower(email),sort:desc;index:idx_expr_composite"`
+	Age   int64  `gorm:"index:idx_expr_composite,expression:abs(age - 18)"`
+	Code  string `gorm:"index:idx_expr_code,expression:substr(code,1,3),collate:nocase"`
+}
+
+func TestParseExpressionIndex(t *testing.T) {
+	user, err := schema.Parse(&UserExpressionIndex{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse user index, got error %v", err)
+	}
+
+	indexes := user.ParseIndexes()
+
+	results := map[string][]string{
+		"idx_expr_name":      {"substr(name,1,3)"},
+		"idx_expr_email":     {"lower(email)"},
+		"idx_expr_composite": {"coalesce(name, '')", "", "abs(age - 18)"},
+		"idx_expr_code":      {"substr(code,1,3)"},
+	}
+
+	for name, expressions := range results {
+		idx, ok := indexes[name]
+		if !ok {
+			t.Fatalf("failed to found index %v from parsed indices %+v", name, indexes)
+		}
+
+		if len(idx.Fields) != len(expressions) {
+			t.Fatalf("index %v should have %v fields, got %v", name, len(expressions), len(idx.Fields))
+		}
+
+		for i, expression := range expressions {
+			if idx.Fields[i].Expression != expression {
+				t.Errorf("index %v field #%v's expression should be %v, got %v", name, i+1, expression, idx.Fields[i].Expression)
+			}
+		}
+	}
+
+	if fields := indexes["idx_expr_composite"].Fields; fields[0].Name != "Name" || fields[1].Name != "Email" || fields[2].Name != "Age" {
+		t.Errorf("composite index should keep declared order, got %v, %v, %v", fields[0].Name, fields[1].Name, fields[2].Name)
+	}
+
+	if idx := indexes["idx_expr_email"]; idx.Class != "UNIQUE" || idx.Fields[0].Sort != "desc" || idx.Fields[0].Unique {
+		t.Errorf("unique expression index should not mark the field unique, got %+v", idx)
+	}
+
+	if idx := indexes["idx_expr_code"]; idx.Fields[0].Collate != "nocase" {
+		t.Errorf("settings after expression should be parsed, got %+v", idx.Fields[0])
+	}
+}
+
+func TestParseUniqueFields(t *testing.T) {
+	user, err := schema.Parse(&UserIndex{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse user index, got error %v", err)
+	}
+
+	var uniqueFields [][]string
+	for _, fields := range user.UniqueFields {
+		names := make([]string, 0, len(fields))
+		for _, field := range fields {
+			names = append(names, field.Name)
+		}
+		uniqueFields = append(uniqueFields, names)
+	}
+
+	expected := [][]string{{"Name2"}, {"Name4"}, {"OID"}, {"Data2C", "Data2A", "Data2B"}}
+	if !reflect.DeepEqual(uniqueFields, expected) {
+		t.Errorf("expected unique fields %v, got %v", expected, uniqueFields)
+	}
+
+	// 解析 schema 不应修改字段的 Unique
+	if user.LookUpField("Name2").Unique {
+		t.Errorf("field Name2 should not be marked as unique when parsing schema")
+	}
+
+	type PartialUniqueIndex struct {
+		ID    uint
+		Email string `gorm:"uniqueIndex:idx_active_email,where:deleted_at IS NULL"`
+		Code  string `gorm:"uniqueIndex"`
+	}
+
+	partial, err := schema.Parse(&PartialUniqueIndex{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse partial unique index, got error %v", err)
+	}
+
+	if len(partial.UniqueFields) != 1 || len(partial.UniqueFields[0]) != 1 || partial.UniqueFields[0][0].Name != "Code" {
+		t.Errorf("partial unique index should not be unique fields, got %v", partial.UniqueFields)
+	}
+}
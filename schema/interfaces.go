@@ -9,6 +9,12 @@ type GormDataTypeInterface interface {
 	GormDataType() string
 }
 
+// ZeroChecker custom zero value checker, fields whose type implements it use GormIsZero to check whether the value is set,
+// types only having IsZero like time.Time keep using reflect's IsZero
+type ZeroChecker interface {
+	GormIsZero() bool
+}
+
 // FieldNewValuePool field new scan value pool
 type FieldNewValuePool interface {
 	Get() interface{}
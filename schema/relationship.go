@@ -41,6 +41,7 @@ type Relationship struct {
 	FieldSchema              *Schema
 	JoinTable                *Schema
 	foreignKeys, primaryKeys []string
+	guessFailures            []string // 猜测关联失败的原因，所有方式都失败时报告
 }
 
 type Polymorphic struct {
@@ -169,23 +170,34 @@ func (schema *Schema) setRelation(relation *Relationship) {
 //	  OwnerID   int
 //	  OwnerType string
 //	}
+//
+// the fields could be specified with `polymorphicTypeField`, `polymorphicIdField` (field names)
+// or `polymorphicTypeColumn`, `polymorphicIdColumn` (column names) for legacy tables, e.g:
+//
+//	type User struct {
+//	  Toys []Toy `gorm:"polymorphic:Owner;polymorphicIdField:OwnerRef;polymorphicTypeColumn:owner_kind"`
+//	}
+//
+//	type Toy struct {
+//	  OwnerRef  int
+//	  OwnerKind string
+//	}
 func (schema *Schema) buildPolymorphicRelation(relation *Relationship, field *Field, polymorphic string) {
-	relation.Polymorphic = &Polymorphic{
-		Value:           schema.Table,
-		PolymorphicType: relation.FieldSchema.FieldsByName[polymorphic+"Type"],
-		PolymorphicID:   relation.FieldSchema.FieldsByName[polymorphic+"ID"],
-	}
+	var typeTried, idTried []string
+	relation.Polymorphic = &Polymorphic{Value: schema.Table}
+	relation.Polymorphic.PolymorphicType, typeTried = relation.FieldSchema.lookUpPolymorphicField(field, "POLYMORPHICTYPEFIELD", "POLYMORPHICTYPECOLUMN", polymorphic+"Type")
+	relation.Polymorphic.PolymorphicID, idTried = relation.FieldSchema.lookUpPolymorphicField(field, "POLYMORPHICIDFIELD", "POLYMORPHICIDCOLUMN", polymorphic+"ID")
 
 	if value, ok := field.TagSettings["POLYMORPHICVALUE"]; ok {
 		relation.Polymorphic.Value = strings.TrimSpace(value)
 	}
 
 	if relation.Polymorphic.PolymorphicType == nil {
-		schema.err = fmt.Errorf("invalid polymorphic type %v for %v on field %s, missing field %s", relation.FieldSchema, schema, field.Name, polymorphic+"Type")
+		schema.err = fmt.Errorf("invalid polymorphic type %v for %v on field %s, missing %s", relation.FieldSchema, schema, field.Name, strings.Join(typeTried, " or "))
 	}
 
 	if relation.Polymorphic.PolymorphicID == nil {
-		schema.err = fmt.Errorf("invalid polymorphic type %v for %v on field %s, missing field %s", relation.FieldSchema, schema, field.Name, polymorphic+"ID")
+		schema.err = fmt.Errorf("invalid polymorphic type %v for %v on field %s, missing %s", relation.FieldSchema, schema, field.Name, strings.Join(idTried, " or "))
 	}
 
 	if schema.err == nil {
@@ -225,6 +237,29 @@ func (schema *Schema) buildPolymorphicRelation(relation *Relationship, field *Fi
 	relation.Type = has
 }
 
+// lookUpPolymorphicField 查找多态关联的字段，优先使用标签指定的字段名、列名，否则使用默认的字段名，返回尝试过的名称
+func (schema *Schema) lookUpPolymorphicField(field *Field, fieldKey, columnKey, defaultName string) (*Field, []string) {
+	var tried []string
+	if name := strings.TrimSpace(field.TagSettings[fieldKey]); name != "" {
+		tried = append(tried, "field "+name)
+		if f := schema.FieldsByName[name]; f != nil {
+			return f, tried
+		}
+	}
+
+	if name := strings.TrimSpace(field.TagSettings[columnKey]); name != "" {
+		tried = append(tried, "column "+name)
+		if f := schema.FieldsByDBName[name]; f != nil {
+			return f, tried
+		}
+	}
+
+	if len(tried) > 0 {
+		return nil, tried
+	}
+	return schema.FieldsByName[defaultName], []string{"field " + defaultName}
+}
+
 func (schema *Schema) buildMany2ManyRelation(relation *Relationship, field *Field, many2many string) {
 	relation.Type = Many2Many
 
@@ -436,10 +471,20 @@ func (schema *Schema) guessRelation(relation *Relationship, field *Field, cgl gu
 			schema.guessRelation(relation, field, guessEmbeddedHas)
 		// case guessEmbeddedHas:
 		default:
-			schema.err = fmt.Errorf("invalid field found for struct %v's field %s: define a valid foreign key for relations or implement the Valuer/Scanner interface", schema, field.Name)
+			schema.err = fmt.Errorf("invalid field found for struct %v's field %s: define a valid foreign key for relations or implement the Valuer/Scanner interface, "+
+				"use a serializer like `gorm:\"serializer:json\"` to store %v as a column, or ignore it with `gorm:\"-\"`", schema, field.Name, field.FieldType)
+			if len(relation.guessFailures) > 0 {
+				schema.err = fmt.Errorf("%w, tried: %s", schema.err, strings.Join(relation.guessFailures, "; "))
+			}
 		}
 	}
 
+	if len(relation.foreignKeys) > 0 && len(relation.primaryKeys) > 0 && len(relation.foreignKeys) != len(relation.primaryKeys) {
+		schema.err = fmt.Errorf("invalid relation for struct %v's field %s: foreign keys %v and references %v should have the same length",
+			schema, field.Name, relation.foreignKeys, relation.primaryKeys)
+		return
+	}
+
 	switch gl {
 	case guessBelongs:
 		primarySchema, foreignSchema = relation.FieldSchema, schema
@@ -462,6 +507,7 @@ func (schema *Schema) guessRelation(relation *Relationship, field *Field, cgl gu
 		for _, foreignKey := range relation.foreignKeys {
 			f := foreignSchema.LookUpField(foreignKey)
 			if f == nil {
+				relation.guessFailures = append(relation.guessFailures, fmt.Sprintf("foreign key %s not found in %v", foreignKey, foreignSchema))
 				reguessOrErr()
 				return
 			}
@@ -483,6 +529,8 @@ func (schema *Schema) guessRelation(relation *Relationship, field *Field, cgl gu
 			primaryFields = primarySchema.PrimaryFields
 		}
 
+		// 按声明的顺序记录找到外键的主键
+		var matchedPrimaryFields []*Field
 	primaryFieldLoop:
 		for _, primaryField := range primaryFields {
 			lookUpName := primarySchemaName + primaryField.Name
@@ -498,18 +546,25 @@ func (schema *Schema) guessRelation(relation *Relationship, field *Field, cgl gu
 			for _, name := range lookUpNames {
 				if f := foreignSchema.LookUpFieldByBindName(field.BindNames, name); f != nil {
 					foreignFields = append(foreignFields, f)
-					primaryFields = append(primaryFields, primaryField)
+					matchedPrimaryFields = append(matchedPrimaryFields, primaryField)
 					continue primaryFieldLoop
 				}
 			}
 			for _, name := range lookUpNames {
 				if f := foreignSchema.LookUpField(name); f != nil {
 					foreignFields = append(foreignFields, f)
-					primaryFields = append(primaryFields, primaryField)
+					matchedPrimaryFields = append(matchedPrimaryFields, primaryField)
 					continue primaryFieldLoop
 				}
 			}
+
+			if len(relation.primaryKeys) > 0 {
+				relation.guessFailures = append(relation.guessFailures, fmt.Sprintf("foreign key of reference %s not found in %v", primaryField.Name, foreignSchema))
+				reguessOrErr()
+				return
+			}
 		}
+		primaryFields = matchedPrimaryFields
 	}
 
 	switch {
@@ -526,6 +581,7 @@ func (schema *Schema) guessRelation(relation *Relationship, field *Field, cgl gu
 					return
 				}
 			} else {
+				relation.guessFailures = append(relation.guessFailures, fmt.Sprintf("reference %s not found in %v", primaryKey, primarySchema))
 				reguessOrErr()
 				return
 			}
@@ -541,7 +597,25 @@ func (schema *Schema) guessRelation(relation *Relationship, field *Field, cgl gu
 		}
 	}
 
-	// build references
+	if len(primaryFields) < len(foreignFields) {
+		relation.guessFailures = append(relation.guessFailures, fmt.Sprintf("references of foreign keys %v not found in %v", relation.foreignKeys, primarySchema))
+		reguessOrErr()
+		return
+	}
+
+	// 指定了多列的外键或引用时，检查每一对的类型是否兼容，避免顺序不一致，单列的外键使用引用的类型
+	if (len(relation.foreignKeys) > 0 || len(relation.primaryKeys) > 0) && len(foreignFields) > 1 {
+		for idx, foreignField := range foreignFields {
+			if !compatibleDataType(foreignField.GORMDataType, primaryFields[idx].GORMDataType) {
+				relation.guessFailures = append(relation.guessFailures, fmt.Sprintf("foreign key %v.%s (%s) isn't compatible with reference %v.%s (%s)",
+					foreignSchema, foreignField.Name, foreignField.GORMDataType, primarySchema, primaryFields[idx].Name, primaryFields[idx].GORMDataType))
+				reguessOrErr()
+				return
+			}
+		}
+	}
+
+	// build references, the pairs of foreign keys and references are in declared order
 	for idx, foreignField := range foreignFields {
 		// use same data type for foreign keys
 		if copyableDataType(primaryFields[idx].DataType) {
@@ -566,6 +640,20 @@ func (schema *Schema) guessRelation(relation *Relationship, field *Field, cgl gu
 	}
 }
 
+// compatibleDataType whether values of the data types could be compared, custom data types are compatible with any types
+func compatibleDataType(a, b DataType) bool {
+	class := func(dataType DataType) DataType {
+		switch dataType {
+		case Int, Uint, Float:
+			return Int
+		case Bool, String, Time, Bytes:
+			return dataType
+		}
+		return ""
+	}
+	return class(a) == "" || class(b) == "" || class(a) == class(b)
+}
+
 type Constraint struct {
 	Name            string
 	Field           *Field
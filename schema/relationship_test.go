@@ -1,6 +1,7 @@
 package schema_test
 
 import (
+	"strings"
 	"sync"
 	"testing"
 
@@ -355,6 +356,38 @@ func TestMany2ManyOverrideJoinForeignKey(t *testing.T) {
 	})
 }
 
+func TestMany2ManySelfReferentialJoinKeys(t *testing.T) {
+	type User struct {
+		gorm.Model
+		Following []User `gorm:"many2many:follows;joinForeignKey:UserID;joinReferences:FollowingID"`
+		Followers []User `gorm:"many2many:follows;joinForeignKey:FollowingID;joinReferences:UserID"`
+		Friends   []User `gorm:"many2many:user_friends;joinForeignKey:UserID"`
+	}
+
+	checkStructRelation(t, &User{}, Relation{
+		Name: "Following", Type: schema.Many2Many, Schema: "User", FieldSchema: "User",
+		JoinTable: JoinTable{Name: "follows", Table: "follows"},
+		References: []Reference{
+			{"ID", "User", "UserID", "follows", "", true},
+			{"ID", "User", "FollowingID", "follows", "", false},
+		},
+	}, Relation{
+		Name: "Followers", Type: schema.Many2Many, Schema: "User", FieldSchema: "User",
+		JoinTable: JoinTable{Name: "follows", Table: "follows"},
+		References: []Reference{
+			{"ID", "User", "FollowingID", "follows", "", true},
+			{"ID", "User", "UserID", "follows", "", false},
+		},
+	}, Relation{
+		Name: "Friends", Type: schema.Many2Many, Schema: "User", FieldSchema: "User",
+		JoinTable: JoinTable{Name: "user_friends", Table: "user_friends"},
+		References: []Reference{
+			{"ID", "User", "UserID", "user_friends", "", true},
+			{"ID", "User", "FriendID", "user_friends", "", false},
+		},
+	})
+}
+
 func TestBuildReadonlyMany2ManyRelation(t *testing.T) {
 	type Profile struct {
 		gorm.Model
@@ -785,3 +818,84 @@ func TestParseConstraintNameWithSchemaQualifiedLongTableName(t *testing.T) {
 		)
 	}
 }
+
+func TestPolymorphicCustomFields(t *testing.T) {
+	type Toy struct {
+		ID        int
+		Name      string
+		OwnerRef  int
+		OwnerKind string `gorm:"column:owner_kind"`
+	}
+
+	type User struct {
+		ID   int
+		Toys []Toy `gorm:"polymorphic:Owner;polymorphicIdField:OwnerRef;polymorphicTypeColumn:owner_kind;polymorphicValue:user"`
+	}
+
+	checkStructRelation(t, &User{}, Relation{
+		Name: "Toys", Type: schema.HasMany, Schema: "User", FieldSchema: "Toy",
+		Polymorphic: Polymorphic{ID: "OwnerRef", Type: "OwnerKind", Value: "user"},
+		References:  []Reference{{"", "", "OwnerKind", "Toy", "user", false}, {"ID", "User", "OwnerRef", "Toy", "", true}},
+	})
+
+	type Pet struct {
+		ID   int
+		Toys []Toy `gorm:"polymorphicTypeField:Kind;polymorphicIdColumn:owner_id;polymorphic:Owner"`
+	}
+
+	_, err := schema.Parse(&Pet{}, &sync.Map{}, schema.NamingStrategy{})
+	if err == nil || !strings.Contains(err.Error(), "missing column owner_id") {
+		t.Errorf("should report the tried names of missing polymorphic fields, got %v", err)
+	}
+}
+
+func TestCompositeForeignKeys(t *testing.T) {
+	type Member struct {
+		ID        uint
+		OrgRegion string
+		OrgCode   int
+	}
+
+	type Org struct {
+		Region  string   `gorm:"primaryKey"`
+		Code    int      `gorm:"primaryKey"`
+		Members []Member `gorm:"foreignKey:OrgRegion,OrgCode;references:Region,Code"`
+	}
+
+	checkStructRelation(t, &Org{}, Relation{
+		Name: "Members", Type: schema.HasMany, Schema: "Org", FieldSchema: "Member",
+		References: []Reference{{"Region", "Org", "OrgRegion", "Member", "", true}, {"Code", "Org", "OrgCode", "Member", "", true}},
+	})
+
+	s, err := schema.Parse(&Org{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse schema, got error %v", err)
+	}
+
+	constraint := s.Relationships.Relations["Members"].ParseConstraint()
+	if len(constraint.ForeignKeys) != 2 || constraint.ForeignKeys[0].DBName != "org_region" || constraint.ForeignKeys[1].DBName != "org_code" ||
+		len(constraint.References) != 2 || constraint.References[0].DBName != "region" || constraint.References[1].DBName != "code" {
+		t.Errorf("constraint should have the columns in declared order, got %+v", constraint)
+	}
+
+	type MismatchedLengthOrg struct {
+		Region  string   `gorm:"primaryKey"`
+		Code    int      `gorm:"primaryKey"`
+		Members []Member `gorm:"foreignKey:OrgRegion,OrgCode;references:Region"`
+	}
+
+	if _, err := schema.Parse(&MismatchedLengthOrg{}, &sync.Map{}, schema.NamingStrategy{}); err == nil || !strings.Contains(err.Error(), "should have the same length") {
+		t.Errorf("should report foreign keys and references of different lengths, got %v", err)
+	}
+
+	type SwappedOrg struct {
+		Region  string   `gorm:"primaryKey"`
+		Code    int      `gorm:"primaryKey"`
+		Members []Member `gorm:"foreignKey:OrgRegion,OrgCode;references:Code,Region"`
+	}
+
+	if _, err := schema.Parse(&SwappedOrg{}, &sync.Map{}, schema.NamingStrategy{}); err == nil ||
+		!strings.Contains(err.Error(), "OrgRegion (string) isn't compatible with reference") || !strings.Contains(err.Error(), "Code (int)") {
+		t.Errorf("should report the incompatible pair, got %v", err)
+	}
+}
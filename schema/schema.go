@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"go/ast"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 
@@ -20,6 +21,8 @@ type Schema struct {
 	Name                    string       // model 结构体的 Name
 	ModelType               reflect.Type // model 结构体的类型
 	Table                   string       // 该 schema 结构体对应的 db 的表名
+	SchemaName              string       // 表所在的 schema（数据库），表名形如 analytics.events 或 model 实现 TablerWithSchema 接口时设置
+	Comment                 string       // 表注释，model 实现 TablerWithComment 接口指定
 	PrioritizedPrimaryField *Field
 	// 优先选择的主键字段 Field 定义，通过 private_key 注解指定，
 	// 或者通过唯一索引或者 auto_increment 注解指定
@@ -32,6 +35,8 @@ type Schema struct {
 	FieldsByDBName      map[string]*Field // db COLUMN 名到 Field 的映射
 	// 有默认值，但是 默认值包含函数 ( ), 或者是 null, ""
 	FieldsWithDefaultDBValue []*Field // fields with default value assigned by database
+	// 唯一字段以及复合唯一索引的字段，解析时计算，创建后用于回查数据库生成的主键
+	UniqueFields [][]*Field
 	// 保存表之间的关联关系
 	Relationships Relationships
 	// 可以在 Create Query Update Delete 的时候修改 sql 定义， model 实现 CreateClausesInterface 等接口
@@ -84,6 +89,35 @@ func (schema Schema) LookUpField(name string) *Field {
 	return nil
 }
 
+// ColumnInfo column metadata of a schema field
+type ColumnInfo struct {
+	Name       string // 结构体字段名
+	DBName     string
+	DataType   DataType
+	PrimaryKey bool
+	Nullable   bool
+	Comment    string
+	Size       int
+}
+
+// Columns returns metadata of the columns in DBNames order, including columns of embedded structs
+func (schema Schema) Columns() []ColumnInfo {
+	columns := make([]ColumnInfo, 0, len(schema.DBNames))
+	for _, dbName := range schema.DBNames {
+		field := schema.FieldsByDBName[dbName]
+		columns = append(columns, ColumnInfo{
+			Name:       field.Name,
+			DBName:     field.DBName,
+			DataType:   field.DataType,
+			PrimaryKey: field.PrimaryKey,
+			Nullable:   !field.NotNull && !field.PrimaryKey,
+			Comment:    field.Comment,
+			Size:       field.Size,
+		})
+	}
+	return columns
+}
+
 // LookUpFieldByBindName looks for the closest field in the embedded struct.
 //
 //	type Struct struct {
@@ -113,6 +147,44 @@ type TablerWithNamer interface {
 	TableName(Namer) string
 }
 
+// TablerWithMode different tables for reading and writing, e.g. querying the denormalized copy users_read while writing users,
+// mode is TableModeRead for query and row statements, TableModeWrite for create, update and delete statements,
+// the table is chosen when executing statements, the table of TableModeWrite is the table of the schema,
+// used by migrations, joins and associations
+type TablerWithMode interface {
+	TableName(mode string) string
+}
+
+// modes of TablerWithMode
+const (
+	TableModeRead  = "read"
+	TableModeWrite = "write"
+)
+
+// PartitionedTabler routes writes of the model to physical tables by the record, e.g. events_2024_01 for the events table partitioned by month,
+// base is the table of the schema, value is the struct value of the record, which is zero when deleting or updating without records,
+// create, update and delete statements use the returned table, records of batch creates and deletes are grouped by table
+type PartitionedTabler interface {
+	PartitionTable(base string, value reflect.Value) string
+}
+
+// TablerWithSchema schema (database) of the table, e.g. analytics for the table analytics.events,
+// it is ignored if the table name is already qualified or specified with Table
+type TablerWithSchema interface {
+	TableSchema() string
+}
+
+// DBTimeModel auto create and update time fields of the model use the current time of the database instead of NowFunc if UseDBTime returns true,
+// e.g. tables ordered by created time written by servers with clock skew, unix timestamp fields are not affected
+type DBTimeModel interface {
+	UseDBTime() bool
+}
+
+// TablerWithComment table comment used when creating table
+type TablerWithComment interface {
+	TableComment() string
+}
+
 // Parse get data type from dialector
 func Parse(dest interface{}, cacheStore *sync.Map, namer Namer) (*Schema, error) {
 	return ParseWithSpecialTableName(dest, cacheStore, namer, "")
@@ -170,12 +242,20 @@ func ParseWithSpecialTableName(dest interface{}, cacheStore *sync.Map, namer Nam
 	if tabler, ok := modelValue.Interface().(TablerWithNamer); ok {
 		tableName = tabler.TableName(namer) // 如果 model 结构体实现了 TablerWithNamer 接口，优先使用 TableName 方法指定的名字
 	}
+	if tabler, ok := modelValue.Interface().(TablerWithMode); ok {
+		tableName = tabler.TableName(TableModeWrite) // 读写分表时，schema 使用写入的表
+	}
 	if en, ok := namer.(embeddedNamer); ok {
 		tableName = en.Table // 如果这个结构体是一个嵌套结构体，使用所在结构体的 tableName
 	}
 	if specialTableName != "" && specialTableName != tableName {
 		tableName = specialTableName // 如果指定了 specialTableName，优先用指定的 specialTableName 作为 tableName
 	}
+	if tabler, ok := modelValue.Interface().(TablerWithSchema); ok && specialTableName == "" && !strings.Contains(tableName, ".") {
+		if tableSchema := tabler.TableSchema(); tableSchema != "" {
+			tableName = tableSchema + "." + tableName // 表名带上 schema
+		}
+	}
 
 	schema := &Schema{
 		Name:             modelType.Name(),
@@ -189,9 +269,17 @@ func ParseWithSpecialTableName(dest interface{}, cacheStore *sync.Map, namer Nam
 		namer:            namer,
 		initialized:      make(chan struct{}),
 	}
+	if tables := strings.Split(tableName, "."); len(tables) == 2 {
+		schema.SchemaName = tables[0]
+	}
+
 	// When the schema initialization is completed, the channel will be closed
 	defer close(schema.initialized) // 初始化完成，就关闭 channel
 
+	if commenter, ok := modelValue.Interface().(TablerWithComment); ok {
+		schema.Comment = commenter.TableComment()
+	}
+
 	// Load exist schema cache, return if exists
 	if v, ok := cacheStore.Load(schemaCacheKey); ok { // 再次检查，如果已经在缓存里面存在了，就等待初始化完成，然后返还结果
 		s := v.(*Schema)
@@ -258,6 +346,11 @@ func ParseWithSpecialTableName(dest interface{}, cacheStore *sync.Map, namer Nam
 		field.setupValuerAndSetter()
 	}
 
+	// 按 order 注解排列列的顺序，未指定的保持声明顺序
+	sort.SliceStable(schema.DBNames, func(i, j int) bool {
+		return schema.FieldsByDBName[schema.DBNames[i]].ColumnOrder < schema.FieldsByDBName[schema.DBNames[j]].ColumnOrder
+	})
+
 	// 如果有 db COLUMN 或者 结构体 名字叫 id 或者 ID 的字段，优先将其当做主键
 	prioritizedPrimaryField := schema.LookUpField("id")
 	if prioritizedPrimaryField == nil {
@@ -299,7 +392,8 @@ func ParseWithSpecialTableName(dest interface{}, cacheStore *sync.Map, namer Nam
 		}
 	}
 
-	if field := schema.PrioritizedPrimaryField; field != nil { // 如果有优先主键值
+	// 主键的默认值由函数生成时，不是自增主键
+	if field := schema.PrioritizedPrimaryField; field != nil && field.DefaultValueFunc == nil { // 如果有优先主键值
 		switch field.GORMDataType {
 		case Int, Uint: // 并且类型是 int uint
 			if _, ok := field.TagSettings["AUTOINCREMENT"]; !ok { // 并且不包含 AUTOINCREMENT 注解
@@ -315,6 +409,14 @@ func ParseWithSpecialTableName(dest interface{}, cacheStore *sync.Map, namer Nam
 		}
 	}
 
+	if dbTimeModel, ok := modelValue.Interface().(DBTimeModel); ok && dbTimeModel.UseDBTime() {
+		for _, field := range schema.Fields {
+			if field.AutoCreateTime == UnixTime || field.AutoUpdateTime == UnixTime {
+				field.DBTime = true
+			}
+		}
+	}
+
 	callbacks := []string{"BeforeCreate", "AfterCreate", "BeforeUpdate", "AfterUpdate", "BeforeSave", "AfterSave", "BeforeDelete", "AfterDelete", "AfterFind"}
 	for _, name := range callbacks {
 		if methodValue := modelValue.MethodByName(name); methodValue.IsValid() {
@@ -328,6 +430,8 @@ func ParseWithSpecialTableName(dest interface{}, cacheStore *sync.Map, namer Nam
 		}
 	}
 
+	schema.UniqueFields = schema.parseUniqueFields()
+
 	// Cache the schema
 	if v, loaded := cacheStore.LoadOrStore(schemaCacheKey, schema); loaded {
 		s := v.(*Schema) // 尝试缓存，如果是已经有了，等待其初始化完成
@@ -339,12 +443,18 @@ func ParseWithSpecialTableName(dest interface{}, cacheStore *sync.Map, namer Nam
 	defer func() {
 		if schema.err != nil {
 			logger.Default.Error(context.Background(), schema.err.Error())
-			cacheStore.Delete(modelType) // 如果初始化失败，删除缓存
+			cacheStore.Delete(schemaCacheKey) // 如果初始化失败，删除缓存
 		}
 	}()
 
-	if _, embedded := schema.cacheStore.Load(embeddedCacheKey); !embedded {
+	// 字段解析出错（如不支持的类型）时直接返回，避免被解析关联关系的错误覆盖
+	if _, embedded := schema.cacheStore.Load(embeddedCacheKey); !embedded && schema.err == nil {
 		// 如果当前的 schema 不是嵌套结构体的
+		if _, err := schema.parseCheckConstraints(); err != nil {
+			schema.err = err
+			return schema, schema.err
+		}
+
 		for _, field := range schema.Fields {
 			if field.DataType == "" && (field.Creatable || field.Updatable || field.Readable) {
 				// 如果 DataType 为空，解析关联关系
@@ -403,3 +513,51 @@ func getOrParse(dest interface{}, cacheStore *sync.Map, namer Namer) (*Schema, e
 
 	return Parse(dest, cacheStore, namer)
 }
+
+// FlushCache removes all parsed schemas from cacheStore, other values like prepared statements are kept,
+// models will be parsed again when using them
+func FlushCache(cacheStore *sync.Map) {
+	cacheStore.Range(func(key, value interface{}) bool {
+		if _, ok := value.(*Schema); ok { // 只删除 Schema，保留 embeddedCacheKey、预编译语句等其它缓存
+			cacheStore.Delete(key)
+		}
+		return true
+	})
+}
+
+// Evict removes model's parsed schemas from cacheStore, including schemas parsed with special table names,
+// in-flight parsing is not affected, it is still cached after finished
+func Evict(cacheStore *sync.Map, model interface{}) {
+	if model == nil {
+		return
+	}
+
+	value := reflect.ValueOf(model)
+	if value.Kind() == reflect.Ptr && value.IsNil() {
+		value = reflect.New(value.Type().Elem())
+	}
+	modelType := reflect.Indirect(value).Type()
+
+	if modelType.Kind() == reflect.Interface {
+		modelType = reflect.Indirect(reflect.ValueOf(model)).Elem().Type()
+	}
+
+	for modelType.Kind() == reflect.Slice || modelType.Kind() == reflect.Array || modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+
+	// 指定了表名的 schema 使用 type+表名 作为 key
+	specialTablePrefix := fmt.Sprintf("%p-", modelType)
+	cacheStore.Range(func(key, value interface{}) bool {
+		if _, ok := value.(*Schema); !ok {
+			return true
+		}
+
+		if key == modelType {
+			cacheStore.Delete(key)
+		} else if k, ok := key.(string); ok && strings.HasPrefix(k, specialTablePrefix) {
+			cacheStore.Delete(key)
+		}
+		return true
+	})
+}
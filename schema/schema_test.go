@@ -1,6 +1,7 @@
 package schema_test
 
 import (
+	"errors"
 	"strings"
 	"sync"
 	"testing"
@@ -162,6 +163,190 @@ func TestCustomizeTableName(t *testing.T) {
 	}
 }
 
+type CommentedTable struct {
+	ID uint
+}
+
+func (CommentedTable) TableComment() string {
+	return "commented table"
+}
+
+func TestTableComment(t *testing.T) {
+	commented, err := schema.Parse(&CommentedTable{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse commented table, got error %v", err)
+	}
+
+	if commented.Comment != "commented table" {
+		t.Errorf("failed to parse table comment with TableComment method, got %v", commented.Comment)
+	}
+}
+
+type SchemaQualifiedTable struct {
+	ID uint
+}
+
+func (SchemaQualifiedTable) TableName() string {
+	return "analytics.events"
+}
+
+type TableWithSchema struct {
+	ID uint
+}
+
+func (TableWithSchema) TableSchema() string {
+	return "analytics"
+}
+
+func TestTableSchema(t *testing.T) {
+	qualified, err := schema.Parse(&SchemaQualifiedTable{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse schema qualified table, got error %v", err)
+	}
+
+	if qualified.Table != "analytics.events" || qualified.SchemaName != "analytics" {
+		t.Errorf("failed to parse schema of table, got %v, %v", qualified.Table, qualified.SchemaName)
+	}
+
+	withSchema, err := schema.Parse(&TableWithSchema{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse table with schema, got error %v", err)
+	}
+
+	if withSchema.Table != "analytics.table_with_schemas" || withSchema.SchemaName != "analytics" {
+		t.Errorf("failed to parse schema with TableSchema method, got %v, %v", withSchema.Table, withSchema.SchemaName)
+	}
+
+	special, err := schema.ParseWithSpecialTableName(&TableWithSchema{}, &sync.Map{}, schema.NamingStrategy{}, "archived.visits")
+	if err != nil {
+		t.Fatalf("failed to parse table with special table name, got error %v", err)
+	}
+
+	if special.Table != "archived.visits" || special.SchemaName != "archived" {
+		t.Errorf("qualified special table name should not be changed, got %v, %v", special.Table, special.SchemaName)
+	}
+}
+
+type TableWithMode struct {
+	ID uint
+}
+
+func (TableWithMode) TableName(mode string) string {
+	return "users_" + mode
+}
+
+func TestTableWithMode(t *testing.T) {
+	s, err := schema.Parse(&TableWithMode{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse table with mode, got error %v", err)
+	}
+
+	if s.Table != "users_write" {
+		t.Errorf("schema should use the table of write mode, got %v", s.Table)
+	}
+}
+
+type OrderedAudit struct {
+	CreatedBy string
+	UpdatedBy string `gorm:"order:110"`
+}
+
+type OrderedColumns struct {
+	Name    string
+	Audit   OrderedAudit `gorm:"embedded;order:100"`
+	ID      uint         `gorm:"order:-1"`
+	Code    string
+	Notes   string `gorm:"order:100"`
+	Ignored string `gorm:"-;order:-10"`
+}
+
+func TestParseFieldOrder(t *testing.T) {
+	s, err := schema.Parse(&OrderedColumns{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse ordered columns, got error %v", err)
+	}
+
+	if expected := []string{"id", "name", "code", "created_by", "notes", "updated_by"}; strings.Join(s.DBNames, ",") != strings.Join(expected, ",") {
+		t.Errorf("columns should be ordered by order tag, expected %v, got %v", expected, s.DBNames)
+	}
+
+	if field := s.LookUpField("CreatedBy"); field.ColumnOrder != 100 {
+		t.Errorf("fields of embedded struct should inherit the order, got %v", field.ColumnOrder)
+	}
+}
+
+func TestEvictSchemaCache(t *testing.T) {
+	cacheStore := &sync.Map{}
+	cacheStore.Store("prepared_stmt", true)
+
+	user, err := schema.Parse(&tests.User{}, cacheStore, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse user, got error %v", err)
+	}
+
+	specialUser, err := schema.ParseWithSpecialTableName(&tests.User{}, cacheStore, schema.NamingStrategy{}, "special_users")
+	if err != nil {
+		t.Fatalf("failed to parse user with special table name, got error %v", err)
+	}
+
+	schema.Evict(cacheStore, &[]*tests.User{})
+
+	if s, err := schema.Parse(&tests.User{}, cacheStore, schema.NamingStrategy{}); err != nil || s == user {
+		t.Errorf("user should be parsed again after evicted, got error %v", err)
+	}
+
+	if s, err := schema.ParseWithSpecialTableName(&tests.User{}, cacheStore, schema.NamingStrategy{}, "special_users"); err != nil || s == specialUser || s.Table != "special_users" {
+		t.Errorf("user with special table name should be parsed again after evicted, got error %v", err)
+	}
+
+	parsePlugin := func(model interface{}) *schema.Schema {
+		s, err := schema.Parse(model, cacheStore, schema.NamingStrategy{})
+		if err != nil {
+			t.Fatalf("failed to parse plugin, got error %v", err)
+		}
+		return s
+	}
+
+	oldPlugin := func() *schema.Schema {
+		type Plugin struct {
+			ID   uint
+			Name string `gorm:"column:name_v1"`
+		}
+		s := parsePlugin(&Plugin{})
+		schema.Evict(cacheStore, &Plugin{})
+		return s
+	}()
+
+	newPlugin := func() *schema.Schema {
+		type Plugin struct {
+			ID   uint
+			Name string `gorm:"column:name_v2"`
+		}
+		return parsePlugin(&Plugin{})
+	}()
+
+	if oldPlugin.Name != newPlugin.Name || oldPlugin.LookUpField("Name").DBName != "name_v1" {
+		t.Errorf("failed to parse plugin, got %v", oldPlugin.LookUpField("Name").DBName)
+	}
+
+	if field := newPlugin.LookUpField("Name"); field == nil || field.DBName != "name_v2" {
+		t.Errorf("rebuilt plugin should use new column name, got %+v", field)
+	}
+
+	schema.FlushCache(cacheStore)
+
+	cacheStore.Range(func(key, value interface{}) bool {
+		if _, ok := value.(*schema.Schema); ok {
+			t.Errorf("schema %v should be removed after flushed", key)
+		}
+		return true
+	})
+
+	if _, ok := cacheStore.Load("prepared_stmt"); !ok {
+		t.Errorf("other cached values should be kept after flushed")
+	}
+}
+
 func TestNestedModel(t *testing.T) {
 	versionUser, err := schema.Parse(&VersionUser{}, &sync.Map{}, schema.NamingStrategy{})
 	if err != nil {
@@ -334,3 +519,55 @@ func TestCompositePrimaryKeyWithAutoIncrement(t *testing.T) {
 		t.Fatalf("PrioritizedPrimaryField of non autoincrement composite key should be nil")
 	}
 }
+
+func TestParseUnsupportedFieldType(t *testing.T) {
+	type Address struct {
+		Street string
+	}
+
+	type UserWithMap struct {
+		ID     uint
+		Labels map[string]string
+	}
+
+	type UserWithSerializedMap struct {
+		ID     uint
+		Labels map[string]string `gorm:"serializer:json"`
+		Skip   map[string]string `gorm:"-"`
+		Func   func()            `gorm:"-"`
+	}
+
+	type UserWithStrings struct {
+		ID   uint
+		Tags []string
+	}
+
+	type UserWithAddresses struct {
+		ID        uint
+		Addresses []Address
+	}
+
+	_, err := schema.Parse(&UserWithMap{}, &sync.Map{}, schema.NamingStrategy{})
+	if !errors.Is(err, schema.ErrUnsupportedDataType) {
+		t.Fatalf("expects ErrUnsupportedDataType for map field, got %v", err)
+	}
+
+	for _, expected := range []string{"map[string]string", "UserWithMap's field Labels", "serializer:json", `gorm:"-"`} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("error message should contain %q, got %v", expected, err)
+		}
+	}
+
+	if _, err := schema.Parse(&UserWithSerializedMap{}, &sync.Map{}, schema.NamingStrategy{}); err != nil {
+		t.Errorf("no error should happen for serialized or ignored fields, got %v", err)
+	}
+
+	if _, err := schema.Parse(&UserWithStrings{}, &sync.Map{}, schema.NamingStrategy{}); err == nil || !strings.Contains(err.Error(), "UserWithStrings's field Tags") {
+		t.Errorf("expects unsupported data type error for []string field, got %v", err)
+	}
+
+	if _, err := schema.Parse(&UserWithAddresses{}, &sync.Map{}, schema.NamingStrategy{}); err == nil ||
+		!strings.Contains(err.Error(), "[]schema_test.Address") || !strings.Contains(err.Error(), "serializer:json") {
+		t.Errorf("expects invalid field error with serializer suggestion for []Address field, got %v", err)
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"encoding/gob"
 	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -34,6 +36,7 @@ func init() {
 	RegisterSerializer("json", JSONSerializer{})
 	RegisterSerializer("unixtime", UnixSecondSerializer{})
 	RegisterSerializer("gob", GobSerializer{})
+	RegisterSerializer("duration", DurationSerializer{})
 }
 
 // Serializer field value serializer
@@ -177,3 +180,92 @@ func (GobSerializer) Value(ctx context.Context, field *Field, dst reflect.Value,
 	err := gob.NewEncoder(buf).Encode(fieldValue)
 	return buf.Bytes(), err
 }
+
+// DurationSerializer duration serializer, saves time.Duration as string like 1h30m0s, e.g. columns with type interval
+// scans Go duration strings, clock format like 01:30:00 or 1 day 01:30:00, and numbers of nanoseconds
+type DurationSerializer struct{}
+
+// Scan implements serializer interface
+func (DurationSerializer) Scan(ctx context.Context, field *Field, dst reflect.Value, dbValue interface{}) (err error) {
+	var d time.Duration
+	switch v := dbValue.(type) {
+	case nil:
+		field.ReflectValueOf(ctx, dst).Set(reflect.New(field.FieldType).Elem())
+		return nil
+	case []byte:
+		d, err = parseDuration(string(v))
+	case string:
+		d, err = parseDuration(v)
+	case int64:
+		d = time.Duration(v)
+	default:
+		return fmt.Errorf("failed to unmarshal duration value: %#v", dbValue)
+	}
+
+	if err == nil {
+		err = field.Set(ctx, dst, d)
+	}
+	return
+}
+
+// Value implements serializer interface
+func (DurationSerializer) Value(ctx context.Context, field *Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	switch v := fieldValue.(type) {
+	case time.Duration:
+		return v.String(), nil
+	case *time.Duration:
+		if v == nil {
+			return nil, nil
+		}
+		return v.String(), nil
+	default:
+		return nil, fmt.Errorf("invalid field type %#v for DurationSerializer, only time.Duration supported", v)
+	}
+}
+
+// parseDuration parses Go duration strings like 1h30m, numbers are nanoseconds, and clock format like 01:30:00, -01:30:00.5, 2 days 01:30:00
+func parseDuration(str string) (time.Duration, error) {
+	str = strings.TrimSpace(str)
+	if d, err := time.ParseDuration(str); err == nil {
+		return d, nil
+	} else if i, err := strconv.ParseInt(str, 10, 64); err == nil {
+		return time.Duration(i), nil
+	}
+
+	var days time.Duration
+	if fields := strings.Fields(str); len(fields) >= 2 && strings.HasPrefix(fields[1], "day") {
+		n, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", str)
+		}
+		days = time.Duration(n) * 24 * time.Hour
+		if str = strings.Join(fields[2:], " "); str == "" {
+			return days, nil
+		}
+	}
+
+	negative := strings.HasPrefix(str, "-")
+	parts := strings.Split(strings.TrimPrefix(str, "-"), ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid duration %q", str)
+	}
+
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", str)
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", str)
+	}
+	seconds, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", str)
+	}
+
+	d := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(math.Round(seconds*float64(time.Second)))
+	if negative {
+		d = -d
+	}
+	return days + d, nil
+}
@@ -4,7 +4,9 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
+	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/jinzhu/now"
 	"gorm.io/gorm/clause"
@@ -74,6 +76,15 @@ func (sd SoftDeleteQueryClause) MergeClause(*clause.Clause) {
 }
 
 func (sd SoftDeleteQueryClause) ModifyStatement(stmt *Statement) {
+	if stmt.Statement.unscopedQuery {
+		return
+	}
+	// 在 where 条件里面加一个 where ${db_name} == ${zero_value} 的条件
+	addSoftDeleteCondition(stmt, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: sd.Field.DBName}, Value: sd.ZeroValue})
+}
+
+// addSoftDeleteCondition add the not deleted condition once, existing single OR conditions are grouped before it
+func addSoftDeleteCondition(stmt *Statement, cond clause.Expression) {
 	if _, ok := stmt.Clauses["soft_delete_enabled"]; !ok && !stmt.Statement.Unscoped {
 		if c, ok := stmt.Clauses["WHERE"]; ok {
 			if where, ok := c.Expression.(clause.Where); ok && len(where.Exprs) >= 1 {
@@ -87,10 +98,7 @@ func (sd SoftDeleteQueryClause) ModifyStatement(stmt *Statement) {
 				}
 			}
 		}
-		// 在 where 条件里面加一个 where ${db_name} == ${zero_value} 的条件
-		stmt.AddClause(clause.Where{Exprs: []clause.Expression{
-			clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: sd.Field.DBName}, Value: sd.ZeroValue},
-		}})
+		stmt.AddClause(clause.Where{Exprs: []clause.Expression{cond}})
 		stmt.Clauses["soft_delete_enabled"] = clause.Clause{}
 	}
 }
@@ -145,26 +153,144 @@ func (sd SoftDeleteDeleteClause) ModifyStatement(stmt *Statement) {
 		stmt.AddClause(clause.Set{{Column: clause.Column{Name: sd.Field.DBName}, Value: curTime}})
 		stmt.SetColumn(sd.Field.DBName, curTime, true)
 
-		if stmt.Schema != nil {
-			_, queryValues := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
-			column, values := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+		addPrimaryKeyConditions(stmt)
+		addSoftDeleteCondition(stmt, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: sd.Field.DBName}, Value: sd.ZeroValue})
+		stmt.AddClauseIfNotExists(clause.Update{})
+		stmt.Build(stmt.DB.Callback().Update().BuildClauses()...)
+	}
+}
+
+// addPrimaryKeyConditions restrict the soft delete update to the primary keys of dest and model
+func addPrimaryKeyConditions(stmt *Statement) {
+	if stmt.Schema != nil {
+		_, queryValues := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
+		column, values := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+
+		if len(values) > 0 {
+			stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: values}}})
+		}
+
+		if stmt.ReflectValue.CanAddr() && stmt.Dest != stmt.Model && stmt.Model != nil {
+			_, queryValues = schema.GetIdentityFieldValuesMap(stmt.Context, reflect.ValueOf(stmt.Model), stmt.Schema.PrimaryFields)
+			column, values = schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
 
 			if len(values) > 0 {
 				stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: values}}})
 			}
+		}
+	}
+}
 
-			if stmt.ReflectValue.CanAddr() && stmt.Dest != stmt.Model && stmt.Model != nil {
-				_, queryValues = schema.GetIdentityFieldValuesMap(stmt.Context, reflect.ValueOf(stmt.Model), stmt.Schema.PrimaryFields)
-				column, values = schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+// DeletedFlag soft delete with a flag column, 0 means not deleted and 1 means deleted, E.g:
+//
+//	type User struct {
+//		ID        uint
+//		IsDeleted gorm.DeletedFlag `gorm:"softDelete:flag,deletedAtField:DeletedAt"`
+//		DeletedAt *time.Time
+//	}
+//
+// the optional deletedAtField is set to the current time when deleting, it should not be a DeletedAt
+type DeletedFlag uint8
 
-				if len(values) > 0 {
-					stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: values}}})
-				}
+func (DeletedFlag) QueryClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{SoftDeleteFlagQueryClause{Field: f}}
+}
+
+func parseDeletedAtFieldTag(f *schema.Field) string {
+	for _, setting := range strings.Split(f.TagSettings["SOFTDELETE"], ",") {
+		if values := strings.SplitN(setting, ":", 2); len(values) == 2 && strings.EqualFold(strings.TrimSpace(values[0]), "deletedAtField") {
+			return strings.TrimSpace(values[1])
+		}
+	}
+	return ""
+}
+
+type SoftDeleteFlagQueryClause struct {
+	Field *schema.Field
+}
+
+func (sd SoftDeleteFlagQueryClause) Name() string {
+	return ""
+}
+
+func (sd SoftDeleteFlagQueryClause) Build(clause.Builder) {
+}
+
+func (sd SoftDeleteFlagQueryClause) MergeClause(*clause.Clause) {
+}
+
+func (sd SoftDeleteFlagQueryClause) ModifyStatement(stmt *Statement) {
+	if stmt.Statement.unscopedQuery {
+		return
+	}
+	addSoftDeleteCondition(stmt, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: sd.Field.DBName}, Value: 0})
+}
+
+func (DeletedFlag) UpdateClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{SoftDeleteFlagUpdateClause{Field: f}}
+}
+
+type SoftDeleteFlagUpdateClause struct {
+	Field *schema.Field
+}
+
+func (sd SoftDeleteFlagUpdateClause) Name() string {
+	return ""
+}
+
+func (sd SoftDeleteFlagUpdateClause) Build(clause.Builder) {
+}
+
+func (sd SoftDeleteFlagUpdateClause) MergeClause(*clause.Clause) {
+}
+
+func (sd SoftDeleteFlagUpdateClause) ModifyStatement(stmt *Statement) {
+	if stmt.SQL.Len() == 0 && !stmt.Statement.Unscoped {
+		SoftDeleteFlagQueryClause(sd).ModifyStatement(stmt)
+	}
+}
+
+func (DeletedFlag) DeleteClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{SoftDeleteFlagDeleteClause{Field: f, DeletedAtField: parseDeletedAtFieldTag(f)}}
+}
+
+type SoftDeleteFlagDeleteClause struct {
+	Field          *schema.Field
+	DeletedAtField string
+}
+
+func (sd SoftDeleteFlagDeleteClause) Name() string {
+	return ""
+}
+
+func (sd SoftDeleteFlagDeleteClause) Build(clause.Builder) {
+}
+
+func (sd SoftDeleteFlagDeleteClause) MergeClause(*clause.Clause) {
+}
+
+func (sd SoftDeleteFlagDeleteClause) ModifyStatement(stmt *Statement) {
+	if stmt.SQL.Len() == 0 && !stmt.Statement.Unscoped {
+		// DELETE 转换为 UPDATE SET ${flag} = 1，同时设置配对的删除时间字段
+		set := clause.Set{{Column: clause.Column{Name: sd.Field.DBName}, Value: 1}}
+		stmt.SetColumn(sd.Field.DBName, 1, true)
+
+		if sd.DeletedAtField != "" {
+			deletedAtField := sd.Field.Schema.LookUpField(sd.DeletedAtField)
+			if deletedAtField == nil {
+				stmt.AddError(fmt.Errorf("%w: %s for soft delete flag %s", ErrInvalidField, sd.DeletedAtField, sd.Field.Name))
+				return
 			}
+
+			curTime := stmt.DB.NowFunc()
+			set = append(set, clause.Assignment{Column: clause.Column{Name: deletedAtField.DBName}, Value: curTime})
+			stmt.SetColumn(deletedAtField.DBName, curTime, true)
 		}
+		stmt.AddClause(set)
 
-		SoftDeleteQueryClause(sd).ModifyStatement(stmt)
+		addPrimaryKeyConditions(stmt)
+		addSoftDeleteCondition(stmt, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: sd.Field.DBName}, Value: 0})
 		stmt.AddClauseIfNotExists(clause.Update{})
-		stmt.Build(stmt.DB.Callback().Update().Clauses...)
+		stmt.Build(stmt.DB.Callback().Update().BuildClauses()...)
 	}
 }
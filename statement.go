@@ -2,8 +2,10 @@ package gorm
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
 	"database/sql/driver"
+	"encoding/hex"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -11,6 +13,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
@@ -25,6 +28,7 @@ type Statement struct {
 	TableExpr *clause.Expr
 	// 表名，可能是临时表的名字  (?) as tmp
 	Table                string
+	TableSchema          string // 表所在的 schema（数据库），如 analytics.events 的 analytics
 	Model                interface{}
 	Unscoped             bool                     // 取消作用域（Scope）限制。通过使用 Unscoped 方法，可以获取到被软删除（Soft Delete）标记的数据，或者取消其他作用域的限制条件。
 	Dest                 interface{}              // 用来接收结果的目标结构体
@@ -35,6 +39,11 @@ type Statement struct {
 	Selects              []string // selected columns 要被查询的字段
 	Omits                []string // omit columns 要被排除的字段
 	Joins                []join
+	Hints                []clause.Hint // 构建时注入到对应子句的注释和提示
+	PreviousDest         interface{}   // 更新、删除前捕获旧值的目标，见 CapturePrevious
+	PreviousColumns      []string      // 需要捕获旧值的列
+	executing            int32         // CheckConcurrentMisuse 时标记正在执行 finisher
+	reused               int32         // CheckConcurrentMisuse 时标记执行期间被其他 finisher 复用
 	Preloads             map[string][]interface{}
 	Settings             sync.Map
 	ConnPool             ConnPool
@@ -42,12 +51,25 @@ type Statement struct {
 	Context              context.Context
 	RaiseErrorOnNotFound bool // 如果没有查询到数据，是否报错
 	SkipHooks            bool
+	SkipPrepare          bool              // 不使用缓存的预编译语句执行
+	TablePrefix          string            // 生成 SQL 时给表名添加的前缀，如按租户分表，不影响 schema 缓存
+	TableSuffix          string            // 生成 SQL 时给表名添加的后缀
+	TxOptions            *sql.TxOptions    // options of the active transaction
+	ColumnMapping        map[string]string // 扫描结果时将列名映射为 model 的列名
+	MapColumnKeys        bool              // 扫描到 map 时是否也使用映射后的列名作为 key
+	NoCache              bool              // 不使用查询缓存，由缓存插件读取，见 NoCache
+	SkipQuery            bool              // 查询结果已由插件（如缓存）填充，gorm:query 不执行查询
 	SQL                  strings.Builder
 	Vars                 []interface{}
 	CurDestIndex         int
 	attrs                []interface{}
 	assigns              []interface{}
 	scopes               []func(*DB) *DB
+	scopeNames           []string // names of scopes, empty for unnamed scopes
+	withoutScopes        []string // names of scopes removed by WithoutScope, named scopes added later are skipped
+	loadedColumns        []string // columns scanned into the model by the last query
+	modelTable           string   // table resolved from the model, resolved again by the mode of the next statement
+	unscopedQuery        bool     // Unscoped propagated by PropagateUnscoped, only shows soft deleted records and never deletes permanently
 }
 
 type join struct {
@@ -57,6 +79,9 @@ type join struct {
 	Selects  []string
 	Omits    []string
 	JoinType clause.JoinType
+	Clause   *clause.Join // pre-built join clause of Joins(clause.Join{...})
+
+	IndexHints []clause.IndexHint // index hints of the joined relation table
 }
 
 // StatementModifier statement modifier interface
@@ -80,6 +105,51 @@ func (stmt *Statement) WriteQuoted(value interface{}) {
 	stmt.QuoteTo(&stmt.SQL, value)
 }
 
+// affixTable adds TablePrefix and TableSuffix to table name, only the table part of names with schema is affixed
+func (stmt *Statement) affixTable(name string) string {
+	if name == "" || (stmt.TablePrefix == "" && stmt.TableSuffix == "") {
+		return name
+	}
+
+	if idx := strings.LastIndexByte(name, '.'); idx > 0 {
+		return name[:idx+1] + stmt.TablePrefix + name[idx+1:] + stmt.TableSuffix
+	}
+	return stmt.TablePrefix + name + stmt.TableSuffix
+}
+
+// affixSchemaTable adds TablePrefix and TableSuffix to name if it is the table of a parsed schema,
+// e.g. tables of relations and join tables, raw names and aliases like relation names of joins or `old` of RETURNING are kept
+func (stmt *Statement) affixSchemaTable(raw bool, name string) string {
+	if raw || name == "" || (stmt.TablePrefix == "" && stmt.TableSuffix == "") {
+		return name
+	}
+
+	// 通过 Table 指定的表名不添加前后缀
+	if stmt.TableExpr != nil && name == stmt.Table {
+		return name
+	}
+
+	var isSchemaTable bool
+	stmt.DB.cacheStore.Range(func(key, value interface{}) bool {
+		if s, ok := value.(*schema.Schema); ok && s.Table == name {
+			isSchemaTable = true
+		}
+		return !isSchemaTable
+	})
+
+	if isSchemaTable {
+		return stmt.affixTable(name)
+	}
+	return name
+}
+
+// AffixTable applies TablePrefix and TableSuffix to Table, used by migrator to operate the affixed table
+func (stmt *Statement) AffixTable() {
+	if stmt.TableExpr == nil {
+		stmt.Table = stmt.affixTable(stmt.Table)
+	}
+}
+
 // QuoteTo write quoted value to writer 为 列名或者表名添加引号
 func (stmt *Statement) QuoteTo(writer clause.Writer, field interface{}) {
 	write := func(raw bool, str string) {
@@ -90,30 +160,58 @@ func (stmt *Statement) QuoteTo(writer clause.Writer, field interface{}) {
 		}
 	}
 
+	// 带 schema 的表名，schema 和表名分别添加引号
+	writeTable := func(raw bool, table string) {
+		if raw || !strings.Contains(table, ".") {
+			write(raw, table)
+			return
+		}
+
+		for idx, name := range strings.Split(table, ".") {
+			if idx > 0 {
+				writer.WriteByte('.')
+			}
+			stmt.DB.Dialector.QuoteTo(writer, name)
+		}
+	}
+
+	// 别名前的关键字可以由 dialector 决定
+	writeAlias := func(table, raw bool, alias string) {
+		keyword := " AS "
+		if table {
+			keyword = " "
+		}
+
+		if aliasWriter, ok := stmt.DB.Dialector.(AliasWriter); ok {
+			keyword = aliasWriter.AliasKeyword(table)
+		}
+		writer.WriteString(keyword)
+		write(raw, alias)
+	}
+
 	switch v := field.(type) {
 	case clause.Table: // 表名
 		if v.Name == clause.CurrentTable { // 如果是当前表占位符
 			if stmt.TableExpr != nil {
 				stmt.TableExpr.Build(stmt)
 			} else {
-				write(v.Raw, stmt.Table) // 写入 statement 的表名
+				writeTable(v.Raw, stmt.Table) // 写入 statement 的表名
 			}
 		} else {
-			write(v.Raw, v.Name) // 如果是直接指定表名
+			writeTable(v.Raw, stmt.affixSchemaTable(v.Raw, v.Name)) // 如果是直接指定表名
 		}
 
 		if v.Alias != "" {
-			writer.WriteByte(' ')
-			write(v.Raw, v.Alias)
+			writeAlias(true, v.Raw, v.Alias)
 		}
 	case clause.Column: // 列名
 		if v.Table != "" {
 			// 表名非空，使用表名.字段名生成 SQL
 			if v.Table == clause.CurrentTable {
 				// 当前表占位符,使用 statement 的 Table Name
-				write(v.Raw, stmt.Table)
+				writeTable(v.Raw, stmt.Table)
 			} else {
-				write(v.Raw, v.Table)
+				writeTable(v.Raw, stmt.affixSchemaTable(v.Raw, v.Table))
 			}
 			writer.WriteByte('.')
 		}
@@ -139,8 +237,7 @@ func (stmt *Statement) QuoteTo(writer clause.Writer, field interface{}) {
 
 		if v.Alias != "" {
 			// 行定义了 Alias
-			writer.WriteString(" AS ")
-			write(v.Raw, v.Alias)
+			writeAlias(false, v.Raw, v.Alias)
 		}
 	case []clause.Column: // 多个列
 		writer.WriteByte('(')
@@ -176,6 +273,75 @@ func (stmt *Statement) Quote(field interface{}) string {
 	return builder.String()
 }
 
+// SupportOrderByField returns true if the dialector supports ordering by FIELD()
+func (stmt *Statement) SupportOrderByField() bool {
+	if supporter, ok := stmt.DB.Dialector.(clause.OrderByFieldSupporter); ok {
+		return supporter.SupportOrderByField()
+	}
+	return false
+}
+
+// JSONQueryStyle returns the style of clause.JSONQuery, defaults to the dialector name
+func (stmt *Statement) JSONQueryStyle() string {
+	if styler, ok := stmt.DB.Dialector.(clause.JSONQueryStyler); ok {
+		return styler.JSONQueryStyle()
+	}
+	return stmt.DB.Dialector.Name()
+}
+
+// BindColumnValue serialize value of condition column if the field has serializer,
+// driver.Valuer and driver values not of the field's type are regarded as serialized
+func (stmt *Statement) BindColumnValue(column interface{}, value interface{}) interface{} {
+	if stmt.Schema == nil || value == nil {
+		return value
+	}
+
+	var name string
+	switch c := column.(type) {
+	case string:
+		name = c
+	case clause.Column:
+		if c.Raw || (c.Table != "" && c.Table != clause.CurrentTable && c.Table != stmt.Table) {
+			return value
+		}
+		name = c.Name
+	default:
+		return value
+	}
+
+	field := stmt.Schema.LookUpField(name)
+	if name == clause.PrimaryKey {
+		field = stmt.Schema.PrioritizedPrimaryField
+	}
+
+	if field == nil || field.Serializer == nil {
+		return value
+	}
+
+	switch value.(type) {
+	case driver.Valuer, clause.Expression, *DB:
+		// serialized value, expression or sub query
+		return value
+	}
+
+	// 不是字段类型的驱动值已经是序列化之后的结果，如 json 字段的字符串
+	if driver.IsValue(value) && !reflect.TypeOf(value).AssignableTo(field.IndirectFieldType) {
+		return value
+	}
+
+	valuer, ok := value.(schema.SerializerValuerInterface)
+	if !ok {
+		valuer = field.Serializer
+	}
+
+	v, err := valuer.Value(stmt.Context, field, reflect.New(stmt.Schema.ModelType).Elem(), value)
+	if err != nil {
+		stmt.AddError(err)
+		return value
+	}
+	return v
+}
+
 // AddVar add var
 func (stmt *Statement) AddVar(writer clause.Writer, vars ...interface{}) {
 	for idx, v := range vars {
@@ -202,11 +368,12 @@ func (stmt *Statement) AddVar(writer clause.Writer, vars ...interface{}) {
 		case clause.Expression: // 子表达式
 			v.Build(stmt)
 		case driver.Valuer:
-			stmt.Vars = append(stmt.Vars, v)
-			stmt.DB.Dialector.BindVarTo(writer, stmt, v)
+			stmt.bindVar(writer, v)
 		case []byte:
 			stmt.Vars = append(stmt.Vars, v)
 			stmt.DB.Dialector.BindVarTo(writer, stmt, v)
+		case time.Time:
+			stmt.bindVar(writer, v.Round(0)) // 去掉单调时钟读数，保证驱动层比较稳定
 		case []interface{}:
 			if len(v) > 0 {
 				writer.WriteByte('(')
@@ -247,7 +414,19 @@ func (stmt *Statement) AddVar(writer clause.Writer, vars ...interface{}) {
 			writer.WriteString(subdb.Statement.SQL.String())
 			stmt.Vars = subdb.Statement.Vars
 		default:
-			switch rv := reflect.ValueOf(v); rv.Kind() {
+			rv := reflect.ValueOf(v)
+			if spec, ok := lookUpBindDataType(rv); ok { // 通过 RegisterDataType 注册的类型
+				if rv.Kind() == reflect.Ptr && rv.IsNil() {
+					stmt.AddVar(writer, nil)
+				} else if dv, err := spec.Bind(reflect.Indirect(rv).Interface()); err != nil {
+					stmt.AddError(err)
+				} else {
+					stmt.bindVar(writer, dv)
+				}
+				continue
+			}
+
+			switch rv.Kind() {
 			case reflect.Slice, reflect.Array:
 				if rv.Len() == 0 {
 					writer.WriteString("(NULL)")
@@ -265,11 +444,83 @@ func (stmt *Statement) AddVar(writer clause.Writer, vars ...interface{}) {
 					writer.WriteByte(')')
 				}
 			default: // 普通且非列表值
-				stmt.Vars = append(stmt.Vars, v)
-				stmt.DB.Dialector.BindVarTo(writer, stmt, v) // 写占位符
+				stmt.bindVar(writer, v) // 写占位符
+			}
+		}
+	}
+}
+
+// lookUpBindDataType look up the registered data type of value or pointer rv
+func lookUpBindDataType(rv reflect.Value) (spec schema.DataTypeSpec, ok bool) {
+	if !rv.IsValid() {
+		return
+	}
+
+	rt := rv.Type()
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if spec, ok = schema.LookUpDataType(rt); ok {
+		ok = spec.Bind != nil
+	}
+	return
+}
+
+// bindVar append v to vars and write its placeholder, reuse the placeholder of an equal var with DedupBindVars
+func (stmt *Statement) bindVar(writer clause.Writer, v interface{}) {
+	if stmt.DB.DedupBindVars {
+		if indexer, ok := stmt.DB.Dialector.(BindVarIndexer); ok {
+			if idx := stmt.varIndex(v); idx >= 0 {
+				indexer.BindVarIndexTo(writer, stmt, idx)
+				return
+			}
+		}
+	}
+
+	stmt.Vars = append(stmt.Vars, v)
+	stmt.DB.Dialector.BindVarTo(writer, stmt, v)
+}
+
+// varIndex returns the index of the var equal to v, only basic comparable values are checked
+func (stmt *Statement) varIndex(v interface{}) int {
+	if v == nil {
+		return -1
+	}
+
+	// 结构体、数组可能包含不可比较的 interface 字段，比较时会 panic
+	switch t := reflect.TypeOf(v); t.Kind() {
+	case reflect.Struct, reflect.Array, reflect.Interface:
+		return -1
+	default:
+		if !t.Comparable() {
+			return -1
+		}
+
+		for idx, vv := range stmt.Vars {
+			if vv != nil && reflect.TypeOf(vv) == t && vv == v {
+				return idx
 			}
 		}
 	}
+	return -1
+}
+
+// LikeEscape returns the ESCAPE clause for LIKE patterns escaped by clause.EscapeLike
+func (stmt *Statement) LikeEscape() string {
+	if escaper, ok := stmt.DB.Dialector.(clause.LikeEscaper); ok {
+		return escaper.LikeEscape()
+	} else if stmt.BackslashEscape() {
+		return clause.BackslashLikeEscape
+	}
+	return clause.DefaultLikeEscape
+}
+
+// BackslashEscape reports whether backslash escapes quotes in string literals of raw sql, dialectors opt in with clause.BackslashEscaper
+func (stmt *Statement) BackslashEscape() bool {
+	if escaper, ok := stmt.DB.Dialector.(clause.BackslashEscaper); ok {
+		return escaper.BackslashEscape()
+	}
+	return false
 }
 
 // AddClause add clause
@@ -292,11 +543,72 @@ func (stmt *Statement) AddClauseIfNotExists(v clause.Interface) {
 	}
 }
 
+// conditionGroup conditions created by AndGroup, OrGroup
+type conditionGroup struct {
+	or    bool
+	conds []interface{}
+}
+
+// AndGroup returns a condition joins conds with AND, each cond accepts the same formats as Where,
+// a string cond with args is passed as []interface{}{"age > ?", 18}, E.g:
+//
+//	db.Where(gorm.OrGroup(gorm.AndGroup(&User{Role: "admin"}), gorm.AndGroup(map[string]interface{}{"age": 18, "active": true})))
+//	// SELECT * FROM users WHERE role = "admin" OR (age = 18 AND active = true)
+//	db.Where(gorm.OrGroup([]interface{}{"age > ?", 18}, []interface{}{"name = ?", "jinzhu"}))
+//	// SELECT * FROM users WHERE age > 18 OR name = "jinzhu"
+func AndGroup(conds ...interface{}) clause.Expression {
+	return conditionGroup{conds: conds}
+}
+
+// OrGroup returns a condition joins conds with OR, each cond accepts the same formats as Where,
+// a struct or map cond is treated as one AND group
+func OrGroup(conds ...interface{}) clause.Expression {
+	return conditionGroup{or: true, conds: conds}
+}
+
+// Build build the group with the statement's BuildCondition if the builder is a statement
+func (group conditionGroup) Build(builder clause.Builder) {
+	if stmt, ok := builder.(*Statement); ok {
+		if expr := stmt.buildConditionGroup(group); expr != nil {
+			expr.Build(builder)
+		}
+	}
+}
+
+// buildConditionGroup 每个条件单独解析后组合，嵌套的分组由 clause.Where 加括号
+func (stmt *Statement) buildConditionGroup(group conditionGroup) clause.Expression {
+	exprs := make([]clause.Expression, 0, len(group.conds))
+	for _, cond := range group.conds {
+		var args []interface{}
+		// 带参数的字符串条件，如 []interface{}{"age > ?", 18}
+		if values, ok := cond.([]interface{}); ok && len(values) > 0 {
+			if _, ok := values[0].(string); ok {
+				cond, args = values[0], values[1:]
+			}
+		}
+
+		if s, ok := cond.(string); ok && len(args) == 0 && strings.Contains(s, "?") {
+			stmt.DB.AddError(fmt.Errorf("%w: condition %q of group has placeholders without args, pass it as []interface{}{%q, args...}", ErrInvalidData, s, s))
+			continue
+		}
+
+		if conds := stmt.BuildCondition(cond, args...); len(conds) > 0 {
+			exprs = append(exprs, clause.And(conds...))
+		}
+	}
+
+	// 单个条件的 OrConditions 会被 Where 当做与前一个条件 OR 连接
+	if group.or && len(exprs) > 1 {
+		return clause.Or(exprs...)
+	}
+	return clause.And(exprs...)
+}
+
 // BuildCondition build condition
 func (stmt *Statement) BuildCondition(query interface{}, args ...interface{}) []clause.Expression {
 	if s, ok := query.(string); ok {
-		// if it is a number, then treats it as primary key
-		if _, err := strconv.Atoi(s); err != nil {
+		// if it looks like a primary key, then treats it as primary key
+		if !stmt.isPrimaryKeyString(s, args) {
 			if s == "" && len(args) == 0 {
 				return nil
 			}
@@ -333,6 +645,10 @@ func (stmt *Statement) BuildCondition(query interface{}, args ...interface{}) []
 		}
 
 		switch v := arg.(type) {
+		case conditionGroup:
+			if expr := stmt.buildConditionGroup(v); expr != nil {
+				conds = append(conds, expr)
+			}
 		case clause.Expression:
 			conds = append(conds, v)
 		case *DB:
@@ -477,6 +793,46 @@ func (stmt *Statement) BuildCondition(query interface{}, args ...interface{}) []
 	return conds
 }
 
+// isPrimaryKeyString numeric strings are primary keys if the primary field is int or uint, strings without spaces and
+// operators are primary keys if the primary field is string, numeric strings are primary keys if the schema is unknown
+func (stmt *Statement) isPrimaryKeyString(s string, args []interface{}) bool {
+	_, err := strconv.Atoi(s)
+	sch := stmt.conditionSchema()
+	if sch == nil || sch.PrioritizedPrimaryField == nil {
+		return err == nil
+	}
+
+	switch sch.PrioritizedPrimaryField.DataType {
+	case schema.Int, schema.Uint:
+		return err == nil
+	case schema.String:
+		if len(args) > 0 || s == "" || strings.ContainsAny(s, " \t\r\n=<>!()?@'\"`") {
+			return false
+		}
+
+		// 字段名是条件，如 Where("active")、Where("users.active")
+		return sch.LookUpField(s) == nil && sch.LookUpField(s[strings.LastIndex(s, ".")+1:]) == nil
+	}
+	return err == nil
+}
+
+// conditionSchema returns the parsed schema, or parses Model or Dest if the statement hasn't been parsed yet
+func (stmt *Statement) conditionSchema() *schema.Schema {
+	if stmt.Schema != nil || stmt.DB == nil {
+		return stmt.Schema
+	}
+
+	for _, value := range []interface{}{stmt.Model, stmt.Dest} {
+		if value == nil {
+			continue
+		}
+		if s, err := schema.Parse(value, stmt.DB.cacheStore, stmt.DB.NamingStrategy); err == nil {
+			return s
+		}
+	}
+	return nil
+}
+
 // Build build sql with clauses names 构建 sql
 func (stmt *Statement) Build(clauses ...string) {
 	var firstClauseWritten bool
@@ -488,7 +844,9 @@ func (stmt *Statement) Build(clauses ...string) {
 			}
 
 			firstClauseWritten = true
-			if b, ok := stmt.DB.ClauseBuilders[name]; ok { // 如果 ClauseBuilders 有对应的 clause, 覆盖 stmt 的
+			if len(stmt.Hints) > 0 {
+				stmt.buildClauseWithHints(name, c)
+			} else if b, ok := stmt.DB.ClauseBuilders[name]; ok { // 如果 ClauseBuilders 有对应的 clause, 覆盖 stmt 的
 				b(c, stmt)
 			} else {
 				c.Build(stmt)
@@ -497,29 +855,269 @@ func (stmt *Statement) Build(clauses ...string) {
 	}
 }
 
+// SupportIndexHint reports whether the dialector supports MySQL style index hints, index hints are written by default,
+// dialectors without index hints could implement clause.IndexHintSupporter to skip them
+func (stmt *Statement) SupportIndexHint() bool {
+	if supporter, ok := stmt.DB.Dialector.(clause.IndexHintSupporter); ok {
+		return supporter.SupportIndexHint()
+	}
+	return true
+}
+
+// SupportGeneratedColumn reports whether the dialector supports generated columns, dialectors supporting them
+// could implement GeneratedColumnSupporter to return generated columns after creating
+func (stmt *Statement) SupportGeneratedColumn() bool {
+	if supporter, ok := stmt.DB.Dialector.(GeneratedColumnSupporter); ok {
+		return supporter.SupportGeneratedColumn()
+	}
+	return false
+}
+
+// buildClauseWithHints build clause and inject its hints, hints after name are inserted after the verb written by the clause
+func (stmt *Statement) buildClauseWithHints(name string, c clause.Clause) {
+	var before, afterName, after []clause.Expression
+	for _, hint := range stmt.Hints {
+		if !strings.EqualFold(hint.Clause, name) || hint.Expression == nil {
+			continue
+		}
+
+		switch hint.Position {
+		case clause.HintBeforeClause:
+			before = append(before, hint.Expression)
+		case clause.HintAfterName:
+			afterName = append(afterName, hint.Expression)
+		case clause.HintAfterClause:
+			// 主表的索引提示写在主表之后，而不是 join 之后
+			if indexHint, ok := hint.Expression.(clause.IndexHint); ok {
+				if from, ok := c.Expression.(clause.From); ok {
+					from.IndexHints = append(from.IndexHints[:len(from.IndexHints):len(from.IndexHints)], indexHint)
+					c.Expression = from
+					continue
+				}
+			}
+			after = append(after, hint.Expression)
+		}
+	}
+
+	for _, expr := range before {
+		expr.Build(stmt)
+		stmt.WriteByte(' ')
+	}
+
+	start := stmt.SQL.Len()
+	if b, ok := stmt.DB.ClauseBuilders[name]; ok {
+		b(c, stmt)
+	} else {
+		c.Build(stmt)
+	}
+
+	if len(afterName) > 0 {
+		// 子句可能由方言的 ClauseBuilder 构建，在构建结果开头的动词后插入，找不到动词时插入到子句前
+		sql := stmt.SQL.String()
+		pos := start
+		if built := sql[start:]; len(built) >= len(name) && strings.EqualFold(built[:len(name)], name) {
+			pos += len(name)
+		}
+
+		stmt.SQL.Reset()
+		stmt.SQL.WriteString(sql[:pos])
+		for idx, expr := range afterName {
+			if idx > 0 || pos > start {
+				stmt.WriteByte(' ')
+			}
+			expr.Build(stmt)
+		}
+		if pos == start {
+			stmt.WriteByte(' ')
+		}
+		stmt.SQL.WriteString(sql[pos:])
+	}
+
+	for _, expr := range after {
+		stmt.WriteByte(' ')
+		expr.Build(stmt)
+	}
+}
+
 func (stmt *Statement) Parse(value interface{}) (err error) {
 	return stmt.ParseWithSpecialTableName(value, "")
 }
 
 func (stmt *Statement) ParseWithSpecialTableName(value interface{}, specialTableName string) (err error) {
 	if stmt.Schema, err = schema.ParseWithSpecialTableName(value, stmt.DB.cacheStore, stmt.DB.NamingStrategy, specialTableName); err == nil && stmt.Table == "" { // 如果解析成功，并且 statemane 没设置表名，  使用 schema 解析的表名
-		if tables := strings.Split(stmt.Schema.Table, "."); len(tables) == 2 { // 如果表名带了db名，取第二段
-			stmt.TableExpr = &clause.Expr{SQL: stmt.Quote(stmt.Schema.Table)}
-			stmt.Table = tables[1]
+		if stmt.Schema.SchemaName != "" { // 如果表名带了 schema，取表名部分，schema 和表名分别添加引号
+			stmt.TableSchema = stmt.Schema.SchemaName
+			stmt.Table = stmt.affixTable(strings.TrimPrefix(stmt.Schema.Table, stmt.TableSchema+"."))
+			stmt.TableExpr = &clause.Expr{SQL: stmt.Quote(clause.Table{Name: stmt.TableSchema + "." + stmt.Table})}
+			stmt.modelTable = stmt.Table
 			return
 		}
 
-		stmt.Table = stmt.Schema.Table // 如果是单独的表名，直接用
+		stmt.Table = stmt.affixTable(stmt.Schema.Table) // 如果是单独的表名，直接用，session 指定了前后缀时添加前后缀
+		stmt.modelTable = stmt.Table
 	}
 	return err
 }
 
+var tablerWithModeType = reflect.TypeOf((*schema.TablerWithMode)(nil)).Elem()
+
+// useTableOfMode uses the table of mode if the model implements schema.TablerWithMode
+func (stmt *Statement) useTableOfMode(mode string) {
+	if !reflect.PtrTo(stmt.Schema.ModelType).Implements(tablerWithModeType) {
+		return
+	}
+
+	stmt.useTable(reflect.New(stmt.Schema.ModelType).Interface().(schema.TablerWithMode).TableName(mode))
+}
+
+// useTable uses the table of the model chosen when executing statements, e.g. the table of mode or partition
+func (stmt *Statement) useTable(table string) {
+	if table == "" {
+		table = stmt.Schema.Table
+	}
+
+	stmt.TableSchema, stmt.TableExpr = "", nil
+	if names := strings.Split(table, "."); len(names) == 2 {
+		stmt.TableSchema = names[0]
+		stmt.Table = stmt.affixTable(names[1])
+		stmt.TableExpr = &clause.Expr{SQL: stmt.Quote(clause.Table{Name: stmt.TableSchema + "." + stmt.Table})}
+	} else {
+		stmt.Table = stmt.affixTable(table)
+	}
+	stmt.modelTable = stmt.Table
+}
+
+var partitionedTablerType = reflect.TypeOf((*schema.PartitionedTabler)(nil)).Elem()
+
+// partitionRecords groups records to write by the tables of schema.PartitionedTabler,
+// uses the table if all records belong to one table, otherwise returns pointers to slices of the records of each table
+func (stmt *Statement) partitionRecords() (groups []reflect.Value) {
+	if !reflect.PtrTo(stmt.Schema.ModelType).Implements(partitionedTablerType) {
+		return nil
+	}
+
+	// 更新时 Dest 可能是 map，使用 Model 中的记录
+	value := stmt.ReflectValue
+	if !value.IsValid() || value.Kind() == reflect.Map {
+		value = reflect.ValueOf(stmt.Model)
+	}
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	partitioner := reflect.New(stmt.Schema.ModelType).Interface().(schema.PartitionedTabler)
+	switch value.Kind() {
+	case reflect.Struct:
+		if value.Type() == stmt.Schema.ModelType {
+			stmt.useTable(partitioner.PartitionTable(stmt.Schema.Table, value))
+		}
+	case reflect.Slice, reflect.Array:
+		var tables []string
+		groupIndex := map[string]int{}
+		for i := 0; i < value.Len(); i++ {
+			record := value.Index(i)
+			for record.Kind() == reflect.Ptr && !record.IsNil() {
+				record = record.Elem()
+			}
+			if record.Kind() != reflect.Struct || record.Type() != stmt.Schema.ModelType {
+				return nil
+			}
+
+			table := partitioner.PartitionTable(stmt.Schema.Table, record)
+			idx, ok := groupIndex[table]
+			if !ok {
+				idx = len(groups)
+				groupIndex[table] = idx
+				tables = append(tables, table)
+				groups = append(groups, reflect.New(reflect.SliceOf(reflect.PtrTo(stmt.Schema.ModelType))))
+			}
+
+			if !record.CanAddr() {
+				copied := reflect.New(record.Type())
+				copied.Elem().Set(record)
+				record = copied.Elem()
+			}
+			groups[idx].Elem().Set(reflect.Append(groups[idx].Elem(), record.Addr()))
+		}
+
+		if len(tables) == 1 {
+			stmt.useTable(tables[0])
+			return nil
+		}
+	}
+	return groups
+}
+
+// LoadedColumns returns columns scanned into the model by the last query, E.g. for AfterFind hooks to detect partially loaded models
+// joined columns are excluded when scanning into the model's schema
+func (stmt *Statement) LoadedColumns() []string {
+	return stmt.loadedColumns
+}
+
+// mapColumns translates scanned columns with ColumnMapping, only the last segment of joined columns like `Company__name` is translated
+func (stmt *Statement) mapColumns(columns []string) []string {
+	if len(stmt.ColumnMapping) == 0 {
+		return columns
+	}
+
+	mapped := make([]string, len(columns))
+	for idx, column := range columns {
+		mapped[idx] = column
+		if name, ok := stmt.ColumnMapping[column]; ok {
+			mapped[idx] = name
+		} else if names := utils.SplitNestedRelationName(column); len(names) > 1 {
+			if name, ok := stmt.ColumnMapping[names[len(names)-1]]; ok {
+				names[len(names)-1] = name
+				mapped[idx] = utils.JoinNestedRelationNames(names)
+			}
+		}
+	}
+	return mapped
+}
+
+// CacheKey stable key of the built SQL, vars and connection for caching query results,
+// should be called after building SQL, e.g. in query callbacks registered after callbacks.BuildQueryCallback,
+// statements in transactions get keys of the transaction's connection
+func (stmt *Statement) CacheKey() string {
+	var conn interface{} = stmt.ConnPool
+	if connector, ok := conn.(GetDBConnector); ok && !stmt.inTransaction() {
+		if sqlDB, err := connector.GetDBConn(); err == nil && sqlDB != nil {
+			conn = sqlDB
+		}
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%T%p\x00%s", stmt.DB.Dialector.Name(), conn, conn, stmt.SQL.String())
+	for _, v := range stmt.Vars {
+		if valuer, ok := v.(driver.Valuer); ok {
+			v, _ = valuer.Value()
+		}
+		fmt.Fprintf(hash, "\x00%T:%v", v, v)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// RewriteSQL rewrites built SQL and vars with Config.QueryRewriter
+func (stmt *Statement) RewriteSQL() {
+	if stmt.DB.QueryRewriter == nil || stmt.SQL.Len() == 0 {
+		return
+	}
+
+	sql, vars := stmt.DB.QueryRewriter.Rewrite(stmt.Context, stmt.SQL.String(), stmt.Vars)
+	stmt.SQL.Reset()
+	stmt.SQL.WriteString(sql)
+	stmt.Vars = vars
+}
+
 func (stmt *Statement) clone() *Statement {
 	newStmt := &Statement{
 		TableExpr:            stmt.TableExpr,
 		Table:                stmt.Table,
+		TableSchema:          stmt.TableSchema,
+		modelTable:           stmt.modelTable,
 		Model:                stmt.Model,
 		Unscoped:             stmt.Unscoped,
+		unscopedQuery:        stmt.unscopedQuery,
 		Dest:                 stmt.Dest,
 		ReflectValue:         stmt.ReflectValue,
 		Clauses:              map[string]clause.Clause{},
@@ -532,6 +1130,14 @@ func (stmt *Statement) clone() *Statement {
 		Context:              stmt.Context,
 		RaiseErrorOnNotFound: stmt.RaiseErrorOnNotFound,
 		SkipHooks:            stmt.SkipHooks,
+		SkipPrepare:          stmt.SkipPrepare,
+		TablePrefix:          stmt.TablePrefix,
+		TableSuffix:          stmt.TableSuffix,
+		TxOptions:            stmt.TxOptions,
+		MapColumnKeys:        stmt.MapColumnKeys,
+		NoCache:              stmt.NoCache,
+		PreviousDest:         stmt.PreviousDest,
+		PreviousColumns:      stmt.PreviousColumns,
 	}
 
 	if stmt.SQL.Len() > 0 {
@@ -553,9 +1159,28 @@ func (stmt *Statement) clone() *Statement {
 		copy(newStmt.Joins, stmt.Joins)
 	}
 
+	if len(stmt.Hints) > 0 {
+		newStmt.Hints = make([]clause.Hint, len(stmt.Hints))
+		copy(newStmt.Hints, stmt.Hints)
+	}
+
+	if len(stmt.ColumnMapping) > 0 {
+		newStmt.ColumnMapping = make(map[string]string, len(stmt.ColumnMapping))
+		for k, v := range stmt.ColumnMapping {
+			newStmt.ColumnMapping[k] = v
+		}
+	}
+
 	if len(stmt.scopes) > 0 {
 		newStmt.scopes = make([]func(*DB) *DB, len(stmt.scopes))
 		copy(newStmt.scopes, stmt.scopes)
+		newStmt.scopeNames = make([]string, len(stmt.scopeNames))
+		copy(newStmt.scopeNames, stmt.scopeNames)
+	}
+
+	if len(stmt.withoutScopes) > 0 {
+		newStmt.withoutScopes = make([]string, len(stmt.withoutScopes))
+		copy(newStmt.withoutScopes, stmt.withoutScopes)
 	}
 
 	stmt.Settings.Range(func(k, v interface{}) bool {
@@ -571,58 +1196,217 @@ func (stmt *Statement) clone() *Statement {
 //	stmt.SetColumn("Name", "jinzhu") // Hooks Method
 //	stmt.SetColumn("Name", "jinzhu", true) // Callbacks Method
 func (stmt *Statement) SetColumn(name string, value interface{}, fromCallbacks ...bool) {
-	if v, ok := stmt.Dest.(map[string]interface{}); ok {
-		v[name] = value
-	} else if v, ok := stmt.Dest.([]map[string]interface{}); ok {
-		for _, m := range v {
-			m[name] = value
+	if maps, ok := stmt.destMaps(); ok {
+		for _, m := range maps {
+			m[stmt.mapColumnKey(m, name)] = value
 		}
 	} else if stmt.Schema != nil {
 		if field := stmt.Schema.LookUpField(name); field != nil {
-			destValue := reflect.ValueOf(stmt.Dest)
-			for destValue.Kind() == reflect.Ptr {
-				destValue = destValue.Elem()
+			stmt.setFields([]*schema.Field{field}, []interface{}{value}, len(fromCallbacks) > 0)
+		} else {
+			stmt.AddError(ErrInvalidField)
+		}
+	} else {
+		stmt.AddError(ErrInvalidField)
+	}
+}
+
+// SetColumns set values of columns like SetColumn, all names are checked before setting any of them,
+// unknown names are reported in one ErrInvalidField
+//
+//	stmt.SetColumns(map[string]interface{}{"Name": "jinzhu", "Age": 18}) // Hooks Method
+//	stmt.SetColumns(map[string]interface{}{"Name": "jinzhu", "Age": 18}, true) // Callbacks Method
+func (stmt *Statement) SetColumns(values map[string]interface{}, fromCallbacks ...bool) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	maps, isMap := stmt.destMaps()
+	if !isMap && stmt.Schema == nil {
+		stmt.AddError(ErrInvalidField)
+		return
+	}
+
+	var (
+		fields  = make([]*schema.Field, len(names))
+		vs      = make([]interface{}, len(names))
+		unknown []string
+	)
+	for idx, name := range names {
+		vs[idx] = values[name]
+		if stmt.Schema != nil {
+			if fields[idx] = stmt.Schema.LookUpField(name); fields[idx] == nil {
+				unknown = append(unknown, name)
 			}
+		}
+	}
 
-			if stmt.ReflectValue != destValue {
-				if !destValue.CanAddr() {
-					destValueCanAddr := reflect.New(destValue.Type())
-					destValueCanAddr.Elem().Set(destValue)
-					stmt.Dest = destValueCanAddr.Interface()
-					destValue = destValueCanAddr.Elem()
-				}
+	if len(unknown) > 0 {
+		stmt.AddError(fmt.Errorf("%w: %s", ErrInvalidField, strings.Join(unknown, ", ")))
+		return
+	}
 
-				switch destValue.Kind() {
-				case reflect.Struct:
-					stmt.AddError(field.Set(stmt.Context, destValue, value))
-				default:
-					stmt.AddError(ErrInvalidData)
-				}
+	if isMap {
+		for _, m := range maps {
+			for idx, name := range names {
+				m[stmt.mapColumnKey(m, name)] = vs[idx]
 			}
+		}
+		return
+	}
 
-			switch stmt.ReflectValue.Kind() {
-			case reflect.Slice, reflect.Array:
-				if len(fromCallbacks) > 0 {
-					for i := 0; i < stmt.ReflectValue.Len(); i++ {
-						stmt.AddError(field.Set(stmt.Context, stmt.ReflectValue.Index(i), value))
-					}
-				} else {
-					stmt.AddError(field.Set(stmt.Context, stmt.ReflectValue.Index(stmt.CurDestIndex), value))
-				}
-			case reflect.Struct:
-				if !stmt.ReflectValue.CanAddr() {
-					stmt.AddError(ErrInvalidValue)
-					return
-				}
+	stmt.setFields(fields, vs, len(fromCallbacks) > 0)
+}
 
-				stmt.AddError(field.Set(stmt.Context, stmt.ReflectValue, value))
+// setFields set values of fields to the destination and the model, all records of slices if fromCallbacks,
+// otherwise the record of CurDestIndex
+func (stmt *Statement) setFields(fields []*schema.Field, values []interface{}, fromCallbacks bool) {
+	set := func(rv reflect.Value) {
+		for idx, field := range fields {
+			stmt.AddError(field.Set(stmt.Context, rv, values[idx]))
+		}
+	}
+
+	destValue := reflect.ValueOf(stmt.Dest)
+	for destValue.Kind() == reflect.Ptr {
+		destValue = destValue.Elem()
+	}
+
+	if stmt.ReflectValue != destValue {
+		if !destValue.CanAddr() {
+			destValueCanAddr := reflect.New(destValue.Type())
+			destValueCanAddr.Elem().Set(destValue)
+			stmt.Dest = destValueCanAddr.Interface()
+			destValue = destValueCanAddr.Elem()
+		}
+
+		switch destValue.Kind() {
+		case reflect.Struct:
+			set(destValue)
+		default:
+			stmt.AddError(ErrInvalidData)
+		}
+	}
+
+	switch stmt.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		if fromCallbacks {
+			for i := 0; i < stmt.ReflectValue.Len(); i++ {
+				set(stmt.ReflectValue.Index(i))
 			}
 		} else {
-			stmt.AddError(ErrInvalidField)
+			set(stmt.ReflectValue.Index(stmt.CurDestIndex))
+		}
+	case reflect.Struct:
+		if !stmt.ReflectValue.CanAddr() {
+			stmt.AddError(ErrInvalidValue)
+			return
+		}
+
+		set(stmt.ReflectValue)
+	}
+}
+
+// ColumnValue returns the value of column to be written, from map destinations, or the struct destination (the record of
+// CurDestIndex for slices), ok is false if the column isn't in the map or the schema
+//
+//	if name, ok := stmt.ColumnValue("Name"); ok {}
+func (stmt *Statement) ColumnValue(name string) (value interface{}, ok bool) {
+	if maps, isMap := stmt.destMaps(); isMap {
+		if len(maps) == 0 {
+			return nil, false
+		}
+
+		m := maps[0]
+		if len(maps) > 1 && stmt.CurDestIndex < len(maps) {
+			m = maps[stmt.CurDestIndex]
+		}
+		value, ok = m[stmt.mapColumnKey(m, name)]
+		return
+	}
+
+	if stmt.Schema == nil {
+		return nil, false
+	}
+
+	field := stmt.Schema.LookUpField(name)
+	if field == nil {
+		return nil, false
+	}
+
+	destValue := reflect.ValueOf(stmt.Dest)
+	for destValue.Kind() == reflect.Ptr {
+		destValue = destValue.Elem()
+	}
+
+	switch destValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		if stmt.CurDestIndex >= destValue.Len() {
+			return nil, false
+		}
+		destValue = reflect.Indirect(destValue.Index(stmt.CurDestIndex))
+	}
+
+	if destValue.Kind() != reflect.Struct || destValue.Type() != stmt.Schema.ModelType {
+		return nil, false
+	}
+
+	value, _ = field.ValueOf(stmt.Context, destValue)
+	return value, true
+}
+
+// UnsetColumn remove column from map destinations, e.g. to skip a column when creating from map in hooks
+//
+//	stmt.UnsetColumn("Name")
+func (stmt *Statement) UnsetColumn(name string) {
+	if maps, ok := stmt.destMaps(); ok {
+		for _, m := range maps {
+			delete(m, stmt.mapColumnKey(m, name))
 		}
 	} else {
-		stmt.AddError(ErrInvalidField)
+		stmt.AddError(ErrInvalidData)
+	}
+}
+
+// destMaps returns the maps of map destinations
+func (stmt *Statement) destMaps() ([]map[string]interface{}, bool) {
+	switch v := stmt.Dest.(type) {
+	case map[string]interface{}:
+		return []map[string]interface{}{v}, true
+	case *map[string]interface{}:
+		return []map[string]interface{}{*v}, true
+	case []map[string]interface{}:
+		return v, true
+	case *[]map[string]interface{}:
+		return *v, true
+	}
+	return nil, false
+}
+
+// mapColumnKey returns the key of the column in the map, the existing key of the same field is preferred
+func (stmt *Statement) mapColumnKey(m map[string]interface{}, name string) string {
+	if _, ok := m[name]; ok || stmt.Schema == nil {
+		return name
 	}
+
+	if field := stmt.Schema.LookUpField(name); field != nil {
+		if _, ok := m[field.DBName]; ok {
+			return field.DBName
+		}
+
+		if _, ok := m[field.Name]; ok {
+			return field.Name
+		}
+	}
+	return name
+}
+
+// inTransaction reports whether the statement is executed in a transaction
+func (stmt *Statement) inTransaction() bool {
+	committer, ok := stmt.ConnPool.(TxCommitter)
+	return ok && committer != nil
 }
 
 // Changed check model changed or not when updating
@@ -639,9 +1423,9 @@ func (stmt *Statement) Changed(fields ...string) bool {
 		if v, ok := selectColumns[field.DBName]; (ok && v) || (!ok && !restricted) {
 			if mv, mok := stmt.Dest.(map[string]interface{}); mok {
 				if fv, ok := mv[field.Name]; ok {
-					return !utils.AssertEqual(fv, fieldValue)
+					return !fieldValueEqual(field, fv, fieldValue)
 				} else if fv, ok := mv[field.DBName]; ok {
-					return !utils.AssertEqual(fv, fieldValue)
+					return !fieldValueEqual(field, fv, fieldValue)
 				}
 			} else {
 				destValue := reflect.ValueOf(stmt.Dest)
@@ -651,9 +1435,9 @@ func (stmt *Statement) Changed(fields ...string) bool {
 
 				changedValue, zero := field.ValueOf(stmt.Context, destValue)
 				if v {
-					return !utils.AssertEqual(changedValue, fieldValue)
+					return !fieldValueEqual(field, changedValue, fieldValue)
 				}
-				return !zero && !utils.AssertEqual(changedValue, fieldValue)
+				return !zero && !fieldValueEqual(field, changedValue, fieldValue)
 			}
 		}
 		return false
@@ -678,6 +1462,84 @@ func (stmt *Statement) Changed(fields ...string) bool {
 	return false
 }
 
+// UpdatingValues returns the values to update keyed by column name when updating, including changes made with SetColumn,
+// map keys are converted to column names, zero fields of struct are skipped unless selected
+//
+//	func (user *User) BeforeUpdate(tx *gorm.DB) error {
+//		if role, ok := tx.Statement.UpdatingValues()["role"]; ok && role != "admin" && role != "member" {
+//			return errors.New("invalid role")
+//		}
+//		return nil
+//	}
+func (stmt *Statement) UpdatingValues() map[string]interface{} {
+	selectColumns, restricted := stmt.SelectAndOmitColumns(false, true)
+	selected := func(name string) (bool, bool) {
+		v, ok := selectColumns[name]
+		return (ok && v) || (!ok && !restricted), ok && v
+	}
+
+	values := map[string]interface{}{}
+	if maps, ok := stmt.destMaps(); ok {
+		if len(maps) != 1 {
+			return values
+		}
+
+		for key, value := range maps[0] {
+			name := key
+			if stmt.Schema != nil {
+				if field := stmt.Schema.LookUpField(key); field != nil && field.DBName != "" {
+					name = field.DBName
+				}
+			}
+
+			if ok, _ := selected(name); ok {
+				values[name] = value
+			}
+		}
+		return values
+	}
+
+	destValue := reflect.ValueOf(stmt.Dest)
+	for destValue.Kind() == reflect.Ptr {
+		destValue = destValue.Elem()
+	}
+
+	if stmt.Schema == nil || destValue.Kind() != reflect.Struct || destValue.Type() != stmt.Schema.ModelType {
+		return values
+	}
+
+	for _, dbName := range stmt.Schema.DBNames {
+		field := stmt.Schema.FieldsByDBName[dbName]
+		if field.PrimaryKey || !field.Updatable {
+			continue
+		}
+
+		if ok, isSelected := selected(dbName); ok {
+			if value, isZero := field.ValueOf(stmt.Context, destValue); isSelected || !isZero {
+				values[dbName] = value
+			}
+		}
+	}
+	return values
+}
+
+// fieldValueEqual compares values of field, times are compared in field's precision
+func fieldValueEqual(field *schema.Field, src, dst interface{}) bool {
+	if field.TimePrecision > 0 && utils.TimeEqual(src, dst, field.TimePrecision) {
+		return true
+	}
+
+	// decimal 按数值比较，如 "1.10" 和 "1.1" 相等
+	if field.GORMDataType == schema.Decimal {
+		if srcRat, ok := decimalRatOf(src); ok {
+			if dstRat, ok := decimalRatOf(dst); ok {
+				return srcRat.Cmp(dstRat) == 0
+			}
+		}
+	}
+	return utils.AssertEqual(src, dst)
+}
+
 var nameMatcher = regexp.MustCompile(`^(?:\W?(\w+?)\W?\.)?\W?(\w+?)\W?$`)
 
 // SelectAndOmitColumns get select and omit columns, select -> true, omit -> false
@@ -3,9 +3,12 @@ package gorm
 import (
 	"fmt"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
 	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
 )
 
 func TestWhereCloneCorruption(t *testing.T) {
@@ -62,3 +65,125 @@ func TestNameMatcher(t *testing.T) {
 		}
 	}
 }
+
+type quoteDialector struct {
+	Dialector
+}
+
+func (quoteDialector) QuoteTo(writer clause.Writer, str string) {
+	writer.WriteByte('"')
+	writer.WriteString(str)
+	writer.WriteByte('"')
+}
+
+type aliasDialector struct {
+	quoteDialector
+	tableKeyword, columnKeyword string
+}
+
+func (dialector aliasDialector) AliasKeyword(table bool) string {
+	if table {
+		return dialector.tableKeyword
+	}
+	return dialector.columnKeyword
+}
+
+func TestQuoteToAlias(t *testing.T) {
+	fields := []interface{}{
+		clause.Table{Name: "users", Alias: "order"},
+		clause.Column{Table: "u", Name: "name", Alias: "select"},
+		clause.Table{Name: "(SELECT 1)", Alias: "t", Raw: true},
+		clause.Column{Name: "count(*)", Alias: "total", Raw: true},
+		[]clause.Column{{Name: "id", Alias: "uid"}, {Name: "name"}},
+	}
+
+	for _, tt := range []struct {
+		dialector Dialector
+		expects   []string
+	}{
+		{
+			dialector: quoteDialector{},
+			expects: []string{
+				`"users" "order"`,
+				`"u"."name" AS "select"`,
+				`(SELECT 1) t`,
+				`count(*) AS total`,
+				`("id" AS "uid","name")`,
+			},
+		},
+		{
+			dialector: aliasDialector{tableKeyword: " AS ", columnKeyword: " "},
+			expects: []string{
+				`"users" AS "order"`,
+				`"u"."name" "select"`,
+				`(SELECT 1) AS t`,
+				`count(*) total`,
+				`("id" "uid","name")`,
+			},
+		},
+	} {
+		stmt := &Statement{DB: &DB{Config: &Config{Dialector: tt.dialector}}}
+		for idx, field := range fields {
+			var sql strings.Builder
+			stmt.QuoteTo(&sql, field)
+			if sql.String() != tt.expects[idx] {
+				t.Errorf("alias of %#v should be written as %v, got %v", field, tt.expects[idx], sql.String())
+			}
+		}
+	}
+}
+
+func TestBuildConditionPrimaryKeyString(t *testing.T) {
+	type intPrimaryKey struct {
+		ID   uint
+		Name string
+	}
+	type stringPrimaryKey struct {
+		Code   string `gorm:"primaryKey"`
+		Name   string
+		Active bool
+	}
+
+	parse := func(value interface{}) *schema.Schema {
+		s, err := schema.Parse(value, &sync.Map{}, schema.NamingStrategy{})
+		if err != nil {
+			t.Fatalf("failed to parse schema, got %v", err)
+		}
+		return s
+	}
+
+	primaryKey := func(value interface{}) []clause.Expression {
+		return []clause.Expression{clause.IN{Column: clause.PrimaryColumn, Values: []interface{}{value}}}
+	}
+
+	tests := []struct {
+		name   string
+		schema *schema.Schema
+		query  string
+		args   []interface{}
+		want   []clause.Expression
+	}{
+		{"int numeric", parse(&intPrimaryKey{}), "123", nil, primaryKey("123")},
+		{"int bare string", parse(&intPrimaryKey{}), "name IS NULL", nil, []clause.Expression{clause.Expr{SQL: "name IS NULL"}}},
+		{"int column", parse(&intPrimaryKey{}), "name", []interface{}{"jinzhu"}, []clause.Expression{clause.Eq{Column: "name", Value: "jinzhu"}}},
+		{"string numeric", parse(&stringPrimaryKey{}), "123", nil, primaryKey("123")},
+		{"string uuid", parse(&stringPrimaryKey{}), "550e8400-e29b-41d4-a716-446655440000", nil, primaryKey("550e8400-e29b-41d4-a716-446655440000")},
+		{"string condition", parse(&stringPrimaryKey{}), "name IS NULL", nil, []clause.Expression{clause.Expr{SQL: "name IS NULL"}}},
+		{"string operator", parse(&stringPrimaryKey{}), "name<>code", nil, []clause.Expression{clause.Expr{SQL: "name<>code"}}},
+		{"string column", parse(&stringPrimaryKey{}), "name", []interface{}{"jinzhu"}, []clause.Expression{clause.Eq{Column: "name", Value: "jinzhu"}}},
+		{"string bare column", parse(&stringPrimaryKey{}), "active", nil, []clause.Expression{clause.Expr{SQL: "active"}}},
+		{"string bare field name", parse(&stringPrimaryKey{}), "Active", nil, []clause.Expression{clause.Expr{SQL: "Active"}}},
+		{"string qualified column", parse(&stringPrimaryKey{}), "string_primary_keys.active", nil, []clause.Expression{clause.Expr{SQL: "string_primary_keys.active"}}},
+		{"schemaless numeric", nil, "123", nil, primaryKey("123")},
+		{"schemaless uuid", nil, "550e8400-e29b-41d4-a716-446655440000", nil, []clause.Expression{clause.Expr{SQL: "550e8400-e29b-41d4-a716-446655440000"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt := &Statement{DB: &DB{Config: &Config{cacheStore: &sync.Map{}, NamingStrategy: schema.NamingStrategy{}}}, Schema: tt.schema}
+			if got := stmt.BuildCondition(tt.query, tt.args...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expects %#v, got %#v", tt.want, got)
+			}
+		})
+	}
+}
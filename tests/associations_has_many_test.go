@@ -1,6 +1,9 @@
 package tests_test
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"testing"
 
 	"gorm.io/gorm"
@@ -545,3 +548,108 @@ func TestHasManyAssociationUnscoped(t *testing.T) {
 		t.Errorf("expected %d contents, got %d", 0, len(contents))
 	}
 }
+
+type AppendBatchOwner struct {
+	ID    uint
+	Name  string
+	Items []AppendBatchItem
+}
+
+type AppendBatchItem struct {
+	ID                 uint
+	AppendBatchOwnerID uint
+	Name               string
+	BeforeCreateCalled bool `gorm:"-"`
+}
+
+func (item *AppendBatchItem) BeforeCreate(*gorm.DB) error {
+	item.BeforeCreateCalled = true
+	return nil
+}
+
+func TestHasManyAssociationAppendInBatches(t *testing.T) {
+	DB.Migrator().DropTable(&AppendBatchOwner{}, &AppendBatchItem{})
+	if err := DB.AutoMigrate(&AppendBatchOwner{}, &AppendBatchItem{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	owner := AppendBatchOwner{Name: "append_in_batches", Items: []AppendBatchItem{{Name: "existing_1"}, {Name: "existing_2"}}}
+	DB.Create(&owner)
+
+	sqlDB, err := DB.DB()
+	if err != nil {
+		t.Fatalf("failed to get sql.DB, got %v", err)
+	}
+
+	countInserts := func(conn *wrapperConnPool) (count int) {
+		for _, sql := range conn.got {
+			if strings.HasPrefix(sql, "INSERT INTO") {
+				count++
+			}
+		}
+		return
+	}
+
+	items := make([]AppendBatchItem, 0, 501)
+	for i := 0; i < 500; i++ {
+		items = append(items, AppendBatchItem{Name: fmt.Sprintf("append_in_batches_%v", i)})
+	}
+	items = append(items, owner.Items[0])
+
+	for _, batchSize := range []int{0, 100} {
+		t.Run(fmt.Sprintf("CreateBatchSize=%v", batchSize), func(t *testing.T) {
+			DB.Where("append_batch_owner_id = ? AND name LIKE ?", owner.ID, "append_in_batches_%").Delete(&AppendBatchItem{})
+			values := make([]AppendBatchItem, len(items))
+			copy(values, items)
+
+			conn := &wrapperConnPool{db: sqlDB}
+			tx := DB.Session(&gorm.Session{NewDB: true, Context: context.Background(), CreateBatchSize: batchSize})
+			tx.Statement.ConnPool = conn
+
+			var result AppendBatchOwner
+			DB.First(&result, owner.ID)
+			if err := tx.Model(&result).Association("Items").Append(values); err != nil {
+				t.Fatalf("failed to append items, got %v", err)
+			}
+
+			expects := 1
+			if batchSize > 0 {
+				expects = (len(values) + batchSize - 1) / batchSize
+			}
+			if count := countInserts(conn); count != expects {
+				t.Errorf("should insert items with %v statements, got %v", expects, count)
+			}
+
+			for _, value := range values[:500] {
+				if value.ID == 0 || value.AppendBatchOwnerID != owner.ID || !value.BeforeCreateCalled {
+					t.Fatalf("appended item should be created with hooks and foreign key, got %+v", value)
+				}
+			}
+
+			if count := DB.Model(&owner).Association("Items").Count(); count != 502 {
+				t.Errorf("owner should have 502 items, got %v", count)
+			}
+		})
+	}
+
+	conn := &wrapperConnPool{db: sqlDB}
+	tx := DB.Session(&gorm.Session{NewDB: true, Context: context.Background(), CreateBatchSize: 100})
+	tx.Statement.ConnPool = conn
+
+	values := make([]AppendBatchItem, 300)
+	for i := range values {
+		values[i] = AppendBatchItem{Name: fmt.Sprintf("replace_in_batches_%v", i)}
+	}
+
+	if err := tx.Model(&owner).Association("Items").Replace(values); err != nil {
+		t.Fatalf("failed to replace items, got %v", err)
+	}
+
+	if count := countInserts(conn); count != 3 {
+		t.Errorf("should insert items with 3 statements, got %v", count)
+	}
+
+	if count := DB.Model(&owner).Association("Items").Count(); count != 300 {
+		t.Errorf("owner should have 300 items, got %v", count)
+	}
+}
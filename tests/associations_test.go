@@ -1,9 +1,11 @@
 package tests_test
 
 import (
+	"errors"
 	"testing"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/schema"
 	. "gorm.io/gorm/utils/tests"
@@ -394,3 +396,238 @@ func TestAssociationEmptyPrimaryKey(t *testing.T) {
 
 	AssertEqual(t, result, user)
 }
+
+func TestAssociationResults(t *testing.T) {
+	user := GetUser("association_results", Config{Company: true, Manager: true, Account: true, Pets: 3})
+
+	result := DB.Session(&gorm.Session{FullSaveAssociations: true}).Save(user)
+	if result.Error != nil {
+		t.Fatalf("failed to save user, got error: %v", result.Error)
+	}
+
+	if result.RowsAffected != 1 {
+		t.Errorf("top-level rows affected should not include associations, got %v", result.RowsAffected)
+	}
+
+	v, ok := result.InstanceGet(callbacks.AssociationResultsKey)
+	if !ok {
+		t.Fatalf("failed to get association results")
+	}
+
+	results, ok := v.([]callbacks.AssociationResult)
+	if !ok || len(results) != 4 {
+		t.Fatalf("should have 4 association results, got %#v", v)
+	}
+
+	// belongs to 关联在保存 user 之前保存，has one、has many 关联在之后保存
+	expects := []struct {
+		Relation     string
+		RowsAffected int64
+	}{{"Company", 1}, {"Manager", 1}, {"Account", 1}, {"Pets", 3}}
+
+	var rowsAffected int64
+	for idx, r := range results {
+		if r.Error != nil {
+			t.Errorf("association %v should not have error, got %v", r.Relation, r.Error)
+		}
+
+		if r.Relation != expects[idx].Relation || r.RowsAffected != expects[idx].RowsAffected {
+			t.Errorf("invalid association result #%d, expects %+v, got %#v", idx, expects[idx], r)
+		}
+		rowsAffected += r.RowsAffected
+	}
+
+	if rowsAffected != 6 {
+		t.Errorf("should save 6 association records, got %v", rowsAffected)
+	}
+}
+
+func TestSaveAssociationsWithNestedColumns(t *testing.T) {
+	user := GetUser("save_associations_nested_columns", Config{Pets: 2})
+	user.Pets[0].Toy = Toy{Name: "save_associations_nested_columns_toy"}
+
+	if err := DB.Omit("Pets.Name", "Pets.Toy.Name").Create(user).Error; err != nil {
+		t.Fatalf("failed to create user, got error: %v", err)
+	}
+
+	var pets []Pet
+	DB.Preload("Toy").Order("id").Find(&pets, "user_id = ?", user.ID)
+	if len(pets) != 2 {
+		t.Fatalf("pets should be saved with foreign key, got %v", len(pets))
+	}
+
+	for _, pet := range pets {
+		if pet.Name != "" {
+			t.Errorf("pet name should be omitted, got %v", pet.Name)
+		}
+	}
+
+	if pets[0].Toy.ID == 0 || pets[0].Toy.Name != "" {
+		t.Errorf("toy should be saved with name omitted, got %+v", pets[0].Toy)
+	}
+
+	user.Pets[0].Name = "save_associations_nested_columns_pet"
+	user.Pets[0].Toy.Name = "save_associations_nested_columns_toy_changed"
+	if err := DB.Session(&gorm.Session{FullSaveAssociations: true}).Omit("Pets.Toy.Name").Save(user).Error; err != nil {
+		t.Fatalf("failed to save user, got error: %v", err)
+	}
+
+	var pet Pet
+	DB.Preload("Toy").First(&pet, user.Pets[0].ID)
+	if pet.Name != "save_associations_nested_columns_pet" || pet.Toy.Name != "" {
+		t.Errorf("pet name should be updated and toy name should be omitted, got %v, %v", pet.Name, pet.Toy.Name)
+	}
+
+	user3 := GetUser("save_associations_nested_columns_omit_fk", Config{Pets: 1})
+	if err := DB.Omit("Pets.UserID").Create(user3).Error; !errors.Is(err, gorm.ErrInvalidField) {
+		t.Errorf("omitting foreign key of association should return ErrInvalidField, got %v", err)
+	}
+
+	var count int64
+	DB.Model(&User{}).Where("name = ?", user3.Name).Count(&count)
+	if count != 0 {
+		t.Errorf("user should not be created when failed to save associations, got %v", count)
+	}
+
+	user2 := GetUser("save_associations_nested_columns_select", Config{Pets: 1})
+	if err := DB.Select("*", "Pets.Name").Create(user2).Error; err != nil {
+		t.Fatalf("failed to create user, got error: %v", err)
+	}
+
+	var pet2 Pet
+	if err := DB.First(&pet2, "user_id = ?", user2.ID).Error; err != nil || pet2.Name != user2.Pets[0].Name {
+		t.Errorf("selected pet should be saved with foreign key, got error %v, name %v", err, pet2.Name)
+	}
+}
+
+type CompositeOrg struct {
+	Region  string `gorm:"primaryKey;size:32"`
+	Code    int    `gorm:"primaryKey;autoIncrement:false"`
+	Name    string
+	Members []CompositeMember `gorm:"foreignKey:OrgRegion,OrgCode;references:Region,Code"`
+}
+
+type CompositeMember struct {
+	ID        uint
+	Name      string
+	OrgRegion string `gorm:"size:32"`
+	OrgCode   int
+	Org       CompositeOrg `gorm:"foreignKey:OrgRegion,OrgCode;references:Region,Code"`
+}
+
+func TestCompositeForeignKeyAssociations(t *testing.T) {
+	DB.Migrator().DropTable(&CompositeMember{}, &CompositeOrg{})
+	if err := DB.AutoMigrate(&CompositeOrg{}, &CompositeMember{}); err != nil {
+		t.Fatalf("failed to migrate, got error: %v", err)
+	}
+
+	if !DB.Migrator().HasConstraint(&CompositeOrg{}, "Members") {
+		t.Errorf("composite foreign key constraint should be created")
+	}
+
+	orgs := []CompositeOrg{
+		{Region: "eu", Code: 1, Name: "eu-1", Members: []CompositeMember{{Name: "alice"}, {Name: "bob"}}},
+		{Region: "us", Code: 1, Name: "us-1", Members: []CompositeMember{{Name: "carol"}}},
+	}
+	if err := DB.Create(&orgs).Error; err != nil {
+		t.Fatalf("failed to create orgs, got error: %v", err)
+	}
+
+	var result []CompositeOrg
+	if err := DB.Preload("Members").Order("region").Find(&result).Error; err != nil {
+		t.Fatalf("failed to preload members, got error: %v", err)
+	}
+	if len(result) != 2 || len(result[0].Members) != 2 || len(result[1].Members) != 1 {
+		t.Fatalf("members should be preloaded by both keys, got %+v", result)
+	}
+
+	var member CompositeMember
+	if err := DB.Preload("Org").First(&member, "name = ?", "carol").Error; err != nil {
+		t.Fatalf("failed to preload org, got error: %v", err)
+	}
+	if member.Org.Name != "us-1" {
+		t.Errorf("org should be preloaded by both keys, got %+v", member.Org)
+	}
+
+	var joined CompositeMember
+	if err := DB.Joins("Org").First(&joined, "composite_members.name = ?", "alice").Error; err != nil {
+		t.Fatalf("failed to join org, got error: %v", err)
+	}
+	if joined.Org.Name != "eu-1" {
+		t.Errorf("org should be joined by both keys, got %+v", joined.Org)
+	}
+}
+
+func TestDisableAssociationSaveOnUpdate(t *testing.T) {
+	disabled, enabled := true, false
+	user := *GetUser("disable-association-save-on-update", Config{Company: true})
+	if err := DB.Session(&gorm.Session{DisableAssociationSaveOnUpdate: &disabled}).Create(&user).Error; err != nil {
+		t.Fatalf("errors happened when create: %v", err)
+	}
+
+	if user.Company.ID == 0 || user.CompanyID == nil || *user.CompanyID != user.Company.ID {
+		t.Fatalf("associations should be saved when creating, got %+v", user.Company)
+	}
+
+	tx := DB.Session(&gorm.Session{DisableAssociationSaveOnUpdate: &disabled})
+
+	user.Name = "disable-association-save-on-update-new"
+	user.Company = Company{Name: "disable-association-save-on-update-company"}
+	if err := tx.Save(&user).Error; err != nil {
+		t.Fatalf("errors happened when save: %v", err)
+	}
+
+	if user.Company.ID != 0 {
+		t.Errorf("associations should not be saved when updating, got %+v", user.Company)
+	}
+
+	var count int64
+	DB.Model(&Company{}).Where("name = ?", user.Company.Name).Count(&count)
+	if count != 0 {
+		t.Errorf("associations should not be saved when updating, got count %v", count)
+	}
+
+	var result User
+	DB.First(&result, user.ID)
+	if result.Name != user.Name {
+		t.Errorf("columns should be updated, got %v", result.Name)
+	}
+
+	if err := tx.Select("*", "Company").Save(&user).Error; err != nil {
+		t.Fatalf("errors happened when save: %v", err)
+	}
+
+	if user.Company.ID == 0 || *user.CompanyID != user.Company.ID {
+		t.Errorf("selected associations should be saved when updating, got %+v", user.Company)
+	}
+
+	user.Company.Name = "disable-association-save-on-update-company-new"
+	if err := tx.Session(&gorm.Session{FullSaveAssociations: true}).Save(&user).Error; err != nil {
+		t.Fatalf("errors happened when save: %v", err)
+	}
+
+	var company Company
+	DB.First(&company, user.Company.ID)
+	if company.Name != user.Company.Name {
+		t.Errorf("associations should be saved with FullSaveAssociations, got %+v", company)
+	}
+
+	// 会话中可以重新开启关联的保存
+	user.Company = Company{Name: "disable-association-save-on-update-company-enabled"}
+	if err := tx.Session(&gorm.Session{DisableAssociationSaveOnUpdate: &enabled}).Save(&user).Error; err != nil {
+		t.Fatalf("errors happened when save: %v", err)
+	}
+
+	if user.Company.ID == 0 || *user.CompanyID != user.Company.ID {
+		t.Errorf("associations should be saved when enabled in session, got %+v", user.Company)
+	}
+
+	if err := tx.Session(&gorm.Session{}).Save(&User{Model: user.Model, Name: user.Name, Company: Company{Name: "disable-association-save-on-update-company-inherited"}}).Error; err != nil {
+		t.Fatalf("errors happened when save: %v", err)
+	}
+
+	DB.Model(&Company{}).Where("name = ?", "disable-association-save-on-update-company-inherited").Count(&count)
+	if count != 0 {
+		t.Errorf("nil DisableAssociationSaveOnUpdate should inherit the setting, got count %v", count)
+	}
+}
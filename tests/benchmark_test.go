@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"gorm.io/gorm"
 	. "gorm.io/gorm/utils/tests"
 )
 
@@ -82,3 +83,35 @@ func BenchmarkDelete(b *testing.B) {
 		DB.Delete(&user)
 	}
 }
+
+func BenchmarkAppendHasMany(b *testing.B) {
+	user := *GetUser("append_has_many", Config{})
+	DB.Create(&user)
+
+	pets := make([]Pet, 100)
+	for x := 0; x < b.N; x++ {
+		for i := range pets {
+			pets[i] = Pet{Name: fmt.Sprintf("append_has_many_%v", i)}
+		}
+		user.Pets = nil
+		DB.Model(&user).Association("Pets").Append(pets)
+	}
+}
+
+func BenchmarkCreateStreamedInsert(b *testing.B) {
+	items := make([]StreamedItem, 100000)
+	for i := range items {
+		items[i] = StreamedItem{Name: fmt.Sprintf("streamed_insert_%v", i), Code: fmt.Sprint(i)}
+	}
+
+	for _, streamed := range []bool{false, true} {
+		db := DB.Session(&gorm.Session{DryRun: true, StreamedInsert: streamed})
+
+		b.Run(fmt.Sprintf("Create100k/streamed=%v", streamed), func(b *testing.B) {
+			b.ReportAllocs()
+			for x := 0; x < b.N; x++ {
+				db.Create(&items)
+			}
+		})
+	}
+}
@@ -3,11 +3,15 @@ package tests_test
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 	"runtime"
 	"strings"
 	"testing"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/utils"
+	. "gorm.io/gorm/utils/tests"
 )
 
 func assertCallbacks(v interface{}, fnames []string) (result bool, msg string) {
@@ -206,3 +210,153 @@ func TestPluginCallbacks(t *testing.T) {
 		t.Errorf("callbacks tests failed, got %v", msg)
 	}
 }
+
+type qualify struct {
+	SQL string
+}
+
+func (q qualify) Name() string {
+	return "QUALIFY"
+}
+
+func (q qualify) Build(builder clause.Builder) {
+	builder.WriteString(q.SQL)
+}
+
+func (q qualify) MergeClause(c *clause.Clause) {
+	c.Expression = q
+}
+
+type hint struct {
+	SQL string
+}
+
+func (h hint) Name() string {
+	return "HINT"
+}
+
+func (h hint) Build(builder clause.Builder) {
+	builder.WriteString(h.SQL)
+}
+
+func (h hint) MergeClause(c *clause.Clause) {
+	c.Expression = h
+}
+
+func TestCallbackClauses(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+
+	db.Callback().Query().ClauseAfter("WHERE", "QUALIFY")
+	if clauses := db.Callback().Query().BuildClauses(); !reflect.DeepEqual(clauses[:4], []string{"SELECT", "FROM", "WHERE", "QUALIFY"}) {
+		t.Fatalf("QUALIFY should be placed after WHERE, got %v", clauses)
+	}
+
+	dryRunDB := db.Session(&gorm.Session{DryRun: true})
+	stmt := dryRunDB.Clauses(qualify{SQL: "ROW_NUMBER() OVER (PARTITION BY name ORDER BY age) = 1"}).
+		Where("age > ?", 10).Order("id").Find(&[]User{}).Statement
+	if !regexp.MustCompile(`WHERE age > .+ QUALIFY ROW_NUMBER\(\) OVER \(PARTITION BY name ORDER BY age\) = 1 ORDER BY id$`).MatchString(stmt.SQL.String()) {
+		t.Errorf("QUALIFY should be built between WHERE and ORDER BY, got %v", stmt.SQL.String())
+	}
+
+	// declared clauses are kept when resetting processor's clauses
+	db.Callback().Query().Clauses = []string{"SELECT", "FROM", "WHERE", "GROUP BY", "ORDER BY", "LIMIT", "FOR"}
+	db.Callback().Query().RegisterClause("HINT", "SELECT", func(c clause.Clause, builder clause.Builder) {
+		builder.WriteString("/*+ ")
+		c.Expression.Build(builder)
+		builder.WriteString(" */")
+	})
+
+	stmt = dryRunDB.Clauses(
+		hint{SQL: "NO_INDEX(users)"},
+		qualify{SQL: "age = 1"},
+	).Where("name = ?", "jinzhu").Find(&[]User{}).Statement
+	if !regexp.MustCompile(`^SELECT \* /\*\+ NO_INDEX\(users\) \*/ FROM .users. WHERE name = .+ QUALIFY age = 1`).MatchString(stmt.SQL.String()) {
+		t.Errorf("declared clauses should be built in order, got %v", stmt.SQL.String())
+	}
+
+	if clauses := DB.Callback().Query().BuildClauses(); utils.Contains(clauses, "QUALIFY") {
+		t.Errorf("declared clauses shouldn't affect other db, got %v", clauses)
+	}
+}
+
+func TestInspectCallbacks(t *testing.T) {
+	db, _ := gorm.Open(nil, nil)
+	queryCallback := db.Callback().Query()
+
+	queryCallback.Register("gorm:query", c1)
+	queryCallback.After("gorm:query").Register("gorm:preload", c2)
+	queryCallback.After("gorm:preload").Register("gorm:after_query", c3)
+
+	// plugin
+	queryCallback.Before("gorm:query").Register("plugin:before_query", c4)
+	queryCallback.After("gorm:preload").Before("gorm:after_query").Register("plugin:after_preload", c5)
+	queryCallback.Match(func(*gorm.DB) bool { return false }).Register("plugin:disabled", c6)
+
+	expects := []gorm.CallbackInfo{
+		{Name: "plugin:before_query", Before: "gorm:query"},
+		{Name: "gorm:query"},
+		{Name: "gorm:preload", After: "gorm:query"},
+		{Name: "plugin:after_preload", Before: "gorm:after_query", After: "gorm:preload"},
+		{Name: "gorm:after_query", After: "gorm:preload"},
+		{Name: "plugin:disabled", Filtered: true},
+	}
+	AssertEqual(t, db.Callback().Inspect()["query"], expects)
+
+	if ok, msg := assertCallbacks(queryCallback, []string{"c4", "c1", "c2", "c5", "c3"}); !ok {
+		t.Errorf("inspected order should be the execution order, got %v", msg)
+	}
+
+	queryCallback.Remove("plugin:before_query")
+	queryCallback.Replace("gorm:preload", c6)
+	AssertEqual(t, queryCallback.Inspect(), []gorm.CallbackInfo{
+		{Name: "gorm:query"},
+		{Name: "gorm:preload"},
+		{Name: "plugin:after_preload", Before: "gorm:after_query", After: "gorm:preload"},
+		{Name: "gorm:after_query", After: "gorm:preload"},
+		{Name: "plugin:disabled", Filtered: true},
+	})
+
+	if ok, msg := assertCallbacks(queryCallback, []string{"c1", "c6", "c5", "c3"}); !ok {
+		t.Errorf("inspected order should be the execution order, got %v", msg)
+	}
+
+	if len(db.Callback().Inspect()["create"]) != 0 {
+		t.Errorf("create callbacks should be empty, got %v", db.Callback().Inspect()["create"])
+	}
+}
+
+func TestTraceCallbacks(t *testing.T) {
+	var users []User
+	result := DB.Session(&gorm.Session{TraceCallbacks: true}).Where("name = ?", "trace_callbacks").Find(&users)
+	if result.Error != nil {
+		t.Fatalf("failed to query, got %v", result.Error)
+	}
+
+	v, ok := result.InstanceGet(gorm.CallbackTimingsKey)
+	if !ok {
+		t.Fatalf("callback timings should be recorded")
+	}
+
+	var names []string
+	for _, timing := range v.([]gorm.CallbackTiming) {
+		if timing.Duration < 0 {
+			t.Errorf("duration of %v should not be negative", timing.Name)
+		}
+		names = append(names, timing.Name)
+	}
+
+	var expects []string
+	for _, info := range DB.Callback().Query().Inspect() {
+		if !info.Filtered {
+			expects = append(expects, info.Name)
+		}
+	}
+	AssertEqual(t, names, expects)
+
+	if _, ok := DB.Where("name = ?", "trace_callbacks").Find(&users).InstanceGet(gorm.CallbackTimingsKey); ok {
+		t.Errorf("callback timings should not be recorded without TraceCallbacks")
+	}
+}
@@ -2,6 +2,7 @@ package tests_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"gorm.io/driver/mysql"
@@ -36,6 +37,45 @@ func TestWithSingleConnection(t *testing.T) {
 	}
 }
 
+func TestConnInitSQL(t *testing.T) {
+	if DB.Dialector.Name() != "sqlite" {
+		t.Skip()
+	}
+
+	db := DB.Session(&gorm.Session{})
+	db.ConnInitSQL = []string{"PRAGMA cache_size = -3210"}
+
+	var cacheSize int
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return tx.Raw("PRAGMA cache_size").Scan(&cacheSize).Error
+	}); err != nil || cacheSize != -3210 {
+		t.Errorf("conn init sql should be executed in transaction, got %v, error %v", cacheSize, err)
+	}
+
+	cacheSize = 0
+	tx := db.Begin()
+	if err := tx.Raw("PRAGMA cache_size").Scan(&cacheSize).Error; err != nil || cacheSize != -3210 {
+		t.Errorf("conn init sql should be executed when beginning transaction, got %v, error %v", cacheSize, err)
+	}
+	tx.Rollback()
+
+	cacheSize = 0
+	if err := db.Connection(func(tx *gorm.DB) error {
+		return tx.Raw("PRAGMA cache_size").Scan(&cacheSize).Error
+	}); err != nil || cacheSize != -3210 {
+		t.Errorf("conn init sql should be executed in connection, got %v, error %v", cacheSize, err)
+	}
+
+	db.ConnInitSQL = []string{"PRAGMA cache_size = -3210", "SET invalid_variable = 1"}
+	if err := db.Transaction(func(tx *gorm.DB) error { return nil }); err == nil || !strings.Contains(err.Error(), "SET invalid_variable") {
+		t.Errorf("should fail to begin transaction when conn init sql failed, got %v", err)
+	}
+
+	if err := db.Connection(func(tx *gorm.DB) error { return nil }); err == nil {
+		t.Errorf("should fail to get connection when conn init sql failed")
+	}
+}
+
 func getSetSQL(driverName string) (string, string) {
 	switch driverName {
 	case mysql.Dialector{}.Name():
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 
 	"gorm.io/driver/mysql"
@@ -171,3 +172,111 @@ func TestConnPoolWrapper(t *testing.T) {
 		t.Fatalf("Should be able to find committed record, but got %v", err)
 	}
 }
+
+type traceIDKey struct{}
+
+type traceCommentRewriter struct{}
+
+func (traceCommentRewriter) Rewrite(ctx context.Context, sql string, vars []interface{}) (string, []interface{}) {
+	if traceID, ok := ctx.Value(traceIDKey{}).(string); ok {
+		return "/* trace_id=" + traceID + " */ " + sql, vars
+	}
+	return sql, vars
+}
+
+type recordingConnPool struct {
+	gorm.ConnPool
+	got []string
+}
+
+func (c *recordingConnPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	c.got = append(c.got, query)
+	return c.ConnPool.ExecContext(ctx, query, args...)
+}
+
+func (c *recordingConnPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	c.got = append(c.got, query)
+	return c.ConnPool.QueryContext(ctx, query, args...)
+}
+
+func (c *recordingConnPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	c.got = append(c.got, query)
+	return c.ConnPool.QueryRowContext(ctx, query, args...)
+}
+
+func TestQueryRewriter(t *testing.T) {
+	const comment = "/* trace_id=rewriter */ "
+
+	ctx := context.WithValue(context.Background(), traceIDKey{}, "rewriter")
+	tx := DB.Session(&gorm.Session{Context: ctx, SkipDefaultTransaction: true})
+	tx.Config.QueryRewriter = traceCommentRewriter{}
+
+	conn := &recordingConnPool{ConnPool: tx.Statement.ConnPool}
+	tx.Statement.ConnPool = conn
+
+	user := *GetUser("query_rewriter", Config{})
+	if err := tx.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user, got error %v", err)
+	}
+
+	var result User
+	if err := tx.First(&result, "name = ?", user.Name).Error; err != nil {
+		t.Fatalf("failed to query user, got error %v", err)
+	}
+
+	if err := tx.Model(&result).Update("age", 20).Error; err != nil {
+		t.Fatalf("failed to update user, got error %v", err)
+	}
+
+	var count int64
+	if err := tx.Raw("SELECT count(*) FROM users WHERE name = ?", user.Name).Scan(&count).Error; err != nil || count != 1 {
+		t.Errorf("should find one user, got %v, error %v", count, err)
+	}
+
+	if err := tx.Delete(&result).Error; err != nil {
+		t.Fatalf("failed to delete user, got error %v", err)
+	}
+
+	if len(conn.got) != 5 {
+		t.Fatalf("should execute 5 statements, got %v", conn.got)
+	}
+
+	for _, sql := range conn.got {
+		if !strings.HasPrefix(sql, comment) || strings.Count(sql, comment) != 1 {
+			t.Errorf("executed sql should be rewritten, got %v", sql)
+		}
+	}
+
+	stmt := tx.Session(&gorm.Session{DryRun: true}).First(&User{}).Statement
+	if !strings.HasPrefix(stmt.SQL.String(), comment) {
+		t.Errorf("dry run sql should be rewritten, got %v", stmt.SQL.String())
+	}
+
+	if sql := tx.ToSQL(func(tx *gorm.DB) *gorm.DB { return tx.Find(&[]User{}) }); !strings.HasPrefix(sql, comment) {
+		t.Errorf("ToSQL should be rewritten, got %v", sql)
+	}
+
+	ptx := DB.Session(&gorm.Session{Context: ctx, PrepareStmt: true})
+	ptx.Config.QueryRewriter = traceCommentRewriter{}
+	var prepared User
+	if err := ptx.Unscoped().First(&prepared, "name = ?", user.Name).Error; err != nil {
+		t.Fatalf("failed to query user with prepared statement, got error %v", err)
+	}
+
+	pdb, ok := ptx.ConnPool.(*gorm.PreparedStmtDB)
+	if !ok {
+		t.Fatalf("should use prepared statement")
+	}
+
+	pdb.Mux.Lock()
+	defer pdb.Mux.Unlock()
+	for query := range pdb.Stmts {
+		if strings.Contains(query, "query_rewriter") || (strings.Contains(query, "name = ?") && !strings.HasPrefix(query, comment)) {
+			t.Errorf("prepared statement should be keyed by the rewritten sql, got %v", query)
+		}
+	}
+
+	if _, ok := pdb.Stmts[comment+"SELECT * FROM `users` WHERE name = ? ORDER BY `users`.`id` LIMIT 1"]; !ok && DB.Dialector.Name() != "postgres" && DB.Dialector.Name() != "sqlserver" {
+		t.Errorf("prepared statement should be keyed by the rewritten sql, got %v", pdb.Stmts)
+	}
+}
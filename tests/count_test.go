@@ -189,3 +189,80 @@ func TestCount(t *testing.T) {
 		t.Errorf("no error should raise when using count with preload, but got %v", err)
 	}
 }
+
+func TestCountWithoutEagerLoading(t *testing.T) {
+	user := *GetUser("count_without_eager_loading", Config{Company: true, Manager: true, Pets: 2})
+	DB.Create(&user)
+
+	var count int64
+	if err := DB.Model(&User{}).Where("users.name = ?", user.Name).Order("name").Preload("Pets").Joins("Company").Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("count with preload and joins, got error: %v, count %v", err, count)
+	}
+
+	if err := DB.Model(&User{}).Joins("Company").Where("Company.name = ?", user.Company.Name).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("count with joins referenced by conditions, got error: %v, count %v", err, count)
+	}
+
+	dryDB := DB.Session(&gorm.Session{DryRun: true})
+	tests := []struct {
+		name    string
+		query   func(tx *gorm.DB) *gorm.DB
+		hasJoin bool
+	}{
+		{"eager loading", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("name").Preload("Pets").Joins("Company").Joins("Manager.Company")
+		}, false},
+		{"referenced by where", func(tx *gorm.DB) *gorm.DB {
+			return tx.Joins("Company").Where("Company.name = ?", "jinzhu")
+		}, true},
+		{"referenced by nested join", func(tx *gorm.DB) *gorm.DB {
+			return tx.Joins("Manager.Company").Where(map[string]interface{}{"Manager__Company.name": "jinzhu"})
+		}, true},
+		{"column containing relation name", func(tx *gorm.DB) *gorm.DB {
+			return tx.Joins("Company").Joins("Manager").Where("CompanyName = ? AND Managers > ?", "jinzhu", 1)
+		}, false},
+		{"referenced by select", func(tx *gorm.DB) *gorm.DB {
+			return tx.Joins("Company").Select("Company.name")
+		}, true},
+		{"inner joins", func(tx *gorm.DB) *gorm.DB {
+			return tx.InnerJoins("Company")
+		}, true},
+		{"raw joins", func(tx *gorm.DB) *gorm.DB {
+			return tx.Joins("LEFT JOIN pets ON pets.user_id = users.id")
+		}, true},
+		{"distinct", func(tx *gorm.DB) *gorm.DB {
+			return tx.Distinct("name").Joins("Company")
+		}, true},
+		{"group", func(tx *gorm.DB) *gorm.DB {
+			return tx.Joins("Company").Group("users.name")
+		}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := test.query(dryDB.Model(&User{})).Count(&count)
+			sql := result.Statement.SQL.String()
+			if result.Error != nil {
+				t.Fatalf("failed to build count SQL, got error %v", result.Error)
+			}
+
+			if strings.Contains(sql, "JOIN") != test.hasJoin {
+				t.Errorf("count SQL should have join: %v, but got %v", test.hasJoin, sql)
+			}
+
+			if strings.Contains(sql, "ORDER BY") {
+				t.Errorf("count SQL should not have order by, but got %v", sql)
+			}
+		})
+	}
+
+	var users []User
+	tx := DB.Model(&User{}).Joins("Company").Where("users.name = ?", user.Name).Order("users.name")
+	if err := tx.Count(&count).Find(&users).Error; err != nil || count != 1 || len(users) != 1 {
+		t.Fatalf("failed to find users after count, got error: %v, count %v", err, count)
+	}
+
+	if users[0].Company.Name != user.Company.Name {
+		t.Errorf("joins should be kept after count, but got company %+v", users[0].Company)
+	}
+}
@@ -2,12 +2,16 @@ package tests_test
 
 import (
 	"errors"
+	"fmt"
+	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/jinzhu/now"
 	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
 	"gorm.io/gorm/clause"
 	. "gorm.io/gorm/utils/tests"
 )
@@ -70,6 +74,112 @@ func TestCreateInBatches(t *testing.T) {
 	}
 }
 
+func TestCreateInBatchesWithOptions(t *testing.T) {
+	existing := *GetUser("create_in_batches_with_options_existing", Config{})
+	DB.Create(&existing)
+
+	newUsers := func(name string) []User {
+		users := make([]User, 0, 6)
+		for i := 0; i < 6; i++ {
+			users = append(users, *GetUser(fmt.Sprintf("%v_%v", name, i), Config{}))
+		}
+		// the third batch fails with duplicated primary key
+		users[5].ID = existing.ID
+		return users
+	}
+
+	type progress struct{ batchIndex, rowsAffected int64 }
+
+	t.Run("Transaction", func(t *testing.T) {
+		var progresses []progress
+		users := newUsers("create_in_batches_with_options_tx")
+		result := DB.CreateInBatchesWithOptions(&users, 2, gorm.CreateBatchOptions{
+			Progress: func(batchIndex, rowsAffected int64) error {
+				progresses = append(progresses, progress{batchIndex, rowsAffected})
+				return nil
+			},
+		})
+
+		if result.Error == nil {
+			t.Fatalf("should failed to create the third batch")
+		}
+
+		var batchErr *gorm.BatchError
+		if errors.As(result.Error, &batchErr) {
+			t.Errorf("should not wrap error without PerBatchTransaction, got %v", result.Error)
+		}
+
+		AssertEqual(t, progresses, []progress{{0, 2}, {1, 2}})
+
+		var count int64
+		DB.Model(&User{}).Where("name LIKE ?", "create_in_batches_with_options_tx%").Count(&count)
+		if count != 0 {
+			t.Errorf("all batches should be rolled back, but got %v records", count)
+		}
+	})
+
+	t.Run("PerBatchTransaction", func(t *testing.T) {
+		var progresses []progress
+		users := newUsers("create_in_batches_with_options_per_batch")
+		result := DB.CreateInBatchesWithOptions(&users, 2, gorm.CreateBatchOptions{
+			Progress: func(batchIndex, rowsAffected int64) error {
+				progresses = append(progresses, progress{batchIndex, rowsAffected})
+				return nil
+			},
+			PerBatchTransaction: true,
+		})
+
+		var batchErr *gorm.BatchError
+		if !errors.As(result.Error, &batchErr) || batchErr.BatchIndex != 2 {
+			t.Fatalf("should failed to create the third batch, got %v", result.Error)
+		}
+
+		if result.RowsAffected != 4 {
+			t.Errorf("affected rows should be 4, but got %v", result.RowsAffected)
+		}
+
+		AssertEqual(t, progresses, []progress{{0, 2}, {1, 2}})
+
+		var count int64
+		DB.Model(&User{}).Where("name LIKE ?", "create_in_batches_with_options_per_batch%").Count(&count)
+		if count != 4 {
+			t.Errorf("committed batches should be kept, but got %v records", count)
+		}
+	})
+
+	t.Run("ProgressError", func(t *testing.T) {
+		users := newUsers("create_in_batches_with_options_progress")[:4]
+		stopErr := errors.New("stop")
+		result := DB.CreateInBatchesWithOptions(&users, 2, gorm.CreateBatchOptions{
+			Progress: func(batchIndex, rowsAffected int64) error {
+				return stopErr
+			},
+			PerBatchTransaction: true,
+		})
+
+		var batchErr *gorm.BatchError
+		if !errors.As(result.Error, &batchErr) || batchErr.BatchIndex != 0 || !errors.Is(result.Error, stopErr) {
+			t.Fatalf("should stop after the first batch, got %v", result.Error)
+		}
+
+		if result.RowsAffected != 2 {
+			t.Errorf("affected rows should be 2, but got %v", result.RowsAffected)
+		}
+	})
+
+	t.Run("OnConflict", func(t *testing.T) {
+		users := newUsers("create_in_batches_with_options_on_conflict")
+		result := DB.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatchesWithOptions(&users, 2, gorm.CreateBatchOptions{PerBatchTransaction: true})
+		if result.Error != nil {
+			t.Fatalf("ON CONFLICT should be applied to every batch, got %v", result.Error)
+		}
+
+		if result.RowsAffected != 5 {
+			t.Errorf("affected rows should be 5, but got %v", result.RowsAffected)
+		}
+	})
+}
+
 func TestCreateInBatchesWithDefaultSize(t *testing.T) {
 	users := []User{
 		*GetUser("create_with_default_batch_size_1", Config{Account: true, Pets: 2, Toys: 3, Company: true, Manager: true, Team: 0, Languages: 1, Friends: 1}),
@@ -138,6 +248,96 @@ func TestCreateFromMap(t *testing.T) {
 	}
 }
 
+func TestCreateDeterministicSQL(t *testing.T) {
+	type DefaultValueRecord struct {
+		ID        uint
+		Name      string
+		Code      string `gorm:"default:(-)"`
+		Age       int    `gorm:"default:(-)"`
+		Email     string `gorm:"default:(-)"`
+		CreatedAt time.Time
+	}
+
+	dryDB := DB.Session(&gorm.Session{DryRun: true})
+	builds := map[string]func() *gorm.DB{
+		"struct": func() *gorm.DB {
+			return dryDB.Create(&DefaultValueRecord{Name: "deterministic"})
+		},
+		"map": func() *gorm.DB {
+			return dryDB.Model(&User{}).Create(map[string]interface{}{
+				"Name": "deterministic", "age": 18, "Active": true, "birthday": time.Now(), "company_id": 1,
+			})
+		},
+		"slice of map": func() *gorm.DB {
+			return dryDB.Model(&User{}).Create([]map[string]interface{}{
+				{"Name": "deterministic_1", "age": 18, "Active": true, "company_id": 1},
+				{"name": "deterministic_2", "Age": 19, "active": false, "CompanyID": 2},
+			})
+		},
+	}
+
+	for name, build := range builds {
+		expected := build().Statement.SQL.String()
+		for i := 0; i < 50; i++ {
+			if sql := build().Statement.SQL.String(); sql != expected {
+				t.Fatalf("%v: generated SQL should be deterministic, expects %v, got %v", name, expected, sql)
+			}
+		}
+	}
+
+	if sql := builds["map"]().Statement.SQL.String(); !regexp.MustCompile(`\(.active.,.age.,.birthday.,.company_id.,.name.\)`).MatchString(sql) {
+		t.Errorf("map columns should be sorted by db names, got %v", sql)
+	}
+
+	if sql := builds["slice of map"]().Statement.SQL.String(); !regexp.MustCompile(`\(.active.,.age.,.company_id.,.name.\)`).MatchString(sql) {
+		t.Errorf("slice of map columns should be sorted by db names, got %v", sql)
+	}
+
+	result := dryDB.Model(&User{}).Create([]map[string]interface{}{
+		{"name": "deterministic_1", "age": 18},
+		{"name": "deterministic_2"},
+	})
+	if !errors.Is(result.Error, gorm.ErrInconsistentMapKeys) || !strings.Contains(result.Error.Error(), "age") {
+		t.Errorf("should return ErrInconsistentMapKeys for maps with different keys, got %v", result.Error)
+	}
+}
+
+func TestCreateFromMapWithSetColumn(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+
+	db.Callback().Create().Before("gorm:create").Register("test:set_column_from_map", func(tx *gorm.DB) {
+		tx.Statement.SetColumn("Name", "create_from_map_with_set_column")
+		tx.Statement.SetColumn("Age", 20)
+		tx.Statement.UnsetColumn("Active")
+	})
+
+	if err := db.Model(&User{}).Create(map[string]interface{}{"name": "create_from_map", "active": true}).Error; err != nil {
+		t.Fatalf("failed to create data from map, got error: %v", err)
+	}
+
+	var result User
+	if err := db.Where("name = ?", "create_from_map_with_set_column").First(&result).Error; err != nil {
+		t.Fatalf("failed to query data after create from map, got error %v", err)
+	}
+
+	if result.Age != 20 || result.Active {
+		t.Errorf("failed to set columns in hooks, got age %v, active %v", result.Age, result.Active)
+	}
+
+	datas := []map[string]interface{}{{"Name": "create_from_map_1"}, {"name": "create_from_map_2"}}
+	if err := db.Model(&User{}).Create(&datas).Error; err != nil {
+		t.Fatalf("failed to create data from slice of map, got error: %v", err)
+	}
+
+	var count int64
+	if db.Model(&User{}).Where("name = ? AND age = ?", "create_from_map_with_set_column", 20).Count(&count); count != 3 {
+		t.Errorf("should create 3 users, got %v", count)
+	}
+}
+
 func TestCreateWithAssociations(t *testing.T) {
 	user := *GetUser("create_with_associations", Config{
 		Account:   true,
@@ -615,3 +815,353 @@ func TestCreateOnConfilctWithDefalutNull(t *testing.T) {
 	AssertEqual(t, u2.Email, "on-confilct-user-email-2")
 	AssertEqual(t, u2.Mobile, "133xxxx")
 }
+
+// Quantity uses negative values as the unset marker
+type Quantity int
+
+func (q Quantity) GormIsZero() bool {
+	return q < 0
+}
+
+func TestCreateWithCustomZeroValue(t *testing.T) {
+	type ZeroValueProduct struct {
+		ID     uint
+		Name   string
+		Stock  Quantity `gorm:"default:10"`
+		Rank   int      `gorm:"default:5;zerovalue:-1"`
+		Active bool     `gorm:"default:true;zerovalue:null"`
+	}
+
+	DB.Migrator().DropTable(&ZeroValueProduct{})
+	if err := DB.AutoMigrate(&ZeroValueProduct{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	products := []ZeroValueProduct{
+		{Name: "zero_value_1", Stock: 0, Rank: 0, Active: false},
+		{Name: "zero_value_2", Stock: -1, Rank: -1, Active: false},
+	}
+
+	for i := range products {
+		if err := DB.Create(&products[i]).Error; err != nil {
+			t.Fatalf("failed to create product, got error %v", err)
+		}
+	}
+
+	var result ZeroValueProduct
+	DB.First(&result, products[0].ID)
+	AssertEqual(t, result.Stock, Quantity(0))
+	AssertEqual(t, result.Rank, 0)
+	AssertEqual(t, result.Active, false)
+
+	AssertEqual(t, products[1].Stock, Quantity(10))
+	AssertEqual(t, products[1].Rank, 5)
+
+	result = ZeroValueProduct{}
+	DB.First(&result, products[1].ID)
+	AssertEqual(t, result.Stock, Quantity(10))
+	AssertEqual(t, result.Rank, 5)
+	AssertEqual(t, result.Active, false)
+
+	var results []ZeroValueProduct
+	DB.Where(&ZeroValueProduct{Rank: 0, Stock: -1}).Find(&results)
+	if len(results) != 1 || results[0].ID != products[0].ID {
+		t.Errorf("zero values should be used as conditions, got %+v", results)
+	}
+}
+
+type GeneratedKeyItem struct {
+	ID   string `gorm:"primaryKey;default:(lower(hex(randomblob(16))))"`
+	Code string `gorm:"uniqueIndex"`
+	Name string
+}
+
+func TestCreateWithGeneratedKey(t *testing.T) {
+	if DB.Dialector.Name() != "sqlite" {
+		t.Skip("database generated hex key is sqlite specific")
+	}
+
+	DB.Migrator().DropTable(&GeneratedKeyItem{})
+	if err := DB.AutoMigrate(&GeneratedKeyItem{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	// create without RETURNING
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+	if err := db.Callback().Create().Replace("gorm:create", callbacks.Create(&callbacks.Config{LastInsertIDReversed: true})); err != nil {
+		t.Fatalf("failed to replace create callback, got error %v", err)
+	}
+
+	checkKey := func(t *testing.T, item GeneratedKeyItem) {
+		var result GeneratedKeyItem
+		if err := DB.First(&result, "code = ?", item.Code).Error; err != nil {
+			t.Fatalf("failed to find created item, got error %v", err)
+		}
+
+		if len(result.ID) != 32 || result.ID != item.ID {
+			t.Errorf("generated key should be filled, expects %v, got %v", result.ID, item.ID)
+		}
+	}
+
+	t.Run("Returning", func(t *testing.T) {
+		item := GeneratedKeyItem{Code: "returning", Name: "item"}
+		if err := DB.Create(&item).Error; err != nil {
+			t.Fatalf("failed to create item, got error %v", err)
+		}
+		checkKey(t, item)
+	})
+
+	t.Run("WithoutReturning", func(t *testing.T) {
+		item := GeneratedKeyItem{Code: "without-returning", Name: "item"}
+		if err := db.Create(&item).Error; err != nil {
+			t.Fatalf("failed to create item, got error %v", err)
+		}
+
+		if item.ID != "" {
+			t.Errorf("last insert id shouldn't be used as generated key, got %v", item.ID)
+		}
+	})
+
+	t.Run("FetchGeneratedKeys", func(t *testing.T) {
+		tx := db.Session(&gorm.Session{FetchGeneratedKeys: true})
+
+		item := GeneratedKeyItem{Code: "fetch", Name: "item"}
+		if err := tx.Create(&item).Error; err != nil {
+			t.Fatalf("failed to create item, got error %v", err)
+		}
+		checkKey(t, item)
+
+		items := []*GeneratedKeyItem{{Code: "fetch-1"}, {Code: "fetch-2"}}
+		if err := tx.Create(&items).Error; err != nil {
+			t.Fatalf("failed to create items, got error %v", err)
+		}
+
+		for _, item := range items {
+			checkKey(t, *item)
+		}
+
+		custom := GeneratedKeyItem{ID: "custom", Code: "fetch-custom"}
+		if err := tx.Create(&custom).Error; err != nil || custom.ID != "custom" {
+			t.Errorf("specified key should be kept, got %v, %v", custom.ID, err)
+		}
+
+		if err := tx.Create(&GeneratedKeyItem{Name: "without unique fields"}).Error; err == nil {
+			t.Errorf("should return error when no unique fields have value")
+		}
+	})
+}
+
+func TestCreateFillTimeDefaultsInApp(t *testing.T) {
+	type TimeDefaultEvent struct {
+		ID         uint
+		Name       string
+		OccurredAt time.Time  `gorm:"default:now()"`
+		ReceivedAt *time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+		Score      int        `gorm:"default:10"`
+	}
+
+	open := func(fill bool) (*gorm.DB, *RecordingDialector) {
+		pool := NewFakeConnPool(FakeResult{RowsAffected: 1, LastInsertID: 1}, FakeResult{RowsAffected: 2, LastInsertID: 3})
+		dialector := &RecordingDialector{DummyDialector: DummyDialector{DisableReturning: true}, ConnPool: pool}
+		db, err := gorm.Open(dialector, &gorm.Config{SkipDefaultTransaction: true, FillTimeDefaultsInApp: fill})
+		if err != nil {
+			t.Fatalf("failed to open, got %v", err)
+		}
+		return db, dialector
+	}
+
+	db, dialector := open(true)
+	curTime := time.Now()
+	db.Config.NowFunc = func() time.Time { return curTime }
+
+	event := TimeDefaultEvent{Name: "fill_time_defaults"}
+	if err := db.Create(&event).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+
+	if !event.OccurredAt.Equal(curTime) || event.ReceivedAt == nil || !event.ReceivedAt.Equal(curTime) || event.ID != 1 {
+		t.Errorf("time defaults should be filled by NowFunc, got %+v", event)
+	}
+
+	if !regexp.MustCompile("^INSERT INTO `time_default_events` \\(`name`,`occurred_at`,`received_at`,`score`\\) VALUES").MatchString(dialector.LastStatement().SQL) {
+		t.Errorf("filled time defaults should be inserted, got %v", dialector.LastStatement().SQL)
+	}
+
+	occurredAt := curTime.Add(-time.Hour)
+	events := []TimeDefaultEvent{{Name: "fill_time_defaults_1", OccurredAt: occurredAt}, {Name: "fill_time_defaults_2"}}
+	if err := db.Create(&events).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+
+	if !events[0].OccurredAt.Equal(occurredAt) || !events[1].OccurredAt.Equal(curTime) || events[0].ReceivedAt == nil || events[1].ReceivedAt == nil {
+		t.Errorf("zero time defaults should be filled by NowFunc, got %+v", events)
+	}
+
+	db, dialector = open(false)
+	event = TimeDefaultEvent{Name: "time_defaults_in_db"}
+	if err := db.Create(&event).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+
+	if !event.OccurredAt.IsZero() || event.ReceivedAt != nil {
+		t.Errorf("time defaults should be left to the database, got %+v", event)
+	}
+
+	if strings.Contains(dialector.LastStatement().SQL, "occurred_at") {
+		t.Errorf("time defaults shouldn't be inserted, got %v", dialector.LastStatement().SQL)
+	}
+}
+
+type StreamedItem struct {
+	ID        uint
+	Name      string
+	Status    string `gorm:"default:active"`
+	Code      string `gorm:"default:(-)"`
+	CreatedAt time.Time
+}
+
+func TestCreateStreamedInsert(t *testing.T) {
+	curTime := time.Now().Round(time.Second)
+	dryRun := DB.Session(&gorm.Session{DryRun: true, NowFunc: func() time.Time { return curTime }})
+
+	newItems := []func() interface{}{
+		func() interface{} {
+			return &[]StreamedItem{{Name: "streamed_1"}, {Name: "streamed_2", Status: "disabled", Code: "code_2"}, {Name: "streamed_3"}}
+		},
+		func() interface{} {
+			return &[]*StreamedItem{{Name: "streamed_4"}}
+		},
+		func() interface{} {
+			return &[]User{*GetUser("streamed_5", Config{}), *GetUser("streamed_6", Config{})}
+		},
+	}
+
+	for idx, newItem := range newItems {
+		items, streamedItems := newItem(), newItem()
+		stmt := dryRun.Omit(clause.Associations).Create(items).Statement
+		streamedStmt := dryRun.Session(&gorm.Session{StreamedInsert: true}).Omit(clause.Associations).Create(streamedItems).Statement
+
+		if values, _ := streamedStmt.Clauses["VALUES"].Expression.(clause.Values); values.Rows == nil || values.Values != nil {
+			t.Fatalf("#%v values should be streamed, got %+v", idx, values)
+		}
+
+		if stmt.SQL.String() != streamedStmt.SQL.String() {
+			t.Errorf("#%v streamed SQL should be same, expects %v, got %v", idx, stmt.SQL.String(), streamedStmt.SQL.String())
+		}
+
+		if !reflect.DeepEqual(stmt.Vars, streamedStmt.Vars) {
+			t.Errorf("#%v streamed vars should be same, expects %v, got %v", idx, stmt.Vars, streamedStmt.Vars)
+		}
+
+		if !reflect.DeepEqual(items, streamedItems) {
+			t.Errorf("#%v default values should be filled, expects %+v, got %+v", idx, items, streamedItems)
+		}
+	}
+
+	db := dryRun.Session(&gorm.Session{})
+	db.Config.StreamedInsertThreshold = 2
+	for length, streamed := range map[int]bool{2: false, 3: true} {
+		items := make([]StreamedItem, length)
+		values, _ := db.Create(&items).Statement.Clauses["VALUES"].Expression.(clause.Values)
+		if (values.Rows != nil) != streamed {
+			t.Errorf("values of %v rows should be streamed: %v, got %+v", length, streamed, values)
+		}
+	}
+
+	DB.Migrator().DropTable(&StreamedItem{})
+	if err := DB.AutoMigrate(&StreamedItem{}); err != nil {
+		t.Fatalf("failed to migrate, got error: %v", err)
+	}
+
+	items := []StreamedItem{{Name: "streamed_1", Code: "code_1"}, {Name: "streamed_2", Status: "disabled", Code: "code_2"}, {Name: "streamed_3", Code: "code_3"}}
+	if err := DB.Session(&gorm.Session{StreamedInsert: true, CreateBatchSize: 2}).Create(&items).Error; err != nil {
+		t.Fatalf("failed to create, got error: %v", err)
+	}
+
+	var results []StreamedItem
+	DB.Order("id").Find(&results)
+	if len(results) != 3 {
+		t.Fatalf("all items should be created, got %+v", results)
+	}
+
+	for idx, result := range results {
+		if result.ID == 0 || result.ID != items[idx].ID || result.Name != items[idx].Name || result.Status != items[idx].Status || result.Code != items[idx].Code {
+			t.Errorf("item #%v should be created, expects %+v, got %+v", idx, items[idx], result)
+		}
+	}
+
+	if results[0].Status != "active" || results[1].Status != "disabled" {
+		t.Errorf("default values should be inserted, got %+v", results)
+	}
+}
+
+type DBTimeEvent struct {
+	ID        uint
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (DBTimeEvent) UseDBTime() bool {
+	return true
+}
+
+func TestCreateWithDBTime(t *testing.T) {
+	appTime := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	tx := DB.Session(&gorm.Session{NowFunc: func() time.Time { return appTime }})
+	dryDB := tx.Session(&gorm.Session{DryRun: true})
+
+	stmt := dryDB.Create(&DBTimeEvent{Name: "db_time"}).Statement
+	if !regexp.MustCompile("VALUES \\(.+,CURRENT_TIMESTAMP,CURRENT_TIMESTAMP\\)").MatchString(stmt.SQL.String()) || len(stmt.Vars) != 1 {
+		t.Errorf("auto time fields should use the database time, got %v, vars %v", stmt.SQL.String(), stmt.Vars)
+	}
+
+	stmt = dryDB.Model(&DBTimeEvent{ID: 1}).Update("name", "db_time").Statement
+	if !regexp.MustCompile("SET .name.=.+,.updated_at.=CURRENT_TIMESTAMP WHERE").MatchString(stmt.SQL.String()) {
+		t.Errorf("auto update time should use the database time, got %v", stmt.SQL.String())
+	}
+
+	stmt = dryDB.Save(&DBTimeEvent{ID: 1, Name: "db_time"}).Statement
+	if !regexp.MustCompile("SET .name.=.+,.created_at.=.+,.updated_at.=CURRENT_TIMESTAMP WHERE").MatchString(stmt.SQL.String()) {
+		t.Errorf("auto update time should use the database time when saving, got %v", stmt.SQL.String())
+	}
+
+	stmt = dryDB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&DBTimeEvent{ID: 1, Name: "db_time"}).Statement
+	if !regexp.MustCompile("ON CONFLICT .+ DO UPDATE SET .updated_at.=CURRENT_TIMESTAMP").MatchString(stmt.SQL.String()) {
+		t.Errorf("auto update time of on conflict should use the database time, got %v", stmt.SQL.String())
+	}
+
+	DB.Migrator().DropTable(&DBTimeEvent{})
+	if err := DB.AutoMigrate(&DBTimeEvent{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	event := DBTimeEvent{Name: "db_time"}
+	if err := tx.Create(&event).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+
+	var result DBTimeEvent
+	if err := DB.First(&result, event.ID).Error; err != nil {
+		t.Fatalf("failed to query, got %v", err)
+	}
+
+	if !result.CreatedAt.After(appTime) || !result.UpdatedAt.After(appTime) {
+		t.Errorf("auto time fields should be the database time, got %+v", result)
+	}
+
+	if DB.Dialector.Name() == "sqlite" && (!event.CreatedAt.Equal(result.CreatedAt) || !event.UpdatedAt.Equal(result.UpdatedAt)) {
+		t.Errorf("database time should be returned, expects %+v, got %+v", result, event)
+	}
+
+	if err := tx.Model(&event).Update("name", "db_time_updated").Error; err != nil {
+		t.Fatalf("failed to update, got %v", err)
+	}
+
+	if err := DB.First(&result, event.ID).Error; err != nil || !result.UpdatedAt.After(appTime) || result.Name != "db_time_updated" {
+		t.Errorf("auto update time should be the database time, got %+v, error %v", result, err)
+	}
+}
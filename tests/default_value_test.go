@@ -1,10 +1,14 @@
 package tests_test
 
 import (
+	"context"
+	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
 )
 
 func TestDefaultValue(t *testing.T) {
@@ -39,3 +43,45 @@ func TestDefaultValue(t *testing.T) {
 		t.Fatalf("Failed to find created data with default data, got %+v", result)
 	}
 }
+
+func TestDefaultValueFunc(t *testing.T) {
+	var sequence int64
+	gorm.RegisterDefaultValuer("test_sequence", func(ctx context.Context) interface{} {
+		return atomic.AddInt64(&sequence, 1)
+	})
+	gorm.RegisterDefaultValuer("test_code", func(ctx context.Context) interface{} {
+		return fmt.Sprintf("CODE-%v", atomic.LoadInt64(&sequence))
+	})
+
+	type DefaultFuncItem struct {
+		ID   int64  `gorm:"primaryKey;default:fn:test_sequence"`
+		Code string `gorm:"default:fn:test_code"`
+		Name string
+	}
+
+	DB.Migrator().DropTable(&DefaultFuncItem{})
+	if err := DB.AutoMigrate(&DefaultFuncItem{}); err != nil {
+		t.Fatalf("failed to migrate with default value func, got error: %v", err)
+	}
+
+	item := DefaultFuncItem{Name: "item"}
+	if err := DB.Create(&item).Error; err != nil {
+		t.Fatalf("failed to create item, got error: %v", err)
+	} else if item.ID != 1 || item.Code != "CODE-1" {
+		t.Fatalf("default values should be generated, got %+v", item)
+	}
+
+	items := []DefaultFuncItem{{Name: "batch1"}, {Name: "batch2", Code: "CUSTOM"}, {Name: "batch3"}, {Name: "batch4"}}
+	if err := DB.CreateInBatches(&items, 3).Error; err != nil {
+		t.Fatalf("failed to create items, got error: %v", err)
+	}
+
+	expects := []DefaultFuncItem{{ID: 2, Code: "CODE-2", Name: "batch1"}, {ID: 3, Code: "CUSTOM", Name: "batch2"}, {ID: 4, Code: "CODE-4", Name: "batch3"}, {ID: 5, Code: "CODE-5", Name: "batch4"}}
+	AssertEqual(t, items, expects)
+
+	var results []DefaultFuncItem
+	if err := DB.Order("id").Find(&results).Error; err != nil {
+		t.Fatalf("failed to find items, got error: %v", err)
+	}
+	AssertEqual(t, results, append([]DefaultFuncItem{{ID: 1, Code: "CODE-1", Name: "item"}}, expects...))
+}
@@ -1,7 +1,10 @@
 package tests_test
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
 	"gorm.io/gorm"
@@ -206,6 +209,160 @@ func TestDeleteSliceWithAssociations(t *testing.T) {
 	}
 }
 
+type CascadeAuthor struct {
+	ID    uint
+	Name  string
+	Books []CascadeBook
+}
+
+type CascadeBook struct {
+	gorm.Model
+	CascadeAuthorID uint
+	Title           string
+	Tags            []CascadeTag `gorm:"many2many:cascade_book_tags"`
+	Chapters        []CascadeChapter
+}
+
+type CascadeChapter struct {
+	ID            uint
+	CascadeBookID uint
+	Title         string
+	Pages         []CascadePage `gorm:"polymorphic:Owner"`
+}
+
+type CascadePage struct {
+	ID        uint
+	OwnerID   uint
+	OwnerType string
+	Number    int
+}
+
+type CascadeTag struct {
+	ID   uint
+	Name string
+}
+
+func TestCascadeDelete(t *testing.T) {
+	tx := DB.Session(&gorm.Session{})
+	tx.Config.DisableForeignKeyConstraintWhenMigrating = true
+
+	DB.Migrator().DropTable(&CascadeAuthor{}, &CascadeBook{}, &CascadeChapter{}, &CascadePage{}, &CascadeTag{}, "cascade_book_tags")
+	if err := tx.AutoMigrate(&CascadeAuthor{}, &CascadeBook{}, &CascadeChapter{}, &CascadePage{}, &CascadeTag{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	newAuthor := func(name string) *CascadeAuthor {
+		return &CascadeAuthor{Name: name, Books: []CascadeBook{
+			{Title: name + "_book1", Tags: []CascadeTag{{Name: name + "_tag1"}}, Chapters: []CascadeChapter{
+				{Title: "chapter1", Pages: []CascadePage{{Number: 1}, {Number: 2}}},
+				{Title: "chapter2", Pages: []CascadePage{{Number: 3}}},
+			}},
+			{Title: name + "_book2", Tags: []CascadeTag{{Name: name + "_tag2"}}, Chapters: []CascadeChapter{
+				{Title: "chapter3", Pages: []CascadePage{{Number: 4}}},
+			}},
+		}}
+	}
+
+	author := newAuthor("cascade")
+	if err := DB.Create(author).Error; err != nil {
+		t.Fatalf("failed to create author, got error %v", err)
+	}
+
+	if err := DB.Session(&gorm.Session{CascadeDelete: true}).Delete(author).Error; err != nil {
+		t.Fatalf("failed to cascade delete author, got error %v", err)
+	}
+
+	var count int64
+	for _, query := range []struct {
+		tx       *gorm.DB
+		name     string
+		expected int64
+	}{
+		{DB.Model(&CascadeAuthor{}).Where("id = ?", author.ID), "authors", 0},
+		{DB.Model(&CascadeBook{}).Where("cascade_author_id = ?", author.ID), "books", 0},
+		{DB.Unscoped().Model(&CascadeBook{}).Where("cascade_author_id = ?", author.ID), "soft deleted books", 2},
+		{DB.Table("cascade_book_tags"), "book tags", 0},
+		{DB.Model(&CascadeTag{}), "tags", 2},
+		{DB.Model(&CascadeChapter{}), "chapters", 0},
+		{DB.Model(&CascadePage{}), "pages", 0},
+	} {
+		if err := query.tx.Count(&count).Error; err != nil || count != query.expected {
+			t.Errorf("%v expects %v, got %v, error %v", query.name, query.expected, count, err)
+		}
+	}
+
+	author = newAuthor("cascade_select")
+	if err := DB.Create(author).Error; err != nil {
+		t.Fatalf("failed to create author, got error %v", err)
+	}
+
+	if err := DB.Select("Books", "Books.Chapters").Delete(author).Error; err != nil {
+		t.Fatalf("failed to delete author with nested associations, got error %v", err)
+	}
+
+	for _, query := range []struct {
+		tx       *gorm.DB
+		name     string
+		expected int64
+	}{
+		{DB.Model(&CascadeBook{}).Where("cascade_author_id = ?", author.ID), "books", 0},
+		{DB.Table("cascade_book_tags"), "book tags", 2},
+		{DB.Model(&CascadeChapter{}), "chapters", 0},
+		{DB.Model(&CascadePage{}), "pages", 4},
+	} {
+		if err := query.tx.Count(&count).Error; err != nil || count != query.expected {
+			t.Errorf("%v expects %v, got %v, error %v", query.name, query.expected, count, err)
+		}
+	}
+
+	// without CascadeDelete, clause.Associations only deletes the direct associations
+	author = newAuthor("cascade_associations")
+	if err := DB.Create(author).Error; err != nil {
+		t.Fatalf("failed to create author, got error %v", err)
+	}
+
+	if err := DB.Select(clause.Associations).Delete(author).Error; err != nil {
+		t.Fatalf("failed to delete author with associations, got error %v", err)
+	}
+
+	for _, query := range []struct {
+		tx       *gorm.DB
+		name     string
+		expected int64
+	}{
+		{DB.Model(&CascadeBook{}).Where("cascade_author_id = ?", author.ID), "books", 0},
+		{DB.Table("cascade_book_tags"), "book tags", 4},
+		{DB.Model(&CascadeChapter{}), "chapters", 3},
+		{DB.Model(&CascadePage{}), "pages", 8},
+	} {
+		if err := query.tx.Count(&count).Error; err != nil || count != query.expected {
+			t.Errorf("%v expects %v, got %v, error %v", query.name, query.expected, count, err)
+		}
+	}
+}
+
+func TestCascadeDeleteWithCycle(t *testing.T) {
+	user1 := GetUser("cascade_delete_cycle_1", Config{Pets: 1})
+	user2 := GetUser("cascade_delete_cycle_2", Config{Pets: 1})
+	if err := DB.Create([]*User{user1, user2}).Error; err != nil {
+		t.Fatalf("failed to create users, got error %v", err)
+	}
+
+	DB.Model(user1).Update("manager_id", user2.ID)
+	DB.Model(user2).Update("manager_id", user1.ID)
+
+	if err := DB.Session(&gorm.Session{CascadeDelete: true}).Omit("Pets", "NamedPet").Delete(user1).Error; err != nil {
+		t.Fatalf("failed to cascade delete users, got error %v", err)
+	}
+
+	var count int64
+	DB.Model(&User{}).Where("name like ?", "cascade_delete_cycle_%").Count(&count)
+	AssertEqual(t, count, 0)
+
+	DB.Model(&Pet{}).Where("user_id IN ?", []uint{user1.ID, user2.ID}).Count(&count)
+	AssertEqual(t, count, 1)
+}
+
 // only sqlite, postgres support returning
 func TestSoftDeleteReturning(t *testing.T) {
 	if DB.Dialector.Name() != "sqlite" && DB.Dialector.Name() != "postgres" {
@@ -220,9 +377,15 @@ func TestSoftDeleteReturning(t *testing.T) {
 	DB.Create(&users)
 
 	var results []User
-	DB.Where("name IN ?", []string{users[0].Name, users[1].Name}).Clauses(clause.Returning{}).Delete(&results)
-	if len(results) != 2 {
-		t.Errorf("failed to return delete data, got %v", results)
+	result := DB.Where("name IN ?", []string{users[0].Name, users[1].Name}).Clauses(clause.Returning{}).Delete(&results)
+	if len(results) != 2 || result.RowsAffected != 2 {
+		t.Errorf("failed to return delete data, got %v, rows affected %v", results, result.RowsAffected)
+	}
+
+	for _, user := range results {
+		if !user.DeletedAt.Valid || user.ID == 0 {
+			t.Errorf("soft deleted data should be returned, got %+v", user)
+		}
 	}
 
 	var count int64
@@ -245,9 +408,23 @@ func TestDeleteReturning(t *testing.T) {
 	DB.Create(&companies)
 
 	var results []Company
-	DB.Where("name IN ?", []string{companies[0].Name, companies[1].Name}).Clauses(clause.Returning{}).Delete(&results)
-	if len(results) != 2 {
-		t.Errorf("failed to return delete data, got %v", results)
+	result := DB.Where("name IN ?", []string{companies[0].Name, companies[1].Name}).Clauses(clause.Returning{}).Delete(&results)
+	if len(results) != 2 || result.RowsAffected != 2 {
+		t.Errorf("failed to return delete data, got %v, rows affected %v", results, result.RowsAffected)
+	}
+
+	user := *GetUser("delete-returning-columns", Config{})
+	DB.Create(&user)
+
+	var deleted User
+	if err := DB.Unscoped().Clauses(clause.Returning{Columns: []clause.Column{{Name: "name"}}}).Delete(&deleted, user.ID).Error; err != nil {
+		t.Fatalf("failed to delete with returning columns, got error %v", err)
+	} else if deleted.Name != user.Name {
+		t.Errorf("failed to return deleted columns, got %+v", deleted)
+	}
+
+	if err := DB.Unscoped().First(&User{}, user.ID).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("user should be hard deleted, got error %v", err)
 	}
 
 	var count int64
@@ -256,3 +433,126 @@ func TestDeleteReturning(t *testing.T) {
 		t.Errorf("failed to delete data, current count %v", count)
 	}
 }
+
+func TestDeleteInBatches(t *testing.T) {
+	DB.Migrator().DropTable(&Product{})
+	DB.AutoMigrate(&Product{})
+
+	sqlDB, err := DB.DB()
+	if err != nil {
+		t.Fatalf("failed to get sql.DB, got %v", err)
+	}
+
+	countStatements := func(conn *wrapperConnPool, prefix string) (count int) {
+		for _, sql := range conn.got {
+			if strings.HasPrefix(sql, prefix) {
+				count++
+			}
+		}
+		return
+	}
+
+	createProducts := func(name string) []Product {
+		products := make([]Product, 25)
+		for i := range products {
+			products[i] = Product{Name: name, Code: fmt.Sprintf("%v_%v", name, i)}
+		}
+		if err := DB.Create(&products).Error; err != nil {
+			t.Fatalf("failed to create products, got %v", err)
+		}
+		return products
+	}
+
+	t.Run("SoftDelete", func(t *testing.T) {
+		products := createProducts("soft_delete_in_batches")
+		conn := &wrapperConnPool{db: sqlDB}
+		tx := DB.Session(&gorm.Session{NewDB: true, Context: context.Background()})
+		tx.Statement.ConnPool = conn
+
+		result := tx.DeleteInBatches(&products, 10)
+		if result.Error != nil || result.RowsAffected != 25 {
+			t.Fatalf("failed to delete in batches, got %v, rows affected %v", result.Error, result.RowsAffected)
+		}
+
+		if count := countStatements(conn, "UPDATE"); count != 3 {
+			t.Errorf("should soft delete with 3 statements, got %v: %v", count, conn.got)
+		}
+
+		for _, product := range products {
+			if product.BeforeDeleteCallTimes != 1 || product.AfterDeleteCallTimes != 1 {
+				t.Fatalf("delete hooks should be called once for each product, got %v", product.GetCallTimes())
+			}
+		}
+
+		var count int64
+		if DB.Model(&Product{}).Where("name = ?", "soft_delete_in_batches").Count(&count); count != 0 {
+			t.Errorf("products should be soft deleted, got %v", count)
+		}
+
+		if DB.Unscoped().Model(&Product{}).Where("name = ?", "soft_delete_in_batches").Count(&count); count != 25 {
+			t.Errorf("products should be kept in database, got %v", count)
+		}
+	})
+
+	t.Run("Unscoped", func(t *testing.T) {
+		products := createProducts("delete_in_batches")
+		conn := &wrapperConnPool{db: sqlDB}
+		tx := DB.Session(&gorm.Session{NewDB: true, Context: context.Background()})
+		tx.Statement.ConnPool = conn
+
+		result := tx.Unscoped().DeleteInBatches(&products, 10)
+		if result.Error != nil || result.RowsAffected != 25 {
+			t.Fatalf("failed to delete in batches, got %v, rows affected %v", result.Error, result.RowsAffected)
+		}
+
+		if count := countStatements(conn, "DELETE"); count != 3 {
+			t.Errorf("should delete with 3 statements, got %v: %v", count, conn.got)
+		}
+
+		var count int64
+		if DB.Unscoped().Model(&Product{}).Where("name = ?", "delete_in_batches").Count(&count); count != 0 {
+			t.Errorf("products should be deleted, got %v", count)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		products := createProducts("delete_in_batches_error")
+		products[15].Code = "dont_delete"
+
+		result := DB.Session(&gorm.Session{SkipDefaultTransaction: true}).DeleteInBatches(&products, 10)
+		if result.Error == nil || result.RowsAffected != 10 {
+			t.Fatalf("should stop at the second batch, got %v, rows affected %v", result.Error, result.RowsAffected)
+		}
+
+		for i, product := range products {
+			expects := int64(1)
+			if i >= 20 {
+				expects = 0
+			}
+
+			if product.BeforeDeleteCallTimes != expects {
+				t.Fatalf("before delete hook of product %v should be called %v times, got %v", i, expects, product.BeforeDeleteCallTimes)
+			}
+		}
+
+		var count int64
+		if DB.Model(&Product{}).Where("name = ?", "delete_in_batches_error").Count(&count); count != 15 {
+			t.Errorf("should delete the first batch only, got %v products left", count)
+		}
+	})
+
+	t.Run("RollbackError", func(t *testing.T) {
+		products := createProducts("delete_in_batches_rollback")
+		products[15].Code = "dont_delete"
+
+		result := DB.DeleteInBatches(&products, 10)
+		if result.Error == nil || result.RowsAffected != 0 {
+			t.Fatalf("rolled back batches should not be counted, got %v, rows affected %v", result.Error, result.RowsAffected)
+		}
+
+		var count int64
+		if DB.Model(&Product{}).Where("name = ?", "delete_in_batches_rollback").Count(&count); count != 25 {
+			t.Errorf("deleted batches should be rolled back, got %v products left", count)
+		}
+	})
+}
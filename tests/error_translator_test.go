@@ -2,12 +2,56 @@ package tests_test
 
 import (
 	"errors"
+	"regexp"
+	"strings"
 	"testing"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/utils/tests"
 )
 
+// sqliteErrorTranslator reports the violated constraints like a sqlite dialector could, by the columns or names in the error messages
+type sqliteErrorTranslator struct {
+	gorm.Dialector
+}
+
+var (
+	sqliteUniqueConstraintRegexp = regexp.MustCompile(`UNIQUE constraint failed: (.+)$`)
+	sqliteCheckConstraintRegexp  = regexp.MustCompile(`CHECK constraint failed: (\S+)$`)
+)
+
+func (d sqliteErrorTranslator) Translate(err error) error {
+	if sqliteCheckConstraintRegexp.MatchString(err.Error()) {
+		return gorm.ErrCheckConstraintViolated
+	}
+
+	if translator, ok := d.Dialector.(gorm.ErrorTranslator); ok {
+		return translator.Translate(err)
+	}
+	return err
+}
+
+func (d sqliteErrorTranslator) TranslateDuplicatedKey(err error) (constraint string, columns []string, ok bool) {
+	matches := sqliteUniqueConstraintRegexp.FindStringSubmatch(err.Error())
+	if len(matches) != 2 {
+		return "", nil, false
+	}
+
+	// UNIQUE constraint failed: users.name, users.age
+	for _, column := range strings.Split(matches[1], ",") {
+		column = strings.TrimSpace(column)
+		columns = append(columns, column[strings.LastIndex(column, ".")+1:])
+	}
+	return "", columns, true
+}
+
+func (d sqliteErrorTranslator) TranslateCheckConstraint(err error) (constraint string, ok bool) {
+	if matches := sqliteCheckConstraintRegexp.FindStringSubmatch(err.Error()); len(matches) == 2 {
+		return matches[1], true
+	}
+	return "", false
+}
+
 func TestDialectorWithErrorTranslatorSupport(t *testing.T) {
 	// it shouldn't translate error when the TranslateError flag is false
 	translatedErr := errors.New("translated error")
@@ -27,3 +71,96 @@ func TestDialectorWithErrorTranslatorSupport(t *testing.T) {
 		t.Fatalf("expected err: %v got err: %v", translatedErr, err)
 	}
 }
+
+func TestDuplicatedKeyError(t *testing.T) {
+	type DuplicatedKeyUser struct {
+		ID   uint
+		Code string `gorm:"size:100;uniqueIndex:idx_duplicated_key_users_code"`
+	}
+
+	if DB.Dialector.Name() != "sqlite" {
+		return
+	}
+
+	db, err := gorm.Open(sqliteErrorTranslator{DB.Dialector}, &gorm.Config{TranslateError: true})
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+
+	db.Migrator().DropTable(&DuplicatedKeyUser{})
+	if err := db.AutoMigrate(&DuplicatedKeyUser{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	if err := db.Create(&DuplicatedKeyUser{Code: "duplicated"}).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	err = db.Create(&DuplicatedKeyUser{Code: "duplicated"}).Error
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		t.Fatalf("expected duplicated key error, got %v", err)
+	}
+
+	var dupErr *gorm.DuplicatedKeyError
+	if !errors.As(err, &dupErr) {
+		t.Fatalf("expected DuplicatedKeyError, got %#v", err)
+	}
+
+	if dupErr.Constraint != "idx_duplicated_key_users_code" || len(dupErr.Columns) != 1 || dupErr.Columns[0] != "code" {
+		t.Errorf("invalid duplicated key error, got constraint %v, columns %v", dupErr.Constraint, dupErr.Columns)
+	}
+}
+
+func TestCheckConstraintError(t *testing.T) {
+	type CheckConstraintUser struct {
+		ID  uint
+		Age int `gorm:"check:chk_check_constraint_users_age,age > 0"`
+	}
+
+	if DB.Dialector.Name() != "sqlite" {
+		return
+	}
+
+	db, err := gorm.Open(sqliteErrorTranslator{DB.Dialector}, &gorm.Config{TranslateError: true})
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+
+	db.Migrator().DropTable(&CheckConstraintUser{})
+	if err := db.AutoMigrate(&CheckConstraintUser{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	if err := db.Create(&CheckConstraintUser{Age: 18}).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	err = db.Create(&CheckConstraintUser{Age: -1}).Error
+	if !errors.Is(err, gorm.ErrCheckConstraintViolated) {
+		t.Fatalf("expected check constraint error, got %v", err)
+	}
+
+	var chkErr *gorm.CheckConstraintError
+	if !errors.As(err, &chkErr) {
+		t.Fatalf("expected CheckConstraintError, got %#v", err)
+	}
+
+	if chkErr.Constraint != "chk_check_constraint_users_age" {
+		t.Errorf("invalid check constraint error, got constraint %v", chkErr.Constraint)
+	}
+
+	if chkErr.Check == nil || chkErr.Check.Field.DBName != "age" || chkErr.Check.Constraint != "age > 0" {
+		t.Errorf("check constraint error should have the parsed check, got %+v", chkErr.Check)
+	}
+
+	// check constraint errors are not recognized by the error messages if the dialector doesn't translate them
+	db, err = OpenTestConnection()
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+	db.Config.TranslateError = true
+
+	if err := db.Create(&CheckConstraintUser{Age: -1}).Error; err == nil || errors.Is(err, gorm.ErrCheckConstraintViolated) {
+		t.Errorf("should return the untranslated error, got %v", err)
+	}
+}
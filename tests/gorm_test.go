@@ -1,9 +1,17 @@
 package tests_test
 
 import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	. "gorm.io/gorm/utils/tests"
 )
 
 func TestReturningWithNullToZeroValues(t *testing.T) {
@@ -91,3 +99,188 @@ func TestReturningWithNullToZeroValues(t *testing.T) {
 
 	}
 }
+
+func TestFlushSchemaCache(t *testing.T) {
+	db := DB.Session(&gorm.Session{PrepareStmt: true})
+	user := *GetUser("flush_schema_cache", Config{})
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user, got error %v", err)
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(&User{}); err != nil {
+		t.Fatalf("failed to parse user, got error %v", err)
+	}
+	parsed := stmt.Schema
+
+	db.FlushSchemaCache()
+
+	stmt = &gorm.Statement{DB: db}
+	if err := stmt.Parse(&User{}); err != nil {
+		t.Fatalf("failed to parse user after flushed, got error %v", err)
+	} else if stmt.Schema == parsed {
+		t.Errorf("user should be parsed again after flushed")
+	}
+
+	var result User
+	if err := db.First(&result, user.ID).Error; err != nil {
+		t.Fatalf("failed to query user after flushed, got error %v", err)
+	}
+	CheckUser(t, result, user)
+
+	// 表名在解析时被缓存，清除缓存后重新解析的表名才会生效
+	flushTableName = "flush_items_v1"
+	dryDB := DB.Session(&gorm.Session{DryRun: true})
+	if sql := dryDB.Find(&FlushItem{}).Statement.SQL.String(); !regexp.MustCompile("FROM .flush_items_v1.$").MatchString(sql) {
+		t.Errorf("should query the table of model, got %v", sql)
+	}
+
+	flushTableName = "flush_items_v2"
+	if sql := dryDB.Find(&FlushItem{}).Statement.SQL.String(); !regexp.MustCompile("FROM .flush_items_v1.$").MatchString(sql) {
+		t.Errorf("should use the cached schema before flushed, got %v", sql)
+	}
+
+	dryDB.FlushSchemaCache()
+	if sql := dryDB.Find(&FlushItem{}).Statement.SQL.String(); !regexp.MustCompile("^SELECT \\* FROM .flush_items_v2.$").MatchString(sql) {
+		t.Errorf("should use the table of the schema parsed again after flushed, got %v", sql)
+	}
+}
+
+var flushTableName string
+
+type FlushItem struct {
+	ID   uint
+	Name string
+}
+
+func (FlushItem) TableName() string {
+	return flushTableName
+}
+
+func TestSchemaOf(t *testing.T) {
+	type SchemaOfAuthor struct {
+		Name  string
+		Email string `gorm:"size:100"`
+	}
+
+	type SchemaOfComment struct {
+		ID             uint
+		SchemaOfPostID uint
+		Content        string
+	}
+
+	type SchemaOfPost struct {
+		ID       uint
+		Title    string         `gorm:"not null;comment:post title"`
+		Author   SchemaOfAuthor `gorm:"embedded;embeddedPrefix:author_"`
+		Tags     []string       `gorm:"serializer:json"`
+		UserID   uint
+		User     User
+		Comments []SchemaOfComment
+	}
+
+	s, err := DB.SchemaOf(&SchemaOfPost{})
+	if err != nil {
+		t.Fatalf("failed to get schema, got error %v", err)
+	}
+
+	if s.Table != "schema_of_posts" || len(s.PrimaryFields) != 1 || s.PrioritizedPrimaryField.DBName != "id" {
+		t.Errorf("invalid schema, table %v, primary fields %v", s.Table, s.PrimaryFields)
+	}
+
+	if rel, ok := s.Relationships.Relations["User"]; !ok || rel.Type != schema.BelongsTo {
+		t.Errorf("User should be a belongs to relation, got %+v", rel)
+	}
+
+	if rel, ok := s.Relationships.Relations["Comments"]; !ok || rel.Type != schema.HasMany {
+		t.Errorf("Comments should be a has many relation, got %+v", rel)
+	}
+
+	expects := []schema.ColumnInfo{
+		{Name: "ID", DBName: "id", DataType: schema.Uint, PrimaryKey: true, Size: 64},
+		{Name: "Title", DBName: "title", DataType: schema.String, Comment: "post title"},
+		{Name: "Name", DBName: "author_name", DataType: schema.String, Nullable: true},
+		{Name: "Email", DBName: "author_email", DataType: schema.String, Nullable: true, Size: 100},
+		{Name: "Tags", DBName: "tags", DataType: schema.String, Nullable: true},
+		{Name: "UserID", DBName: "user_id", DataType: schema.Uint, Nullable: true, Size: 64},
+	}
+	if columns := s.Columns(); !reflect.DeepEqual(columns, expects) {
+		t.Errorf("invalid columns, expects %+v, got %+v", expects, columns)
+	}
+
+	var wg sync.WaitGroup
+	schemas := make([]*schema.Schema, 10)
+	for i := range schemas {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			schemas[i], _ = DB.SchemaOf(&SchemaOfPost{})
+		}(i)
+	}
+	wg.Wait()
+
+	stmt := &gorm.Statement{DB: DB}
+	if err := stmt.Parse(&SchemaOfPost{}); err != nil {
+		t.Fatalf("failed to parse post, got error %v", err)
+	}
+
+	for _, parsed := range append(schemas, stmt.Schema) {
+		if parsed != s {
+			t.Errorf("schema should be cached, got %p, expects %p", parsed, s)
+		}
+	}
+}
+
+func TestCheckConcurrentMisuse(t *testing.T) {
+	run := func(check bool) (first, second error) {
+		db, err := gorm.Open(&RecordingDialector{}, &gorm.Config{CheckConcurrentMisuse: check, SkipDefaultTransaction: true})
+		if err != nil {
+			t.Fatalf("failed to open, got %v", err)
+		}
+
+		// block the first query until the second one finished
+		var calls int32
+		entered, release := make(chan struct{}), make(chan struct{})
+		db.Callback().Query().Before("gorm:query").Register("tests:block", func(tx *gorm.DB) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				close(entered)
+				<-release
+			}
+		})
+
+		shared := db.Where("name = ?", "concurrent_misuse")
+		done := make(chan error)
+		go func() {
+			done <- shared.Find(&[]User{}).Error
+		}()
+
+		<-entered
+		second = shared.Find(&[]User{}).Error
+		close(release)
+		return <-done, second
+	}
+
+	first, second := run(true)
+	if !errors.Is(second, gorm.ErrConcurrentStatementReuse) {
+		t.Errorf("concurrent finisher should return ErrConcurrentStatementReuse, got %v", second)
+	}
+
+	if !errors.Is(first, gorm.ErrConcurrentStatementReuse) || !strings.Contains(first.Error(), `statement of table "users" was reused`) {
+		t.Errorf("executing finisher should report the reused statement, got %v", first)
+	}
+
+	first, second = run(false)
+	if errors.Is(first, gorm.ErrConcurrentStatementReuse) || errors.Is(second, gorm.ErrConcurrentStatementReuse) {
+		t.Errorf("shouldn't check concurrent misuse if disabled, got %v, %v", first, second)
+	}
+
+	db, _ := gorm.Open(&RecordingDialector{}, &gorm.Config{CheckConcurrentMisuse: true})
+	shared := db.Where("name = ?", "sequential")
+	if err := shared.Find(&[]User{}).Error; err != nil {
+		t.Errorf("sequential finisher shouldn't fail, got %v", err)
+	}
+
+	if err := shared.Find(&[]User{}).Error; err != nil {
+		t.Errorf("sequential finisher shouldn't fail, got %v", err)
+	}
+}
@@ -1,8 +1,13 @@
 package tests_test
 
 import (
+	"errors"
+	"regexp"
+	"strings"
 	"testing"
 
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	. "gorm.io/gorm/utils/tests"
 )
 
@@ -107,3 +112,90 @@ func TestGroupBy(t *testing.T) {
 		}
 	}
 }
+
+func TestGroupByStructured(t *testing.T) {
+	users := []User{
+		{Name: "group_structured", Age: 10}, {Name: "group_structured", Age: 20}, {Name: "group_structured", Age: 30},
+		{Name: "group_structured1", Age: 40},
+	}
+	if err := DB.Create(&users).Error; err != nil {
+		t.Fatalf("errors happened when create: %v", err)
+	}
+
+	type result struct {
+		Name  string
+		Total int
+	}
+
+	var results []result
+	if err := DB.Model(&User{}).Select("name, sum(age) as total").Where("name LIKE ?", "group_structured%").
+		Group(clause.Column{Table: clause.CurrentTable, Name: "name"}).
+		Having(clause.Gt{Column: clause.Expr{SQL: "count(*)"}, Value: 1}).Find(&results).Error; err != nil {
+		t.Fatalf("no error should happen, but got %v", err)
+	}
+
+	if len(results) != 1 || results[0].Name != "group_structured" || results[0].Total != 60 {
+		t.Errorf("should group by the structured column, got %+v", results)
+	}
+
+	results = nil
+	if err := DB.Model(&User{}).Select("name, sum(age) as total").Where("name LIKE ?", "group_structured%").
+		Group(clause.Expr{SQL: "name"}).Having(clause.Lt{Column: clause.Expr{SQL: "sum(age)"}, Value: 50}).Find(&results).Error; err != nil {
+		t.Fatalf("no error should happen, but got %v", err)
+	}
+
+	if len(results) != 1 || results[0].Name != "group_structured1" || results[0].Total != 40 {
+		t.Errorf("should group by the expression, got %+v", results)
+	}
+
+	stmt := DB.Session(&gorm.Session{DryRun: true}).Model(&User{}).Select("company_id, count(*)").
+		Group(clause.Column{Name: "company_id"}).Group(clause.Expr{SQL: "LEFT(name, ?)", Vars: []interface{}{1}}).
+		Having(clause.Gt{Column: clause.Expr{SQL: "count(*)"}, Value: 5}).Find(&[]User{}).Statement
+	if !regexp.MustCompile(`GROUP BY .company_id.,LEFT\(name, .+\) HAVING count\(\*\) > `).MatchString(stmt.SQL.String()) {
+		t.Errorf("columns should be quoted and expressions should be built, got %v", stmt.SQL.String())
+	}
+
+	if len(stmt.Vars) < 2 || stmt.Vars[len(stmt.Vars)-2] != 1 || stmt.Vars[len(stmt.Vars)-1] != 5 {
+		t.Errorf("vars of group by and having should be added, got %v", stmt.Vars)
+	}
+
+	if err := DB.Model(&User{}).Group(1).Find(&[]User{}).Error; err == nil {
+		t.Errorf("should return error for unsupported group args")
+	}
+}
+
+func TestStrictGroupBy(t *testing.T) {
+	tx := DB.Session(&gorm.Session{StrictGroupBy: true})
+
+	var results []struct {
+		Name  string
+		Total int
+	}
+	if err := tx.Model(&User{}).Select("name, age, sum(age) as total").Group("name").Find(&results).Error; !errors.Is(err, gorm.ErrNonAggregatedColumn) || !strings.Contains(err.Error(), "age") {
+		t.Errorf("should report non-aggregated columns, got %v", err)
+	}
+
+	if err := tx.Model(&User{}).Select("name", "age").Group("name").Find(&results).Error; !errors.Is(err, gorm.ErrNonAggregatedColumn) {
+		t.Errorf("should report non-aggregated columns, got %v", err)
+	}
+
+	if err := DB.Model(&User{}).Select("name, age").Group("name").Session(&gorm.Session{DryRun: true}).Find(&results).Error; err != nil {
+		t.Errorf("should not check without StrictGroupBy, got %v", err)
+	}
+
+	passes := []*gorm.DB{
+		tx.Model(&User{}).Select("name, sum(age) as total").Group("name"),
+		tx.Model(&User{}).Select("`users`.`name`, COUNT(*) AS total, 1").Group("users.name"),
+		tx.Model(&User{}).Select("Name", "MAX(age) total", "COALESCE(SUM(age), 0) AS sum").Group(clause.Column{Name: "name"}),
+		tx.Model(&User{}).Select("name, active, count(*) as total").Group("name, active"),
+		tx.Model(&User{}).Select("name, active, count(*) as total").Group("name").Group("active"),
+		tx.Model(&User{}).Select("LOWER(name), count(*)").Group(clause.Expr{SQL: "LOWER(name)"}),
+		tx.Model(&User{}).Select("id, name").Group(clause.Column{Name: clause.PrimaryKey}).Group("name"),
+	}
+
+	for idx, pass := range passes {
+		if err := pass.Find(&results).Error; err != nil {
+			t.Errorf("#%d should pass the check, got %v", idx, err)
+		}
+	}
+}
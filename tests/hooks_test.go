@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"gorm.io/gorm"
 	. "gorm.io/gorm/utils/tests"
@@ -566,3 +567,321 @@ func TestUpdateCallbacks(t *testing.T) {
 		t.Fatalf("before update should not be called")
 	}
 }
+
+type Product6Brand struct {
+	ID   uint
+	Name string
+}
+
+type Product6 struct {
+	ID              uint
+	Name            string
+	Price           int
+	Product6BrandID uint
+	Product6Brand   Product6Brand
+	LoadedColumns   []string `gorm:"-"`
+}
+
+func (p *Product6) AfterFind(tx *gorm.DB) error {
+	p.LoadedColumns = tx.Statement.LoadedColumns()
+	return nil
+}
+
+func TestAfterFindLoadedColumns(t *testing.T) {
+	DB.Migrator().DropTable(&Product6{}, &Product6Brand{})
+	DB.AutoMigrate(&Product6Brand{}, &Product6{})
+
+	product := Product6{Name: "loaded_columns", Price: 10, Product6Brand: Product6Brand{Name: "brand"}}
+	if err := DB.Create(&product).Error; err != nil {
+		t.Fatalf("failed to create product, got error %v", err)
+	}
+
+	var result Product6
+	if err := DB.Select("id", "name").First(&result, product.ID).Error; err != nil {
+		t.Fatalf("failed to query product, got error %v", err)
+	}
+	AssertEqual(t, result.LoadedColumns, []string{"id", "name"})
+
+	var results []Product6
+	if err := DB.Find(&results, product.ID).Error; err != nil {
+		t.Fatalf("failed to query products, got error %v", err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("should find one product, got %v", len(results))
+	}
+	AssertEqual(t, results[0].LoadedColumns, []string{"id", "name", "price", "product6_brand_id"})
+
+	result = Product6{}
+	if err := DB.Joins("Product6Brand").First(&result, product.ID).Error; err != nil {
+		t.Fatalf("failed to query product with joins, got error %v", err)
+	}
+	AssertEqual(t, result.Product6Brand.Name, "brand")
+	AssertEqual(t, result.LoadedColumns, []string{"id", "name", "price", "product6_brand_id"})
+}
+
+type TimePrecisionEvent struct {
+	ID         uint
+	Name       string
+	HappenedAt time.Time
+	Changed    bool `gorm:"-"`
+}
+
+func (e *TimePrecisionEvent) BeforeUpdate(tx *gorm.DB) error {
+	e.Changed = tx.Statement.Changed("HappenedAt")
+	return nil
+}
+
+func TestChangedWithTimePrecision(t *testing.T) {
+	DB.Migrator().DropTable(&TimePrecisionEvent{})
+	DB.AutoMigrate(&TimePrecisionEvent{})
+
+	now := time.Now()
+	event := TimePrecisionEvent{Name: "time_precision", HappenedAt: now.Round(0)}
+	DB.Create(&event)
+
+	// monotonic clock reading is ignored
+	DB.Model(&event).Updates(map[string]interface{}{"HappenedAt": now})
+	if event.Changed {
+		t.Errorf("time only differs in monotonic clock shouldn't be changed")
+	}
+
+	// beyond default microsecond precision
+	happenedAt := time.Date(2023, 1, 2, 3, 4, 5, 123456000, time.UTC)
+	DB.Model(&event).Updates(TimePrecisionEvent{HappenedAt: happenedAt})
+	DB.Model(&event).Updates(TimePrecisionEvent{HappenedAt: happenedAt.Add(100 * time.Nanosecond)})
+	if event.Changed {
+		t.Errorf("time truncated to the same microsecond shouldn't be changed")
+	}
+
+	DB.Model(&event).Updates(TimePrecisionEvent{HappenedAt: event.HappenedAt.Add(time.Millisecond)})
+	if !event.Changed {
+		t.Errorf("time differs in milliseconds should be changed")
+	}
+}
+
+func TestSaveOntoStaleModel(t *testing.T) {
+	DB.Migrator().DropTable(&TimePrecisionEvent{})
+	DB.AutoMigrate(&TimePrecisionEvent{})
+
+	event := TimePrecisionEvent{Name: "save_stale_model", HappenedAt: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)}
+	DB.Create(&event)
+
+	// 数据库中的记录已被修改，内存中的 model 是旧的
+	DB.Model(&TimePrecisionEvent{}).Where("id = ?", event.ID).Update("name", "save_stale_model_changed")
+
+	readBack := event
+	if result := DB.Model(&event).Save(&readBack); result.Error != nil || result.RowsAffected != 1 {
+		t.Fatalf("save should always update, got error %v, rows affected %v", result.Error, result.RowsAffected)
+	}
+
+	var result TimePrecisionEvent
+	if DB.First(&result, event.ID); result.Name != "save_stale_model" {
+		t.Errorf("record should be updated by save, got %v", result.Name)
+	}
+}
+
+type ValidatedProduct struct {
+	gorm.Model
+	Name    string
+	Price   int64
+	Code    string
+	Checked bool `gorm:"-"`
+}
+
+func (p *ValidatedProduct) BeforeUpdate(tx *gorm.DB) error {
+	p.Checked = true
+	if p.Price < 0 {
+		return errors.New("price can't be negative")
+	}
+
+	if code, ok := tx.Statement.UpdatingValues()["code"]; ok && code == "" {
+		return errors.New("code can't be blank")
+	}
+
+	if tx.Statement.Changed("Name") {
+		tx.Statement.SetColumn("Code", strings.ToUpper(p.Name))
+	}
+	return nil
+}
+
+func TestBeforeUpdateWithMap(t *testing.T) {
+	DB.Migrator().DropTable(&ValidatedProduct{})
+	DB.AutoMigrate(&ValidatedProduct{})
+
+	product := ValidatedProduct{Name: "apple", Price: 10, Code: "APPLE"}
+	DB.Create(&product)
+
+	if err := DB.Model(&product).Updates(map[string]interface{}{"price": -1}).Error; err == nil {
+		t.Errorf("should fail to update with invalid value from map")
+	}
+
+	if !product.Checked {
+		t.Errorf("changes of hooks should be kept on model")
+	}
+
+	if err := DB.Model(&product).Update("Code", "").Error; err == nil {
+		t.Errorf("should fail to update with invalid value from UpdatingValues")
+	}
+
+	var result ValidatedProduct
+	DB.First(&result, product.ID)
+	AssertObjEqual(t, result, product, "Name", "Price", "Code")
+	if product.Price != 10 || product.Code != "APPLE" {
+		t.Errorf("model should not be changed if failed to update, got %+v", product)
+	}
+
+	if err := DB.Model(&product).Updates(map[string]interface{}{"name": "banana", "price": 20}).Error; err != nil {
+		t.Fatalf("failed to update with map, got %v", err)
+	}
+
+	DB.First(&result, product.ID)
+	if result.Name != "banana" || result.Price != 20 || result.Code != "BANANA" {
+		t.Errorf("changes of SetColumn in hooks should be updated, got %+v", result)
+	}
+
+	if err := DB.Model(&product).Update("price", gorm.Expr("price - ?", 5)).Error; err != nil {
+		t.Fatalf("failed to update with expression, got %v", err)
+	}
+
+	DB.First(&result, product.ID)
+	if result.Price != 15 {
+		t.Errorf("failed to update with expression, got %+v", result)
+	}
+}
+
+func TestUpdatingValues(t *testing.T) {
+	stmt := &gorm.Statement{DB: DB}
+	if err := stmt.Parse(&ValidatedProduct{}); err != nil {
+		t.Fatalf("failed to parse, got %v", err)
+	}
+
+	stmt.Dest = map[string]interface{}{"Name": "apple", "price": 10, "unknown": 1}
+	AssertEqual(t, stmt.UpdatingValues(), map[string]interface{}{"name": "apple", "price": 10, "unknown": 1})
+
+	stmt.SetColumn("Code", "APPLE")
+	AssertEqual(t, stmt.UpdatingValues()["code"], "APPLE")
+
+	stmt.Selects = []string{"name"}
+	AssertEqual(t, stmt.UpdatingValues(), map[string]interface{}{"name": "apple"})
+
+	stmt.Selects = []string{"name", "code"}
+	stmt.Dest = &ValidatedProduct{Name: "banana", Price: 20}
+	AssertEqual(t, stmt.UpdatingValues(), map[string]interface{}{"name": "banana", "code": ""})
+}
+
+type StampedProduct struct {
+	gorm.Model
+	Name      string
+	Code      string
+	Price     int64
+	Stamped   bool
+	Misspells []string `gorm:"-"`
+}
+
+func (p *StampedProduct) BeforeSave(tx *gorm.DB) error {
+	name, _ := tx.Statement.ColumnValue("Name")
+	values := map[string]interface{}{
+		"Code":    strings.ToUpper(name.(string)),
+		"price":   100,
+		"Stamped": true,
+	}
+	for _, misspell := range p.Misspells {
+		values[misspell] = "misspelled"
+	}
+
+	tx.Statement.SetColumns(values)
+	return nil
+}
+
+func TestBeforeSaveSetColumns(t *testing.T) {
+	DB.Migrator().DropTable(&StampedProduct{})
+	DB.AutoMigrate(&StampedProduct{})
+
+	product := StampedProduct{Name: "apple"}
+	if err := DB.Create(&product).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+
+	var result StampedProduct
+	DB.First(&result, product.ID)
+	if result.Code != "APPLE" || result.Price != 100 || !result.Stamped {
+		t.Errorf("columns set by hooks should be saved, got %+v", result)
+	}
+
+	products := []StampedProduct{{Name: "banana"}, {Name: "cherry"}}
+	if err := DB.Create(&products).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+
+	if products[0].Code != "BANANA" || products[1].Code != "CHERRY" || !products[1].Stamped {
+		t.Errorf("columns should be set to each record, got %+v", products)
+	}
+
+	misspelled := StampedProduct{Name: "durian", Misspells: []string{"Prise"}}
+	err := DB.Create(&misspelled).Error
+	if !errors.Is(err, gorm.ErrInvalidField) || !strings.Contains(err.Error(), "Prise") {
+		t.Errorf("should return ErrInvalidField with the unknown name, got %v", err)
+	}
+
+	if misspelled.Code != "" || misspelled.Price != 0 || misspelled.Stamped {
+		t.Errorf("no column should be set if any name is unknown, got %+v", misspelled)
+	}
+
+	var count int64
+	DB.Model(&StampedProduct{}).Where("name = ?", "durian").Count(&count)
+	if count != 0 {
+		t.Errorf("record shouldn't be created, got %v", count)
+	}
+
+	if err := DB.Model(&product).Updates(map[string]interface{}{"name": "avocado"}).Error; err != nil {
+		t.Fatalf("failed to update, got %v", err)
+	}
+
+	DB.First(&result, product.ID)
+	if result.Name != "avocado" || result.Code != "AVOCADO" {
+		t.Errorf("columns set by hooks should be updated from map, got %+v", result)
+	}
+
+	product.Misspells = []string{"Stampd", "Cod"}
+	err = DB.Model(&product).Updates(map[string]interface{}{"name": "blueberry"}).Error
+	if !errors.Is(err, gorm.ErrInvalidField) || !strings.Contains(err.Error(), "Cod, Stampd") {
+		t.Errorf("should return all unknown names in one error, got %v", err)
+	}
+}
+
+func TestStatementColumnValue(t *testing.T) {
+	stmt := &gorm.Statement{DB: DB}
+	if err := stmt.Parse(&StampedProduct{}); err != nil {
+		t.Fatalf("failed to parse, got %v", err)
+	}
+
+	stmt.Dest = map[string]interface{}{"name": "apple"}
+	if v, ok := stmt.ColumnValue("Name"); !ok || v != "apple" {
+		t.Errorf("should read value from map, got %v, %v", v, ok)
+	}
+
+	if _, ok := stmt.ColumnValue("Code"); ok {
+		t.Errorf("column not in map shouldn't be found")
+	}
+
+	stmt.Dest = []map[string]interface{}{{"name": "apple"}, {"name": "banana"}}
+	stmt.CurDestIndex = 1
+	if v, ok := stmt.ColumnValue("name"); !ok || v != "banana" {
+		t.Errorf("should read value from map of CurDestIndex, got %v, %v", v, ok)
+	}
+
+	stmt.Dest = &[]StampedProduct{{Name: "cherry"}, {Name: "durian", Price: 10}}
+	if v, ok := stmt.ColumnValue("price"); !ok || v != int64(10) {
+		t.Errorf("should read value from struct of CurDestIndex, got %v, %v", v, ok)
+	}
+
+	stmt.Dest = &StampedProduct{Name: "cherry"}
+	if v, ok := stmt.ColumnValue("Name"); !ok || v != "cherry" {
+		t.Errorf("should read value from struct, got %v, %v", v, ok)
+	}
+
+	if _, ok := stmt.ColumnValue("Unknown"); ok {
+		t.Errorf("unknown column shouldn't be found")
+	}
+}
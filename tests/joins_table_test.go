@@ -1,6 +1,7 @@
 package tests_test
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -114,3 +115,142 @@ func TestOverrideJoinTable(t *testing.T) {
 		t.Errorf("person's addresses expects 2, got %v", count)
 	}
 }
+
+type Follower struct {
+	ID             uint
+	Name           string
+	Following      []Follower `gorm:"many2many:follows;joinForeignKey:FollowerID;joinReferences:FollowingID"`
+	Followers      []Follower `gorm:"many2many:follows;joinForeignKey:FollowingID;joinReferences:FollowerID"`
+	FollowingJoins []Follow   `gorm:"-"`
+	FollowerJoins  []*Follow  `gorm:"-"`
+}
+
+type Follow struct {
+	FollowerID  uint `gorm:"primaryKey"`
+	FollowingID uint `gorm:"primaryKey"`
+	CreatedAt   time.Time
+	State       string
+}
+
+func TestSelfReferentialJoinTable(t *testing.T) {
+	db := DB.Session(&gorm.Session{})
+	for _, field := range []string{"Following", "Followers"} {
+		if err := db.SetupJoinTable(&Follower{}, field, &Follow{}); err != nil {
+			t.Fatalf("Failed to setup join table for %v, got error %v", field, err)
+		}
+	}
+
+	db.Migrator().DropTable(&Follow{}, &Follower{})
+	if err := db.AutoMigrate(&Follower{}, &Follow{}); err != nil {
+		t.Fatalf("Failed to migrate, got %v", err)
+	}
+
+	alice, bob, carol := Follower{Name: "alice"}, Follower{Name: "bob"}, Follower{Name: "carol"}
+	db.Create(&[]*Follower{&alice, &bob, &carol})
+
+	if err := db.Model(&alice).Association("Following").Append(&bob, &carol); err != nil {
+		t.Fatalf("Failed to append following, got error %v", err)
+	}
+
+	if err := db.Model(&carol).Association("Following").Append(&bob); err != nil {
+		t.Fatalf("Failed to append following, got error %v", err)
+	}
+
+	var follows []Follow
+	if err := db.Order("follower_id, following_id").Find(&follows).Error; err != nil || len(follows) != 3 {
+		t.Fatalf("Failed to find follows, got error %v, length %v", err, len(follows))
+	}
+
+	if follows[0].FollowerID != alice.ID || follows[0].FollowingID != bob.ID || follows[0].CreatedAt.IsZero() {
+		t.Fatalf("Follow should be created with join model, got %+v", follows[0])
+	}
+
+	db.Model(&Follow{}).Where("follower_id = ? AND following_id = ?", alice.ID, bob.ID).Update("state", "accepted")
+	db.Model(&Follow{}).Where("follower_id = ? AND following_id = ?", alice.ID, carol.ID).Update("state", "pending")
+	db.Model(&Follow{}).Where("follower_id = ? AND following_id = ?", carol.ID, bob.ID).Update("state", "accepted")
+
+	t.Run("Preload", func(t *testing.T) {
+		var result Follower
+		if err := db.Preload("Following", func(tx *gorm.DB) *gorm.DB { return tx.Order("id") }).Preload("Followers").First(&result, alice.ID).Error; err != nil {
+			t.Fatalf("Failed to preload, got error %v", err)
+		}
+
+		if len(result.Following) != 2 || result.Following[0].Name != "bob" || result.Following[1].Name != "carol" || len(result.Followers) != 0 {
+			t.Fatalf("Failed to preload self-referential many2many, got %+v", result)
+		}
+
+		var bobResult Follower
+		if err := db.Preload("Followers", func(tx *gorm.DB) *gorm.DB { return tx.Order("id") }).First(&bobResult, bob.ID).Error; err != nil {
+			t.Fatalf("Failed to preload, got error %v", err)
+		}
+
+		if len(bobResult.Followers) != 2 || bobResult.Followers[0].Name != "alice" || bobResult.Followers[1].Name != "carol" {
+			t.Fatalf("Failed to preload followers, got %+v", bobResult.Followers)
+		}
+	})
+
+	t.Run("PreloadJoinTable", func(t *testing.T) {
+		var results []Follower
+		if err := db.Preload("Following", gorm.PreloadJoinTable("FollowingJoins")).Order("id").Find(&results, []uint{alice.ID, carol.ID}).Error; err != nil {
+			t.Fatalf("Failed to preload join table, got error %v", err)
+		}
+
+		if len(results) != 2 || len(results[0].Following) != 2 || len(results[0].FollowingJoins) != 2 || len(results[1].FollowingJoins) != 1 {
+			t.Fatalf("Failed to preload join rows, got %+v", results)
+		}
+
+		states := map[uint]string{}
+		for _, follow := range results[0].FollowingJoins {
+			if follow.FollowerID != alice.ID || follow.CreatedAt.IsZero() {
+				t.Errorf("Join row should belong to alice, got %+v", follow)
+			}
+			states[follow.FollowingID] = follow.State
+		}
+
+		if states[bob.ID] != "accepted" || states[carol.ID] != "pending" {
+			t.Errorf("Join rows should have extra columns, got %+v", results[0].FollowingJoins)
+		}
+
+		if follow := results[1].FollowingJoins[0]; follow.FollowerID != carol.ID || follow.FollowingID != bob.ID || follow.State != "accepted" {
+			t.Errorf("Join row should belong to carol, got %+v", follow)
+		}
+	})
+
+	t.Run("PreloadJoinTableWithConditions", func(t *testing.T) {
+		var result Follower
+		if err := db.Preload("Following", gorm.PreloadJoinTable("FollowingJoins", "state = ?", "accepted")).First(&result, alice.ID).Error; err != nil {
+			t.Fatalf("Failed to preload join table, got error %v", err)
+		}
+
+		if len(result.Following) != 1 || result.Following[0].Name != "bob" || len(result.FollowingJoins) != 1 || result.FollowingJoins[0].State != "accepted" {
+			t.Fatalf("Failed to preload accepted following, got %+v", result)
+		}
+
+		var bobResult Follower
+		if err := db.Preload("Followers", gorm.PreloadJoinTable("", "state = ?", "accepted"), "name <> ?", "alice").First(&bobResult, bob.ID).Error; err != nil {
+			t.Fatalf("Failed to preload join table, got error %v", err)
+		}
+
+		if len(bobResult.Followers) != 1 || bobResult.Followers[0].Name != "carol" || len(bobResult.FollowerJoins) != 0 {
+			t.Fatalf("Failed to preload accepted followers, got %+v", bobResult)
+		}
+
+		if err := db.Preload("Followers", gorm.PreloadJoinTable("FollowerJoins", "state = ?", "accepted")).First(&bobResult, bob.ID).Error; err != nil {
+			t.Fatalf("Failed to preload join table, got error %v", err)
+		}
+
+		if len(bobResult.Followers) != 2 || len(bobResult.FollowerJoins) != 2 || bobResult.FollowerJoins[0].FollowingID != bob.ID {
+			t.Fatalf("Failed to preload follower join rows, got %+v", bobResult)
+		}
+	})
+
+	t.Run("InvalidJoinField", func(t *testing.T) {
+		if err := db.Preload("Following", gorm.PreloadJoinTable("Name")).First(&Follower{}, alice.ID).Error; !errors.Is(err, gorm.ErrInvalidField) {
+			t.Errorf("should returns invalid field error, got %v", err)
+		}
+
+		if err := db.Preload("Following", gorm.PreloadJoinTable("Followers")).First(&Follower{}, alice.ID).Error; !errors.Is(err, gorm.ErrInvalidField) {
+			t.Errorf("should returns invalid field error for slice of other types, got %v", err)
+		}
+	})
+}
@@ -1,11 +1,14 @@
 package tests_test
 
 import (
+	"errors"
 	"regexp"
 	"sort"
+	"strings"
 	"testing"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	. "gorm.io/gorm/utils/tests"
 )
 
@@ -311,7 +314,8 @@ func TestJoinArgsWithDB(t *testing.T) {
 	if err := DB.Joins("NamedPet", onQuery3).Where("users.name = ?", user.Name).First(&user3).Error; err != nil {
 		t.Fatalf("Failed to load with joins on, got error: %v", err)
 	}
-	AssertEqual(t, user3.NamedPet.ID, 0)
+	// keys of the relation are always selected
+	AssertEqual(t, user3.NamedPet.ID, user1.NamedPet.ID)
 	AssertEqual(t, user3.NamedPet.Name, "joins-args-db_pet_2")
 
 	// test select
@@ -400,3 +404,177 @@ func TestNestedJoins(t *testing.T) {
 		CheckPet(t, *user.Manager.NamedPet, *users2[idx].Manager.NamedPet)
 	}
 }
+
+func TestJoinsDeduplicate(t *testing.T) {
+	user := *GetUser("joins-deduplicate", Config{Company: true, Pets: 2})
+	DB.Create(&user)
+
+	joinCompany := func(db *gorm.DB) *gorm.DB {
+		return db.Joins("Company")
+	}
+	joinPets := func(db *gorm.DB) *gorm.DB {
+		return db.Joins("LEFT JOIN pets ON pets.user_id = users.id AND pets.name = ?", user.Pets[0].Name)
+	}
+
+	stmt := DB.Session(&gorm.Session{DryRun: true}).Scopes(joinCompany, joinCompany, joinPets, joinPets).Find(&User{}).Statement
+	if count := len(regexp.MustCompile(`JOIN`).FindAllString(stmt.SQL.String(), -1)); count != 2 {
+		t.Errorf("identical joins should be joined once, got %v", stmt.SQL.String())
+	}
+
+	var result User
+	if err := DB.Scopes(joinCompany, joinCompany, joinPets, joinPets).Where("users.name = ?", user.Name).First(&result).Error; err != nil {
+		t.Fatalf("failed to query with repeated joins, got %v", err)
+	}
+	AssertEqual(t, result.Company.Name, user.Company.Name)
+
+	stmt = DB.Session(&gorm.Session{DryRun: true}).Joins("LEFT JOIN pets ON pets.user_id = users.id AND pets.name = ?", "another").Scopes(joinPets).Find(&User{}).Statement
+	if count := len(regexp.MustCompile(`JOIN`).FindAllString(stmt.SQL.String(), -1)); count != 2 {
+		t.Errorf("joins with different vars should not be deduplicated, got %v", stmt.SQL.String())
+	}
+}
+
+func TestJoinsWithClause(t *testing.T) {
+	user := *GetUser("joins-clause", Config{Pets: 2})
+	DB.Create(&user)
+
+	join := clause.Join{
+		Type:  clause.InnerJoin,
+		Table: clause.Table{Name: "pets", Alias: "p"},
+		ON: clause.Where{Exprs: []clause.Expression{
+			clause.Eq{Column: clause.Column{Table: "p", Name: "user_id"}, Value: clause.PrimaryColumn},
+			clause.Eq{Column: clause.Column{Table: "p", Name: "name"}, Value: user.Pets[1].Name},
+		}},
+	}
+
+	stmt := DB.Session(&gorm.Session{DryRun: true}).Joins(join).Scopes(func(db *gorm.DB) *gorm.DB {
+		return db.Joins(join)
+	}).Find(&User{}).Statement
+	if !regexp.MustCompile("FROM .users. INNER JOIN .pets. .p. ON .p.\\..user_id. = .users.\\..id. AND .p.\\..name. = ").MatchString(stmt.SQL.String()) {
+		t.Errorf("join clause should be rendered as it is, got %v", stmt.SQL.String())
+	}
+
+	if count := len(regexp.MustCompile(`JOIN`).FindAllString(stmt.SQL.String(), -1)); count != 1 {
+		t.Errorf("identical join clauses should be joined once, got %v", stmt.SQL.String())
+	}
+
+	var results []User
+	if err := DB.Joins(&join).Where("users.name = ?", user.Name).Find(&results).Error; err != nil {
+		t.Fatalf("failed to query with join clause, got %v", err)
+	}
+
+	if len(results) != 1 || results[0].ID != user.ID {
+		t.Errorf("should find user with join clause, got %+v", results)
+	}
+
+	var count int64
+	if err := DB.Model(&User{}).Joins(join).Where("users.name = ?", user.Name).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("should count user with join clause, got %v, %v", count, err)
+	}
+}
+
+func TestJoinsSelectDuplicatedColumns(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		DB.Create(&Company{Name: "joins-duplicated-columns-padding"})
+	}
+
+	user := *GetUser("joins-duplicated-columns", Config{Company: true})
+	DB.Create(&user)
+
+	if user.ID == uint(user.Company.ID) {
+		DB.Create(&Company{Name: "joins-duplicated-columns-padding"})
+		user.Company = Company{Name: "joins-duplicated-columns-company"}
+		DB.Save(&user)
+	}
+
+	for _, selects := range []string{"users.*, Company.*", "Company.*, users.*", "`users`.*, `Company`.`id`, `Company`.`name`"} {
+		var result User
+		if err := DB.Joins("Company").Select(selects).First(&result, "users.id = ?", user.ID).Error; err != nil {
+			t.Fatalf("failed to query with %v, got error %v", selects, err)
+		}
+
+		if result.ID != user.ID || result.Name != user.Name || result.Company.ID != user.Company.ID || result.Company.Name != user.Company.Name {
+			t.Errorf("duplicated columns of %v should be assigned to the model and the joined relation, got %v, %v, %+v", selects, result.ID, result.Name, result.Company)
+		}
+	}
+
+	var users []User
+	err := DB.Raw("SELECT users.*, companies.* FROM users LEFT JOIN companies ON companies.id = users.company_id WHERE users.id = ?", user.ID).Scan(&users).Error
+	if !errors.Is(err, gorm.ErrAmbiguousColumn) {
+		t.Errorf("should report ambiguous columns for raw SQL, got %v", err)
+	}
+
+	if len(users) != 1 || users[0].ID != user.ID || users[0].Name != user.Name {
+		t.Errorf("ambiguous columns should not overwrite the model, got %+v", users)
+	}
+}
+
+func TestJoinsSelectRelationColumns(t *testing.T) {
+	dryDB := DB.Session(&gorm.Session{DryRun: true})
+
+	stmts := map[string]*gorm.Statement{
+		"select": dryDB.Joins("Manager").Select("Manager.name").Find(&[]User{}).Statement,
+		"func": dryDB.Joins("Manager", func(tx *gorm.DB) *gorm.DB {
+			return tx.Select("Name")
+		}).Find(&[]User{}).Statement,
+	}
+
+	for name, stmt := range stmts {
+		sql := stmt.SQL.String()
+		if !regexp.MustCompile(`.Manager.\..name. AS .Manager__name.`).MatchString(sql) || !regexp.MustCompile(`.Manager.\..id. AS .Manager__id.`).MatchString(sql) {
+			t.Errorf("%v: selected columns and keys of the relation should be selected, got %v", name, sql)
+		}
+
+		if strings.Contains(sql, "Manager__age") || strings.Contains(sql, "Manager__created_at") {
+			t.Errorf("%v: other columns of the relation should not be selected, got %v", name, sql)
+		}
+
+		if !regexp.MustCompile(`SELECT .users.\..id.,.*.users.\..age.`).MatchString(sql) {
+			t.Errorf("%v: columns of the model should be selected, got %v", name, sql)
+		}
+	}
+
+	sql := dryDB.Joins("Account").Omit("Account.number", "Account.id", "Account.user_id").Find(&[]User{}).Statement.SQL.String()
+	if strings.Contains(sql, "Account__number") || !strings.Contains(sql, "Account__created_at") {
+		t.Errorf("omitted columns of the relation should not be selected, got %v", sql)
+	}
+
+	if !strings.Contains(sql, "Account__id") || !strings.Contains(sql, "Account__user_id") {
+		t.Errorf("keys of the relation should always be selected, got %v", sql)
+	}
+
+	sql = dryDB.Joins("Manager.Company").Select("Manager.Company.name").Find(&[]User{}).Statement.SQL.String()
+	if !strings.Contains(sql, "Manager__Company__name") || !strings.Contains(sql, "Manager__Company__id") || !strings.Contains(sql, "Manager__age") {
+		t.Errorf("columns of the nested relation should be selected, got %v", sql)
+	}
+
+	manager := GetUser("joins-select-relation-manager", Config{Account: true})
+	user := GetUser("joins-select-relation", Config{Account: true})
+	user.Manager = manager
+	DB.Create(user)
+
+	var result User
+	if err := DB.Joins("Manager").Joins("Account").Select("Manager.name").Omit("Account.number").First(&result, user.ID).Error; err != nil {
+		t.Fatalf("failed to query with relation columns, got error: %v", err)
+	}
+
+	if result.Name != user.Name || result.Age != user.Age {
+		t.Errorf("columns of the model should be scanned, got %+v", result)
+	}
+
+	if result.Manager == nil || result.Manager.ID != manager.ID || result.Manager.Name != manager.Name {
+		t.Fatalf("selected columns of the relation should be scanned, got %+v", result.Manager)
+	}
+
+	if result.Manager.Age != 0 || !result.Manager.CreatedAt.IsZero() {
+		t.Errorf("not selected columns of the relation should be zero, got %+v", result.Manager)
+	}
+
+	if result.Account.ID != user.Account.ID || !result.Account.UserID.Valid || result.Account.Number != "" || result.Account.CreatedAt.IsZero() {
+		t.Errorf("omitted columns of the relation should be zero, got %+v", result.Account)
+	}
+
+	var noManager User
+	if err := DB.Joins("Manager").Select("Manager.name").First(&noManager, manager.ID).Error; err != nil || noManager.Manager != nil {
+		t.Errorf("relation without record should be nil, got error: %v, manager: %+v", err, noManager.Manager)
+	}
+}
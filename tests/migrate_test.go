@@ -6,13 +6,16 @@ import (
 	"math/rand"
 	"os"
 	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
+	"gorm.io/gorm/migrator"
 	"gorm.io/gorm/schema"
 	. "gorm.io/gorm/utils/tests"
 )
@@ -220,6 +223,73 @@ func TestSmartMigrateColumn(t *testing.T) {
 	}
 }
 
+func TestMigrateColumnOrderTag(t *testing.T) {
+	type ColumnOrderAudit struct {
+		CreatedBy string
+		UpdatedBy string
+	}
+
+	type ColumnOrderUser struct {
+		ColumnOrderAudit `gorm:"order:100"`
+		Name             string
+		ID               uint `gorm:"order:-1"`
+		Age              int
+		DeletedAt        gorm.DeletedAt `gorm:"order:101"`
+	}
+
+	DB.Migrator().DropTable(&ColumnOrderUser{})
+	var sqls []string
+	tx := DB.Session(&gorm.Session{Logger: Tracer{
+		Logger: DB.Config.Logger,
+		Test: func(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+			sql, _ := fc()
+			sqls = append(sqls, sql)
+		},
+	}})
+
+	if err := tx.Migrator().CreateTable(&ColumnOrderUser{}); err != nil {
+		t.Fatalf("failed to create table, got %v", err)
+	}
+
+	var createTableSQL string
+	for _, sql := range sqls {
+		if strings.HasPrefix(sql, "CREATE TABLE") {
+			createTableSQL = sql
+		}
+	}
+
+	if !regexp.MustCompile(`\(.id. .+,.name. .+,.age. .+,.created_by. .+,.updated_by. .+,.deleted_at. [^,]+,`).MatchString(createTableSQL) {
+		t.Errorf("columns should be created in order, got %v", createTableSQL)
+	}
+
+	columnTypes, err := DB.Migrator().ColumnTypes(&ColumnOrderUser{})
+	if err != nil {
+		t.Fatalf("failed to get column types, got %v", err)
+	}
+
+	var columns []string
+	for _, columnType := range columnTypes {
+		columns = append(columns, columnType.Name())
+	}
+	AssertEqual(t, columns, []string{"id", "name", "age", "created_by", "updated_by", "deleted_at"})
+
+	for i := 0; i < 3; i++ {
+		stmt := DB.Session(&gorm.Session{DryRun: true}).Create(&ColumnOrderUser{Name: "jinzhu", Age: 18}).Statement
+		if !regexp.MustCompile(`INSERT INTO .column_order_users. \(.name.,.age.,.created_by.,.updated_by.,.deleted_at.\)`).MatchString(stmt.SQL.String()) {
+			t.Errorf("columns should be inserted in order, got %v", stmt.SQL.String())
+		}
+	}
+
+	user := ColumnOrderUser{Name: "jinzhu", Age: 18, ColumnOrderAudit: ColumnOrderAudit{CreatedBy: "admin"}}
+	if err := DB.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+
+	var result ColumnOrderUser
+	DB.First(&result, user.ID)
+	AssertObjEqual(t, result, user, "ID", "Name", "Age", "CreatedBy")
+}
+
 func TestMigrateWithColumnComment(t *testing.T) {
 	type UserWithColumnComment struct {
 		gorm.Model
@@ -386,6 +456,169 @@ func TestMigrateIndexes(t *testing.T) {
 	}
 }
 
+func TestMigrateExpressionIndexes(t *testing.T) {
+	if DB.Dialector.Name() != "sqlite" {
+		t.Skip()
+	}
+
+	type ExpressionIndexStruct struct {
+		gorm.Model
+		Name  string `gorm:"index:idx_expression_structs_name,expression:substr(name\\,1\\,3)"`
+		Email string `gorm:"uniqueIndex:idx_expression_structs_email,expression:lower(email);index:idx_expression_structs_composite,priority:1"`
+		Age   int    `gorm:"index:idx_expression_structs_composite,priority:1,expression:abs(age - 18)"`
+	}
+
+	DB.Migrator().DropTable(&ExpressionIndexStruct{})
+	if err := DB.AutoMigrate(&ExpressionIndexStruct{}); err != nil {
+		t.Fatalf("failed to migrate expression indexes, got %v", err)
+	}
+
+	results := map[string]string{
+		"idx_expression_structs_name":      "substr(name,1,3)",
+		"idx_expression_structs_email":     "lower(email)",
+		"idx_expression_structs_composite": "`email`,abs(age - 18)",
+	}
+
+	for name, expression := range results {
+		if !DB.Migrator().HasIndex(&ExpressionIndexStruct{}, name) {
+			t.Fatalf("failed to find expression index %v", name)
+		}
+
+		var sql string
+		DB.Raw("SELECT sql FROM sqlite_master WHERE type = ? AND name = ?", "index", name).Scan(&sql)
+		if !strings.Contains(sql, expression) {
+			t.Errorf("index %v should be created with expression %v, got %v", name, expression, sql)
+		}
+	}
+
+	if err := DB.Create(&ExpressionIndexStruct{Name: "jinzhu", Email: "Jinzhu@example.com"}).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+
+	if err := DB.Create(&ExpressionIndexStruct{Name: "jinzhu", Email: "jinzhu@EXAMPLE.com"}).Error; err == nil {
+		t.Errorf("should failed to create with duplicated lower(email)")
+	}
+
+	// migrate again, the expression indexes should be detected by name
+	if err := DB.AutoMigrate(&ExpressionIndexStruct{}); err != nil {
+		t.Fatalf("failed to migrate expression indexes again, got %v", err)
+	}
+}
+
+func TestMigratePartialIndexes(t *testing.T) {
+	if DB.Dialector.Name() != "sqlite" && DB.Dialector.Name() != "postgres" {
+		t.Skip()
+	}
+
+	type PartialIndexStruct struct {
+		gorm.Model
+		Code string `gorm:"size:255;uniqueIndex:idx_partial_index_structs_code,where:deleted_at IS NULL"`
+	}
+
+	DB.Migrator().DropTable(&PartialIndexStruct{})
+	if err := DB.AutoMigrate(&PartialIndexStruct{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	if !DB.Migrator().HasIndex(&PartialIndexStruct{}, "idx_partial_index_structs_code") {
+		t.Fatalf("failed to find partial index")
+	}
+
+	deleted := PartialIndexStruct{Code: "partial"}
+	DB.Create(&deleted)
+	DB.Delete(&deleted)
+
+	if err := DB.Create(&PartialIndexStruct{Code: "partial"}).Error; err != nil {
+		t.Fatalf("deleted records should not be covered by the partial index, got error %v", err)
+	}
+
+	if err := DB.Create(&PartialIndexStruct{Code: "partial"}).Error; err == nil {
+		t.Fatalf("should violate the partial unique index")
+	}
+}
+
+func TestMigratePartialIndexesWithGenericMigrator(t *testing.T) {
+	type PartialIndexUser struct {
+		gorm.Model
+		Code string `gorm:"size:255;uniqueIndex:idx_partial_index_users_code,where:deleted_at IS NULL"`
+		Name string `gorm:"size:255;index:idx_partial_index_users_name,include:code"`
+	}
+
+	var sqls []string
+	db := DB.Session(&gorm.Session{DryRun: true, Logger: Tracer{
+		Logger: DB.Config.Logger,
+		Test: func(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+			sql, _ := fc()
+			sqls = append(sqls, sql)
+		},
+	}})
+
+	m := migrator.Migrator{Config: migrator.Config{DB: db, Dialector: DB.Dialector, SupportIndexWhere: true, SupportIndexInclude: true}}
+	for _, name := range []string{"idx_partial_index_users_code", "idx_partial_index_users_name"} {
+		if err := m.CreateIndex(&PartialIndexUser{}, name); err != nil {
+			t.Fatalf("failed to create index %v, got error %v", name, err)
+		}
+	}
+
+	if len(sqls) != 2 || !strings.HasSuffix(sqls[0], " WHERE deleted_at IS NULL") || !strings.Contains(sqls[1], " INCLUDE (") {
+		t.Errorf("partial and covering indexes should be rendered, got %v", sqls)
+	}
+
+	sqls = nil
+	m.SupportIndexWhere, m.SupportIndexInclude = false, false
+	for _, name := range []string{"idx_partial_index_users_code", "idx_partial_index_users_name"} {
+		if err := m.CreateIndex(&PartialIndexUser{}, name); err != nil {
+			t.Fatalf("failed to create index %v, got error %v", name, err)
+		}
+	}
+
+	if len(sqls) != 2 || strings.Contains(sqls[0], "WHERE") || strings.Contains(sqls[1], "INCLUDE") {
+		t.Errorf("WHERE and INCLUDE should be skipped when unsupported, got %v", sqls)
+	}
+}
+func TestMigrateIndexFillfactor(t *testing.T) {
+	type FillfactorUser struct {
+		ID   uint
+		Name string `gorm:"size:255;index:idx_fillfactor_users_name,option:fillfactor=70"`
+	}
+
+	type InvalidFillfactorUser struct {
+		ID   uint
+		Name string `gorm:"size:255;index:idx_invalid_fillfactor_users_name,option:fillfactor=70) WITH (autovacuum_enabled=false"`
+	}
+
+	var sqls []string
+	db := DB.Session(&gorm.Session{DryRun: true, Logger: Tracer{
+		Logger: DB.Config.Logger,
+		Test: func(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+			sql, _ := fc()
+			sqls = append(sqls, sql)
+		},
+	}})
+
+	m := migrator.Migrator{Config: migrator.Config{DB: db, Dialector: DB.Dialector}}
+	if err := m.CreateIndex(&FillfactorUser{}, "idx_fillfactor_users_name"); err != nil {
+		t.Fatalf("failed to create index, got error %v", err)
+	}
+
+	if len(sqls) != 1 || !strings.HasSuffix(sqls[0], " fillfactor=70") {
+		t.Errorf("fillfactor should be rendered, got %v", sqls)
+	}
+
+	sqls = nil
+	if err := m.CreateIndex(&InvalidFillfactorUser{}, "idx_invalid_fillfactor_users_name"); err == nil || !strings.Contains(err.Error(), "invalid fillfactor") {
+		t.Errorf("should return error for invalid fillfactor, got %v", err)
+	}
+
+	if err := m.CreateTable(&InvalidFillfactorUser{}); err == nil || !strings.Contains(err.Error(), "invalid fillfactor") {
+		t.Errorf("should return error for invalid fillfactor, got %v", err)
+	}
+
+	if len(sqls) != 0 {
+		t.Errorf("invalid fillfactor should not be written to DDL, got %v", sqls)
+	}
+}
+
 func TestTiDBMigrateColumns(t *testing.T) {
 	if !isTiDB() {
 		t.Skip()
@@ -1598,3 +1831,501 @@ func TestMigrateExistingBoolColumnPG(t *testing.T) {
 		}
 	}
 }
+
+type CommentedTable struct {
+	ID   uint
+	Name string `gorm:"comment:first comment"`
+}
+
+func (CommentedTable) TableComment() string {
+	return "commented table"
+}
+
+func TestMigrateColumnComment(t *testing.T) {
+	DB.Migrator().DropTable(&CommentedTable{})
+	if err := DB.AutoMigrate(&CommentedTable{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	if !DB.Migrator().HasTable(&CommentedTable{}) {
+		t.Fatalf("table should be created with table comment")
+	}
+
+	if _, ok := DB.Migrator().(gorm.CommentReader); !ok {
+		t.Fatalf("migrator should implement gorm.CommentReader")
+	}
+
+	if DB.Dialector.Name() != "mysql" && DB.Dialector.Name() != "postgres" {
+		return
+	}
+
+	if comment, err := DB.Migrator().(gorm.CommentReader).ColumnComment(&CommentedTable{}, "Name"); err != nil || comment != "first comment" {
+		t.Fatalf("column comment should be 'first comment', got %v, error %v", comment, err)
+	}
+
+	type CommentedTableV2 struct {
+		ID   uint
+		Name string `gorm:"comment:second comment"`
+	}
+
+	var commentDDL []string
+	session := DB.Table("commented_tables").Session(&gorm.Session{Logger: Tracer{
+		Logger: DB.Config.Logger,
+		Test: func(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+			if sql, _ := fc(); strings.Contains(sql, "second comment") {
+				commentDDL = append(commentDDL, sql)
+			}
+		},
+	}})
+
+	if err := session.AutoMigrate(&CommentedTableV2{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	if len(commentDDL) == 0 {
+		t.Errorf("should emit comment DDL when the comment changed")
+	}
+
+	if comment, err := DB.Migrator().(gorm.CommentReader).ColumnComment(&CommentedTable{}, "name"); err != nil || comment != "second comment" {
+		t.Errorf("column comment should be 'second comment', got %v, error %v", comment, err)
+	}
+
+	// comment unchanged, no DDL
+	commentDDL = nil
+	if err := session.AutoMigrate(&CommentedTableV2{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	if len(commentDDL) != 0 {
+		t.Errorf("should not emit comment DDL when the comment unchanged, got %v", commentDDL)
+	}
+}
+
+// migrator.ColumnType reads the collation of columns
+var _ gorm.ColumnCollationReader = migrator.ColumnType{}
+
+func TestMigrateColumnCollation(t *testing.T) {
+	type CollatedUser struct {
+		ID   uint
+		Name string `gorm:"size:100;not null;charset:utf8mb4;collate:utf8mb4_unicode_ci"`
+	}
+
+	stmt := &gorm.Statement{DB: DB}
+	if err := stmt.Parse(&CollatedUser{}); err != nil {
+		t.Fatalf("failed to parse user, got error %v", err)
+	}
+	field := stmt.Schema.LookUpField("Name")
+
+	m := migrator.Migrator{Config: migrator.Config{DB: DB, Dialector: DB.Dialector, SupportCollate: true}}
+	dataType := m.DataTypeOf(field)
+	if fullDataType := m.FullDataTypeOf(field).SQL; fullDataType != dataType+" CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL" {
+		t.Errorf("full data type should include charset and collation, got %v", fullDataType)
+	}
+
+	m.SupportCollate = false
+	if fullDataType := m.FullDataTypeOf(field).SQL; fullDataType != dataType+" NOT NULL" {
+		t.Errorf("full data type should omit charset and collation when unsupported, got %v", fullDataType)
+	}
+
+	if DB.Dialector.Name() != "mysql" {
+		return
+	}
+
+	DB.Migrator().DropTable(&CollatedUser{})
+	if err := DB.AutoMigrate(&CollatedUser{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	type CollatedUserV2 struct {
+		ID   uint
+		Name string `gorm:"size:100;not null;charset:utf8mb4;collate:utf8mb4_bin"`
+	}
+
+	if err := DB.Table("collated_users").AutoMigrate(&CollatedUserV2{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	columnTypes, err := DB.Table("collated_users").Migrator().ColumnTypes(&CollatedUserV2{})
+	if err != nil {
+		t.Fatalf("failed to get column types, got error %v", err)
+	}
+
+	for _, columnType := range columnTypes {
+		if columnType.Name() == "name" {
+			if collation, ok := columnType.(gorm.ColumnCollationReader).Collation(); ok && collation != "utf8mb4_bin" {
+				t.Errorf("column collation should be changed, got %v", collation)
+			}
+		}
+	}
+}
+
+type generatedColumnDialector struct {
+	gorm.Dialector
+}
+
+func (d generatedColumnDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return migrator.Migrator{Config: migrator.Config{DB: db, Dialector: d, SupportGeneratedColumn: true}}
+}
+
+func (d generatedColumnDialector) SupportGeneratedColumn() bool {
+	return true
+}
+
+func TestMigrateGeneratedColumn(t *testing.T) {
+	type GeneratedUser struct {
+		ID        uint
+		FirstName string
+		LastName  string
+		FullName  string `gorm:"generated:first_name || ' ' || last_name;stored"`
+	}
+
+	stmt := &gorm.Statement{DB: DB}
+	if err := stmt.Parse(&GeneratedUser{}); err != nil {
+		t.Fatalf("failed to parse user, got error %v", err)
+	}
+	field := stmt.Schema.LookUpField("FullName")
+
+	m := migrator.Migrator{Config: migrator.Config{DB: DB, Dialector: DB.Dialector, SupportGeneratedColumn: true}}
+	dataType := m.DataTypeOf(field)
+	if fullDataType := m.FullDataTypeOf(field).SQL; fullDataType != dataType+" GENERATED ALWAYS AS (first_name || ' ' || last_name) STORED" {
+		t.Errorf("full data type should include generated expression, got %v", fullDataType)
+	}
+
+	m.SupportGeneratedColumn = false
+	if fullDataType := m.FullDataTypeOf(field).SQL; fullDataType != dataType {
+		t.Errorf("full data type should omit generated expression when unsupported, got %v", fullDataType)
+	}
+
+	user := GeneratedUser{FirstName: "generated", LastName: "user", FullName: "ignored"}
+	result := DB.Session(&gorm.Session{DryRun: true}).Select("*").Create(&user)
+	if sql := result.Statement.SQL.String(); strings.Contains(sql[:strings.Index(sql, "VALUES")], "full_name") {
+		t.Errorf("generated column should not be inserted, got %v", sql)
+	}
+
+	result = DB.Session(&gorm.Session{DryRun: true}).Model(&GeneratedUser{ID: 1}).Select("*").Updates(&user)
+	if sql := result.Statement.SQL.String(); strings.Contains(sql, "full_name") {
+		t.Errorf("generated column should not be updated, got %v", sql)
+	}
+
+	if DB.Dialector.Name() != "sqlite" {
+		return
+	}
+
+	// generated columns are not created and returned if the dialector doesn't support them
+	result = DB.Session(&gorm.Session{DryRun: true}).Create(&GeneratedUser{FirstName: "generated", LastName: "user"})
+	if sql := result.Statement.SQL.String(); !strings.Contains(sql, "RETURNING") || strings.Contains(sql, "full_name") {
+		t.Errorf("generated column should not be returned if unsupported, got %v", sql)
+	}
+
+	db, err := gorm.Open(generatedColumnDialector{Dialector: DB.Dialector}, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open db, got error %v", err)
+	}
+
+	DB.Migrator().DropTable(&GeneratedUser{})
+	if err := db.Migrator().CreateTable(&GeneratedUser{}); err != nil {
+		t.Fatalf("failed to create table with generated column, got error %v", err)
+	}
+
+	user = GeneratedUser{FirstName: "generated", LastName: "user"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user, got error %v", err)
+	}
+
+	if user.FullName != "generated user" {
+		t.Errorf("generated column should be returned after create, got %v", user.FullName)
+	}
+
+	if err := DB.Model(&user).Clauses(clause.Returning{}).Update("last_name", "updated").Error; err != nil {
+		t.Fatalf("failed to update user, got error %v", err)
+	}
+
+	if user.FullName != "generated updated" {
+		t.Errorf("generated column should be returned after update, got %v", user.FullName)
+	}
+
+	var result2 GeneratedUser
+	if err := DB.First(&result2, user.ID).Error; err != nil || result2.FullName != "generated updated" {
+		t.Errorf("generated column should be queried, got %v, err %v", result2.FullName, err)
+	}
+}
+
+func TestMigrateRenameColumnByPreviousColumn(t *testing.T) {
+	type RenamingUser struct {
+		ID       uint
+		UserName string
+		Age      int
+	}
+
+	DB.Migrator().DropTable(&RenamingUser{})
+	if err := DB.AutoMigrate(&RenamingUser{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	user := RenamingUser{UserName: "previous_column", Age: 18}
+	if err := DB.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user, got error %v", err)
+	}
+
+	type RenamingUserV2 struct {
+		ID   uint
+		Name string `gorm:"column:display_name;previousColumn:login,user_name"`
+		Age  int
+	}
+
+	if err := DB.Table("renaming_users").AutoMigrate(&RenamingUserV2{}); err != nil {
+		t.Fatalf("failed to migrate with previous column, got error %v", err)
+	}
+
+	if DB.Migrator().HasColumn("renaming_users", "user_name") || !DB.Migrator().HasColumn("renaming_users", "display_name") {
+		t.Fatalf("column user_name should be renamed to display_name")
+	}
+
+	var result RenamingUserV2
+	if err := DB.Table("renaming_users").First(&result, user.ID).Error; err != nil || result.Name != user.UserName || result.Age != user.Age {
+		t.Errorf("data should be preserved after renaming column, got %+v, error %v", result, err)
+	}
+
+	// migrate again, previous columns don't exist now
+	if err := DB.Table("renaming_users").AutoMigrate(&RenamingUserV2{}); err != nil {
+		t.Fatalf("failed to migrate again, got error %v", err)
+	}
+
+	if err := DB.Migrator().AddColumn(&RenamingUser{}, "UserName"); err != nil {
+		t.Fatalf("failed to add column, got error %v", err)
+	}
+
+	if err := DB.Table("renaming_users").AutoMigrate(&RenamingUserV2{}); err == nil || !strings.Contains(err.Error(), "user_name") {
+		t.Errorf("should return error when both column and previous column exist, got %v", err)
+	}
+}
+
+func TestMigrateAddCheckConstraint(t *testing.T) {
+	type CheckedAmount struct {
+		Value int
+	}
+
+	type CheckedOrder struct {
+		ID    uint
+		Price CheckedAmount `gorm:"embedded;embeddedPrefix:price_"`
+	}
+
+	DB.Migrator().DropTable(&CheckedOrder{})
+	if err := DB.AutoMigrate(&CheckedOrder{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	if err := DB.Create(&CheckedOrder{Price: CheckedAmount{Value: 10}}).Error; err != nil {
+		t.Fatalf("failed to create order, got error %v", err)
+	}
+
+	type CheckedAmountV2 struct {
+		Value int `gorm:"check:value_positive,price_value > 0"`
+	}
+
+	type CheckedOrderV2 struct {
+		ID    uint
+		Price CheckedAmountV2 `gorm:"embedded;embeddedPrefix:price_"`
+	}
+
+	tx := DB.Table("checked_orders").Session(&gorm.Session{})
+	if tx.Migrator().HasConstraint(&CheckedOrderV2{}, "price_value_positive") {
+		t.Fatalf("check constraint price_value_positive should not exist before migrating")
+	}
+
+	if err := tx.AutoMigrate(&CheckedOrderV2{}); err != nil {
+		t.Fatalf("failed to migrate with check constraint, got error %v", err)
+	}
+
+	if !tx.Migrator().HasConstraint(&CheckedOrderV2{}, "price_value_positive") {
+		t.Fatalf("failed to create check constraint price_value_positive")
+	}
+
+	var count int64
+	if err := tx.Model(&CheckedOrderV2{}).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("data should be kept after adding check constraint, got %v, error %v", count, err)
+	}
+
+	if err := tx.Create(&CheckedOrderV2{Price: CheckedAmountV2{Value: -1}}).Error; err == nil {
+		t.Errorf("should fail to create order violating the check constraint")
+	}
+
+	// migrate again with existing check constraint
+	if err := tx.AutoMigrate(&CheckedOrderV2{}); err != nil {
+		t.Errorf("failed to migrate again, got error %v", err)
+	}
+}
+
+type referencedColumnsDialector struct {
+	gorm.Dialector
+	columns []string
+}
+
+func (d referencedColumnsDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return referencedColumnsMigrator{Migrator: d.Dialector.Migrator(db), columns: d.columns}
+}
+
+type referencedColumnsMigrator struct {
+	gorm.Migrator
+	columns []string
+}
+
+func (m referencedColumnsMigrator) ReferencedColumns(dst interface{}) ([]string, error) {
+	return m.columns, nil
+}
+
+func (m referencedColumnsMigrator) SyncColumns(dst interface{}, opts gorm.SyncOptions) (*gorm.SyncResult, error) {
+	return m.Migrator.(gorm.ColumnsSyncer).SyncColumns(dst, opts)
+}
+
+func TestMigrateSyncColumns(t *testing.T) {
+	type SyncUserV1 struct {
+		ID         uint
+		Nickname   string
+		LegacyCode string
+		Score      int
+		Name       string
+		Age        int
+	}
+
+	type SyncUser struct {
+		ID   uint
+		Name string
+		Age  int
+	}
+
+	type SyncUserWithoutID struct {
+		Name string
+		Age  int
+	}
+
+	tx := DB.Table("sync_users").Session(&gorm.Session{})
+	tx.Migrator().DropTable("sync_users")
+	if err := tx.AutoMigrate(&SyncUserV1{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := tx.Exec("ALTER TABLE sync_users ADD COLUMN legacy_note varchar(100)").Error; err != nil {
+		t.Fatalf("failed to add column manually, got error %v", err)
+	}
+	if err := tx.Create(&SyncUserV1{Nickname: "nick", LegacyCode: "code", Score: 10, Name: "sync", Age: 18}).Error; err != nil {
+		t.Fatalf("failed to create user, got error %v", err)
+	}
+
+	syncColumns := func(tx *gorm.DB, dst interface{}, opts migrator.SyncOptions) (*migrator.SyncResult, error) {
+		return tx.Migrator().(gorm.ColumnsSyncer).SyncColumns(dst, opts)
+	}
+
+	opts := migrator.SyncOptions{DropExtraColumns: true, DryRun: true, IgnoreColumns: []string{"legacy_%"}}
+	if _, ok := tx.Migrator().(migrator.ReferencedColumnsInterface); !ok {
+		// 无法查询外键时不删除任何列，使用实现了 ReferencedColumns 的 Migrator 继续测试
+		result, err := syncColumns(tx, &SyncUser{}, opts)
+		if err != nil {
+			t.Fatalf("failed to sync columns, got error %v", err)
+		}
+		if len(result.Columns) != 0 || len(result.Warnings) != 2 || !strings.Contains(result.Warnings[0], "column nickname") {
+			t.Errorf("columns should not be dropped when foreign keys can't be looked up, got %+v", result)
+		}
+
+		tx = tx.Session(&gorm.Session{})
+		tx.Dialector = referencedColumnsDialector{Dialector: tx.Dialector}
+	}
+
+	result, err := syncColumns(tx, &SyncUser{}, opts)
+	if err != nil {
+		t.Fatalf("failed to sync columns, got error %v", err)
+	}
+	AssertEqual(t, result.Columns, []string{"nickname", "score"})
+	AssertEqual(t, len(result.Warnings), 0)
+	if len(result.Statements) != 2 || !regexp.MustCompile(`(?i)DROP COLUMN .nickname.`).MatchString(result.Statements[0]) ||
+		!regexp.MustCompile(`(?i)DROP COLUMN .score.`).MatchString(result.Statements[1]) {
+		t.Errorf("invalid planned statements, got %v", result.Statements)
+	}
+	for _, column := range []string{"nickname", "score", "legacy_code", "legacy_note"} {
+		if !tx.Migrator().HasColumn(&SyncUser{}, column) {
+			t.Errorf("column %v should not be dropped in dry run mode", column)
+		}
+	}
+
+	// primary keys are never dropped
+	result, err = syncColumns(tx, &SyncUserWithoutID{}, opts)
+	if err != nil {
+		t.Fatalf("failed to sync columns, got error %v", err)
+	}
+	AssertEqual(t, result.Columns, []string{"nickname", "score"})
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "column id") {
+		t.Errorf("should warn primary key is skipped, got %v", result.Warnings)
+	}
+
+	// columns referenced by foreign keys are never dropped
+	referencedTx := tx.Session(&gorm.Session{})
+	referencedTx.Dialector = referencedColumnsDialector{Dialector: DB.Dialector, columns: []string{"score"}}
+	result, err = syncColumns(referencedTx, &SyncUser{}, opts)
+	if err != nil {
+		t.Fatalf("failed to sync columns, got error %v", err)
+	}
+	AssertEqual(t, result.Columns, []string{"nickname"})
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "column score") {
+		t.Errorf("should warn referenced column is skipped, got %v", result.Warnings)
+	}
+
+	// nothing is dropped unless it is enabled explicitly
+	result, err = syncColumns(tx, &SyncUser{}, migrator.SyncOptions{})
+	if err != nil || len(result.Columns) != 0 || len(result.Statements) != 0 {
+		t.Errorf("nothing should be dropped without flags, got %+v, error %v", result, err)
+	}
+
+	opts.DryRun = false
+	if _, err = syncColumns(tx, &SyncUser{}, opts); err != nil {
+		t.Fatalf("failed to sync columns, got error %v", err)
+	}
+	for _, column := range []string{"nickname", "score"} {
+		if tx.Migrator().HasColumn(&SyncUser{}, column) {
+			t.Errorf("column %v should be dropped", column)
+		}
+	}
+	for _, column := range []string{"id", "name", "age", "legacy_code", "legacy_note"} {
+		if !tx.Migrator().HasColumn(&SyncUser{}, column) {
+			t.Errorf("column %v should be kept", column)
+		}
+	}
+
+	var user SyncUser
+	if err := tx.First(&user).Error; err != nil || user.Name != "sync" || user.Age != 18 {
+		t.Errorf("data should be kept after dropping columns, got %+v, error %v", user, err)
+	}
+
+	t.Run("indexes", func(t *testing.T) {
+		type SyncIndexUser struct {
+			ID   uint
+			Name string `gorm:"index"`
+			Age  int
+		}
+
+		if _, err := tx.Migrator().GetIndexes(&SyncIndexUser{}); err != nil {
+			t.Skipf("GetIndexes is not supported, got error %v", err)
+		}
+
+		if err := tx.Exec("CREATE INDEX idx_sync_users_age ON sync_users(age)").Error; err != nil {
+			t.Fatalf("failed to create index manually, got error %v", err)
+		}
+		if err := tx.AutoMigrate(&SyncIndexUser{}); err != nil {
+			t.Fatalf("failed to migrate, got error %v", err)
+		}
+
+		result, err := syncColumns(tx, &SyncIndexUser{}, migrator.SyncOptions{DropExtraIndexes: true, DryRun: true})
+		if err != nil {
+			t.Fatalf("failed to sync indexes, got error %v", err)
+		}
+		AssertEqual(t, result.Indexes, []string{"idx_sync_users_age"})
+
+		if _, err = syncColumns(tx, &SyncIndexUser{}, migrator.SyncOptions{DropExtraIndexes: true}); err != nil {
+			t.Fatalf("failed to sync indexes, got error %v", err)
+		}
+		if tx.Migrator().HasIndex(&SyncIndexUser{}, "idx_sync_users_age") {
+			t.Errorf("index idx_sync_users_age should be dropped")
+		}
+		if !tx.Migrator().HasIndex(&SyncIndexUser{}, "Name") {
+			t.Errorf("index of name should be kept")
+		}
+	})
+}
@@ -145,3 +145,47 @@ func TestNamedPolymorphic(t *testing.T) {
 		t.Errorf("Hamster's other toy should be cleared with Clear")
 	}
 }
+
+type LegacyToy struct {
+	ID        int
+	Name      string
+	OwnerRef  int
+	OwnerKind string
+}
+
+type LegacyOwner struct {
+	ID   int
+	Name string
+	Toys []LegacyToy `gorm:"polymorphic:Owner;polymorphicIdField:OwnerRef;polymorphicTypeColumn:owner_kind;polymorphicValue:legacy"`
+}
+
+func TestPolymorphicCustomFields(t *testing.T) {
+	DB.Migrator().DropTable(&LegacyOwner{}, &LegacyToy{})
+	if err := DB.AutoMigrate(&LegacyOwner{}, &LegacyToy{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	owner := LegacyOwner{Name: "legacy", Toys: []LegacyToy{{Name: "ball"}, {Name: "bike"}}}
+	if err := DB.Create(&owner).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	if owner.Toys[0].OwnerRef != owner.ID || owner.Toys[0].OwnerKind != "legacy" {
+		t.Errorf("polymorphic fields should be saved, got %+v", owner.Toys[0])
+	}
+
+	DB.Create(&LegacyToy{Name: "other", OwnerRef: owner.ID, OwnerKind: "others"})
+
+	var result LegacyOwner
+	if err := DB.Preload("Toys").First(&result, owner.ID).Error; err != nil {
+		t.Fatalf("failed to preload, got error %v", err)
+	}
+
+	if len(result.Toys) != 2 || result.Toys[0].Name != "ball" || result.Toys[1].Name != "bike" {
+		t.Errorf("toys should be preloaded by the custom polymorphic fields, got %+v", result.Toys)
+	}
+
+	if count := DB.Model(&result).Association("Toys").Count(); count != 2 {
+		t.Errorf("toys count should be 2, got %v", count)
+	}
+}
@@ -1,12 +1,17 @@
 package tests_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"reflect"
 	"regexp"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -252,6 +257,64 @@ func TestPreloadGoroutine(t *testing.T) {
 	wg.Wait()
 }
 
+func TestPreloadWithBatchSize(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+
+	var users []User
+	for i := 0; i < 7; i++ {
+		users = append(users, *GetUser("preload_with_batch_size_"+strconv.Itoa(i), Config{Pets: 2, Company: true, Languages: 2}))
+	}
+	db.Create(&users)
+
+	var (
+		mutex   sync.Mutex
+		queries = map[string]int{}
+		userIDs []uint
+	)
+
+	for _, user := range users {
+		userIDs = append(userIDs, user.ID)
+	}
+
+	db.Callback().Query().After("gorm:query").Register("test:count_preload_queries", func(tx *gorm.DB) {
+		mutex.Lock()
+		queries[tx.Statement.Table]++
+		mutex.Unlock()
+	})
+
+	var results []User
+	if err := db.Session(&gorm.Session{PreloadBatchSize: 2}).Preload("Pets", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("name DESC")
+	}).Preload("Company").Preload("Languages").Find(&results, userIDs).Error; err != nil {
+		t.Fatalf("failed to preload in batches, got error %v", err)
+	}
+
+	for table, count := range map[string]int{"pets": 4, "companies": 4, "user_speaks": 4, "languages": 4} {
+		if queries[table] != count {
+			t.Errorf("%v should be queried %v times, got %v", table, count, queries[table])
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ID < results[j].ID
+	})
+
+	for idx, user := range users {
+		sort.Slice(user.Languages, func(i, j int) bool {
+			return user.Languages[i].Code < user.Languages[j].Code
+		})
+		sort.Slice(results[idx].Languages, func(i, j int) bool {
+			return results[idx].Languages[i].Code < results[idx].Languages[j].Code
+		})
+
+		user.Pets[0], user.Pets[1] = user.Pets[1], user.Pets[0]
+		CheckUser(t, results[idx], user)
+	}
+}
+
 func TestPreloadWithDiffModel(t *testing.T) {
 	user := *GetUser("preload_with_diff_model", Config{Account: true})
 
@@ -444,3 +507,244 @@ func TestEmbedPreload(t *testing.T) {
 		})
 	}
 }
+
+func TestPreloadCount(t *testing.T) {
+	type CountPet struct {
+		ID          uint
+		CountUserID uint
+		Name        string
+	}
+
+	type CountLanguage struct {
+		Code string `gorm:"primarykey"`
+		Name string
+	}
+
+	type CountUser struct {
+		ID             uint
+		Name           string
+		Pets           []CountPet
+		PetsCount      int             `gorm:"->;-:migration"`
+		Languages      []CountLanguage `gorm:"many2many:count_user_languages"`
+		LanguagesCount int64           `gorm:"->;-:migration"`
+	}
+
+	DB.Migrator().DropTable(&CountUser{}, &CountPet{}, &CountLanguage{}, "count_user_languages")
+	if err := DB.AutoMigrate(&CountUser{}, &CountPet{}, &CountLanguage{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	languages := []CountLanguage{{Code: "count_en", Name: "en"}, {Code: "count_zh", Name: "zh"}}
+	users := []CountUser{
+		{Name: "preload_count_1", Pets: []CountPet{{Name: "pet1"}, {Name: "pet2"}, {Name: "cat"}}, Languages: languages},
+		{Name: "preload_count_2", Pets: []CountPet{{Name: "cat"}}, Languages: languages[:1]},
+		{Name: "preload_count_3"},
+	}
+	if err := DB.Create(&users).Error; err != nil {
+		t.Fatalf("failed to create users, got error %v", err)
+	}
+
+	var results []CountUser
+	if err := DB.Preload("Pets", gorm.PreloadCount("PetsCount")).Preload("Languages", gorm.PreloadCount("LanguagesCount")).Order("id").Find(&results).Error; err != nil {
+		t.Fatalf("failed to preload count, got error %v", err)
+	}
+
+	expects := []struct {
+		pets      int
+		languages int64
+	}{{3, 2}, {1, 1}, {0, 0}}
+	for idx, user := range results {
+		if user.PetsCount != expects[idx].pets || user.LanguagesCount != expects[idx].languages {
+			t.Errorf("#%d counts should be %+v, got pets %v, languages %v", idx, expects[idx], user.PetsCount, user.LanguagesCount)
+		}
+
+		if user.Pets != nil || user.Languages != nil {
+			t.Errorf("#%d associations should not be loaded, got %+v", idx, user)
+		}
+	}
+
+	// counting with conditions, the count field is reset before counting
+	user := CountUser{ID: users[0].ID, PetsCount: 10, LanguagesCount: 10}
+	if err := DB.Preload("Pets", gorm.PreloadCount("PetsCount"), "name <> ?", "cat").Preload("Languages", gorm.PreloadCount("LanguagesCount"), func(db *gorm.DB) *gorm.DB {
+		return db.Where("count_languages.name = ?", "zh")
+	}).Find(&user).Error; err != nil {
+		t.Fatalf("failed to preload count with conditions, got error %v", err)
+	}
+
+	if user.PetsCount != 2 || user.LanguagesCount != 1 {
+		t.Errorf("counts should respect conditions, got pets %v, languages %v", user.PetsCount, user.LanguagesCount)
+	}
+
+	if err := DB.Preload("Pets", gorm.PreloadCount("UnknownCount")).Find(&user).Error; !errors.Is(err, gorm.ErrInvalidField) {
+		t.Errorf("should return error for unknown count field, got %v", err)
+	}
+}
+
+func TestPreloadOptions(t *testing.T) {
+	users := []User{
+		*GetUser("preload_options_1", Config{Pets: 3, Languages: 3}),
+		*GetUser("preload_options_2", Config{Pets: 1, Languages: 1}),
+	}
+	DB.Create(&users)
+
+	var results []User
+	if err := DB.Preload("Pets", gorm.PreloadOptions{
+		Select: []string{"Name"},
+		Order:  []clause.OrderByColumn{{Column: clause.Column{Name: "name"}, Desc: true}},
+		Limit:  2,
+	}).Order("id").Find(&results, []uint{users[0].ID, users[1].ID}).Error; err != nil {
+		t.Fatalf("failed to preload with options, got %v", err)
+	}
+
+	if len(results) != 2 || len(results[0].Pets) != 2 || len(results[1].Pets) != 1 {
+		t.Fatalf("pets should be limited for each user, got %+v", results)
+	}
+
+	AssertEqual(t, results[0].Pets[0].Name, "preload_options_1_pet_3")
+	AssertEqual(t, results[0].Pets[1].Name, "preload_options_1_pet_2")
+	AssertEqual(t, results[1].Pets[0].Name, "preload_options_2_pet_1")
+	for _, pet := range append(results[0].Pets, results[1].Pets...) {
+		if pet.ID != 0 || pet.CreatedAt != (time.Time{}) || pet.UserID == nil {
+			t.Errorf("only selected columns and foreign keys should be loaded, got %+v", pet)
+		}
+	}
+
+	results = nil
+	if err := DB.Preload("Pets", gorm.PreloadOptions{
+		Where: []clause.Expression{clause.Neq{Column: "name", Value: "preload_options_1_pet_1"}},
+		Omit:  []string{"Name", "UserID"},
+	}).Preload("Languages", gorm.PreloadOptions{
+		Order: []clause.OrderByColumn{{Column: clause.Column{Name: "code"}}},
+		Limit: 1,
+	}).Find(&results, users[0].ID).Error; err != nil {
+		t.Fatalf("failed to preload with options, got %v", err)
+	}
+
+	if len(results) != 1 || len(results[0].Pets) != 2 || len(results[0].Languages) != 1 {
+		t.Fatalf("failed to preload with options, got %+v", results)
+	}
+
+	for _, pet := range results[0].Pets {
+		if pet.ID == 0 || pet.Name != "" || pet.UserID == nil || *pet.UserID != users[0].ID {
+			t.Errorf("omitted columns except foreign keys shouldn't be loaded, got %+v", pet)
+		}
+	}
+	AssertEqual(t, results[0].Languages[0].Code, users[0].Languages[0].Code)
+
+	if err := DB.Preload("Pets", gorm.PreloadOptions{Limit: 1}, "name <> ?", "pet").Find(&results).Error; !errors.Is(err, gorm.ErrPreloadOptionsWithConds) {
+		t.Errorf("should fail to preload with options and conditions, got %v", err)
+	}
+
+	if err := DB.Preload("Pets", gorm.PreloadOptions{Limit: 1}, func(db *gorm.DB) *gorm.DB {
+		return db.Order("name")
+	}).Find(&results).Error; !errors.Is(err, gorm.ErrPreloadOptionsWithConds) {
+		t.Errorf("should fail to preload with options and closures, got %v", err)
+	}
+}
+
+func TestStrictPreload(t *testing.T) {
+	user := *GetUser("strict_preload", Config{Company: true, Pets: 2})
+	other := *GetUser("strict_preload_other", Config{Pets: 1})
+	DB.Create(&user)
+	DB.Create(&other)
+
+	strictDB := DB.Session(&gorm.Session{StrictPreload: true})
+
+	var result User
+	if err := strictDB.Preload("Company").Preload("Pets").First(&result, user.ID).Error; err != nil {
+		t.Fatalf("failed to preload, got error %v", err)
+	}
+	CheckUser(t, result, user)
+
+	// the foreign key company_id of users is not selected
+	if err := DB.Select("id", "name").Preload("Company").First(&User{}, user.ID).Error; err != nil {
+		t.Errorf("should not check references without StrictPreload, got %v", err)
+	}
+
+	if err := strictDB.Select("id", "name").Preload("Company").First(&User{}, user.ID).Error; !errors.Is(err, gorm.ErrPreloadReferencesNotLoaded) {
+		t.Errorf("should report references not loaded, got %v", err)
+	}
+
+	if err := strictDB.Preload("Pets", func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("name")
+	}).First(&User{}, user.ID).Error; !errors.Is(err, gorm.ErrPreloadReferencesNotLoaded) {
+		t.Errorf("should report references of preloaded records not loaded, got %v", err)
+	}
+
+	// pets selected with the foreign key of another user can't be matched to the user
+	err := strictDB.Preload("Pets", func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("id, name, ? AS user_id", other.ID)
+	}).First(&User{}, user.ID).Error
+	if !errors.Is(err, gorm.ErrPreloadOrphanRecords) || !strings.Contains(err.Error(), "2 of 2 records") {
+		t.Errorf("should report orphan records, got %v", err)
+	}
+}
+
+func TestStrictPreloadRenamedForeignKey(t *testing.T) {
+	type StrictPreloadOwner struct {
+		ID   uint
+		Name string
+	}
+
+	type StrictPreloadItem struct {
+		ID      uint
+		Name    string
+		OwnerID uint
+		Owner   StrictPreloadOwner
+	}
+
+	DB.Migrator().DropTable(&StrictPreloadItem{}, &StrictPreloadOwner{})
+	if err := DB.AutoMigrate(&StrictPreloadOwner{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	// the foreign key owner_id was renamed to owner_ref in the database
+	if err := DB.Exec("CREATE TABLE strict_preload_items (id INTEGER PRIMARY KEY, name VARCHAR(100), owner_ref INTEGER)").Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+
+	owner := StrictPreloadOwner{Name: "owner"}
+	DB.Create(&owner)
+	DB.Exec("INSERT INTO strict_preload_items (id, name, owner_ref) VALUES (?, ?, ?)", 1, "item", owner.ID)
+
+	var items []StrictPreloadItem
+	if err := DB.Preload("Owner").Find(&items).Error; err != nil || len(items) != 1 || items[0].Owner.ID != 0 {
+		t.Errorf("should preload nothing silently without StrictPreload, got %v, %+v", err, items)
+	}
+
+	err := DB.Session(&gorm.Session{StrictPreload: true}).Preload("Owner").Find(&items).Error
+	if !errors.Is(err, gorm.ErrPreloadReferencesNotLoaded) || !strings.Contains(err.Error(), "OwnerID") {
+		t.Errorf("should report foreign key not loaded, got %v", err)
+	}
+}
+
+func TestPreloadOrder(t *testing.T) {
+	user := *GetUser("preload_order", Config{Company: true, Manager: true, Pets: 2, Toys: 1})
+	DB.Create(&user)
+
+	var tables []string
+	tableRegexp := regexp.MustCompile("FROM .(\\w+).")
+	tx := DB.Session(&gorm.Session{Logger: Tracer{
+		Logger: DB.Config.Logger,
+		Test: func(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+			sql, _ := fc()
+			if matches := tableRegexp.FindStringSubmatch(sql); len(matches) == 2 {
+				tables = append(tables, matches[1])
+			}
+		},
+	}})
+
+	for i := 0; i < 5; i++ {
+		tables = nil
+		var result User
+		if err := tx.Preload("Toys").Preload("Pets.Toy").Preload("Manager").Preload("Company").Preload("Pets").First(&result, user.ID).Error; err != nil {
+			t.Fatalf("failed to preload, got error %v", err)
+		}
+
+		// relations are preloaded in order of names, nested relations within their parents,
+		// SQL is traced after executed, so nested preloads are traced before their parents
+		if expects := []string{"companies", "users", "toys", "pets", "toys", "users"}; !reflect.DeepEqual(tables, expects) {
+			t.Fatalf("invalid preload order, expects %v, got %v", expects, tables)
+		}
+	}
+}
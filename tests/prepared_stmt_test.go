@@ -2,6 +2,8 @@ package tests_test
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"sync"
 	"testing"
@@ -194,3 +196,284 @@ func TestPreparedStmtReset(t *testing.T) {
 		t.Fatalf("prepared stmt should be empty")
 	}
 }
+
+func TestPreparedStmtStats(t *testing.T) {
+	if _, ok := DB.Session(&gorm.Session{PrepareStmt: true}).PreparedStmts(); !ok {
+		t.Fatalf("should return the prepared statements manager")
+	}
+
+	if p, ok := DB.Session(&gorm.Session{}).PreparedStmts(); ok || p != nil {
+		t.Fatalf("should not return the prepared statements manager without PrepareStmt, got %v", p)
+	}
+
+	user := *GetUser("prepared_stmt_stats", Config{})
+	DB.Create(&user)
+
+	pdb := &gorm.PreparedStmtDB{ConnPool: DB.ConnPool, Stmts: map[string]*gorm.Stmt{}, Mux: &sync.RWMutex{}}
+	tx := DB.Session(&gorm.Session{Context: context.Background(), NewDB: true})
+	tx.Statement.ConnPool = pdb
+
+	if p, ok := tx.PreparedStmts(); !ok || p != pdb {
+		t.Fatalf("should return the prepared statements manager of the conn pool, got %v", p)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := tx.First(&User{}, user.ID).Error; err != nil {
+			t.Fatalf("failed to query with prepared stmt, got error %v", err)
+		}
+	}
+
+	stats := pdb.Stats()
+	if stats.Count != 1 || len(stats.Stmts) != 1 {
+		t.Fatalf("should have one prepared stmt, got %+v", stats)
+	}
+
+	if stat := stats.Stmts[0]; stat.Uses != 3 || stat.CreatedAt.IsZero() || len(stat.Digest) != 40 || stat.SQL == "" {
+		t.Errorf("invalid prepared stmt stat, got %+v", stat)
+	}
+
+	pdb.Reset()
+	if stats := pdb.Stats(); stats.Count != 0 {
+		t.Fatalf("prepared stmts should be empty after reset, got %+v", stats)
+	}
+
+	var result User
+	if err := tx.First(&result, user.ID).Error; err != nil {
+		t.Fatalf("failed to query after reset, got error %v", err)
+	}
+	CheckUser(t, result, user)
+
+	if stats := pdb.Stats(); stats.Count != 1 || stats.Stmts[0].Uses != 1 {
+		t.Errorf("should prepare again after reset, got %+v", stats)
+	}
+
+	if err := pdb.ResetContext(context.Background()); err != nil {
+		t.Fatalf("failed to reset prepared stmts, got error %v", err)
+	}
+	if stats := pdb.Stats(); stats.Count != 0 {
+		t.Fatalf("prepared stmts should be empty after reset, got %+v", stats)
+	}
+
+	if err := tx.First(&result, user.ID).Error; err != nil {
+		t.Fatalf("failed to query after reset, got error %v", err)
+	}
+	CheckUser(t, result, user)
+}
+
+type invalidatedConnPool struct {
+	gorm.ConnPool
+	mux      sync.Mutex
+	prepares int
+	failures int
+}
+
+func (pool *invalidatedConnPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	pool.mux.Lock()
+	pool.prepares++
+	if pool.failures > 0 {
+		pool.failures--
+		pool.mux.Unlock()
+		return nil, driver.ErrBadConn
+	}
+	pool.mux.Unlock()
+
+	return pool.ConnPool.PrepareContext(ctx, query)
+}
+
+func TestPreparedStmtRePrepare(t *testing.T) {
+	user := *GetUser("prepared_stmt_re_prepare", Config{})
+	DB.Create(&user)
+
+	pool := &invalidatedConnPool{ConnPool: DB.ConnPool}
+	pdb := &gorm.PreparedStmtDB{ConnPool: pool, Stmts: map[string]*gorm.Stmt{}, Mux: &sync.RWMutex{}}
+	tx := DB.Session(&gorm.Session{Context: context.Background(), NewDB: true})
+	tx.Statement.ConnPool = pdb
+
+	if err := tx.First(&User{}, user.ID).Error; err != nil || pool.prepares != 1 {
+		t.Fatalf("failed to query with prepared stmt, got error %v, prepares %v", err, pool.prepares)
+	}
+
+	// statements are invalid after database restarted, prepare again once
+	pool.failures = 1
+	pdb.Reset()
+	var result User
+	if err := tx.First(&result, user.ID).Error; err != nil || pool.prepares != 3 {
+		t.Fatalf("should prepare again when the statement is invalid, got error %v, prepares %v", err, pool.prepares)
+	}
+	CheckUser(t, result, user)
+
+	pool.failures = 2
+	pdb.Reset()
+	if err := tx.First(&User{}, user.ID).Error; !errors.Is(err, driver.ErrBadConn) || pool.prepares != 5 {
+		t.Fatalf("should only prepare again once, got error %v, prepares %v", err, pool.prepares)
+	}
+
+	pdb.Mux.RLock()
+	defer pdb.Mux.RUnlock()
+	if len(pdb.Stmts) != 0 {
+		t.Errorf("failed prepared stmt should not be cached, got %v", len(pdb.Stmts))
+	}
+}
+
+type invalidStmtDialector struct {
+	gorm.Dialector
+}
+
+func (d invalidStmtDialector) Translate(err error) error {
+	if err != nil && err.Error() == "sql: statement is closed" {
+		return gorm.ErrInvalidPreparedStmt
+	}
+	if translator, ok := d.Dialector.(gorm.ErrorTranslator); ok {
+		return translator.Translate(err)
+	}
+	return err
+}
+
+func TestPreparedStmtRePrepareInvalidStmt(t *testing.T) {
+	db, err := gorm.Open(invalidStmtDialector{Dialector: DB.Dialector}, &gorm.Config{PrepareStmt: true, TranslateError: true})
+	if err != nil {
+		t.Fatalf("failed to open db, got error %v", err)
+	}
+
+	user := *GetUser("prepared_stmt_invalid_stmt", Config{})
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user, got error %v", err)
+	}
+
+	pdb, ok := db.ConnPool.(*gorm.PreparedStmtDB)
+	if !ok {
+		t.Fatalf("should assign PreparedStmtDB to ConnPool, got %T", db.ConnPool)
+	}
+
+	// cached statements become invalid after database restarted, they are closed here to simulate it
+	invalidate := func() {
+		pdb.Mux.RLock()
+		defer pdb.Mux.RUnlock()
+		for _, stmt := range pdb.Stmts {
+			stmt.Close()
+		}
+	}
+
+	var result User
+	if err := db.First(&result, user.ID).Error; err != nil {
+		t.Fatalf("failed to query, got error %v", err)
+	}
+
+	// the invalid statement is prepared again, but not executed again
+	invalidate()
+	result = User{}
+	if err := db.First(&result, user.ID).Error; !errors.Is(err, gorm.ErrInvalidPreparedStmt) {
+		t.Fatalf("should return the error of the invalid statement, got error %v", err)
+	}
+
+	if err := db.First(&result, user.ID).Error; err != nil {
+		t.Fatalf("should query with the statement prepared again, got error %v", err)
+	}
+	CheckUser(t, result, user)
+
+	if err := db.Exec("UPDATE users SET age = ? WHERE id = ?", 30, user.ID).Error; err != nil {
+		t.Fatalf("failed to update, got error %v", err)
+	}
+	invalidate()
+	if err := db.Exec("UPDATE users SET age = ? WHERE id = ?", 31, user.ID).Error; !errors.Is(err, gorm.ErrInvalidPreparedStmt) {
+		t.Fatalf("should return the error of the invalid statement, got error %v", err)
+	}
+
+	var age int
+	DB.Model(&User{}).Select("age").Where("id = ?", user.ID).Scan(&age)
+	if age != 30 {
+		t.Errorf("the invalid statement shouldn't be executed again, got age %v", age)
+	}
+
+	if err := db.Exec("UPDATE users SET age = ? WHERE id = ?", 31, user.ID).Error; err != nil {
+		t.Fatalf("should execute with the statement prepared again, got error %v", err)
+	}
+
+	DB.Model(&User{}).Select("age").Where("id = ?", user.ID).Scan(&age)
+	if age != 31 {
+		t.Errorf("statement should be executed after prepared again, got age %v", age)
+	}
+}
+
+func TestPing(t *testing.T) {
+	if err := DB.Ping(context.Background()); err != nil {
+		t.Errorf("failed to ping database, got error %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := DB.Ping(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("ping should respect context, got error %v", err)
+	}
+}
+
+func TestPreparedStmtSkipPrepare(t *testing.T) {
+	db, err := gorm.Open(DB.Dialector, &gorm.Config{PrepareStmt: true})
+	if err != nil {
+		t.Fatalf("failed to open db, got error %v", err)
+	}
+
+	conn, ok := db.ConnPool.(*gorm.PreparedStmtDB)
+	if !ok {
+		t.Fatalf("should use PreparedStmtDB when PrepareStmt is enabled")
+	}
+
+	preparedCount := func() int {
+		conn.Mux.RLock()
+		defer conn.Mux.RUnlock()
+		return len(conn.Stmts)
+	}
+
+	user := *GetUser("skip_prepare", Config{})
+	if err := db.SkipPrepare().Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user, got error %v", err)
+	}
+
+	if count := preparedCount(); count != 0 {
+		t.Errorf("create with default transaction should not be prepared, got %v statements", count)
+	}
+
+	var users []User
+	if err := db.SkipPrepare().Where("name IN ?", []string{user.Name, "skip_prepare_2"}).Find(&users).Error; err != nil || len(users) != 1 {
+		t.Errorf("failed to find users, got %v, error %v", len(users), err)
+	}
+
+	if err := db.SkipPrepare().Exec("UPDATE users SET age = ? WHERE id = ?", 20, user.ID).Error; err != nil {
+		t.Errorf("failed to exec, got error %v", err)
+	}
+
+	var age int
+	if err := db.SkipPrepare().Raw("SELECT age FROM users WHERE id = ?", user.ID).Scan(&age).Error; err != nil || age != 20 {
+		t.Errorf("failed to raw query, got %v, error %v", age, err)
+	}
+
+	var result User
+	if err := db.SkipPrepare().Where("id = ?", user.ID).Session(&gorm.Session{}).First(&result).Error; err != nil {
+		t.Errorf("failed to find user with cloned statement, got error %v", err)
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.SkipPrepare().Model(&User{}).Where("id = ?", user.ID).Update("age", 30).Error; err != nil {
+			return err
+		}
+		return tx.SkipPrepare().First(&result, user.ID).Error
+	}); err != nil {
+		t.Errorf("failed to run transaction, got error %v", err)
+	}
+
+	if count := preparedCount(); count != 0 {
+		t.Errorf("statements should not be prepared when skipped, got %v statements", count)
+	}
+
+	if result.Age != 30 {
+		t.Errorf("user should be updated in transaction, got %v", result.Age)
+	}
+
+	if err := db.First(&result, user.ID).Error; err != nil {
+		t.Errorf("failed to find user, got error %v", err)
+	}
+
+	if count := preparedCount(); count != 1 {
+		t.Errorf("other statements should still be prepared, got %v statements", count)
+	}
+}
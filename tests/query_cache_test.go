@@ -0,0 +1,137 @@
+package tests_test
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	. "gorm.io/gorm/utils/tests"
+)
+
+// memoryQueryCache in-memory query cache plugin, caches results of queries by Statement.CacheKey
+type memoryQueryCache struct {
+	results sync.Map
+	hits    int
+	misses  int
+}
+
+type cachedResult struct {
+	value        reflect.Value
+	rowsAffected int64
+}
+
+func (cache *memoryQueryCache) Name() string {
+	return "tests:memory_query_cache"
+}
+
+func (cache *memoryQueryCache) Initialize(db *gorm.DB) error {
+	query := db.Callback().Query()
+	if err := query.Before("gorm:query").Register(callbacks.BuildQueryCallback, callbacks.BuildQuery); err != nil {
+		return err
+	}
+
+	if err := query.After(callbacks.BuildQueryCallback).Before("gorm:query").Register("tests:cache_load", cache.load); err != nil {
+		return err
+	}
+	return query.After("gorm:query").Register("tests:cache_store", cache.store)
+}
+
+func (cache *memoryQueryCache) load(db *gorm.DB) {
+	if db.Error != nil || db.Statement.NoCache || db.DryRun {
+		return
+	}
+
+	v, ok := cache.results.Load(db.Statement.CacheKey())
+	if !ok || v.(cachedResult).value.Type() != db.Statement.ReflectValue.Type() {
+		cache.misses++
+		return
+	}
+
+	result := v.(cachedResult)
+	db.Statement.ReflectValue.Set(copyValue(result.value))
+	db.RowsAffected = result.rowsAffected
+	db.Statement.SkipQuery = true
+	cache.hits++
+}
+
+func (cache *memoryQueryCache) store(db *gorm.DB) {
+	if db.Error != nil || db.Statement.NoCache || db.Statement.SkipQuery || db.DryRun {
+		return
+	}
+
+	cache.results.Store(db.Statement.CacheKey(), cachedResult{value: copyValue(db.Statement.ReflectValue), rowsAffected: db.RowsAffected})
+}
+
+// copyValue copies value, elements of slices are copied to a new slice
+func copyValue(value reflect.Value) reflect.Value {
+	copied := reflect.New(value.Type()).Elem()
+	if value.Kind() == reflect.Slice {
+		copied.Set(reflect.MakeSlice(value.Type(), value.Len(), value.Len()))
+		reflect.Copy(copied, value)
+	} else {
+		copied.Set(value)
+	}
+	return copied
+}
+
+func TestQueryCachePlugin(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+
+	cache := &memoryQueryCache{}
+	if err := db.Use(cache); err != nil {
+		t.Fatalf("failed to use the plugin, got error %v", err)
+	}
+
+	users := []User{*GetUser("query_cache_1", Config{}), *GetUser("query_cache_2", Config{})}
+	db.Create(&users)
+
+	var result User
+	if err := db.First(&result, users[0].ID).Error; err != nil || result.Name != "query_cache_1" || cache.misses != 1 || cache.hits != 0 {
+		t.Fatalf("the first query should miss, got %+v, %v, hits %v, misses %v", result, err, cache.hits, cache.misses)
+	}
+
+	// change the record behind the cache
+	db.Exec("UPDATE users SET name = ? WHERE id = ?", "query_cache_changed", users[0].ID)
+
+	var cached User
+	if tx := db.First(&cached, users[0].ID); tx.Error != nil || cached.Name != "query_cache_1" || tx.RowsAffected != 1 || cache.hits != 1 {
+		t.Errorf("the same query should hit the cache, got %+v, %v, hits %v", cached, tx.Error, cache.hits)
+	}
+
+	var bypassed User
+	if err := db.NoCache().First(&bypassed, users[0].ID).Error; err != nil || bypassed.Name != "query_cache_changed" || cache.hits != 1 {
+		t.Errorf("NoCache should bypass the cache, got %+v, %v, hits %v", bypassed, err, cache.hits)
+	}
+
+	var found []User
+	if tx := db.Where("name LIKE ?", "query_cache_%").Order("id").Find(&found); tx.Error != nil || len(found) != 2 || cache.misses != 2 {
+		t.Errorf("different queries should miss, got %v, %v, misses %v", len(found), tx.Error, cache.misses)
+	}
+
+	found = nil
+	if tx := db.Where("name LIKE ?", "query_cache_%").Order("id").Find(&found); tx.Error != nil || len(found) != 2 || tx.RowsAffected != 2 || cache.hits != 2 {
+		t.Errorf("should hit the cache of slices, got %v, %v, %v, hits %v", len(found), tx.RowsAffected, tx.Error, cache.hits)
+	}
+
+	db.Transaction(func(tx *gorm.DB) error {
+		var user User
+		if err := tx.First(&user, users[0].ID).Error; err != nil || user.Name != "query_cache_changed" || cache.hits != 2 {
+			t.Errorf("queries in transactions should have different cache keys, got %+v, %v, hits %v", user, err, cache.hits)
+		}
+		return nil
+	})
+
+	stmt := db.Session(&gorm.Session{DryRun: true}).First(&User{}, users[0].ID).Statement
+	if key := stmt.CacheKey(); key == "" || key != stmt.CacheKey() {
+		t.Errorf("cache key should be stable, got %v", key)
+	}
+
+	if key := db.Session(&gorm.Session{DryRun: true}).First(&User{}, users[1].ID).Statement.CacheKey(); key == stmt.CacheKey() {
+		t.Errorf("cache keys of different vars should be different")
+	}
+}
@@ -1,7 +1,9 @@
 package tests_test
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -246,6 +248,63 @@ func TestFind(t *testing.T) {
 	}
 }
 
+func TestFirstByLastBy(t *testing.T) {
+	type UUIDRecord struct {
+		ID        string `gorm:"primaryKey"`
+		Name      string
+		CreatedAt time.Time
+	}
+
+	DB.Migrator().DropTable(&UUIDRecord{})
+	if err := DB.AutoMigrate(&UUIDRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	dryDB := DB.Session(&gorm.Session{DryRun: true})
+	result := dryDB.FirstBy(&UUIDRecord{}, "CreatedAt", "name = ?", "first")
+	if !regexp.MustCompile("SELECT \\* FROM .*uuid_records.* WHERE name = .+ ORDER BY .*uuid_records.*\\..*created_at.* LIMIT").MatchString(result.Statement.SQL.String()) {
+		t.Fatalf("Build FirstBy, but got %v", result.Statement.SQL.String())
+	}
+
+	result = dryDB.LastBy(&UUIDRecord{}, "created_at")
+	if !regexp.MustCompile("SELECT \\* FROM .*uuid_records.* ORDER BY .*uuid_records.*\\..*created_at.* DESC LIMIT").MatchString(result.Statement.SQL.String()) {
+		t.Fatalf("Build LastBy, but got %v", result.Statement.SQL.String())
+	}
+
+	result = dryDB.Table("uuid_records").FirstBy(&map[string]interface{}{}, "uuid_records.name")
+	if !regexp.MustCompile("SELECT \\* FROM .*uuid_records.* ORDER BY .*uuid_records.*\\..*name.* LIMIT").MatchString(result.Statement.SQL.String()) {
+		t.Fatalf("Build FirstBy without schema, but got %v", result.Statement.SQL.String())
+	}
+
+	now := time.Now().Round(time.Second)
+	records := []UUIDRecord{
+		{ID: "f47ac10b-58cc-4372-a567-0e02b2c3d479", Name: "first", CreatedAt: now.Add(-time.Hour)},
+		{ID: "9b2e6a3c-1f0d-4c8e-9a7b-5d4c3b2a1f0e", Name: "second", CreatedAt: now},
+		{ID: "0c8d7e6f-5a4b-4c3d-8e2f-1a0b9c8d7e6f", Name: "third", CreatedAt: now.Add(time.Hour)},
+	}
+	if err := DB.Create(&records).Error; err != nil {
+		t.Fatalf("failed to create records, got error %v", err)
+	}
+
+	var first, last UUIDRecord
+	if err := DB.FirstBy(&first, "CreatedAt").Error; err != nil || first.Name != "first" {
+		t.Errorf("FirstBy should find the earliest record, got %v, err %v", first.Name, err)
+	}
+
+	if err := DB.LastBy(&last, "CreatedAt").Error; err != nil || last.Name != "third" {
+		t.Errorf("LastBy should find the latest record, got %v, err %v", last.Name, err)
+	}
+
+	var filtered UUIDRecord
+	if err := DB.LastBy(&filtered, "created_at", "name <> ?", "third").Error; err != nil || filtered.Name != "second" {
+		t.Errorf("LastBy with conditions should find the second record, got %v, err %v", filtered.Name, err)
+	}
+
+	if err := DB.FirstBy(&UUIDRecord{}, "CreatedAt", "name = ?", "not-exists").Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("FirstBy should return ErrRecordNotFound, got %v", err)
+	}
+}
+
 func TestQueryWithAssociation(t *testing.T) {
 	user := *GetUser("query_with_association", Config{Account: true, Pets: 2, Toys: 1, Company: true, Manager: true, Team: 2, Languages: 1, Friends: 3})
 
@@ -658,6 +717,81 @@ func TestOrWithAllFields(t *testing.T) {
 	}
 }
 
+func TestConditionGroups(t *testing.T) {
+	dryDB := DB.Session(&gorm.Session{DryRun: true})
+
+	result := dryDB.Where(gorm.OrGroup(
+		gorm.AndGroup(&User{Name: "jinzhu"}),
+		gorm.AndGroup(map[string]interface{}{"age": 18, "active": true}),
+	)).Find(&User{})
+	if !regexp.MustCompile("SELECT \\* FROM .*users.* WHERE \\(.*users.*\\..*name.* = .+ OR \\(.*active.* = .+ AND .*age.* = .+\\)\\) AND .*users.*\\..*deleted_at.* IS NULL").MatchString(result.Statement.SQL.String()) {
+		t.Fatalf("Build OR group, but got %v", result.Statement.SQL.String())
+	}
+
+	result = dryDB.Where("role = ?", "admin").Where(gorm.OrGroup(
+		gorm.AndGroup([]interface{}{"age > ?", 18}, gorm.OrGroup(map[string]interface{}{"name": "jinzhu"}, "name IS NULL")),
+		gorm.AndGroup(User{Age: 20}, gorm.OrGroup([]interface{}{"active = ?", true}, User{Name: "jinzhu 2"})),
+	)).Find(&User{})
+	explainedSQL := dryDB.Dialector.Explain(result.Statement.SQL.String(), result.Statement.Vars...)
+	if !regexp.MustCompile("^SELECT \\* FROM .users. WHERE role = .admin. AND \\(\\(age > 18 AND \\(.name. = .jinzhu. OR name IS NULL\\)\\) OR \\(.users.\\..age. = 20 AND \\(active = (true|1) OR .users.\\..name. = .jinzhu 2.\\)\\)\\) AND .users.\\..deleted_at. IS NULL$").MatchString(explainedSQL) {
+		t.Fatalf("Build nested groups, but got %v", explainedSQL)
+	}
+	AssertEqual(t, result.Statement.Vars, []interface{}{"admin", 18, "jinzhu", uint(20), true, "jinzhu 2"})
+
+	if err := dryDB.Where(gorm.AndGroup("age > ?")).Find(&User{}).Error; !errors.Is(err, gorm.ErrInvalidData) {
+		t.Errorf("should reject placeholders without args in groups, got %v", err)
+	}
+
+	result = dryDB.Where(gorm.OrGroup(
+		DB.Where("age > ?", 10).Where("name <> ?", "jinzhu"),
+		gorm.AndGroup(clause.Expr{SQL: "id IN (?)", Vars: []interface{}{DB.Table("pets").Select("user_id").Where("name = ?", "pet")}}),
+	)).Find(&User{})
+	if !regexp.MustCompile("WHERE \\(\\(age > .+ AND name <> .+\\) OR id IN \\(SELECT .*user_id.* FROM .*pets.* WHERE name = .+\\)\\) AND").MatchString(result.Statement.SQL.String()) {
+		t.Fatalf("Build group with subquery, but got %v", result.Statement.SQL.String())
+	}
+
+	result = dryDB.Where("role = ?", "admin").Where(gorm.OrGroup(User{Name: "jinzhu"})).Find(&User{})
+	if !regexp.MustCompile("WHERE role = .+ AND .*users.*\\..*name.* = .+ AND").MatchString(result.Statement.SQL.String()) {
+		t.Fatalf("Build single condition OR group, but got %v", result.Statement.SQL.String())
+	}
+}
+
+func TestLikeExpressions(t *testing.T) {
+	users := []User{
+		*GetUser("like_50%_off", Config{}),
+		*GetUser("like_50a_off", Config{}),
+		*GetUser("like_50%_off_today", Config{}),
+	}
+	DB.Create(&users)
+
+	dryDB := DB.Session(&gorm.Session{DryRun: true})
+	result := dryDB.Where(clause.Contains{Column: "name", Value: "50%_off"}).Find(&User{})
+	if !regexp.MustCompile("WHERE .*name.* LIKE .+ ESCAPE '\\\\'").MatchString(result.Statement.SQL.String()) {
+		t.Fatalf("Build Contains, but got %v", result.Statement.SQL.String())
+	}
+
+	result = dryDB.Not(clause.HasPrefix{Column: "name", Value: "like_"}).Find(&User{})
+	if !regexp.MustCompile("WHERE .*name.* NOT LIKE .+ ESCAPE '\\\\'").MatchString(result.Statement.SQL.String()) {
+		t.Fatalf("Build Not HasPrefix, but got %v", result.Statement.SQL.String())
+	}
+
+	var names []string
+	DB.Model(&User{}).Where(clause.Contains{Column: "name", Value: "50%_off"}).Order("name").Pluck("name", &names)
+	if len(names) != 2 || names[0] != "like_50%_off" || names[1] != "like_50%_off_today" {
+		t.Errorf("Contains should escape wildcards, got %v", names)
+	}
+
+	DB.Model(&User{}).Where(clause.HasPrefix{Column: "name", Value: "like_50%"}).Where(clause.HasSuffix{Column: "name", Value: "_off"}).Pluck("name", &names)
+	if len(names) != 1 || names[0] != "like_50%_off" {
+		t.Errorf("HasPrefix and HasSuffix should escape wildcards, got %v", names)
+	}
+
+	DB.Model(&User{}).Where(clause.HasPrefix{Column: "name", Value: "like_50"}).Not(clause.Contains{Column: "name", Value: "%"}).Pluck("name", &names)
+	if len(names) != 1 || names[0] != "like_50a_off" {
+		t.Errorf("Not Contains should escape wildcards, got %v", names)
+	}
+}
+
 func TestPluck(t *testing.T) {
 	users := []*User{
 		GetUser("pluck-user1", Config{}),
@@ -845,6 +979,55 @@ func TestPluckWithSelect(t *testing.T) {
 	AssertEqual(t, userAges, []int{26, 27})
 }
 
+func TestPluckMultipleColumns(t *testing.T) {
+	users := []User{
+		{Name: "pluck_multiple_1", Age: 10},
+		{Name: "pluck_multiple_2", Age: 20},
+	}
+
+	DB.Create(&users)
+
+	names := map[uint]string{}
+	if err := DB.Model(&User{}).Where("name like ?", "pluck_multiple%").PluckMap("ID", "Name", &names).Error; err != nil {
+		t.Fatalf("got error when pluck map: %v", err)
+	}
+
+	AssertEqual(t, names, map[uint]string{users[0].ID: users[0].Name, users[1].ID: users[1].Name})
+
+	var ages map[string]uint
+	if err := DB.Model(&User{}).Where("name like ?", "pluck_multiple%").Distinct().PluckMap("name", "age", &ages).Error; err != nil {
+		t.Fatalf("got error when pluck map into nil map: %v", err)
+	}
+
+	AssertEqual(t, ages, map[string]uint{"pluck_multiple_1": 10, "pluck_multiple_2": 20})
+
+	var results []struct {
+		ID   uint
+		Name string
+	}
+	if err := DB.Model(&User{}).Where("name like ?", "pluck_multiple%").Order("name").Pluck("id, name", &results).Error; err != nil {
+		t.Fatalf("got error when pluck multiple columns: %v", err)
+	}
+
+	if len(results) != 2 || results[0].ID != users[0].ID || results[1].Name != users[1].Name {
+		t.Errorf("failed to pluck multiple columns, got %+v", results)
+	}
+
+	var ids []uint
+	if err := DB.Model(&User{}).Where("name like ?", "pluck_multiple%").Pluck("id, name", &ids).Error; !errors.Is(err, gorm.ErrInvalidValue) {
+		t.Errorf("should returns ErrInvalidValue when plucking multiple columns into a scalar slice, got %v", err)
+	}
+
+	var list []string
+	if err := DB.Model(&User{}).Where("name like ?", "pluck_multiple%").PluckMap("id", "name", &list).Error; !errors.Is(err, gorm.ErrInvalidValue) {
+		t.Errorf("should returns ErrInvalidValue when dest is not a map, got %v", err)
+	}
+
+	if err := DB.Model(&User{}).Where("name like ?", "pluck_multiple%").Select("id", "name", "age").PluckMap("id", "name", &names).Error; !errors.Is(err, gorm.ErrInvalidValue) {
+		t.Errorf("should returns ErrInvalidValue when selected columns do not match the map, got %v", err)
+	}
+}
+
 func TestSelectWithVariables(t *testing.T) {
 	DB.Save(&User{Name: "select_with_variables"})
 
@@ -1135,6 +1318,55 @@ func TestSubQuery(t *testing.T) {
 	}
 }
 
+func TestSubQueryWithExists(t *testing.T) {
+	dryDB := DB.Session(&gorm.Session{DryRun: true})
+	subQuery := func() *gorm.DB {
+		return DB.Model(&Pet{}).Select("1").Where("pets.user_id = users.id AND pets.name = ?", "exists_pet")
+	}
+
+	stmt := dryDB.Where("name = ?", "exists_user").Where(clause.Exists{Query: subQuery()}).Where("age > ?", 10).Find(&User{}).Statement
+	if !regexp.MustCompile(`WHERE name = .+ AND EXISTS \(SELECT 1 FROM .pets. WHERE \(pets.user_id = users.id AND pets.name = .+\) AND .pets.\..deleted_at. IS NULL\) AND age > .+`).MatchString(stmt.SQL.String()) {
+		t.Errorf("invalid EXISTS subquery, got %v", stmt.SQL.String())
+	}
+
+	if !reflect.DeepEqual(stmt.Vars, []interface{}{"exists_user", "exists_pet", 10}) {
+		t.Errorf("vars of the subquery should keep the order, got %v", stmt.Vars)
+	}
+
+	stmt = dryDB.Where(clause.Not(clause.Exists{Query: subQuery()})).Find(&User{}).Statement
+	if !regexp.MustCompile(`WHERE NOT EXISTS \(SELECT 1 FROM .pets. WHERE .+ IS NULL\) AND .users.\..deleted_at. IS NULL`).MatchString(stmt.SQL.String()) {
+		t.Errorf("invalid NOT EXISTS subquery, got %v", stmt.SQL.String())
+	}
+
+	stmt = dryDB.Not(clause.Exists{Query: subQuery()}).Find(&User{}).Statement
+	if !strings.Contains(stmt.SQL.String(), "WHERE NOT EXISTS (SELECT 1 FROM") {
+		t.Errorf("invalid NOT EXISTS subquery with Not, got %v", stmt.SQL.String())
+	}
+
+	stmt = dryDB.Where("name = ?", "exists_user").Where(clause.Or(clause.Exists{Query: subQuery()}, clause.Gt{Column: "age", Value: 10})).Find(&User{}).Statement
+	if !regexp.MustCompile(`WHERE name = .+ AND \(EXISTS \(SELECT 1 FROM .pets. WHERE .+ IS NULL\) OR .age. > .+\)`).MatchString(stmt.SQL.String()) {
+		t.Errorf("invalid EXISTS subquery in OR conditions, got %v", stmt.SQL.String())
+	}
+
+	if !reflect.DeepEqual(stmt.Vars, []interface{}{"exists_user", "exists_pet", 10}) {
+		t.Errorf("vars of the subquery should keep the order, got %v", stmt.Vars)
+	}
+
+	user := *GetUser("exists_user", Config{Pets: 1})
+	user.Pets[0].Name = "exists_pet"
+	DB.Create(&user)
+	DB.Create(GetUser("exists_user", Config{}))
+
+	var users []User
+	if err := DB.Where("name = ?", "exists_user").Where(clause.Exists{Query: subQuery()}).Find(&users).Error; err != nil || len(users) != 1 || users[0].ID != user.ID {
+		t.Errorf("should find the user with pets, got error %v, users %v", err, len(users))
+	}
+
+	if err := DB.Where("name = ?", "exists_user").Not(clause.Exists{Query: subQuery()}).Find(&users).Error; err != nil || len(users) != 1 || users[0].ID == user.ID {
+		t.Errorf("should find the user without pets, got error %v, users %v", err, len(users))
+	}
+}
+
 func TestSubQueryWithRaw(t *testing.T) {
 	users := []User{
 		{Name: "subquery_raw_1", Age: 10},
@@ -1380,3 +1612,513 @@ func TestQueryError(t *testing.T) {
 	}, Value: 1}).Scan(&p2).Error
 	AssertEqual(t, err, gorm.ErrModelValueRequired)
 }
+
+func TestOrderByValues(t *testing.T) {
+	users := []User{
+		*GetUser("order_by_values_1", Config{}),
+		*GetUser("order_by_values_2", Config{}),
+		*GetUser("order_by_values_3", Config{}),
+	}
+	DB.Create(&users)
+
+	ids := []interface{}{users[2].ID, users[0].ID, users[1].ID}
+
+	var result []User
+	if err := DB.Where("id IN ?", ids).Order(clause.OrderByValues{Column: clause.Column{Name: "id"}, Values: ids}).Find(&result).Error; err != nil {
+		t.Fatalf("failed to order by values, got error: %v", err)
+	}
+
+	if len(result) != 3 {
+		t.Fatalf("failed to find users, got %v", len(result))
+	}
+
+	for idx, id := range ids {
+		if result[idx].ID != id {
+			t.Errorf("#%v user should be %v, got %v", idx, id, result[idx].ID)
+		}
+	}
+
+	// users are ordered by the given ages, then by name
+	DB.Model(&users[0]).Update("age", 10)
+	DB.Model(&User{}).Where("id IN ?", []interface{}{users[1].ID, users[2].ID}).Update("age", 20)
+	if err := DB.Where("id IN ?", ids).Order(clause.OrderByValues{Column: clause.Column{Name: "age"}, Values: []interface{}{10, 20}}).Order("name DESC").Find(&result).Error; err != nil {
+		t.Fatalf("failed to order by values and name, got error: %v", err)
+	}
+	if len(result) != 3 || result[0].Name != users[0].Name || result[1].Name != users[2].Name || result[2].Name != users[1].Name {
+		t.Errorf("should order by values and name, got %v", result)
+	}
+
+	stmt := DB.Session(&gorm.Session{DryRun: true}).Order(clause.OrderByValues{Column: clause.Column{Name: "id"}, Values: ids}).Order("id").Find(&User{}).Statement
+	if !regexp.MustCompile(`ORDER BY CASE .id. WHEN .+ END,id$`).MatchString(stmt.SQL.String()) {
+		t.Errorf("should keep both orders, got %v", stmt.SQL.String())
+	}
+
+	stmt = DB.Session(&gorm.Session{DryRun: true}).Order(clause.OrderByValues{Column: clause.Column{Name: "id"}}).Find(&User{}).Statement
+	if strings.Contains(stmt.SQL.String(), "ORDER BY") {
+		t.Errorf("empty values should not add ORDER BY, got %v", stmt.SQL.String())
+	}
+}
+
+func TestExists(t *testing.T) {
+	users := []User{
+		*GetUser("exists_1", Config{Company: true}),
+		*GetUser("exists_2", Config{}),
+		*GetUser("exists_deleted", Config{}),
+	}
+	DB.Create(&users)
+	DB.Delete(&users[2])
+
+	byName := func(name string) func(*gorm.DB) *gorm.DB {
+		return func(db *gorm.DB) *gorm.DB {
+			return db.Where("name = ?", name)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		query  *gorm.DB
+		exists bool
+	}{
+		{"Present", DB.Model(&User{}).Where("name = ?", "exists_1"), true},
+		{"Absent", DB.Model(&User{}).Where("name = ?", "exists_absent"), false},
+		{"SoftDeleted", DB.Model(&User{}).Where("name = ?", "exists_deleted"), false},
+		{"Unscoped", DB.Unscoped().Model(&User{}).Where("name = ?", "exists_deleted"), true},
+		{"Scopes", DB.Model(&User{}).Scopes(byName("exists_2")), true},
+		{"Joins", DB.Model(&User{}).Joins("Company").Where("Company.name = ?", users[0].Company.Name).Where("users.name LIKE ?", "exists%"), true},
+		{"JoinsAbsent", DB.Model(&User{}).Joins("Company").Where("Company.name = ?", users[0].Company.Name).Where("users.name = ?", "exists_2"), false},
+		{"OrderAndPreload", DB.Model(&User{}).Preload("Pets").Order("name desc").Where("name LIKE ?", "exists%"), true},
+		{"Table", DB.Table("users").Where("name = ?", "exists_deleted"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exists, err := tt.query.Exists()
+			if err != nil {
+				t.Fatalf("failed to check exists, got %v", err)
+			}
+
+			if exists != tt.exists {
+				t.Errorf("exists should be %v, got %v", tt.exists, exists)
+			}
+		})
+	}
+
+	result := DB.Session(&gorm.Session{DryRun: true}).Model(&User{}).Preload("Pets").Order("name desc").Where("name = ?", "exists_1")
+	result.Exists()
+	if sql := result.Statement.SQL.String(); !regexp.MustCompile(`SELECT 1 FROM .users. WHERE name = .+ AND .users.\..deleted_at. IS NULL LIMIT .?1`).MatchString(sql) {
+		t.Errorf("exists should query with SELECT 1 and LIMIT 1 without ORDER BY, got %v", sql)
+	}
+
+	_, hasOrder := result.Statement.Clauses["ORDER BY"]
+	_, hasSelect := result.Statement.Clauses["SELECT"]
+	_, hasLimit := result.Statement.Clauses["LIMIT"]
+	if !hasOrder || hasSelect || hasLimit || len(result.Statement.Preloads) != 1 {
+		t.Errorf("statement should be restored after exists, got %+v", result.Statement.Clauses)
+	}
+}
+
+func TestTakeOptional(t *testing.T) {
+	users := []User{*GetUser("take_optional_1", Config{}), *GetUser("take_optional_deleted", Config{})}
+	DB.Create(&users)
+	DB.Delete(&users[1])
+
+	var user User
+	if found, err := DB.Where("name = ?", "take_optional_1").TakeOptional(&user); err != nil || !found {
+		t.Fatalf("should find the record, got found %v, err %v", found, err)
+	}
+	CheckUser(t, user, users[0])
+
+	var absent User
+	if found, err := DB.TakeOptional(&absent, "name = ?", "take_optional_absent"); err != nil || found {
+		t.Errorf("should not find the record without error, got found %v, err %v", found, err)
+	}
+
+	if found, err := DB.TakeOptional(&absent, "name = ?", "take_optional_deleted"); err != nil || found {
+		t.Errorf("should not find the soft deleted record, got found %v, err %v", found, err)
+	}
+
+	if found, err := DB.Unscoped().TakeOptional(&absent, "name = ?", "take_optional_deleted"); err != nil || !found || absent.ID != users[1].ID {
+		t.Errorf("should find the soft deleted record with Unscoped, got found %v, err %v", found, err)
+	}
+
+	if _, err := DB.Table("not_exist_table").TakeOptional(&absent); err == nil {
+		t.Errorf("should return the query error")
+	}
+}
+
+type JSONQueryUser struct {
+	ID         uint
+	Name       string
+	Attributes string
+}
+
+func TestJSONQuery(t *testing.T) {
+	// JSON functions of PostgreSQL require json or jsonb columns
+	if DB.Dialector.Name() != "sqlite" && DB.Dialector.Name() != "mysql" {
+		t.Skip()
+	}
+
+	DB.Migrator().DropTable(&JSONQueryUser{})
+	if err := DB.AutoMigrate(&JSONQueryUser{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	users := []JSONQueryUser{
+		{Name: "json_query_1", Attributes: `{"role": {"name": "admin"}, "age": "30", "tags": ["a", "b"]}`},
+		{Name: "json_query_2", Attributes: `{"role": {"name": "member"}, "age": "20", "nickname": null}`},
+		{Name: "json_query_3", Attributes: `{"age": "25"}`},
+	}
+	DB.Create(&users)
+
+	var result []JSONQueryUser
+	if err := DB.Where(clause.JSONQuery{Column: "attributes", Path: []string{"role", "name"}, Equals: "admin"}).Find(&result).Error; err != nil {
+		t.Fatalf("failed to query with nested path, got %v", err)
+	}
+	if len(result) != 1 || result[0].Name != "json_query_1" {
+		t.Errorf("should find user with nested path, got %+v", result)
+	}
+
+	result = nil
+	DB.Where(clause.JSONQuery{Column: "attributes", Path: []string{"tags", "1"}, Equals: "b"}).Find(&result)
+	if len(result) != 1 || result[0].Name != "json_query_1" {
+		t.Errorf("should find user with array index, got %+v", result)
+	}
+
+	result = nil
+	DB.Where(clause.JSONQuery{Column: "attributes", Path: []string{"role"}, HasKey: true}).Order("id").Find(&result)
+	if len(result) != 2 || result[0].Name != "json_query_1" || result[1].Name != "json_query_2" {
+		t.Errorf("should find users with key, got %+v", result)
+	}
+
+	result = nil
+	DB.Where(clause.JSONQuery{Column: "attributes", Path: []string{"nickname"}, HasKey: true}).Find(&result)
+	if len(result) != 1 || result[0].Name != "json_query_2" {
+		t.Errorf("should find user with null value key, got %+v", result)
+	}
+
+	result = nil
+	DB.Where(clause.JSONQuery{Column: "attributes", Path: []string{"role", "level"}, HasKey: true}).Find(&result)
+	if len(result) != 0 {
+		t.Errorf("should not find users without nested key, got %+v", result)
+	}
+
+	result = nil
+	DB.Order(clause.JSONQuery{Column: "attributes", Path: []string{"age"}, Desc: true}).Find(&result)
+	if len(result) != 3 || result[0].Name != "json_query_1" || result[1].Name != "json_query_3" || result[2].Name != "json_query_2" {
+		t.Errorf("should order by JSON path, got %+v", result)
+	}
+}
+
+func TestFirstLastOrder(t *testing.T) {
+	dryDB := DB.Session(&gorm.Session{DryRun: true})
+
+	stmt := dryDB.Order("age DESC").First(&User{}).Statement
+	if !regexp.MustCompile("ORDER BY age DESC LIMIT").MatchString(stmt.SQL.String()) {
+		t.Errorf("First shouldn't order by primary key when ordered, got %v", stmt.SQL.String())
+	}
+
+	stmt = dryDB.First(&User{}).Statement
+	if !regexp.MustCompile("ORDER BY .users.\\..id. LIMIT").MatchString(stmt.SQL.String()) {
+		t.Errorf("First should order by primary key, got %v", stmt.SQL.String())
+	}
+
+	stmt = dryDB.Session(&gorm.Session{ForceStableOrder: true}).Order("age DESC").Last(&User{}).Statement
+	if !regexp.MustCompile("ORDER BY age DESC,.users.\\..id. DESC LIMIT").MatchString(stmt.SQL.String()) {
+		t.Errorf("Last should order by primary key with ForceStableOrder, got %v", stmt.SQL.String())
+	}
+
+	type CompositeKeyItem struct {
+		Locale string `gorm:"primaryKey"`
+		Code   string `gorm:"primaryKey"`
+		Name   string
+	}
+
+	stmt = dryDB.First(&CompositeKeyItem{}).Statement
+	if !regexp.MustCompile("ORDER BY .composite_key_items.\\..locale.,.composite_key_items.\\..code. LIMIT").MatchString(stmt.SQL.String()) {
+		t.Errorf("First should order by all primary keys, got %v", stmt.SQL.String())
+	}
+
+	stmt = dryDB.Last(&CompositeKeyItem{}).Statement
+	if !regexp.MustCompile("ORDER BY .composite_key_items.\\..locale. DESC,.composite_key_items.\\..code. DESC LIMIT").MatchString(stmt.SQL.String()) {
+		t.Errorf("Last should order by all primary keys, got %v", stmt.SQL.String())
+	}
+
+	if err := DB.Order("age DESC").Where("name = ?", "first_last_order_not_exists").First(&User{}).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("should return record not found error, got %v", err)
+	}
+}
+
+func TestFindWithStringPrimaryKey(t *testing.T) {
+	type StringKeyOrder struct {
+		Number string `gorm:"primaryKey;size:64"`
+		Amount int
+	}
+
+	DB.Migrator().DropTable(&StringKeyOrder{})
+	if err := DB.AutoMigrate(&StringKeyOrder{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	orders := []StringKeyOrder{{Number: "00123", Amount: 1}, {Number: "550e8400-e29b-41d4-a716-446655440000", Amount: 2}}
+	if err := DB.Create(&orders).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+
+	var order StringKeyOrder
+	if err := DB.First(&order, "00123").Error; err != nil || order.Amount != 1 {
+		t.Errorf("numeric string should be a string primary key, got %+v, %v", order, err)
+	}
+
+	order = StringKeyOrder{}
+	if err := DB.First(&order, "550e8400-e29b-41d4-a716-446655440000").Error; err != nil || order.Amount != 2 {
+		t.Errorf("bare string should be a string primary key, got %+v, %v", order, err)
+	}
+
+	var count int64
+	if err := DB.Model(&StringKeyOrder{}).Where("amount > 0").Count(&count).Error; err != nil || count != 2 {
+		t.Errorf("conditions should still be conditions, got %v, %v", count, err)
+	}
+
+	var user User
+	stmt := DB.Session(&gorm.Session{DryRun: true}).First(&user, "123").Statement
+	if !regexp.MustCompile("WHERE .users.\\..id. = ").MatchString(stmt.SQL.String()) || !reflect.DeepEqual(stmt.Vars, []interface{}{"123"}) {
+		t.Errorf("numeric string should be an int primary key, got %v %v", stmt.SQL.String(), stmt.Vars)
+	}
+}
+
+func TestSelectExprField(t *testing.T) {
+	type SelectExprItem struct {
+		ID       uint
+		Name     string
+		Price    int
+		Quantity int
+		Total    int `gorm:"->;selectExpr:price * quantity"`
+	}
+
+	DB.Migrator().DropTable(&SelectExprItem{})
+	if err := DB.AutoMigrate(&SelectExprItem{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	if DB.Migrator().HasColumn(&SelectExprItem{}, "total") {
+		t.Errorf("select expression field shouldn't be migrated")
+	}
+
+	items := []SelectExprItem{{Name: "select_expr_1", Price: 3, Quantity: 4, Total: 100}, {Name: "select_expr_2", Price: 5, Quantity: 6}}
+	if err := DB.Create(&items).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+
+	dryDB := DB.Session(&gorm.Session{DryRun: true})
+	stmt := dryDB.Find(&[]SelectExprItem{}).Statement
+	if !regexp.MustCompile(`SELECT .select_expr_items.\..id.,.*\(price \* quantity\) AS .?total.? FROM`).MatchString(stmt.SQL.String()) {
+		t.Errorf("select expression should be selected, got %v", stmt.SQL.String())
+	}
+
+	stmt = dryDB.Select("name", "price").Find(&[]SelectExprItem{}).Statement
+	if strings.Contains(stmt.SQL.String(), "quantity") {
+		t.Errorf("select expression should be skipped if not selected, got %v", stmt.SQL.String())
+	}
+
+	stmt = dryDB.Omit("total").Find(&[]SelectExprItem{}).Statement
+	if strings.Contains(stmt.SQL.String(), "price * quantity") {
+		t.Errorf("select expression should be skipped if omitted, got %v", stmt.SQL.String())
+	}
+
+	var results []SelectExprItem
+	if err := DB.Where("name LIKE ?", "select_expr_%").Order("id").Find(&results).Error; err != nil {
+		t.Fatalf("failed to find, got %v", err)
+	}
+
+	if len(results) != 2 || results[0].Total != 12 || results[1].Total != 30 {
+		t.Errorf("computed values should be scanned, got %+v", results)
+	}
+
+	var result SelectExprItem
+	if err := DB.Select("id", "total").First(&result, results[1].ID).Error; err != nil || result.Total != 30 || result.Name != "" {
+		t.Errorf("selected select expression should be scanned, got %+v, %v", result, err)
+	}
+
+	var totals []struct {
+		Name  string
+		Total int
+	}
+	if err := DB.Model(&SelectExprItem{}).Where("name LIKE ?", "select_expr_%").Order("id").Find(&totals).Error; err != nil || len(totals) != 2 || totals[1].Total != 30 {
+		t.Errorf("smaller struct should use the select expression of the model, got %+v, %v", totals, err)
+	}
+
+	if err := DB.Model(&results[0]).Updates(SelectExprItem{Price: 10, Total: 1}).Error; err != nil {
+		t.Fatalf("failed to update, got %v", err)
+	}
+
+	result = SelectExprItem{}
+	DB.First(&result, results[0].ID)
+	if result.Total != 40 {
+		t.Errorf("computed value should be updated, got %+v", result)
+	}
+}
+
+func TestFindToChannel(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+	sqlDB, _ := db.DB()
+
+	users := make([]User, 10)
+	for i := range users {
+		users[i] = *GetUser(fmt.Sprintf("find_to_channel_%02d", i), Config{})
+	}
+	db.Create(&users)
+
+	ch := make(chan User, 1)
+	done := make(chan *gorm.DB)
+	go func() {
+		done <- db.Where("name LIKE ?", "find_to_channel_%").Order("name").FindToChannel(ch)
+	}()
+
+	var names []string
+	for user := range ch {
+		names = append(names, user.Name)
+	}
+
+	result := <-done
+	if result.Error != nil || result.RowsAffected != 10 || len(names) != 10 || names[0] != "find_to_channel_00" || names[9] != "find_to_channel_09" {
+		t.Errorf("all records should be sent in order, got %v, %v, %v", names, result.RowsAffected, result.Error)
+	}
+
+	// cancel halfway
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pointers := make(chan *User, 1)
+	go func() {
+		done <- db.WithContext(ctx).Where("name LIKE ?", "find_to_channel_%").Order("name").FindToChannel(pointers)
+	}()
+
+	var received int
+	for user := range pointers {
+		if received++; received == 3 {
+			cancel()
+			time.Sleep(10 * time.Millisecond)
+		}
+		if user == nil || user.ID == 0 {
+			t.Errorf("record should be scanned, got %+v", user)
+		}
+	}
+
+	if result = <-done; !errors.Is(result.Error, context.Canceled) || result.RowsAffected >= 10 || received >= 10 {
+		t.Errorf("should stop when the context is canceled, got %v records, %v", received, result.Error)
+	}
+
+	if inUse := sqlDB.Stats().InUse; inUse != 0 {
+		t.Errorf("rows should be closed, got %v connections in use", inUse)
+	}
+
+	// AfterFind hooks
+	db.AutoMigrate(&Product{})
+	db.Where("name LIKE ?", "find_to_channel_%").Delete(&Product{})
+	db.Create(&[]Product{{Name: "find_to_channel_1"}, {Name: "find_to_channel_2"}})
+	products := make(chan *Product)
+	go func() {
+		done <- db.Where("name LIKE ?", "find_to_channel_%").FindToChannel(products)
+	}()
+
+	for product := range products {
+		if product.AfterFindCallTimes != 1 {
+			t.Errorf("AfterFind should be called before sending, got %+v", product)
+		}
+	}
+
+	if result = <-done; result.Error != nil || result.RowsAffected != 2 {
+		t.Errorf("failed to find products, got %v, %v", result.RowsAffected, result.Error)
+	}
+
+	// SQL errors and invalid channels close the channel
+	ch = make(chan User)
+	if err := db.Where("invalid_column = ?", 1).FindToChannel(ch).Error; err == nil {
+		t.Errorf("should return error of the query")
+	}
+	if _, ok := <-ch; ok {
+		t.Errorf("channel should be closed on error")
+	}
+
+	if err := db.FindToChannel(make(chan string)).Error; !errors.Is(err, gorm.ErrInvalidValue) {
+		t.Errorf("should return ErrInvalidValue for channels of non-struct, got %v", err)
+	}
+
+	if err := db.FindToChannel(make(<-chan User)).Error; !errors.Is(err, gorm.ErrInvalidValue) {
+		t.Errorf("should return ErrInvalidValue for receive-only channels, got %v", err)
+	}
+
+	if inUse := sqlDB.Stats().InUse; inUse != 0 {
+		t.Errorf("rows should be closed, got %v connections in use", inUse)
+	}
+}
+
+type queryPlanDialector struct {
+	gorm.Dialector
+}
+
+func (queryPlanDialector) ExplainKeyword() string {
+	return "EXPLAIN QUERY PLAN"
+}
+
+func TestExplainQuery(t *testing.T) {
+	user := *GetUser("explain_query", Config{})
+	DB.Create(&user)
+
+	var plan []map[string]interface{}
+	var users []User
+	query := DB.Model(&User{}).Where("id = ?", user.ID)
+	if err := query.ExplainQuery(&plan).Error; err != nil {
+		t.Fatalf("failed to explain query, got error: %v", err)
+	}
+
+	if len(plan) == 0 {
+		t.Fatalf("plan rows should be returned")
+	}
+
+	if err := query.Find(&users).Error; err != nil || len(users) != 1 || users[0].Name != user.Name {
+		t.Errorf("query should be usable after explained, got error: %v, users: %v", err, users)
+	}
+
+	if err := DB.Raw("UPDATE users SET name = ? WHERE id = ?", "explain_query_updated", user.ID).ExplainQuery(&plan).Error; !errors.Is(err, gorm.ErrExplainWriteStatement) {
+		t.Errorf("write statements should be refused, got error: %v", err)
+	}
+
+	if DB.Dialector.Name() == "sqlite" {
+		// 没有实现 QueryPlanExplainer 时使用 EXPLAIN，sqlite 返回字节码
+		if _, ok := plan[0]["opcode"]; !ok {
+			t.Errorf("plan should be explained with EXPLAIN by default, got %v", plan[0])
+		}
+
+		db, err := gorm.Open(queryPlanDialector{Dialector: DB.Dialector}, &gorm.Config{})
+		if err != nil {
+			t.Fatalf("failed to open db, got error %v", err)
+		}
+
+		plan = nil
+		if err := db.Model(&User{}).Where("id = ?", user.ID).ExplainQuery(&plan).Error; err != nil {
+			t.Fatalf("failed to explain query, got error: %v", err)
+		}
+
+		var details []string
+		for _, row := range plan {
+			details = append(details, fmt.Sprint(row["detail"]))
+		}
+		if detail := strings.Join(details, ";"); !strings.Contains(detail, "users") || !strings.Contains(detail, "PRIMARY KEY") {
+			t.Errorf("plan should search users by primary key, got %v", detail)
+		}
+
+		plan = nil
+		if err := db.Raw("UPDATE users SET name = ? WHERE id = ?", "explain_query_updated", user.ID).ExplainQuery(&plan, &gorm.ExplainOptions{AllowWrites: true}).Error; err != nil || len(plan) == 0 {
+			t.Errorf("write statements should be explained with AllowWrites, got error: %v, plan: %v", err, plan)
+		}
+
+		var result User
+		DB.First(&result, user.ID)
+		if result.Name != user.Name {
+			t.Errorf("explained update should not be executed, got %v", result.Name)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package tests_test
 
 import (
+	"errors"
 	"reflect"
 	"sort"
 	"strings"
@@ -240,3 +241,258 @@ func TestScanToEmbedded(t *testing.T) {
 	err := DB.Raw("SELECT * FROM users INNER JOIN users Manager ON users.manager_id = Manager.id WHERE users.id = ?", user.ID).Scan(&user2).Error
 	AssertEqual(t, err, nil)
 }
+
+func TestScanWithMapColumns(t *testing.T) {
+	user := *GetUser("scan_with_map_columns", Config{Company: true})
+	DB.Create(&user)
+
+	mapping := map[string]string{"legacy_name": "name", "legacy_age": "age"}
+
+	var result User
+	if err := DB.MapColumns(mapping).Raw("SELECT id, name AS legacy_name, age AS legacy_age FROM users WHERE id = ?", user.ID).Scan(&result).Error; err != nil {
+		t.Fatalf("failed to scan with mapped columns, got error %v", err)
+	}
+
+	if result.ID != user.ID || result.Name != user.Name || result.Age != user.Age {
+		t.Errorf("mapped columns should be scanned into fields, got %+v", result)
+	}
+
+	var results []User
+	if err := DB.MapColumns(mapping).Model(&User{}).Select("id, name AS legacy_name").Where("id = ?", user.ID).Find(&results).Error; err != nil || len(results) != 1 {
+		t.Fatalf("failed to find with mapped columns, got error %v, results %+v", err, results)
+	} else if results[0].Name != user.Name {
+		t.Errorf("mapped columns should be found into fields, got %+v", results[0])
+	}
+
+	var joined User
+	if err := DB.MapColumns(mapping).Raw(
+		"SELECT users.id, companies.name AS Company__legacy_name FROM users LEFT JOIN companies ON companies.id = users.company_id WHERE users.id = ?", user.ID,
+	).Scan(&joined).Error; err != nil {
+		t.Fatalf("failed to scan joined columns with mapped columns, got error %v", err)
+	} else if joined.Company.Name != user.Company.Name {
+		t.Errorf("the last segment of joined columns should be mapped, got %+v", joined.Company)
+	}
+
+	var values, mappedValues map[string]interface{}
+	DB.MapColumns(mapping).Raw("SELECT name AS legacy_name FROM users WHERE id = ?", user.ID).Scan(&values)
+	if _, ok := values["legacy_name"]; !ok {
+		t.Errorf("map keys should not be mapped by default, got %v", values)
+	}
+
+	DB.MapColumns(mapping, true).Raw("SELECT name AS legacy_name FROM users WHERE id = ?", user.ID).Scan(&mappedValues)
+	if mappedValues["name"] != user.Name {
+		t.Errorf("map keys should be mapped, got %v", mappedValues)
+	}
+}
+
+func TestScanRowsWithJoins(t *testing.T) {
+	users := []User{*GetUser("scan_rows_joins_1", Config{Company: true, Manager: true}), *GetUser("scan_rows_joins_2", Config{Company: true})}
+	DB.Create(&users)
+
+	rows, err := DB.Model(&User{}).Joins("Company").Joins("Manager").Joins("Manager.Company").
+		Where("users.name IN ?", []string{users[0].Name, users[1].Name}).Order("users.id").Rows()
+	if err != nil {
+		t.Fatalf("failed to query rows, got error %v", err)
+	}
+	defer rows.Close()
+
+	var results []User
+	for rows.Next() {
+		var user User
+		if err := DB.ScanRows(rows, &user); err != nil {
+			t.Fatalf("failed to scan rows, got error %v", err)
+		}
+		results = append(results, user)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expects 2 users, got %v", len(results))
+	}
+
+	for i, user := range results {
+		if user.Name != users[i].Name || user.Company.ID != users[i].Company.ID || user.Company.Name != users[i].Company.Name {
+			t.Errorf("company should be scanned, expects %+v, got %+v", users[i].Company, user.Company)
+		}
+	}
+
+	if results[0].Manager == nil || results[0].Manager.Name != users[0].Manager.Name || results[0].Manager.Company.Name != users[0].Manager.Company.Name {
+		t.Errorf("nested manager should be scanned, expects %+v, got %+v", users[0].Manager, results[0].Manager)
+	}
+
+	if results[1].Manager != nil {
+		t.Errorf("manager should be nil, got %+v", results[1].Manager)
+	}
+
+	// raw sql with aliased prefixes, unknown relations are ignored
+	rows, err = DB.Raw(`SELECT users.id, users.name, companies.id AS "Company__id", companies.name AS "Company__name", companies.name AS "Company__Unknown__name" FROM users LEFT JOIN companies ON users.company_id = companies.id WHERE users.name = ?`, users[1].Name).Rows()
+	if err != nil {
+		t.Fatalf("failed to query raw rows, got error %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user User
+		if err := DB.ScanRows(rows, &user); err != nil {
+			t.Fatalf("failed to scan raw rows, got error %v", err)
+		}
+
+		if user.Name != users[1].Name || user.Company.Name != users[1].Company.Name {
+			t.Errorf("company should be scanned from raw rows, got %+v", user)
+		}
+	}
+}
+
+func TestScanNullWithPolicy(t *testing.T) {
+	company := Company{Name: "null-scan-company"}
+	DB.Create(&company)
+	users := []User{
+		{Name: "null-scan-user1", CompanyID: &company.ID},
+		{Name: "null-scan-user2"},
+	}
+	DB.Create(&users)
+
+	type result struct {
+		Name        string
+		CompanyID   int
+		CompanyName string
+	}
+
+	query := func(db *gorm.DB) *gorm.DB {
+		return db.Table("users").Select("users.name, companies.id AS company_id, companies.name AS company_name").
+			Joins("LEFT JOIN companies ON companies.id = users.company_id")
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		res := result{CompanyID: 99, CompanyName: "prefilled"}
+		if err := query(DB).Where("users.name = ?", users[1].Name).Scan(&res).Error; err != nil {
+			t.Fatalf("failed to scan, got error %v", err)
+		}
+
+		if res.Name != users[1].Name || res.CompanyID != 99 || res.CompanyName != "prefilled" {
+			t.Errorf("fields should be untouched by default, got %+v", res)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		var results []result
+		err := query(DB.Session(&gorm.Session{NullScanPolicy: gorm.NullScanError})).
+			Where("users.name IN ?", []string{users[0].Name, users[1].Name}).Order("users.name").Scan(&results).Error
+		if !errors.Is(err, gorm.ErrNullValue) {
+			t.Fatalf("should return ErrNullValue, got %v", err)
+		}
+
+		if len(results) != 2 || results[0].CompanyID != int(company.ID) || results[1].Name != users[1].Name {
+			t.Errorf("other rows and fields should be scanned, got %+v", results)
+		}
+	})
+
+	t.Run("ZeroValue", func(t *testing.T) {
+		var results []result
+		err := query(DB.Session(&gorm.Session{NullScanPolicy: gorm.NullScanZeroValue})).
+			Where("users.name IN ?", []string{users[0].Name, users[1].Name}).Order("users.name").Scan(&results).Error
+		if err != nil {
+			t.Fatalf("failed to scan, got error %v", err)
+		}
+
+		if len(results) != 2 || results[0].CompanyID != int(company.ID) || results[0].CompanyName != company.Name ||
+			results[1].CompanyID != 0 || results[1].CompanyName != "" {
+			t.Errorf("failed to scan NULL as zero value, got %+v", results)
+		}
+
+		res := result{CompanyID: 99, CompanyName: "prefilled"}
+		if err := query(DB.Session(&gorm.Session{NullScanPolicy: gorm.NullScanZeroValue})).
+			Where("users.name = ?", users[1].Name).Scan(&res).Error; err != nil {
+			t.Fatalf("failed to scan, got error %v", err)
+		}
+
+		if res.Name != users[1].Name || res.CompanyID != 0 || res.CompanyName != "" {
+			t.Errorf("NULL should reset fields to zero value, got %+v", res)
+		}
+	})
+
+	t.Run("SkipField", func(t *testing.T) {
+		res := result{CompanyID: 99, CompanyName: "prefilled"}
+		if err := query(DB.Session(&gorm.Session{NullScanPolicy: gorm.NullScanSkipField})).
+			Where("users.name = ?", users[1].Name).Scan(&res).Error; err != nil {
+			t.Fatalf("failed to scan, got error %v", err)
+		}
+
+		if res.Name != users[1].Name || res.CompanyID != 99 || res.CompanyName != "prefilled" {
+			t.Errorf("NULL should keep previous values, got %+v", res)
+		}
+	})
+}
+
+type RawHookUser struct {
+	ID          uint
+	Name        string
+	DisplayName string `gorm:"-"`
+	Pets        []RawHookPet
+}
+
+func (u *RawHookUser) AfterFind(tx *gorm.DB) error {
+	u.DisplayName = "user: " + u.Name
+	return nil
+}
+
+type RawHookPet struct {
+	ID            uint
+	RawHookUserID uint
+	Name          string
+}
+
+func TestScanRunHooksOnRaw(t *testing.T) {
+	DB.Migrator().DropTable(&RawHookUser{}, &RawHookPet{})
+	if err := DB.AutoMigrate(&RawHookUser{}, &RawHookPet{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	users := []RawHookUser{
+		{Name: "raw_hook_1", Pets: []RawHookPet{{Name: "pet_1"}, {Name: "pet_2"}}},
+		{Name: "raw_hook_2", Pets: []RawHookPet{{Name: "pet_3"}}},
+	}
+	if err := DB.Create(&users).Error; err != nil {
+		t.Fatalf("failed to create users, got error %v", err)
+	}
+
+	var results []RawHookUser
+	if err := DB.Raw("SELECT * FROM raw_hook_users ORDER BY id").Preload("Pets").Scan(&results).Error; err != nil {
+		t.Fatalf("failed to scan, got error %v", err)
+	}
+	if len(results) != 2 || results[0].DisplayName != "" || results[0].Pets != nil {
+		t.Errorf("hooks and preloads shouldn't run without RunHooksOnRaw, got %+v", results)
+	}
+
+	tx := DB.Session(&gorm.Session{RunHooksOnRaw: true})
+	results = nil
+	if err := tx.Raw("SELECT * FROM raw_hook_users ORDER BY id").Preload("Pets").Scan(&results).Error; err != nil {
+		t.Fatalf("failed to scan, got error %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("should find 2 users, got %v", len(results))
+	}
+	for i, result := range results {
+		if result.DisplayName != "user: "+users[i].Name {
+			t.Errorf("AfterFind should run, got %+v", result)
+		}
+		if len(result.Pets) != len(users[i].Pets) {
+			t.Errorf("pets should be preloaded, got %+v", result.Pets)
+		}
+	}
+
+	var user RawHookUser
+	if err := tx.Raw("SELECT * FROM raw_hook_users WHERE name = ?", "raw_hook_2").Scan(&user).Error; err != nil {
+		t.Fatalf("failed to scan, got error %v", err)
+	}
+	if user.DisplayName != "user: raw_hook_2" || user.Pets != nil {
+		t.Errorf("AfterFind should run for struct, got %+v", user)
+	}
+
+	user = RawHookUser{}
+	if err := tx.Session(&gorm.Session{SkipHooks: true}).Raw("SELECT * FROM raw_hook_users WHERE name = ?", "raw_hook_2").Scan(&user).Error; err != nil {
+		t.Fatalf("failed to scan, got error %v", err)
+	}
+	if user.DisplayName != "" {
+		t.Errorf("AfterFind shouldn't run with SkipHooks, got %+v", user)
+	}
+}
@@ -7,14 +7,18 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"reflect"
 	"regexp"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"gorm.io/gorm/migrator"
+	"gorm.io/gorm/schema"
 	. "gorm.io/gorm/utils/tests"
 )
 
@@ -391,3 +395,248 @@ func TestGORMValuer(t *testing.T) {
 		t.Errorf("generated vars is not equal, got %v", stmt.Vars)
 	}
 }
+
+// RegisteredMoney is like types of third-party packages, can't add methods to it
+type RegisteredMoney struct {
+	units    int64
+	currency string
+}
+
+type RegisteredDataTypeStruct struct {
+	ID       uint
+	Price    RegisteredMoney
+	Discount *RegisteredMoney
+}
+
+func init() {
+	gorm.RegisterDataType(reflect.TypeOf(RegisteredMoney{}), gorm.DataTypeSpec{
+		GORMType: schema.String,
+		DBType: func(dialect string, field *schema.Field) string {
+			switch dialect {
+			case "dummy":
+				return "money_text"
+			case "sqlserver":
+				return "nvarchar(64)"
+			default:
+				return "varchar(64)"
+			}
+		},
+		Bind: func(v interface{}) (driver.Value, error) {
+			m := v.(RegisteredMoney)
+			if m.currency == "" {
+				return nil, errors.New("currency required")
+			}
+			return fmt.Sprintf("%d %s", m.units, m.currency), nil
+		},
+		Scan: func(dst reflect.Value, src interface{}) error {
+			var str string
+			switch v := src.(type) {
+			case []byte:
+				str = string(v)
+			case string:
+				str = v
+			default:
+				return fmt.Errorf("unsupported money value %#v", src)
+			}
+
+			var m RegisteredMoney
+			if _, err := fmt.Sscanf(str, "%d %s", &m.units, &m.currency); err != nil {
+				return err
+			}
+			dst.Set(reflect.ValueOf(m))
+			return nil
+		},
+	})
+}
+
+func TestRegisteredDataType(t *testing.T) {
+	DB.Migrator().DropTable(&RegisteredDataTypeStruct{})
+	if err := DB.Migrator().AutoMigrate(&RegisteredDataTypeStruct{}); err != nil {
+		t.Fatalf("no error should happen when migrate registered data type, got error %v", err)
+	}
+
+	data := RegisteredDataTypeStruct{Price: RegisteredMoney{units: 1250, currency: "USD"}}
+	if err := DB.Create(&data).Error; err != nil {
+		t.Fatalf("no error should happen when create registered data type, got error %v", err)
+	}
+
+	var result RegisteredDataTypeStruct
+	if err := DB.First(&result, data.ID).Error; err != nil {
+		t.Fatalf("no error should happen when query registered data type, got error %v", err)
+	}
+	AssertEqual(t, result, data)
+
+	var found RegisteredDataTypeStruct
+	if err := DB.Where("price = ?", RegisteredMoney{units: 1250, currency: "USD"}).First(&found).Error; err != nil {
+		t.Fatalf("no error should happen when query with registered data type, got error %v", err)
+	}
+	AssertEqual(t, found, data)
+
+	discount := RegisteredMoney{units: 100, currency: "EUR"}
+	if err := DB.Model(&data).Update("Discount", &discount).Error; err != nil {
+		t.Fatalf("no error should happen when update registered data type, got error %v", err)
+	}
+
+	var updated RegisteredDataTypeStruct
+	DB.First(&updated, data.ID)
+	if updated.Discount == nil || *updated.Discount != discount {
+		t.Fatalf("discount should be updated, got %#v", updated.Discount)
+	}
+
+	if err := DB.Create(&RegisteredDataTypeStruct{Price: RegisteredMoney{units: 1}}).Error; err == nil || !strings.Contains(err.Error(), "currency required") {
+		t.Fatalf("should returns bind error, got %v", err)
+	}
+
+	if err := DB.Table("registered_data_type_structs").Where("id = ?", data.ID).Update("price", "invalid").Error; err != nil {
+		t.Fatalf("no error should happen when update raw value, got error %v", err)
+	}
+
+	if err := DB.First(&RegisteredDataTypeStruct{}, data.ID).Error; err == nil || !strings.Contains(err.Error(), "Price") {
+		t.Fatalf("should returns scan error, got %v", err)
+	}
+}
+
+func TestRegisteredDataTypeOf(t *testing.T) {
+	stmt := &gorm.Statement{DB: DB}
+	if err := stmt.Parse(&RegisteredDataTypeStruct{}); err != nil {
+		t.Fatalf("failed to parse registered data type struct, got error %v", err)
+	}
+
+	field := stmt.Schema.LookUpField("Price")
+	if field.DataType != schema.String {
+		t.Errorf("data type should be string, got %v", field.DataType)
+	}
+
+	m := migrator.Migrator{Config: migrator.Config{DB: DB, Dialector: DummyDialector{}}}
+	if dataType := m.DataTypeOf(field); dataType != "money_text" {
+		t.Errorf("data type of dummy dialector should be money_text, got %v", dataType)
+	}
+
+	if dataType := m.DataTypeOf(stmt.Schema.LookUpField("Discount")); dataType != "money_text" {
+		t.Errorf("data type of pointer field should be money_text, got %v", dataType)
+	}
+}
+
+type BigDecimalAccount struct {
+	ID      uint
+	Balance big.Int `gorm:"precision:38"`
+	Rate    big.Rat `gorm:"precision:38;scale:20"`
+	Limit   *big.Int
+	Fee     *big.Rat
+	Code    big.Int `gorm:"type:varchar(100)"`
+}
+
+func TestBigDecimal(t *testing.T) {
+	DB.Migrator().DropTable(&BigDecimalAccount{})
+	if DB.Dialector.Name() == "sqlite" {
+		// decimal columns of sqlite have NUMERIC affinity, which converts values beyond 15 digits to REAL, keep them as text
+		if err := DB.Exec("CREATE TABLE big_decimal_accounts (id integer PRIMARY KEY AUTOINCREMENT, balance text, rate text, `limit` text, fee text, code varchar(100))").Error; err != nil {
+			t.Fatalf("failed to create table, got error %v", err)
+		}
+	} else if err := DB.AutoMigrate(&BigDecimalAccount{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	// exceeds the precision of float64
+	balance, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	rate, _ := new(big.Rat).SetString("1234567890123456.12345678901234567891")
+	limit, _ := new(big.Int).SetString("-98765432109876543210", 10)
+	account := BigDecimalAccount{Balance: *balance, Rate: *rate, Limit: limit}
+	if err := DB.Create(&account).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	var result BigDecimalAccount
+	if err := DB.First(&result, account.ID).Error; err != nil {
+		t.Fatalf("failed to query, got error %v", err)
+	}
+	if result.Balance.Cmp(balance) != 0 || result.Rate.Cmp(rate) != 0 || result.Limit == nil || result.Limit.Cmp(limit) != 0 || result.Fee != nil {
+		t.Errorf("decimal values should be kept, got %v, %v, %v, %v", &result.Balance, result.Rate.FloatString(20), result.Limit, result.Fee)
+	}
+
+	var count int64
+	if err := DB.Model(&BigDecimalAccount{}).Where("balance = ?", balance).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("failed to query with big.Int, got %v, error %v", count, err)
+	}
+
+	fee := big.NewRat(1, 8)
+	if err := DB.Model(&result).Update("Fee", fee).Error; err != nil {
+		t.Fatalf("failed to update, got error %v", err)
+	}
+	var fees []string
+	DB.Model(&BigDecimalAccount{}).Where("id = ?", account.ID).Pluck("fee", &fees)
+	AssertEqual(t, fees, []string{"0.125"})
+
+	var updated BigDecimalAccount
+	DB.First(&updated, account.ID)
+	if updated.Fee == nil || updated.Fee.Cmp(fee) != 0 {
+		t.Errorf("fee should be updated, got %v", updated.Fee)
+	}
+
+	// scan from float and string columns
+	var scanned struct {
+		Balance big.Int
+		Rate    big.Rat
+	}
+	if err := DB.Raw("SELECT '42' AS balance, 0.1 AS rate").Scan(&scanned).Error; err != nil {
+		t.Fatalf("failed to scan, got error %v", err)
+	}
+	if scanned.Balance.Int64() != 42 || scanned.Rate.Cmp(big.NewRat(1, 10)) != 0 {
+		t.Errorf("failed to scan decimal values, got %v, %v", &scanned.Balance, &scanned.Rate)
+	}
+
+	if err := DB.Raw("SELECT '1.5' AS balance").Scan(&scanned).Error; err == nil {
+		t.Errorf("should fail to scan non-integer value into big.Int")
+	}
+
+	// compare numerically
+	var changed bool
+	DB.Callback().Update().Before("gorm:update").Register("test:big_decimal_changed", func(tx *gorm.DB) {
+		if _, ok := tx.Statement.Model.(*BigDecimalAccount); ok {
+			changed = tx.Statement.Changed("Fee")
+		}
+	})
+	defer DB.Callback().Update().Remove("test:big_decimal_changed")
+
+	DB.Model(&updated).Updates(map[string]interface{}{"fee": "0.1250"})
+	if changed {
+		t.Errorf("0.1250 should equal to 0.125")
+	}
+	DB.Model(&updated).Updates(map[string]interface{}{"fee": "0.126"})
+	if !changed {
+		t.Errorf("0.126 should not equal to 0.125")
+	}
+}
+
+func TestBigDecimalDataTypeOf(t *testing.T) {
+	stmt := &gorm.Statement{DB: DB}
+	if err := stmt.Parse(&BigDecimalAccount{}); err != nil {
+		t.Fatalf("failed to parse, got error %v", err)
+	}
+
+	if field := stmt.Schema.LookUpField("Balance"); field.DataType != schema.Decimal {
+		t.Errorf("data type should be decimal, got %v", field.DataType)
+	}
+
+	// the column type is decided by the dialector with the precision and scale
+	m := migrator.Migrator{Config: migrator.Config{DB: DB, Dialector: DummyDialector{}}}
+	for name, expected := range map[string]string{
+		"Balance": "numeric(38,0)",
+		"Rate":    "numeric(38,20)",
+		"Limit":   "",
+		"Fee":     "",
+	} {
+		field := stmt.Schema.LookUpField(name)
+		if dataType := m.DataTypeOf(field); dataType != expected {
+			t.Errorf("data type of %v should be %v, got %v", name, expected, dataType)
+		}
+
+		if dataType, expected := DB.Migrator().FullDataTypeOf(field).SQL, DB.Dialector.DataTypeOf(field); dataType != expected {
+			t.Errorf("data type of %v should be %v, got %v", name, expected, dataType)
+		}
+	}
+
+	if dataType := DB.Migrator().FullDataTypeOf(stmt.Schema.LookUpField("Code")).SQL; !strings.Contains(dataType, "varchar(100)") {
+		t.Errorf("type tag should be used, got %v", dataType)
+	}
+}
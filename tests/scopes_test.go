@@ -123,3 +123,88 @@ func TestComplexScopes(t *testing.T) {
 		})
 	}
 }
+
+func TestNamedScopes(t *testing.T) {
+	users := []*User{
+		GetUser("NamedScopeUser1", Config{}),
+		GetUser("NamedScopeUser2", Config{}),
+		GetUser("NamedScopeUser3", Config{}),
+	}
+	DB.Create(&users)
+
+	tenantDB := DB.Where("name LIKE ?", "NamedScopeUser%").ScopeNamed("tenant", NameIn([]string{"NamedScopeUser1"})).Session(&gorm.Session{})
+	if !tenantDB.HasScope("tenant") {
+		t.Fatalf("tenant scope should exist")
+	}
+
+	var users1, users2, users3 []User
+	tenantDB.Find(&users1)
+	if len(users1) != 1 {
+		t.Errorf("should find one user with tenant scope, but got %v", len(users1))
+	}
+
+	adminDB := tenantDB.WithoutScope("tenant")
+	if adminDB.HasScope("tenant") {
+		t.Errorf("tenant scope should be removed")
+	}
+
+	adminDB.Find(&users2)
+	if len(users2) != 3 {
+		t.Errorf("should find three users without tenant scope, but got %v", len(users2))
+	}
+
+	tenantDB.Scopes(NameIn([]string{"NamedScopeUser1", "NamedScopeUser2"})).Find(&users3)
+	if len(users3) != 1 || !tenantDB.HasScope("tenant") {
+		t.Errorf("tenant scope should still be applied, but got %v", len(users3))
+	}
+}
+
+type tenantScopePlugin struct {
+	names []string
+}
+
+func (tenantScopePlugin) Name() string {
+	return "tenant_scope"
+}
+
+func (p tenantScopePlugin) Initialize(db *gorm.DB) error {
+	return db.Callback().Query().Before("gorm:query").Register("tenant_scope:query", func(db *gorm.DB) {
+		db.ScopeNamed("tenant", NameIn(p.names))
+	})
+}
+
+func TestNamedScopesWithPlugin(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+
+	if err := db.Use(tenantScopePlugin{names: []string{"PluginScopeUser1"}}); err != nil {
+		t.Fatalf("failed to use plugin, got error %v", err)
+	}
+
+	users := []*User{
+		GetUser("PluginScopeUser1", Config{}),
+		GetUser("PluginScopeUser2", Config{}),
+		GetUser("PluginScopeUser3", Config{}),
+	}
+	db.Create(&users)
+
+	var users1, users2, users3 []User
+	db.Where("name LIKE ?", "PluginScopeUser%").Find(&users1)
+	if len(users1) != 1 || users1[0].Name != "PluginScopeUser1" {
+		t.Errorf("tenant scope of plugin should be applied, but got %v", len(users1))
+	}
+
+	db.WithoutScope("tenant").Where("name LIKE ?", "PluginScopeUser%").Find(&users2)
+	if len(users2) != 3 {
+		t.Errorf("tenant scope of plugin should be removed, but got %v", len(users2))
+	}
+
+	var count int64
+	db.Where("name LIKE ?", "PluginScopeUser%").Find(&users3)
+	db.Model(&User{}).Where("name LIKE ?", "PluginScopeUser%").Count(&count)
+	if len(users3) != 1 || count != 1 {
+		t.Errorf("tenant scope of plugin should be applied to other queries, but got %v, count %v", len(users3), count)
+	}
+}
@@ -3,6 +3,7 @@ package tests_test
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"fmt"
 	"reflect"
 	"strings"
@@ -227,3 +228,140 @@ func TestSerializerAssignFirstOrCreate(t *testing.T) {
 	AssertEqual(t, result.Roles, data.Roles)
 	AssertEqual(t, result.JobInfo.Location, data.JobInfo.Location)
 }
+
+type UpperCaseSerializer struct{}
+
+func (UpperCaseSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	switch value := dbValue.(type) {
+	case []byte:
+		return field.Set(ctx, dst, strings.ToLower(string(value)))
+	case string:
+		return field.Set(ctx, dst, strings.ToLower(value))
+	}
+	return fmt.Errorf("unsupported data %#v", dbValue)
+}
+
+func (UpperCaseSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	return strings.ToUpper(fmt.Sprint(fieldValue)), nil
+}
+
+type SerializerLookupUser struct {
+	Code  string   `gorm:"primaryKey;size:100;serializer:upper"`
+	Email string   `gorm:"size:100;uniqueIndex;serializer:upper"`
+	Tags  []string `gorm:"serializer:json"`
+}
+
+func TestSerializerLookupConditions(t *testing.T) {
+	schema.RegisterSerializer("upper", UpperCaseSerializer{})
+	DB.Migrator().DropTable(&SerializerLookupUser{})
+	if err := DB.AutoMigrate(&SerializerLookupUser{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	users := []SerializerLookupUser{{Code: "code1", Email: "jinzhu@example.org", Tags: []string{"a", "b"}}, {Code: "code2", Email: "gorm@example.org"}}
+	if err := DB.Create(&users).Error; err != nil {
+		t.Fatalf("failed to create users, got error %v", err)
+	}
+
+	var count int64
+	DB.Model(&SerializerLookupUser{}).Where("email = ?", "JINZHU@EXAMPLE.ORG").Count(&count)
+	if count != 1 {
+		t.Fatalf("email should be serialized when creating, got %v", count)
+	}
+
+	var result1, result2, result3 SerializerLookupUser
+	if err := DB.First(&result1, map[string]interface{}{"email": "jinzhu@example.org"}).Error; err != nil || result1.Code != "code1" {
+		t.Errorf("failed to find user with map conditions, got error %v, %+v", err, result1)
+	}
+
+	if err := DB.Where(&SerializerLookupUser{Email: "jinzhu@example.org"}).First(&result2).Error; err != nil || result2.Code != "code1" {
+		t.Errorf("failed to find user with struct conditions, got error %v, %+v", err, result2)
+	}
+
+	if err := DB.Where("Email", "gorm@example.org").First(&result3).Error; err != nil || result3.Code != "code2" {
+		t.Errorf("failed to find user with column condition, got error %v, %+v", err, result3)
+	}
+
+	var results []SerializerLookupUser
+	if err := DB.Find(&results, []string{"code1", "code2"}).Error; err != nil || len(results) != 2 {
+		t.Errorf("failed to find users with primary keys, got error %v, %+v", err, results)
+	}
+
+	if err := DB.Where(map[string]interface{}{"email": []string{"jinzhu@example.org", "gorm@example.org"}}).Find(&results).Error; err != nil || len(results) != 2 {
+		t.Errorf("failed to find users with IN conditions, got error %v, %+v", err, results)
+	}
+
+	// serialized values are not serialized again
+	var result4, result5 SerializerLookupUser
+	if err := DB.Where(map[string]interface{}{"tags": `["a","b"]`}).First(&result4).Error; err != nil || result4.Code != "code1" {
+		t.Errorf("failed to find user with serialized value, got error %v, %+v", err, result4)
+	}
+
+	if err := DB.Where("email", sql.NullString{String: "GORM@EXAMPLE.ORG", Valid: true}).First(&result5).Error; err != nil || result5.Code != "code2" {
+		t.Errorf("failed to find user with driver.Valuer, got error %v, %+v", err, result5)
+	}
+
+	// raw string conditions are not serialized
+	DB.Model(&SerializerLookupUser{}).Where("email = ?", "jinzhu@example.org").Count(&count)
+	if count != 0 {
+		t.Errorf("raw conditions should not be serialized, got %v", count)
+	}
+}
+
+type DurationStruct struct {
+	ID       uint
+	Timeout  time.Duration  `gorm:"durationUnit:s"`
+	Delay    *time.Duration `gorm:"durationUnit:ms"`
+	Period   time.Duration  `gorm:"serializer:duration"`
+	Cooldown *time.Duration `gorm:"serializer:duration"`
+}
+
+func TestDuration(t *testing.T) {
+	DB.Migrator().DropTable(&DurationStruct{})
+	if err := DB.AutoMigrate(&DurationStruct{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	delay := 1500 * time.Millisecond
+	data := DurationStruct{Timeout: 90 * time.Second, Delay: &delay, Period: time.Hour + 30*time.Minute}
+	if err := DB.Create(&data).Error; err != nil {
+		t.Fatalf("failed to create data, got error %v", err)
+	}
+
+	var raw struct {
+		Timeout  int64
+		Delay    int64
+		Period   string
+		Cooldown *string
+	}
+	if err := DB.Table("duration_structs").Where("id = ?", data.ID).Scan(&raw).Error; err != nil {
+		t.Fatalf("failed to query raw values, got error %v", err)
+	}
+
+	if raw.Timeout != 90 || raw.Delay != 1500 || raw.Period != "1h30m0s" || raw.Cooldown != nil {
+		t.Errorf("durations should be saved in their units, got %+v", raw)
+	}
+
+	var result DurationStruct
+	if err := DB.First(&result, data.ID).Error; err != nil {
+		t.Fatalf("failed to query data, got error %v", err)
+	}
+	AssertEqual(t, result, data)
+
+	var count int64
+	if DB.Model(&DurationStruct{}).Where(&DurationStruct{Timeout: 90 * time.Second}).Count(&count); count != 1 {
+		t.Errorf("should find data with duration conditions, got %v", count)
+	}
+
+	if err := DB.Exec("UPDATE duration_structs SET period = ?, cooldown = ? WHERE id = ?", "90m", "01:30:00.5", data.ID).Error; err != nil {
+		t.Fatalf("failed to update durations, got error %v", err)
+	}
+
+	if err := DB.First(&result, data.ID).Error; err != nil {
+		t.Fatalf("failed to query data, got error %v", err)
+	}
+
+	if result.Period != 90*time.Minute || result.Cooldown == nil || *result.Cooldown != 90*time.Minute+500*time.Millisecond {
+		t.Errorf("failed to parse duration strings, got %v, %v", result.Period, result.Cooldown)
+	}
+}
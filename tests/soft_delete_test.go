@@ -6,6 +6,7 @@ import (
 	"errors"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/jinzhu/now"
 	"gorm.io/gorm"
@@ -167,3 +168,161 @@ func TestSoftDeleteZeroValue(t *testing.T) {
 		t.Errorf("Can't find permanently deleted record")
 	}
 }
+
+func TestSoftDeleteFlag(t *testing.T) {
+	type SoftDeleteFlagBook struct {
+		ID        uint
+		Name      string
+		IsDeleted gorm.DeletedFlag `gorm:"softDelete:flag,deletedAtField:DeletedAt"`
+		DeletedAt *time.Time
+	}
+	DB.Migrator().DropTable(&SoftDeleteFlagBook{})
+	if err := DB.AutoMigrate(&SoftDeleteFlagBook{}); err != nil {
+		t.Fatalf("failed to auto migrate soft delete flag table, got %v", err)
+	}
+
+	book := SoftDeleteFlagBook{Name: "flag-book"}
+	DB.Create(&book)
+
+	dryDB := DB.Session(&gorm.Session{DryRun: true})
+	result := dryDB.Where("name = ?", book.Name).Find(&SoftDeleteFlagBook{})
+	if !regexp.MustCompile("WHERE name = .+ AND .*soft_delete_flag_books.*\\..*is_deleted.* = .+").MatchString(result.Statement.SQL.String()) {
+		t.Fatalf("Build query with soft delete flag, but got %v", result.Statement.SQL.String())
+	}
+
+	result = dryDB.Delete(&book)
+	if !regexp.MustCompile("UPDATE .*soft_delete_flag_books.* SET .*is_deleted.*=.+,.*deleted_at.*=.+ WHERE .*id.* = .+ AND .*is_deleted.* = .+").MatchString(result.Statement.SQL.String()) {
+		t.Fatalf("Build delete with soft delete flag, but got %v", result.Statement.SQL.String())
+	}
+
+	if err := DB.Delete(&book).Error; err != nil {
+		t.Fatalf("No error should happen when soft delete book, but got %v", err)
+	}
+
+	if book.IsDeleted != 1 || book.DeletedAt == nil {
+		t.Errorf("book should be marked as deleted, got flag %v, deleted at %v", book.IsDeleted, book.DeletedAt)
+	}
+
+	var count int64
+	if DB.Model(&SoftDeleteFlagBook{}).Where("name = ?", book.Name).Count(&count).Error != nil || count != 0 {
+		t.Errorf("Count soft deleted record, expects: %v, got: %v", 0, count)
+	}
+
+	if err := DB.Model(&SoftDeleteFlagBook{}).Where("id = ?", book.ID).Update("name", "flag-book-2").Error; err != nil {
+		t.Fatalf("No error should happen when update soft deleted book, but got %v", err)
+	}
+
+	var result2 SoftDeleteFlagBook
+	if err := DB.Unscoped().First(&result2, "id = ?", book.ID).Error; err != nil {
+		t.Fatalf("Should find soft deleted record with Unscoped, but got err %v", err)
+	}
+
+	if result2.Name != book.Name || result2.IsDeleted != 1 || result2.DeletedAt == nil {
+		t.Errorf("soft deleted record should not be updated, got %+v", result2)
+	}
+
+	if err := DB.Unscoped().Model(&result2).Updates(map[string]interface{}{"is_deleted": 0, "deleted_at": nil}).Error; err != nil {
+		t.Fatalf("No error should happen when restore book, but got %v", err)
+	}
+
+	if err := DB.First(&SoftDeleteFlagBook{}, "id = ?", book.ID).Error; err != nil {
+		t.Errorf("Should find restored record, but got err %v", err)
+	}
+
+	DB.Unscoped().Delete(&book)
+	if err := DB.Unscoped().First(&SoftDeleteFlagBook{}, "id = ?", book.ID).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Can't find permanently deleted record")
+	}
+}
+
+func TestPropagateUnscoped(t *testing.T) {
+	user := *GetUser("propagate_unscoped", Config{Pets: 3, Account: true})
+	if err := DB.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user, got error %v", err)
+	}
+	DB.Delete(&user.Pets[0])
+	DB.Delete(&user.Account)
+
+	db := DB.Session(&gorm.Session{PropagateUnscoped: true})
+
+	t.Run("Preload", func(t *testing.T) {
+		var result User
+		if err := db.Unscoped().Preload("Pets").Preload("Account").First(&result, user.ID).Error; err != nil {
+			t.Fatalf("failed to preload, got error %v", err)
+		}
+		if len(result.Pets) != 3 || result.Account.ID == 0 {
+			t.Errorf("soft deleted associations should be preloaded, got pets %v, account %+v", len(result.Pets), result.Account)
+		}
+
+		var scoped User
+		db.Preload("Pets").Preload("Account").First(&scoped, user.ID)
+		if len(scoped.Pets) != 2 || scoped.Account.ID != 0 {
+			t.Errorf("soft deleted associations should not be preloaded without Unscoped, got pets %v, account %+v", len(scoped.Pets), scoped.Account)
+		}
+
+		var overridden User
+		db.Preload("Pets", func(tx *gorm.DB) *gorm.DB { return tx.Unscoped() }).First(&overridden, user.ID)
+		if len(overridden.Pets) != 3 {
+			t.Errorf("preload with Unscoped should load soft deleted pets, got %v", len(overridden.Pets))
+		}
+	})
+
+	t.Run("Joins", func(t *testing.T) {
+		var result User
+		if err := db.Unscoped().Joins("Account").First(&result, user.ID).Error; err != nil {
+			t.Fatalf("failed to query with joins, got error %v", err)
+		}
+		if result.Account.ID == 0 {
+			t.Errorf("soft deleted account should be joined")
+		}
+
+		var scoped User
+		db.Joins("Account").First(&scoped, user.ID)
+		if scoped.Account.ID != 0 {
+			t.Errorf("soft deleted account should not be joined without Unscoped, got %+v", scoped.Account)
+		}
+	})
+
+	t.Run("NewDB", func(t *testing.T) {
+		var pets []Pet
+		db.Unscoped().Session(&gorm.Session{NewDB: true}).Find(&pets, "user_id = ?", user.ID)
+		if len(pets) != 3 {
+			t.Errorf("Unscoped should be propagated to new db session, got %v", len(pets))
+		}
+
+		DB.Unscoped().Session(&gorm.Session{NewDB: true}).Find(&pets, "user_id = ?", user.ID)
+		if len(pets) != 2 {
+			t.Errorf("Unscoped should not be propagated to new db session by default, got %v", len(pets))
+		}
+	})
+
+	t.Run("Association", func(t *testing.T) {
+		deletedPet, pet := *user.Pets[0], *user.Pets[1]
+
+		var pets []Pet
+		if err := db.Unscoped().Model(&user).Association("Pets").Find(&pets); err != nil || len(pets) != 3 {
+			t.Errorf("soft deleted pets should be found with association of unscoped db, got %v, %v", len(pets), err)
+		}
+
+		if err := db.Unscoped().Model(&user).Association("Pets").Delete(&pet); err != nil {
+			t.Fatalf("failed to delete association, got error %v", err)
+		}
+		if err := DB.Unscoped().First(&Pet{}, pet.ID).Error; err != nil {
+			t.Errorf("pet should be kept when deleting association with propagation, got %v", err)
+		}
+
+		if err := db.Unscoped().Session(&gorm.Session{NewDB: true}).Delete(&Pet{}, deletedPet.ID).Error; err != nil {
+			t.Fatalf("failed to delete pet, got error %v", err)
+		}
+		if err := DB.Unscoped().First(&Pet{}, deletedPet.ID).Error; err != nil {
+			t.Errorf("propagated Unscoped should not delete permanently, got %v", err)
+		}
+
+		if err := db.Unscoped().Model(&user).Association("Pets").Unscoped().Delete(&deletedPet); err != nil {
+			t.Fatalf("failed to delete association, got error %v", err)
+		}
+		if err := DB.Unscoped().First(&Pet{}, deletedPet.ID).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("pet should be deleted permanently with association Unscoped, got %v", err)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package tests_test
 
 import (
 	"regexp"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -110,6 +111,43 @@ func TestRaw(t *testing.T) {
 	}
 }
 
+func TestExecResult(t *testing.T) {
+	result, err := DB.ExecResult("INSERT INTO users (name, age, created_at, updated_at) VALUES (?, ?, ?, ?)", "exec_result_user", 18, time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("failed to exec, got %v", err)
+	}
+
+	if result.RowsAffected != 1 {
+		t.Errorf("rows affected should be 1, got %v", result.RowsAffected)
+	}
+
+	if DB.Dialector.Name() == "postgres" {
+		if result.LastInsertIDError == nil {
+			t.Errorf("should returns error of LastInsertID for postgres")
+		}
+	} else {
+		var user User
+		if err := DB.First(&user, "name = ?", "exec_result_user").Error; err != nil {
+			t.Fatalf("failed to query inserted user, got %v", err)
+		}
+
+		if result.LastInsertIDError != nil || result.LastInsertID != int64(user.ID) {
+			t.Errorf("last insert id should be %v, got %v, %v", user.ID, result.LastInsertID, result.LastInsertIDError)
+		}
+	}
+
+	tx := DB.Exec("UPDATE users SET age = ? WHERE name = ?", 20, "exec_result_user")
+	if v, ok := tx.InstanceGet(gorm.ExecResultKey); !ok {
+		t.Errorf("result of Exec should be set")
+	} else if execResult, ok := v.(gorm.ExecResult); !ok || execResult.RowsAffected != tx.RowsAffected || execResult.RowsAffected != 1 {
+		t.Errorf("invalid result of Exec, got %#v", v)
+	}
+
+	if _, err := DB.ExecResult("UPDATE non_existing_table SET name = ?", "jinzhu"); err == nil {
+		t.Errorf("should returns error of exec")
+	}
+}
+
 func TestRowsWithGroup(t *testing.T) {
 	users := []User{
 		{Name: "having_user_1", Age: 1},
@@ -168,6 +206,53 @@ func TestDryRun(t *testing.T) {
 	}
 }
 
+type numberedDialector struct {
+	gorm.Dialector
+}
+
+func (numberedDialector) BindVarTo(writer clause.Writer, stmt *gorm.Statement, v interface{}) {
+	writer.WriteByte('$')
+	writer.WriteString(strconv.Itoa(len(stmt.Vars)))
+}
+
+func (numberedDialector) BindVarIndexTo(writer clause.Writer, stmt *gorm.Statement, idx int) {
+	writer.WriteByte('$')
+	writer.WriteString(strconv.Itoa(idx + 1))
+}
+
+func TestDedupBindVars(t *testing.T) {
+	db, err := gorm.Open(numberedDialector{Dialector: DB.Dialector}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+
+	query := func(tx *gorm.DB) *gorm.Statement {
+		return tx.Where("company_id = ? AND name = ?", 1, "dedup").
+			Where("manager_id = ? OR company_id = ?", 2, 1).
+			Where("age IN ?", []int{2, 3, 2}).
+			Where("name <> ?", "dedup").Find(&[]User{}).Statement
+	}
+
+	stmt := query(db)
+	if len(stmt.Vars) != 8 {
+		t.Errorf("vars should not be deduplicated by default, got %v", stmt.Vars)
+	}
+
+	stmt = query(db.Session(&gorm.Session{DedupBindVars: true}))
+	if len(stmt.Vars) != 4 {
+		t.Errorf("vars should be deduplicated, got %v", stmt.Vars)
+	}
+
+	if !regexp.MustCompile(`WHERE \(company_id = \$1 AND name = \$2\) AND \(manager_id = \$3 OR company_id = \$1\) AND age IN \(\$3,\$4,\$3\) AND name <> \$2 AND .*deleted_at.* IS NULL`).MatchString(stmt.SQL.String()) {
+		t.Errorf("placeholders should be reused, got %v", stmt.SQL.String())
+	}
+
+	stmt = DB.Session(&gorm.Session{DryRun: true, DedupBindVars: true}).Where("name = ? OR name = ?", "dedup", "dedup").Find(&[]User{}).Statement
+	if len(stmt.Vars) != 2 {
+		t.Errorf("vars should not be deduplicated without BindVarIndexer, got %v", stmt.Vars)
+	}
+}
+
 type ageInt int8
 
 func (ageInt) String() string {
@@ -388,11 +473,11 @@ func TestToSQL(t *testing.T) {
 	sql = DB.ToSQL(func(tx *gorm.DB) *gorm.DB {
 		return tx.Model(&User{}).Where(&User{Name: "foo", Age: 20}).Limit(10).Offset(5).Order("name ASC").First(&User{})
 	})
-	assertEqualSQL(t, `SELECT * FROM "users" WHERE "users"."name" = 'foo' AND "users"."age" = 20 AND "users"."deleted_at" IS NULL ORDER BY name ASC,"users"."id" LIMIT 1 OFFSET 5`, sql)
+	assertEqualSQL(t, `SELECT * FROM "users" WHERE "users"."name" = 'foo' AND "users"."age" = 20 AND "users"."deleted_at" IS NULL ORDER BY name ASC LIMIT 1 OFFSET 5`, sql)
 
-	// last and unscoped
+	// last and unscoped, order by primary key with ForceStableOrder
 	sql = DB.ToSQL(func(tx *gorm.DB) *gorm.DB {
-		return tx.Model(&User{}).Unscoped().Where(&User{Name: "bar", Age: 12}).Limit(10).Offset(5).Order("name ASC").Last(&User{})
+		return tx.Session(&gorm.Session{ForceStableOrder: true}).Model(&User{}).Unscoped().Where(&User{Name: "bar", Age: 12}).Limit(10).Offset(5).Order("name ASC").Last(&User{})
 	})
 	assertEqualSQL(t, `SELECT * FROM "users" WHERE "users"."name" = 'bar' AND "users"."age" = 12 ORDER BY name ASC,"users"."id" DESC LIMIT 1 OFFSET 5`, sql)
 
@@ -497,3 +582,105 @@ func replaceQuoteInSQL(sql string) string {
 
 	return sql
 }
+
+func TestHints(t *testing.T) {
+	user := *GetUser("hints", Config{})
+	dryRunDB := DB.Session(&gorm.Session{DryRun: true})
+
+	stmt := dryRunDB.Hint(clause.CommentBefore("SELECT", "app='api',route='/users'")).Find(&[]User{}).Statement
+	if sql := stmt.SQL.String(); !strings.HasPrefix(sql, "/* app='api',route='/users' */ SELECT * FROM ") {
+		t.Errorf("comment should be placed before SELECT, got %v", sql)
+	}
+
+	stmt = dryRunDB.Hint(clause.OptimizerHints("SELECT", "MAX_EXECUTION_TIME(1000)")).Where("name = ?", user.Name).First(&User{}).Statement
+	if sql := stmt.SQL.String(); !strings.HasPrefix(sql, "SELECT /*+ MAX_EXECUTION_TIME(1000) */ * FROM ") {
+		t.Errorf("optimizer hints should be placed after SELECT, got %v", sql)
+	}
+
+	stmt = dryRunDB.Hint(clause.CommentAfter("INSERT", "app='api'")).Create(&user).Statement
+	if sql := stmt.SQL.String(); !strings.HasPrefix(sql, "INSERT /* app='api' */ INTO ") {
+		t.Errorf("comment should be placed after INSERT, got %v", sql)
+	}
+
+	stmt = dryRunDB.Hint(clause.CommentBefore("UPDATE", "app='api'"), clause.OptimizerHints("UPDATE", "NO_INDEX_MERGE(users)")).
+		Model(&User{}).Where("name = ?", user.Name).Update("age", 18).Statement
+	if sql := stmt.SQL.String(); !strings.HasPrefix(sql, "/* app='api' */ UPDATE /*+ NO_INDEX_MERGE(users) */ ") {
+		t.Errorf("hints should be placed around UPDATE, got %v", sql)
+	}
+
+	stmt = dryRunDB.Hint(clause.CommentAfter("DELETE", "app='api'")).Where("name = ?", user.Name).Delete(&User{}).Statement
+	if sql := stmt.SQL.String(); !strings.HasPrefix(sql, "UPDATE ") || strings.Contains(sql, "/*") {
+		t.Errorf("soft delete doesn't have DELETE clause, comment should be ignored, got %v", sql)
+	}
+
+	stmt = dryRunDB.Hint(clause.CommentAfter("DELETE", "app='api'")).Unscoped().Where("name = ?", user.Name).Delete(&User{}).Statement
+	if sql := stmt.SQL.String(); !strings.HasPrefix(sql, "DELETE /* app='api' */ FROM ") {
+		t.Errorf("comment should be placed after DELETE, got %v", sql)
+	}
+
+	stmt = dryRunDB.Hint(clause.CommentBefore("SELECT", "route='/users' */ DROP TABLE users; /*")).Find(&[]User{}).Statement
+	if sql := stmt.SQL.String(); !strings.HasPrefix(sql, "/* route='/users' * / DROP TABLE users; / * */ SELECT ") {
+		t.Errorf("comment should be escaped, got %v", sql)
+	}
+
+	// hints are kept by the chained statement only
+	tx := DB.Hint(clause.CommentBefore("SELECT", "app='api'"))
+	if err := tx.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user with hints, got error %v", err)
+	}
+
+	var result User
+	if err := tx.Where("name = ?", user.Name).First(&result).Error; err != nil {
+		t.Fatalf("failed to query user with hints, got error %v", err)
+	}
+	CheckUser(t, result, user)
+
+	if stmt := DB.Session(&gorm.Session{DryRun: true}).Find(&[]User{}).Statement; strings.Contains(stmt.SQL.String(), "/*") {
+		t.Errorf("hints should not be kept by db, got %v", stmt.SQL.String())
+	}
+}
+
+func TestIndexHints(t *testing.T) {
+	db, _ := gorm.Open(DummyDialector{}, &gorm.Config{DryRun: true})
+
+	stmt := db.Hint(clause.UseIndex("idx_users_name")).Joins("Company").Find(&[]User{}).Statement
+	if sql := stmt.SQL.String(); !strings.Contains(sql, " FROM `users` USE INDEX (`idx_users_name`) LEFT JOIN `companies` `Company` ON ") {
+		t.Errorf("index hint should be placed after the main table, got %v", sql)
+	}
+
+	hint := clause.IndexHint{Type: clause.ForceIndexHint, Keys: []string{"idx_companies_name"}}
+	stmt = db.Joins("Manager.Company", hint).Find(&[]User{}).Statement
+	if sql := stmt.SQL.String(); !strings.Contains(sql, "LEFT JOIN `users` `Manager` ON ") ||
+		!strings.Contains(sql, "LEFT JOIN `companies` `Manager__Company` FORCE INDEX (`idx_companies_name`) ON ") {
+		t.Errorf("index hint should be placed after the joined table, got %v", sql)
+	}
+
+	stmt = db.Joins("Company", func(tx *gorm.DB) *gorm.DB { return tx.Select("name") }, hint).Find(&[]User{}).Statement
+	if sql := stmt.SQL.String(); !strings.Contains(sql, "LEFT JOIN `companies` `Company` FORCE INDEX (`idx_companies_name`) ON ") {
+		t.Errorf("index hint should be placed after the joined table, got %v", sql)
+	}
+
+	var count int64
+	stmt = db.Hint(clause.IgnoreIndex("idx_users_name")).Model(&User{}).Where("age > ?", 18).Count(&count).Statement
+	if sql := stmt.SQL.String(); !strings.Contains(sql, "SELECT count(*) FROM `users` IGNORE INDEX (`idx_users_name`) WHERE ") {
+		t.Errorf("index hint should be kept when counting, got %v", sql)
+	}
+
+	// index hints survive cloning statements
+	tx := db.Hint(clause.UseIndex("idx_users_age")).Where("age > ?", 18).Session(&gorm.Session{})
+	for i := 0; i < 2; i++ {
+		if sql := tx.Find(&[]User{}).Statement.SQL.String(); !strings.Contains(sql, " FROM `users` USE INDEX (`idx_users_age`) WHERE ") {
+			t.Errorf("index hint should be kept by the cloned statement, got %v", sql)
+		}
+	}
+	if sql := tx.Model(&User{}).Count(&count).Statement.SQL.String(); !strings.Contains(sql, " FROM `users` USE INDEX (`idx_users_age`) WHERE ") {
+		t.Errorf("index hint should be kept by the cloned statement when counting, got %v", sql)
+	}
+
+	// skipped when the dialector doesn't support index hints
+	dummyDB, _ := gorm.Open(DummyDialector{DisableIndexHints: true}, &gorm.Config{DryRun: true})
+	stmt = dummyDB.Hint(clause.UseIndex("idx_users_name")).Joins("Company", hint).Find(&[]User{}).Statement
+	if sql := stmt.SQL.String(); strings.Contains(sql, "INDEX") {
+		t.Errorf("index hints should be skipped, got %v", sql)
+	}
+}
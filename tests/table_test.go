@@ -1,10 +1,16 @@
 package tests_test
 
 import (
+	"context"
+	"database/sql/driver"
+	"reflect"
 	"regexp"
 	"testing"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/migrator"
 	"gorm.io/gorm/schema"
 	"gorm.io/gorm/utils/tests"
 	. "gorm.io/gorm/utils/tests"
@@ -172,3 +178,390 @@ func TestTableWithNamer(t *testing.T) {
 		t.Errorf("Table with namer, got %v", sql)
 	}
 }
+
+type AffixedOwner struct {
+	ID    uint
+	Name  string
+	Items []AffixedItem
+	Tags  []AffixedTag `gorm:"many2many:affixed_owner_tags"`
+}
+
+type AffixedItem struct {
+	ID             uint
+	AffixedOwnerID uint
+	Name           string
+}
+
+type AffixedTag struct {
+	ID   uint
+	Name string
+}
+
+func TestTableSuffix(t *testing.T) {
+	dryDB := DB.Session(&gorm.Session{DryRun: true, TableSuffix: "_t42"})
+
+	r := dryDB.Joins("Company").Where("name = ?", "jinzhu").Find(&User{}).Statement
+	if !regexp.MustCompile("FROM .users_t42. LEFT JOIN .companies_t42. .Company. ON .users_t42.\\..company_id. = .Company.\\..id.").MatchString(r.Statement.SQL.String()) {
+		t.Errorf("joins with table suffix, got %v", r.Statement.SQL.String())
+	}
+
+	if !regexp.MustCompile("WHERE name = \\?").MatchString(r.Statement.SQL.String()) {
+		t.Errorf("raw conditions should not be changed, got %v", r.Statement.SQL.String())
+	}
+
+	r = dryDB.Table("users").Find(&User{}).Statement
+	if !regexp.MustCompile("SELECT \\* FROM .users. WHERE .users.\\..deleted_at.").MatchString(r.Statement.SQL.String()) {
+		t.Errorf("table specified with Table should not be changed, got %v", r.Statement.SQL.String())
+	}
+
+	// 只给 schema 的表名添加前后缀，别名不变
+	r = dryDB.Clauses(clause.Where{Exprs: []clause.Expression{clause.Eq{Column: clause.Column{Table: "u", Name: "name"}, Value: "jinzhu"}}}).Find(&User{}).Statement
+	if !regexp.MustCompile("FROM .users_t42. WHERE .u.\\..name. = ").MatchString(r.Statement.SQL.String()) {
+		t.Errorf("table alias should not be changed, got %v", r.Statement.SQL.String())
+	}
+
+	r = DB.Session(&gorm.Session{DryRun: true, TablePrefix: "user"}).Joins("Company").Find(&User{}).Statement
+	if !regexp.MustCompile("FROM .userusers. LEFT JOIN .usercompanies. .Company. ON").MatchString(r.Statement.SQL.String()) {
+		t.Errorf("tables starting with the prefix should be affixed, got %v", r.Statement.SQL.String())
+	}
+
+	tables := []interface{}{"affixed_owners_t42", "affixed_items_t42", "affixed_tags_t42", "affixed_owner_tags_t42"}
+	DB.Migrator().DropTable(tables...)
+
+	var sqls []string
+	tx := DB.Session(&gorm.Session{TableSuffix: "_t42", Logger: Tracer{
+		Logger: DB.Config.Logger,
+		Test: func(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+			sql, _ := fc()
+			sqls = append(sqls, sql)
+		},
+	}})
+
+	if err := tx.AutoMigrate(&AffixedOwner{}, &AffixedItem{}, &AffixedTag{}); err != nil {
+		t.Fatalf("failed to migrate with table suffix, got %v", err)
+	}
+
+	for _, table := range tables {
+		if !DB.Migrator().HasTable(table) {
+			t.Errorf("table %v should be created", table)
+		}
+	}
+
+	for _, table := range []string{"affixed_owners", "affixed_items", "affixed_tags", "affixed_owner_tags"} {
+		if DB.Migrator().HasTable(table) {
+			t.Errorf("table %v should not be created", table)
+		}
+	}
+
+	owner := AffixedOwner{
+		Name:  "owner",
+		Items: []AffixedItem{{Name: "item1"}, {Name: "item2"}},
+		Tags:  []AffixedTag{{Name: "tag1"}},
+	}
+	if err := tx.Create(&owner).Error; err != nil {
+		t.Fatalf("failed to create with table suffix, got %v", err)
+	}
+
+	var result AffixedOwner
+	sqls = nil
+	if err := tx.Preload("Items").Preload("Tags").First(&result, owner.ID).Error; err != nil {
+		t.Fatalf("failed to query with table suffix, got %v", err)
+	}
+
+	if len(result.Items) != 2 || len(result.Tags) != 1 {
+		t.Errorf("failed to preload with table suffix, got %+v", result)
+	}
+
+	for _, sql := range sqls {
+		if !regexp.MustCompile("_t42.").MatchString(sql) || regexp.MustCompile("affixed_[a-z_]+s.( |$)").MatchString(sql) {
+			t.Errorf("all tables should have suffix, got %v", sql)
+		}
+	}
+
+	var count int64
+	DB.Table("affixed_items_t42").Where("affixed_owner_id = ?", owner.ID).Count(&count)
+	if count != 2 {
+		t.Errorf("should create 2 items in suffixed table, got %v", count)
+	}
+}
+
+type SchemaVisit struct {
+	ID   uint
+	Page string
+}
+
+func (SchemaVisit) TableSchema() string {
+	return "analytics"
+}
+
+type SchemaEvent struct {
+	ID      uint
+	Name    string
+	VisitID uint
+	Visit   SchemaVisit
+}
+
+func (SchemaEvent) TableName() string {
+	return "analytics.events"
+}
+
+func TestTableWithSchema(t *testing.T) {
+	dryDB := DB.Session(&gorm.Session{DryRun: true})
+
+	r := dryDB.Where("name = ?", "click").Find(&SchemaEvent{}).Statement
+	if !regexp.MustCompile("SELECT \\* FROM .analytics.\\..events. WHERE name = ").MatchString(r.Statement.SQL.String()) {
+		t.Errorf("table with schema, got %v", r.Statement.SQL.String())
+	}
+	AssertEqual(t, r.Statement.TableSchema, "analytics")
+	AssertEqual(t, r.Statement.Table, "events")
+
+	r = dryDB.Find(&SchemaVisit{}).Statement
+	if !regexp.MustCompile("SELECT \\* FROM .analytics.\\..schema_visits.").MatchString(r.Statement.SQL.String()) {
+		t.Errorf("table with TableSchema, got %v", r.Statement.SQL.String())
+	}
+
+	r = dryDB.Joins("Visit").Find(&SchemaEvent{}).Statement
+	if !regexp.MustCompile("FROM .analytics.\\..events. LEFT JOIN .analytics.\\..schema_visits. .Visit. ON .events.\\..visit_id. = .Visit.\\..id.").MatchString(r.Statement.SQL.String()) {
+		t.Errorf("joins table with schema, got %v", r.Statement.SQL.String())
+	}
+
+	r = dryDB.Table("analytics.events").Select("name").Find(&[]SchemaEvent{}).Statement
+	if !regexp.MustCompile("SELECT .name. FROM .analytics.\\..events.").MatchString(r.Statement.SQL.String()) {
+		t.Errorf("table with schema, got %v", r.Statement.SQL.String())
+	}
+	AssertEqual(t, r.Statement.TableSchema, "analytics")
+
+	r = dryDB.Session(&gorm.Session{TablePrefix: "t_"}).Joins("Visit").Find(&SchemaEvent{}).Statement
+	if !regexp.MustCompile("FROM .analytics.\\..t_events. LEFT JOIN .analytics.\\..t_schema_visits. .Visit. ON .t_events.\\..visit_id. = .Visit.\\..id.").MatchString(r.Statement.SQL.String()) {
+		t.Errorf("table prefix should only be added to the table of schema, got %v", r.Statement.SQL.String())
+	}
+
+	m := migrator.Migrator{Config: migrator.Config{DB: DB}}
+	stmt := &gorm.Statement{DB: DB}
+	if err := stmt.Parse(&SchemaEvent{}); err != nil {
+		t.Fatalf("failed to parse table with schema, got %v", err)
+	}
+
+	currentSchema, table := m.CurrentSchema(stmt, stmt.Table)
+	AssertEqual(t, currentSchema, "analytics")
+	AssertEqual(t, table, "events")
+
+	tableStmt := &gorm.Statement{DB: DB, Table: "events", TableSchema: "analytics"}
+	if quoted := tableStmt.Quote(m.CurrentTable(tableStmt)); !regexp.MustCompile("^.analytics.\\..events.$").MatchString(quoted) {
+		t.Errorf("current table should be quoted with schema, got %v", quoted)
+	}
+
+	currentSchema, table = m.CurrentSchema(stmt, "reports.daily")
+	AssertEqual(t, currentSchema, "reports")
+	AssertEqual(t, table, "daily")
+
+	currentSchema, table = m.CurrentSchema(&gorm.Statement{DB: DB, Table: "users"}, "users")
+	AssertEqual(t, currentSchema, DB.Migrator().CurrentDatabase())
+	AssertEqual(t, table, "users")
+
+	// HasTable 按 schema 和表名分别查询
+	pool := NewFakeConnPool(
+		FakeResult{Columns: []string{"count"}, Rows: [][]driver.Value{{int64(1)}}},
+		FakeResult{Columns: []string{"count"}, Rows: [][]driver.Value{{int64(0)}}},
+	)
+	recordDB, err := gorm.Open(&RecordingDialector{ConnPool: pool}, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open, got %v", err)
+	}
+
+	m = migrator.Migrator{Config: migrator.Config{DB: recordDB}}
+	if !m.HasTable(&SchemaEvent{}) {
+		t.Errorf("table with schema should exist")
+	}
+
+	if m.HasTable("reports.daily") {
+		t.Errorf("table with schema should not exist")
+	}
+
+	queries := pool.Queries()
+	if len(queries) != 2 {
+		t.Fatalf("should query table with schema twice, got %v", queries)
+	}
+	AssertEqual(t, queries[0].Vars, []interface{}{"analytics", "events", "BASE TABLE"})
+	AssertEqual(t, queries[1].Vars, []interface{}{"reports", "daily", "BASE TABLE"})
+}
+
+type ReadWriteUser struct {
+	ID   uint
+	Name string
+	Age  int
+}
+
+func (ReadWriteUser) TableName(mode string) string {
+	if mode == schema.TableModeRead {
+		return "read_write_users_read"
+	}
+	return "read_write_users"
+}
+
+func TestTableWithMode(t *testing.T) {
+	DB.Migrator().DropTable(&ReadWriteUser{})
+	DB.Exec("DROP VIEW IF EXISTS read_write_users_read")
+	if err := DB.AutoMigrate(&ReadWriteUser{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	if !DB.Migrator().HasTable("read_write_users") || DB.Migrator().HasTable("read_write_users_read") {
+		t.Fatalf("the write table should be migrated")
+	}
+
+	// the denormalized copy for reading, names of the copy are upper case
+	if err := DB.Exec("CREATE VIEW read_write_users_read AS SELECT id, UPPER(name) AS name, age FROM read_write_users").Error; err != nil {
+		t.Fatalf("failed to create view, got %v", err)
+	}
+
+	dryDB := DB.Session(&gorm.Session{DryRun: true})
+	for sql, expected := range map[string]string{
+		dryDB.Find(&[]ReadWriteUser{}).Statement.SQL.String():                                      "SELECT * FROM `read_write_users_read`",
+		dryDB.Model(&ReadWriteUser{}).Where("age > ?", 1).Count(new(int64)).Statement.SQL.String(): "SELECT count(*) FROM `read_write_users_read` WHERE age > ?",
+		dryDB.Model(&ReadWriteUser{}).Pluck("name", new([]string)).Statement.SQL.String():          "SELECT `name` FROM `read_write_users_read`",
+		dryDB.Create(&ReadWriteUser{Name: "dry_run"}).Statement.SQL.String():                       "INSERT INTO `read_write_users` (`name`,`age`) VALUES (?,?)",
+		dryDB.Model(&ReadWriteUser{ID: 1}).Update("age", 2).Statement.SQL.String():                 "UPDATE `read_write_users` SET `age`=? WHERE `id` = ?",
+		dryDB.Delete(&ReadWriteUser{ID: 1}).Statement.SQL.String():                                 "DELETE FROM `read_write_users` WHERE `read_write_users`.`id` = ?",
+		dryDB.Table("read_write_users").Find(&[]ReadWriteUser{}).Statement.SQL.String():            "SELECT * FROM `read_write_users`",
+	} {
+		if !regexp.MustCompile("^" + regexp.QuoteMeta(expected)).MatchString(sql) {
+			t.Errorf("expects %v, got %v", expected, sql)
+		}
+	}
+
+	users := []ReadWriteUser{{Name: "read_write_1", Age: 10}, {Name: "read_write_2", Age: 20}}
+	if err := DB.Create(&users).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+
+	if err := DB.Model(&users[0]).Update("age", 11).Error; err != nil {
+		t.Fatalf("failed to update, got %v", err)
+	}
+
+	var result ReadWriteUser
+	if err := DB.First(&result, users[0].ID).Error; err != nil || result.Name != "READ_WRITE_1" || result.Age != 11 {
+		t.Errorf("should read from the read table, got %+v, %v", result, err)
+	}
+
+	// the chain queried the read table, update it still goes to the write table
+	query := DB.Model(&ReadWriteUser{}).Where("id = ?", users[1].ID)
+	var names []string
+	if err := query.Pluck("name", &names).Error; err != nil || len(names) != 1 || names[0] != "READ_WRITE_2" {
+		t.Errorf("should pluck from the read table, got %v, %v", names, err)
+	}
+
+	if err := query.Update("name", "read_write_updated").Error; err != nil {
+		t.Errorf("should update the write table, got %v", err)
+	}
+
+	// the table specified by Table is used even if the chain resolved the same table from the model
+	names = nil
+	if err := query.Table("read_write_users").Pluck("name", &names).Error; err != nil || len(names) != 1 || names[0] != "read_write_updated" {
+		t.Errorf("should pluck from the specified table, got %v, %v", names, err)
+	}
+
+	var count int64
+	if err := DB.Model(&ReadWriteUser{}).Where("name = ?", "READ_WRITE_UPDATED").Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("should count in the read table, got %v, %v", count, err)
+	}
+
+	if err := DB.Delete(&users[0]).Error; err != nil {
+		t.Errorf("failed to delete, got %v", err)
+	}
+	DB.Exec("DROP VIEW IF EXISTS read_write_users_read")
+}
+
+type PartitionedEvent struct {
+	ID         uint
+	Name       string
+	HappenedAt time.Time
+}
+
+func (PartitionedEvent) PartitionTable(base string, value reflect.Value) string {
+	if happenedAt := value.FieldByName("HappenedAt").Interface().(time.Time); !happenedAt.IsZero() {
+		return base + "_" + happenedAt.Format("2006_01")
+	}
+	return base
+}
+
+func TestPartitionedTable(t *testing.T) {
+	tables := []string{"partitioned_events", "partitioned_events_2024_01", "partitioned_events_2024_02"}
+	for _, table := range tables {
+		DB.Migrator().DropTable(table)
+		if err := DB.Table(table).AutoMigrate(&PartitionedEvent{}); err != nil {
+			t.Fatalf("failed to migrate %v, got %v", table, err)
+		}
+	}
+
+	january := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	february := time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)
+
+	// capture INSERTs in dry run mode
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+
+	var inserts []string
+	db.Callback().Create().After("gorm:create").Register("test:capture_partitions", func(tx *gorm.DB) {
+		inserts = append(inserts, tx.Statement.SQL.String())
+	})
+
+	dryEvents := []PartitionedEvent{{Name: "a", HappenedAt: january}, {Name: "b", HappenedAt: february}, {Name: "c", HappenedAt: january}}
+	if err := db.Session(&gorm.Session{DryRun: true}).Create(&dryEvents).Error; err != nil {
+		t.Fatalf("failed to create in dry run mode, got %v", err)
+	}
+
+	if len(inserts) != 2 ||
+		!regexp.MustCompile("^INSERT INTO `partitioned_events_2024_01` .* VALUES \\([^)]+\\),\\([^)]+\\)( |$)").MatchString(inserts[0]) ||
+		!regexp.MustCompile("^INSERT INTO `partitioned_events_2024_02` .* VALUES \\([^)]+\\)( |$)").MatchString(inserts[1]) {
+		t.Errorf("should insert into two partitions, got %v", inserts)
+	}
+
+	events := []PartitionedEvent{{Name: "a", HappenedAt: january}, {Name: "b", HappenedAt: february}, {Name: "c", HappenedAt: january}}
+	result := DB.Create(&events)
+	if result.Error != nil || result.RowsAffected != 3 {
+		t.Fatalf("failed to create, got %v, %v", result.RowsAffected, result.Error)
+	}
+
+	if events[0].ID == 0 || events[1].ID == 0 || events[2].ID == 0 {
+		t.Errorf("primary keys should be assigned to the records, got %+v", events)
+	}
+
+	for table, expected := range map[string]int64{tables[0]: 0, tables[1]: 2, tables[2]: 1} {
+		var count int64
+		if DB.Table(table).Count(&count); count != expected {
+			t.Errorf("%v should have %v records, got %v", table, expected, count)
+		}
+	}
+
+	event := PartitionedEvent{Name: "single", HappenedAt: february}
+	if err := DB.Create(&event).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+
+	if err := DB.Model(&event).Update("name", "single_updated").Error; err != nil {
+		t.Errorf("failed to update, got %v", err)
+	}
+
+	var name string
+	if DB.Table(tables[2]).Where("id = ?", event.ID).Select("name").Scan(&name); name != "single_updated" {
+		t.Errorf("should update the partition of the record, got %v", name)
+	}
+
+	if result := DB.Delete(&events); result.Error != nil || result.RowsAffected != 3 {
+		t.Errorf("failed to delete, got %v, %v", result.RowsAffected, result.Error)
+	}
+
+	if err := DB.Delete(&event).Error; err != nil {
+		t.Errorf("failed to delete, got %v", err)
+	}
+
+	for _, table := range tables {
+		var count int64
+		if DB.Table(table).Count(&count); count != 0 {
+			t.Errorf("%v should be empty, got %v", table, count)
+		}
+	}
+}
@@ -2,9 +2,15 @@ package tests_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"testing"
 	"time"
 
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
 	"gorm.io/gorm/logger"
+	. "gorm.io/gorm/utils/tests"
 )
 
 type Tracer struct {
@@ -32,3 +38,113 @@ func (S Tracer) Trace(ctx context.Context, begin time.Time, fc func() (sql strin
 	S.Logger.Trace(ctx, begin, fc, err)
 	S.Test(ctx, begin, fc, err)
 }
+
+type spanKey struct{}
+
+type fakeSpan struct {
+	ID       int
+	Op       string
+	ParentID int
+	Table    string
+	Err      error
+	Finished bool
+}
+
+type fakeSpanTracer struct {
+	spans []*fakeSpan
+}
+
+func (tracer *fakeSpanTracer) Start(ctx context.Context, op string, stmt *gorm.Statement) (context.Context, func(err error)) {
+	span := &fakeSpan{ID: len(tracer.spans) + 1, Op: op, Table: stmt.Table}
+	if parent, ok := ctx.Value(spanKey{}).(*fakeSpan); ok {
+		span.ParentID = parent.ID
+	}
+	tracer.spans = append(tracer.spans, span)
+
+	return context.WithValue(ctx, spanKey{}, span), func(err error) {
+		span.Err = err
+		span.Finished = true
+	}
+}
+
+func (tracer *fakeSpanTracer) String() string {
+	var str string
+	for _, span := range tracer.spans {
+		str += fmt.Sprintf("%+v\n", *span)
+	}
+	return str
+}
+
+func TestRegisterTracer(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+
+	tracer := &fakeSpanTracer{}
+	if err := callbacks.RegisterTracer(db, &fakeSpanTracer{}); err != nil {
+		t.Fatalf("failed to register tracer, got error %v", err)
+	}
+
+	// registering again replaces the previous tracer
+	if err := callbacks.RegisterTracer(db, tracer); err != nil {
+		t.Fatalf("failed to register tracer again, got error %v", err)
+	}
+
+	user := *GetUser("register_tracer", Config{Pets: 1})
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user, got error %v", err)
+	}
+
+	if len(tracer.spans) != 2 || tracer.spans[0].Op != "create" || tracer.spans[1].Op != "create" || tracer.spans[1].ParentID != tracer.spans[0].ID {
+		t.Fatalf("association span should be nested under create span, got %v", tracer)
+	}
+
+	var result User
+	db.First(&result, user.ID)
+	db.Model(&result).Update("age", 20)
+	db.Table("users").Where("id = ?", user.ID).Select("name").Row()
+	db.Exec("UPDATE users SET age = ? WHERE id = ?", 21, user.ID)
+	db.Delete(&result)
+
+	var ops []string
+	for _, span := range tracer.spans[2:] {
+		ops = append(ops, span.Op)
+		if !span.Finished || span.Err != nil || span.ParentID != 0 {
+			t.Errorf("span should be finished without error, got %+v", *span)
+		}
+	}
+	AssertEqual(t, ops, []string{"query", "update", "row", "raw", "delete"})
+
+	// finish spans with error even if callbacks failed
+	tracer.spans = nil
+	if err := db.Table("non_existing_table_for_tracer").Find(&[]User{}).Error; err == nil {
+		t.Fatalf("should fail when querying non existing table")
+	}
+
+	if err := db.Model(&User{}).Update("name", "no_where").Error; !errors.Is(err, gorm.ErrMissingWhereClause) {
+		t.Fatalf("should return ErrMissingWhereClause, got %v", err)
+	}
+
+	if len(tracer.spans) != 2 || tracer.spans[0].Err == nil || !errors.Is(tracer.spans[1].Err, gorm.ErrMissingWhereClause) {
+		t.Errorf("span should be finished with error, got %v", tracer)
+	}
+
+	// spans nest under the transaction's context
+	tracer.spans = nil
+	parent := &fakeSpan{ID: 100, Op: "transaction"}
+	db.WithContext(context.WithValue(context.Background(), spanKey{}, parent)).Transaction(func(tx *gorm.DB) error {
+		tx.Unscoped().First(&User{}, user.ID)
+		return tx.Model(&User{}).Where("id = ?", user.ID).Update("age", 22).Error
+	})
+
+	if len(tracer.spans) != 2 {
+		t.Fatalf("should have 2 spans in transaction, got %v", tracer)
+	}
+
+	for _, span := range tracer.spans {
+		if span.ParentID != parent.ID || !span.Finished {
+			t.Errorf("span should be nested under transaction, got %+v", *span)
+		}
+	}
+}
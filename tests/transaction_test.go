@@ -2,8 +2,12 @@ package tests_test
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
 	"errors"
+	"reflect"
 	"testing"
+	"time"
 
 	"gorm.io/gorm"
 	. "gorm.io/gorm/utils/tests"
@@ -397,3 +401,172 @@ func TestTransactionWithHooks(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+type txOptionsConnPool struct {
+	gorm.ConnPool
+	opts []*sql.TxOptions
+}
+
+func (c *txOptionsConnPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	c.opts = append(c.opts, opts)
+	return c.ConnPool.(gorm.TxBeginner).BeginTx(ctx, nil)
+}
+
+func TestTransactionWithOptions(t *testing.T) {
+	connPool := &txOptionsConnPool{ConnPool: DB.ConnPool}
+	db := DB.Session(&gorm.Session{Context: context.Background()})
+	db.Statement.ConnPool = connPool
+
+	readOnly := &sql.TxOptions{ReadOnly: true}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if tx.Statement.TxOptions != readOnly {
+			t.Errorf("transaction options should be exposed in statement, got %v", tx.Statement.TxOptions)
+		}
+
+		if err := tx.Transaction(func(tx *gorm.DB) error { return nil }, &sql.TxOptions{}); !errors.Is(err, gorm.ErrNestedTransactionOptions) {
+			t.Errorf("nested transaction should not change options, got %v", err)
+		}
+
+		var users []User
+		if err := tx.Find(&users).Error; err != nil {
+			t.Errorf("query should work in read-only transaction, got %v", err)
+		}
+
+		return tx.Create(GetUser("transaction_with_options", Config{})).Error
+	}, readOnly)
+
+	if !errors.Is(err, gorm.ErrReadOnlyTransaction) {
+		t.Errorf("create should fail in read-only transaction, got %v", err)
+	}
+
+	if len(connPool.opts) != 1 || connPool.opts[0] != readOnly {
+		t.Fatalf("transaction options should reach BeginTx, got %v", connPool.opts)
+	}
+
+	serializable := &sql.TxOptions{Isolation: sql.LevelSerializable}
+	db.Config.DefaultTransactionOptions = serializable
+	if err := db.Create(GetUser("transaction_with_default_options", Config{})).Error; err != nil {
+		t.Fatalf("failed to create user, got error %v", err)
+	}
+
+	if len(connPool.opts) != 2 || connPool.opts[1] != serializable {
+		t.Errorf("default transaction options should reach BeginTx, got %v", connPool.opts)
+	}
+}
+
+var errDeadlock = errors.New("Error 1213: Deadlock found when trying to get lock")
+
+type retryableDialector struct {
+	*RecordingDialector
+}
+
+func (retryableDialector) IsRetryableError(err error) bool {
+	return errors.Is(err, errDeadlock)
+}
+
+type retryHookUser struct {
+	ID   uint
+	Name string
+}
+
+func (u *retryHookUser) BeforeCreate(tx *gorm.DB) error {
+	u.Name += "!"
+	return nil
+}
+
+func TestRetryPolicy(t *testing.T) {
+	var attempts []int
+	pool := NewFakeConnPool(FakeResult{Err: errDeadlock}, FakeResult{Err: errDeadlock}, FakeResult{RowsAffected: 1})
+	db, err := gorm.Open(retryableDialector{&RecordingDialector{ConnPool: pool}}, &gorm.Config{
+		RetryPolicy: &gorm.RetryPolicy{
+			MaxAttempts: 3,
+			Backoff: func(attempt int) time.Duration {
+				attempts = append(attempts, attempt)
+				return time.Millisecond
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to open, got %v", err)
+	}
+
+	if result := db.Exec("UPDATE users SET age = ? WHERE name = ?", 18, "jinzhu"); result.Error != nil || result.RowsAffected != 1 {
+		t.Fatalf("should succeed after retrying, got %v, %v", result.Error, result.RowsAffected)
+	}
+
+	queries := pool.Queries()
+	if len(queries) != 3 {
+		t.Fatalf("should execute 3 times, got %v", queries)
+	}
+	for _, query := range queries {
+		AssertEqual(t, query, FakeQuery{SQL: "UPDATE users SET age = ? WHERE name = ?", Vars: []interface{}{18, "jinzhu"}})
+	}
+	AssertEqual(t, attempts, []int{1, 2})
+
+	// create with RETURNING, statement is rebuilt for each attempt
+	pool.Reset()
+	pool.AddResults(FakeResult{Err: errDeadlock}, FakeResult{Columns: []string{"id"}, Rows: [][]driver.Value{{int64(10)}}})
+	user := User{Name: "retry", Age: 18}
+	if err := db.Select("Name", "Age").Create(&user).Error; err != nil {
+		t.Fatalf("failed to create after retrying, got %v", err)
+	}
+
+	if queries = pool.Queries(); len(queries) != 2 || queries[0].SQL != queries[1].SQL || len(queries[1].Vars) != 4 {
+		t.Errorf("statement should be rebuilt when retrying, got %v", queries)
+	}
+	AssertEqual(t, user.ID, uint(10))
+
+	// changes of hooks to dest are restored before retrying
+	pool.Reset()
+	pool.AddResults(FakeResult{Err: errDeadlock}, FakeResult{Columns: []string{"id"}, Rows: [][]driver.Value{{int64(11)}, {int64(12)}}})
+	hookUsers := []*retryHookUser{{Name: "retry_hook_1"}, {Name: "retry_hook_2"}}
+	if err := db.Create(&hookUsers).Error; err != nil {
+		t.Fatalf("failed to create after retrying, got %v", err)
+	}
+
+	if queries = pool.Queries(); len(queries) != 2 || !reflect.DeepEqual(queries[0].Vars, queries[1].Vars) {
+		t.Errorf("retried statement should be built from the restored dest, got %v", queries)
+	}
+	AssertEqual(t, hookUsers, []*retryHookUser{{ID: 11, Name: "retry_hook_1!"}, {ID: 12, Name: "retry_hook_2!"}})
+
+	// not retryable or exceeds max attempts
+	pool.Reset()
+	pool.AddResults(FakeResult{Err: errors.New("syntax error")}, FakeResult{RowsAffected: 1})
+	if err := db.Exec("UPDATE users SET age = 1").Error; err == nil || len(pool.Queries()) != 1 {
+		t.Errorf("non retryable errors shouldn't be retried, got %v, %v", err, pool.Queries())
+	}
+
+	pool.Reset()
+	pool.AddResults(FakeResult{Err: errDeadlock}, FakeResult{Err: errDeadlock}, FakeResult{Err: errDeadlock}, FakeResult{RowsAffected: 1})
+	if err := db.Exec("UPDATE users SET age = 1").Error; !errors.Is(err, errDeadlock) || len(pool.Queries()) != 3 {
+		t.Errorf("should fail after max attempts, got %v, %v", err, pool.Queries())
+	}
+
+	// transaction reruns the function, statements in transaction are not retried themselves
+	pool.Reset()
+	pool.AddResults(FakeResult{RowsAffected: 1}, FakeResult{Err: errDeadlock}, FakeResult{RowsAffected: 1}, FakeResult{RowsAffected: 1})
+	var calls int
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		calls++
+		if err := tx.Exec("UPDATE users SET age = 1").Error; err != nil {
+			return err
+		}
+		return tx.Exec("UPDATE users SET age = 2").Error
+	}); err != nil {
+		t.Errorf("transaction should succeed after retrying, got %v", err)
+	}
+
+	if calls != 2 || len(pool.Queries()) != 4 {
+		t.Errorf("transaction should be retried, got %v calls, %v", calls, pool.Queries())
+	}
+
+	// RetryableErr overrides the dialector
+	pool.Reset()
+	pool.AddResults(FakeResult{Err: errDeadlock}, FakeResult{RowsAffected: 1})
+	noRetryDB, _ := gorm.Open(retryableDialector{&RecordingDialector{ConnPool: pool}}, &gorm.Config{
+		RetryPolicy: &gorm.RetryPolicy{MaxAttempts: 3, RetryableErr: func(error) bool { return false }},
+	})
+	if err := noRetryDB.Exec("UPDATE users SET age = 1").Error; !errors.Is(err, errDeadlock) || len(pool.Queries()) != 1 {
+		t.Errorf("errors shouldn't be retried, got %v, %v", err, pool.Queries())
+	}
+}
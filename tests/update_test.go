@@ -1,6 +1,7 @@
 package tests_test
 
 import (
+	"database/sql/driver"
 	"errors"
 	"regexp"
 	"sort"
@@ -805,3 +806,255 @@ func TestUpdateWithDiffSchema(t *testing.T) {
 	AssertEqual(t, err, nil)
 	AssertEqual(t, "update-diff-schema-2", user.Name)
 }
+
+type ImmutableDocument struct {
+	ID         uint
+	Title      string
+	CreatedBy  string `gorm:"<-:create;->"`
+	ApprovedBy string `gorm:"immutable"`
+}
+
+func TestUpdateNonUpdatableColumns(t *testing.T) {
+	DB.Migrator().DropTable(&ImmutableDocument{})
+	if err := DB.AutoMigrate(&ImmutableDocument{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	doc := ImmutableDocument{Title: "title", CreatedBy: "creator", ApprovedBy: "approver"}
+	if err := DB.Create(&doc).Error; err != nil {
+		t.Fatalf("failed to create document, got error %v", err)
+	}
+
+	check := func(title, createdBy, approvedBy string) {
+		t.Helper()
+		var result ImmutableDocument
+		DB.First(&result, doc.ID)
+		AssertEqual(t, result.Title, title)
+		AssertEqual(t, result.CreatedBy, createdBy)
+		AssertEqual(t, result.ApprovedBy, approvedBy)
+	}
+	check("title", "creator", "approver")
+
+	// struct updates drop non-updatable columns silently
+	if err := DB.Model(&doc).Updates(ImmutableDocument{Title: "title2", CreatedBy: "other", ApprovedBy: "other"}).Error; err != nil {
+		t.Fatalf("failed to update with struct, got error %v", err)
+	}
+	check("title2", "creator", "approver")
+
+	// map updates drop create-only columns
+	if err := DB.Model(&doc).Updates(map[string]interface{}{"title": "title3", "created_by": "other"}).Error; err != nil {
+		t.Fatalf("failed to update with map, got error %v", err)
+	}
+	check("title3", "creator", "approver")
+
+	if err := DB.Model(&doc).Update("created_by", "other").Error; err != nil {
+		t.Fatalf("failed to update single column, got error %v", err)
+	}
+	check("title3", "creator", "approver")
+
+	// explicitly updating immutable columns returns error
+	if err := DB.Model(&doc).Updates(map[string]interface{}{"title": "title4", "approved_by": "other"}).Error; !errors.Is(err, gorm.ErrImmutableColumn) {
+		t.Errorf("should return ErrImmutableColumn when updating with map, got %v", err)
+	}
+
+	if err := DB.Model(&doc).Update("ApprovedBy", "other").Error; !errors.Is(err, gorm.ErrImmutableColumn) {
+		t.Errorf("should return ErrImmutableColumn when updating single column, got %v", err)
+	}
+
+	if err := DB.Model(&doc).Select("title", "approved_by").Updates(ImmutableDocument{Title: "title4", ApprovedBy: "other"}).Error; !errors.Is(err, gorm.ErrImmutableColumn) {
+		t.Errorf("should return ErrImmutableColumn when selecting immutable column, got %v", err)
+	}
+	check("title3", "creator", "approver")
+}
+
+func TestOptimisticLock(t *testing.T) {
+	type VersionedProduct struct {
+		ID      uint
+		Name    string
+		Price   int
+		Version gorm.Version
+	}
+
+	DB.Migrator().DropTable(&VersionedProduct{})
+	if err := DB.AutoMigrate(&VersionedProduct{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	product := VersionedProduct{Name: "optimistic_lock", Price: 100}
+	if err := DB.Create(&product).Error; err != nil || product.Version != 1 {
+		t.Fatalf("version should be initialized to 1, got %v, error %v", product.Version, err)
+	}
+
+	products := []VersionedProduct{{Name: "optimistic_lock_1"}, {Name: "optimistic_lock_2", Version: 5}}
+	if err := DB.Create(&products).Error; err != nil || products[0].Version != 1 || products[1].Version != 5 {
+		t.Fatalf("versions should be initialized when zero, got %+v, error %v", products, err)
+	}
+
+	if err := DB.Model(&VersionedProduct{}).Create(map[string]interface{}{"Name": "optimistic_lock_map"}).Error; err != nil {
+		t.Fatalf("failed to create from map, got error %v", err)
+	}
+
+	var mapProduct VersionedProduct
+	if err := DB.First(&mapProduct, "name = ?", "optimistic_lock_map").Error; err != nil || mapProduct.Version != 1 {
+		t.Fatalf("version should be initialized when creating from map, got %+v, error %v", mapProduct, err)
+	}
+
+	// simulate concurrent modification with two copies of the same record
+	var copy1, copy2 VersionedProduct
+	DB.First(&copy1, product.ID)
+	DB.First(&copy2, product.ID)
+
+	values := map[string]interface{}{"price": 200}
+	if err := DB.Model(&copy1).Updates(values).Error; err != nil || copy1.Version != 2 || copy1.Price != 200 {
+		t.Fatalf("failed to update with map, got %+v, error %v", copy1, err)
+	} else if len(values) != 1 {
+		t.Errorf("map to update shouldn't be changed, got %+v", values)
+	}
+
+	if err := DB.Model(&copy2).Update("price", 300).Error; !errors.Is(err, gorm.ErrOptimisticLockConflict) {
+		t.Fatalf("should return optimistic lock conflict error, got %v", err)
+	}
+
+	if err := DB.Model(&copy1).Updates(VersionedProduct{Name: "optimistic_lock_new"}).Error; err != nil || copy1.Version != 3 {
+		t.Fatalf("failed to update with struct, got %+v, error %v", copy1, err)
+	}
+
+	copy1.Price = 400
+	if err := DB.Save(&copy1).Error; err != nil || copy1.Version != 4 {
+		t.Fatalf("failed to save, got %+v, error %v", copy1, err)
+	}
+
+	copy2.Price = 500
+	if err := DB.Save(&copy2).Error; !errors.Is(err, gorm.ErrOptimisticLockConflict) {
+		t.Fatalf("should return optimistic lock conflict error when saving, got %v", err)
+	}
+
+	var result VersionedProduct
+	if err := DB.First(&result, product.ID).Error; err != nil {
+		t.Fatalf("failed to find product, got error %v", err)
+	}
+	AssertObjEqual(t, result, VersionedProduct{ID: product.ID, Name: "optimistic_lock_new", Price: 400, Version: 4}, "ID", "Name", "Price", "Version")
+
+	// deleted record is not a conflict
+	DB.Delete(&result)
+	if result := DB.Model(&copy1).Update("price", 600); result.Error != nil || result.RowsAffected != 0 {
+		t.Errorf("should update nothing without error for deleted record, got %v, rows %v", result.Error, result.RowsAffected)
+	}
+}
+
+type previousReturningDialector struct {
+	*RecordingDialector
+}
+
+func (previousReturningDialector) PreviousValueColumn(column string) clause.Column {
+	return clause.Column{Table: "old", Name: column}
+}
+
+func TestUpdateCapturePrevious(t *testing.T) {
+	user := *GetUser("capture_previous", Config{})
+	user.Age = 18
+	DB.Create(&user)
+
+	var prev User
+	if err := DB.Model(&user).CapturePrevious(&prev, "Name", "age").Updates(map[string]interface{}{"name": "capture_previous_new", "age": 20}).Error; err != nil {
+		t.Fatalf("failed to update with capture previous, got %v", err)
+	}
+
+	if prev.Name != "capture_previous" || prev.Age != 18 {
+		t.Errorf("previous values should be captured, got %+v", prev)
+	}
+
+	if user.Name != "capture_previous_new" || user.Age != 20 {
+		t.Errorf("model should be updated, got %+v", user)
+	}
+
+	var result User
+	DB.First(&result, user.ID)
+	if result.Name != "capture_previous_new" || result.Age != 20 {
+		t.Errorf("new values should be saved, got %+v", result)
+	}
+
+	users := []User{*GetUser("capture_previous_1", Config{}), *GetUser("capture_previous_2", Config{})}
+	DB.Create(&users)
+
+	var prevs []map[string]interface{}
+	if err := DB.Model(&User{}).Where("name LIKE ?", "capture_previous_%").CapturePrevious(&prevs, "name").Update("active", true).Error; err != nil {
+		t.Fatalf("failed to update with capture previous, got %v", err)
+	}
+
+	var names []string
+	for _, p := range prevs {
+		names = append(names, p["name"].(string))
+	}
+	sort.Strings(names)
+	AssertEqual(t, names, []string{"capture_previous_1", "capture_previous_2", "capture_previous_new"})
+
+	if len(prevs[0]) != 1 {
+		t.Errorf("only selected columns should be captured, got %v", prevs[0])
+	}
+
+	var count int64
+	DB.Model(&User{}).Where("name LIKE ? AND active = ?", "capture_previous_%", true).Count(&count)
+	if count != 3 {
+		t.Errorf("all matched records should be updated, got %v", count)
+	}
+
+	var dryRunPrev User
+	stmt := DB.Session(&gorm.Session{DryRun: true}).Model(&user).CapturePrevious(&dryRunPrev, "name").Update("name", "dry_run").Statement
+	if dryRunPrev.Name != "" || strings.Contains(stmt.SQL.String(), "RETURNING") {
+		t.Errorf("DryRun shouldn't capture previous values, got %+v, %v", dryRunPrev, stmt.SQL.String())
+	}
+}
+
+func TestDeleteCapturePrevious(t *testing.T) {
+	users := []User{*GetUser("delete_capture_previous_1", Config{}), *GetUser("delete_capture_previous_2", Config{})}
+	DB.Create(&users)
+
+	var prevs []User
+	if err := DB.Where("name LIKE ?", "delete_capture_previous_%").CapturePrevious(&prevs).Delete(&User{}).Error; err != nil {
+		t.Fatalf("failed to delete with capture previous, got %v", err)
+	}
+
+	if len(prevs) != 2 || prevs[0].Name == "" || prevs[0].DeletedAt.Valid {
+		t.Errorf("previous records should be captured, got %+v", prevs)
+	}
+
+	var count int64
+	DB.Model(&User{}).Where("name LIKE ?", "delete_capture_previous_%").Count(&count)
+	if count != 0 {
+		t.Errorf("records should be deleted, got %v", count)
+	}
+}
+
+func TestUpdateCapturePreviousReturning(t *testing.T) {
+	pool := NewFakeConnPool(FakeResult{Columns: []string{"name", "age"}, Rows: [][]driver.Value{{"jinzhu", int64(18)}}})
+	dialector := previousReturningDialector{&RecordingDialector{ConnPool: pool}}
+	db, err := gorm.Open(dialector, &gorm.Config{SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open, got %v", err)
+	}
+
+	var prev User
+	if err := db.Model(&User{}).Where("id = ?", 1).CapturePrevious(&prev, "name", "Age").Update("name", "jinzhu2").Error; err != nil {
+		t.Fatalf("failed to update with capture previous, got %v", err)
+	}
+
+	if prev.Name != "jinzhu" || prev.Age != 18 {
+		t.Errorf("previous values should be scanned from RETURNING, got %+v", prev)
+	}
+
+	queries := pool.Queries()
+	if len(queries) != 1 || !regexp.MustCompile("^UPDATE `users` SET .* RETURNING `old`.`name`,`old`.`age`$").MatchString(queries[0].SQL) {
+		t.Errorf("previous values should be returned by the UPDATE statement, got %v", queries)
+	}
+
+	// RETURNING 的 old 不是表名，不添加前后缀
+	if err := db.Session(&gorm.Session{TableSuffix: "_t42"}).Model(&User{}).Where("id = ?", 1).CapturePrevious(&prev, "name").Update("name", "jinzhu3").Error; err != nil {
+		t.Fatalf("failed to update with capture previous, got %v", err)
+	}
+
+	queries = pool.Queries()
+	if len(queries) != 2 || !regexp.MustCompile("^UPDATE `users_t42` SET .* RETURNING `old`.`name`$").MatchString(queries[1].SQL) {
+		t.Errorf("old of RETURNING should not be affixed, got %v", queries)
+	}
+}
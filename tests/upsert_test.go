@@ -1,7 +1,10 @@
 package tests_test
 
 import (
+	"errors"
 	"regexp"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -310,6 +313,115 @@ func TestFindOrCreate(t *testing.T) {
 	}
 }
 
+func TestFirstOrCreateConcurrently(t *testing.T) {
+	type ConcurrentLanguage struct {
+		ID   uint
+		Code string `gorm:"size:100;uniqueIndex"`
+		Name string
+	}
+
+	DB.Migrator().DropTable(&ConcurrentLanguage{})
+	if err := DB.AutoMigrate(&ConcurrentLanguage{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	firstOrCreate := func(code string, fc func(tx *gorm.DB) *gorm.DB) []ConcurrentLanguage {
+		var (
+			wg, queried sync.WaitGroup
+			queries     int32
+			errs        = make(chan error, 20)
+			langs       = make([]ConcurrentLanguage, 20)
+		)
+
+		// a separate DB to hold all goroutines after the first query, so they will create the same record concurrently
+		db, err := gorm.Open(DB.Dialector, &gorm.Config{})
+		if err != nil {
+			t.Fatalf("failed to open db, got error %v", err)
+		}
+		if sqlDB, err := db.DB(); err == nil {
+			defer sqlDB.Close()
+		}
+
+		queried.Add(len(langs))
+		db.Callback().Query().After("gorm:query").Register("wait_all_queried", func(*gorm.DB) {
+			if atomic.AddInt32(&queries, 1) <= int32(len(langs)) {
+				queried.Done()
+				queried.Wait()
+			}
+		})
+
+		for i := range langs {
+			wg.Add(1)
+			go func(lang *ConcurrentLanguage) {
+				defer wg.Done()
+				tx := db.Clauses(clause.OnConflict{DoNothing: true}).Where(ConcurrentLanguage{Code: code})
+				errs <- fc(tx).FirstOrCreate(lang).Error
+			}(&langs[i])
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			if err != nil {
+				t.Errorf("no error should happen when FirstOrCreate concurrently, but got %v", err)
+			}
+		}
+
+		var count int64
+		if DB.Model(&ConcurrentLanguage{}).Where("code = ?", code).Count(&count); count != 1 {
+			t.Errorf("should only create 1 language, but got %v", count)
+		}
+		return langs
+	}
+
+	langs := firstOrCreate("first_or_create_concurrently", func(tx *gorm.DB) *gorm.DB {
+		return tx.Attrs(ConcurrentLanguage{Name: "attrs"})
+	})
+	for _, lang := range langs {
+		if lang.ID != langs[0].ID || lang.ID == 0 || lang.Code != "first_or_create_concurrently" || lang.Name != "attrs" {
+			t.Errorf("language should be created or found with attrs, but got %+v", lang)
+		}
+	}
+
+	langs = firstOrCreate("first_or_create_concurrently_assign", func(tx *gorm.DB) *gorm.DB {
+		return tx.Assign(ConcurrentLanguage{Name: "assign"})
+	})
+	for _, lang := range langs {
+		if lang.ID != langs[0].ID || lang.ID == 0 || lang.Code != "first_or_create_concurrently_assign" || lang.Name != "assign" {
+			t.Errorf("language should be created or updated with assign, but got %+v", lang)
+		}
+	}
+}
+
+func TestFirstOrCreateConflictOnOtherKey(t *testing.T) {
+	type ConflictLanguage struct {
+		ID   uint
+		Code string `gorm:"size:100;uniqueIndex"`
+		Name string `gorm:"size:100;uniqueIndex"`
+	}
+
+	db, err := gorm.Open(DB.Dialector, &gorm.Config{TranslateError: true})
+	if err != nil {
+		t.Fatalf("failed to open db, got error %v", err)
+	}
+
+	db.Migrator().DropTable(&ConflictLanguage{})
+	if err := db.AutoMigrate(&ConflictLanguage{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	if err := db.Create(&ConflictLanguage{Code: "conflict_on_other_key_1", Name: "conflict_on_other_key"}).Error; err != nil {
+		t.Fatalf("failed to create language, got error %v", err)
+	}
+
+	// 冲突发生在 name 上，按 code 查询不到记录，应返回冲突错误
+	var lang ConflictLanguage
+	err = db.Where(ConflictLanguage{Code: "conflict_on_other_key_2"}).Attrs(ConflictLanguage{Name: "conflict_on_other_key"}).FirstOrCreate(&lang).Error
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		t.Errorf("should return the duplicated key error, got %v", err)
+	}
+}
+
 func TestUpdateWithMissWhere(t *testing.T) {
 	type User struct {
 		ID   uint   `gorm:"column:id;<-:create"`
@@ -326,3 +438,122 @@ func TestUpdateWithMissWhere(t *testing.T) {
 		t.Fatalf("invalid updating SQL, got %v", tx.Statement.SQL.String())
 	}
 }
+
+func TestUpsertConflictTarget(t *testing.T) {
+	if name := DB.Dialector.Name(); name != "sqlite" && name != "postgres" {
+		t.Skip("conflict target is only supported by ON CONFLICT")
+	}
+
+	lang := Language{Code: "upsert_target", Name: "upsert_target"}
+	r := DB.Session(&gorm.Session{DryRun: true}).Clauses(clause.OnConflict{OnConstraint: "languages_name_key", UpdateAll: true}).Create(&lang)
+	if r.Error != nil {
+		t.Fatalf("failed to build upsert on constraint, got error %v", r.Error)
+	}
+
+	if !regexp.MustCompile(`ON CONFLICT ON CONSTRAINT languages_name_key DO UPDATE SET .name.=.*excluded.*name.$`).MatchString(r.Statement.SQL.String()) {
+		t.Errorf("should upsert on constraint, got %v", r.Statement.SQL.String())
+	}
+
+	r = DB.Session(&gorm.Session{DryRun: true}).Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "name"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Neq{Column: "name", Value: ""}}},
+		UpdateAll:   true,
+	}).Create(&lang)
+	if r.Error != nil {
+		t.Fatalf("failed to build upsert with target where, got error %v", r.Error)
+	}
+
+	if !regexp.MustCompile(`ON CONFLICT \(.name.\) WHERE .name. <> \S+ DO UPDATE SET .name.=.*excluded.*name.$`).MatchString(r.Statement.SQL.String()) {
+		t.Errorf("should upsert with target where, got %v", r.Statement.SQL.String())
+	}
+
+	r = DB.Session(&gorm.Session{DryRun: true}).Clauses(clause.OnConflict{
+		OnConstraint: "languages_name_key",
+		Columns:      []clause.Column{{Name: "name"}},
+		DoNothing:    true,
+	}).Create(&lang)
+	if r.Error == nil {
+		t.Errorf("should return error when using constraint with columns, got %v", r.Statement.SQL.String())
+	}
+}
+
+func TestOnConflictForIndex(t *testing.T) {
+	type UpsertMember struct {
+		ID       uint
+		TenantID uint   `gorm:"uniqueIndex:idx_upsert_members_tenant_email,priority:1"`
+		Email    string `gorm:"size:100;uniqueIndex:idx_upsert_members_tenant_email,priority:2"`
+		Code     string `gorm:"size:100;uniqueIndex:idx_upsert_members_code,where:deleted = false"`
+		Name     string `gorm:"index"`
+		Deleted  bool
+	}
+
+	onConflict, err := gorm.OnConflictForIndex(DB, &UpsertMember{}, "idx_upsert_members_tenant_email")
+	if err != nil {
+		t.Fatalf("failed to build on conflict, got %v", err)
+	}
+	AssertEqual(t, onConflict, clause.OnConflict{Columns: []clause.Column{{Name: "tenant_id"}, {Name: "email"}}})
+
+	onConflict, err = gorm.OnConflictForIndex(DB, &UpsertMember{}, "idx_upsert_members_code")
+	if err != nil {
+		t.Fatalf("failed to build on conflict, got %v", err)
+	}
+	AssertEqual(t, onConflict, clause.OnConflict{
+		Columns:     []clause.Column{{Name: "code"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted = false"}}},
+	})
+
+	// looking up indexes doesn't change the fields of the shared schema
+	if s, err := DB.SchemaOf(&UpsertMember{}); err != nil || s.LookUpField("Code").Unique {
+		t.Errorf("field of single field unique index shouldn't be marked unique, got error %v", err)
+	}
+
+	for _, name := range []string{"idx_upsert_members_unknown", "idx_upsert_members_name", "Email"} {
+		if _, err := gorm.OnConflictForIndex(DB, &UpsertMember{}, name); !errors.Is(err, gorm.ErrUniqueIndexNotFound) {
+			t.Errorf("should return ErrUniqueIndexNotFound for %v, got %v", name, err)
+		}
+	}
+
+	onConflict, err = gorm.OnConflictForPrimaryKey(DB, &UpsertMember{})
+	if err != nil {
+		t.Fatalf("failed to build on conflict, got %v", err)
+	}
+	AssertEqual(t, onConflict, clause.OnConflict{Columns: []clause.Column{{Name: "id"}}})
+
+	if name := DB.Dialector.Name(); name != "sqlite" && name != "postgres" {
+		return
+	}
+
+	DB.Migrator().DropTable(&UpsertMember{})
+	if err := DB.AutoMigrate(&UpsertMember{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	onConflict, _ = gorm.OnConflictForIndex(DB, &UpsertMember{}, "idx_upsert_members_tenant_email")
+	onConflict.DoUpdates = clause.AssignmentColumns([]string{"name"})
+	members := []UpsertMember{{TenantID: 1, Email: "a@example.com", Code: "a", Name: "a"}, {TenantID: 2, Email: "a@example.com", Code: "b", Name: "b"}}
+	if err := DB.Clauses(onConflict).Create(&members).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+
+	member := UpsertMember{TenantID: 1, Email: "a@example.com", Code: "c", Name: "a2"}
+	if err := DB.Clauses(onConflict).Create(&member).Error; err != nil {
+		t.Fatalf("failed to upsert, got %v", err)
+	}
+
+	var names []string
+	DB.Model(&UpsertMember{}).Order("tenant_id").Pluck("name", &names)
+	AssertEqual(t, names, []string{"a2", "b"})
+
+	onConflict, _ = gorm.OnConflictForIndex(DB, &UpsertMember{}, "idx_upsert_members_code")
+	onConflict.DoUpdates = clause.AssignmentColumns([]string{"name"})
+	member = UpsertMember{TenantID: 3, Email: "c@example.com", Code: "b", Name: "b2"}
+	if err := DB.Clauses(onConflict).Create(&member).Error; err != nil {
+		t.Fatalf("failed to upsert on partial index, got %v", err)
+	}
+
+	var count int64
+	DB.Model(&UpsertMember{}).Where("code = ? AND name = ?", "b", "b2").Count(&count)
+	if count != 1 {
+		t.Errorf("should update the conflicting record of the partial index, got %v", count)
+	}
+}
@@ -1,6 +1,8 @@
 package tests
 
 import (
+	"fmt"
+
 	"gorm.io/gorm"
 	"gorm.io/gorm/callbacks"
 	"gorm.io/gorm/clause"
@@ -9,20 +11,29 @@ import (
 )
 
 type DummyDialector struct {
-	TranslatedErr error
+	TranslatedErr     error
+	DisableReturning  bool // builds statements without RETURNING like databases not supporting it
+	DisableIndexHints bool // skips index hints like databases not supporting them
+	BackslashEscapes  bool // treats backslash as the escape char in string literals like MySQL
 }
 
 func (DummyDialector) Name() string {
 	return "dummy"
 }
 
-func (DummyDialector) Initialize(db *gorm.DB) error {
-	callbacks.RegisterDefaultCallbacks(db, &callbacks.Config{
+func (d DummyDialector) Initialize(db *gorm.DB) error {
+	config := &callbacks.Config{
 		CreateClauses:        []string{"INSERT", "VALUES", "ON CONFLICT", "RETURNING"},
 		UpdateClauses:        []string{"UPDATE", "SET", "WHERE", "RETURNING"},
 		DeleteClauses:        []string{"DELETE", "FROM", "WHERE", "RETURNING"},
 		LastInsertIDReversed: true,
-	})
+	}
+	if d.DisableReturning {
+		config.CreateClauses = []string{"INSERT", "VALUES", "ON CONFLICT"}
+		config.UpdateClauses = []string{"UPDATE", "SET", "WHERE"}
+		config.DeleteClauses = []string{"DELETE", "FROM", "WHERE"}
+	}
+	callbacks.RegisterDefaultCallbacks(db, config)
 
 	return nil
 }
@@ -91,10 +102,21 @@ func (DummyDialector) Explain(sql string, vars ...interface{}) string {
 	return logger.ExplainSQL(sql, nil, `"`, vars...)
 }
 
-func (DummyDialector) DataTypeOf(*schema.Field) string {
+func (DummyDialector) DataTypeOf(field *schema.Field) string {
+	if field.DataType == schema.Decimal && field.Precision > 0 {
+		return fmt.Sprintf("numeric(%d,%d)", field.Precision, field.Scale)
+	}
 	return ""
 }
 
+func (d DummyDialector) SupportIndexHint() bool {
+	return !d.DisableIndexHints
+}
+
+func (d DummyDialector) BackslashEscape() bool {
+	return d.BackslashEscapes
+}
+
 func (d DummyDialector) Translate(err error) error {
 	return d.TranslatedErr
 }
@@ -0,0 +1,226 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+)
+
+// FakeResult result of a statement executed with FakeConnPool, queries return Rows with Columns,
+// e.g. the rows of RETURNING when creating
+type FakeResult struct {
+	Columns      []string
+	Rows         [][]driver.Value
+	RowsAffected int64
+	LastInsertID int64
+	Err          error
+}
+
+// FakeQuery statement executed with FakeConnPool
+type FakeQuery struct {
+	SQL  string
+	Vars []interface{}
+}
+
+// FakeConnPool ConnPool without database, statements executed get the results added in order,
+// or an empty result if no result left, e.g:
+//
+//	pool := NewFakeConnPool(FakeResult{Columns: []string{"id", "name"}, Rows: [][]driver.Value{{int64(1), "jinzhu"}}})
+//	db, _ := gorm.Open(&RecordingDialector{ConnPool: pool}, &gorm.Config{})
+//	db.Find(&users)
+type FakeConnPool struct {
+	*sql.DB
+	mu      sync.Mutex
+	results []FakeResult
+	queries []FakeQuery
+}
+
+// NewFakeConnPool returns a FakeConnPool with results
+func NewFakeConnPool(results ...FakeResult) *FakeConnPool {
+	pool := &FakeConnPool{results: results}
+	pool.DB = sql.OpenDB(fakeConnector{pool: pool})
+	return pool
+}
+
+// AddResults add results for the following statements
+func (pool *FakeConnPool) AddResults(results ...FakeResult) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	pool.results = append(pool.results, results...)
+}
+
+// Queries returns statements executed
+func (pool *FakeConnPool) Queries() []FakeQuery {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	return append([]FakeQuery(nil), pool.queries...)
+}
+
+// Reset clear results and statements executed
+func (pool *FakeConnPool) Reset() {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	pool.results = nil
+	pool.queries = nil
+}
+
+func (pool *FakeConnPool) next(query string, args []driver.NamedValue) FakeResult {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	vars := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		vars = append(vars, arg.Value)
+	}
+	pool.queries = append(pool.queries, FakeQuery{SQL: query, Vars: vars})
+
+	if len(pool.results) == 0 {
+		return FakeResult{}
+	}
+
+	result := pool.results[0]
+	pool.results = pool.results[1:]
+	return result
+}
+
+type fakeConnector struct {
+	pool *FakeConnPool
+}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{pool: c.pool}, nil
+}
+
+func (c fakeConnector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("fake driver can only be used with FakeConnPool")
+}
+
+type fakeConn struct {
+	pool *FakeConnPool
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+// CheckNamedValue keep vars as they are, so that the vars could be checked
+func (c *fakeConn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	result := c.pool.next(query, args)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return fakeExecResult{lastInsertID: result.LastInsertID, rowsAffected: result.RowsAffected}, nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	result := c.pool.next(query, args)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return &fakeRows{columns: result.Columns, rows: result.Rows}, nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, namedValues(args))
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, namedValues(args))
+}
+
+func (s *fakeStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.ExecContext(ctx, s.query, args)
+}
+
+func (s *fakeStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	values := make([]driver.NamedValue, 0, len(args))
+	for idx, arg := range args {
+		values = append(values, driver.NamedValue{Ordinal: idx + 1, Value: arg})
+	}
+	return values
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error {
+	return nil
+}
+
+func (fakeTx) Rollback() error {
+	return nil
+}
+
+type fakeExecResult struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+func (r fakeExecResult) LastInsertId() (int64, error) {
+	return r.lastInsertID, nil
+}
+
+func (r fakeExecResult) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	idx     int
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.idx >= len(r.rows) {
+		return io.EOF
+	}
+
+	copy(dest, r.rows[r.idx])
+	r.idx++
+	return nil
+}
@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils"
+)
+
+// RecordedStatement statement built by RecordingDialector
+type RecordedStatement struct {
+	SQL  string
+	Vars []interface{}
+}
+
+// RecordingDialector DummyDialector records the SQL and vars of every statement built, including statements of DryRun,
+// statements are executed with ConnPool, a FakeConnPool without results by default, e.g:
+//
+//	dialector := &RecordingDialector{}
+//	db, _ := gorm.Open(dialector, &gorm.Config{})
+//	db.Where("name = ?", "jinzhu").Find(&users)
+//	dialector.LastStatement() // SELECT * FROM `users` WHERE name = ? AND `users`.`deleted_at` IS NULL, [jinzhu]
+type RecordingDialector struct {
+	DummyDialector
+	ConnPool gorm.ConnPool
+
+	mu         sync.Mutex
+	statements []RecordedStatement
+}
+
+func (d *RecordingDialector) Initialize(db *gorm.DB) error {
+	if err := d.DummyDialector.Initialize(db); err != nil {
+		return err
+	}
+
+	if d.ConnPool == nil {
+		d.ConnPool = NewFakeConnPool()
+	}
+	db.ConnPool = d.ConnPool
+
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().After("*").Register("tests:record", d.record),
+		callbacks.Query().After("*").Register("tests:record", d.record),
+		callbacks.Update().After("*").Register("tests:record", d.record),
+		callbacks.Delete().After("*").Register("tests:record", d.record),
+		callbacks.Row().After("*").Register("tests:record", d.record),
+		callbacks.Raw().After("*").Register("tests:record", d.record),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *RecordingDialector) record(db *gorm.DB) {
+	if db.Statement.SQL.Len() == 0 {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.statements = append(d.statements, RecordedStatement{
+		SQL:  db.Statement.SQL.String(),
+		Vars: append([]interface{}(nil), db.Statement.Vars...),
+	})
+}
+
+// Statements returns statements recorded
+func (d *RecordingDialector) Statements() []RecordedStatement {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]RecordedStatement(nil), d.statements...)
+}
+
+// LastStatement returns the last statement recorded
+func (d *RecordingDialector) LastStatement() RecordedStatement {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.statements) == 0 {
+		return RecordedStatement{}
+	}
+	return d.statements[len(d.statements)-1]
+}
+
+// Reset clear statements recorded
+func (d *RecordingDialector) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.statements = nil
+}
+
+// NormalizeSQL collapses consecutive spaces of sql, so that the SQL could be compared regardless of the format
+func NormalizeSQL(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
+
+// AssertSQL asserts the chain builds the expected SQL, vars are explained into the SQL, e.g:
+//
+//	AssertSQL(t, db, func(tx *gorm.DB) *gorm.DB {
+//		return tx.Where("name = ?", "jinzhu").Find(&[]User{})
+//	}, "SELECT * FROM `users` WHERE name = \"jinzhu\" AND `users`.`deleted_at` IS NULL")
+func AssertSQL(t *testing.T, db *gorm.DB, fc func(tx *gorm.DB) *gorm.DB, expected string) {
+	if got := NormalizeSQL(db.ToSQL(fc)); got != NormalizeSQL(expected) {
+		t.Errorf("%v: expect SQL: %v, got %v", utils.FileWithLineNum(), NormalizeSQL(expected), got)
+	}
+}
@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestRecordingDialectorFind(t *testing.T) {
+	pool := NewFakeConnPool(FakeResult{
+		Columns: []string{"id", "name", "age"},
+		Rows:    [][]driver.Value{{int64(1), "jinzhu", int64(18)}, {int64(2), "jinzhu2", int64(20)}},
+	})
+	dialector := &RecordingDialector{ConnPool: pool}
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open, got %v", err)
+	}
+
+	var users []User
+	if err := db.Where("name LIKE ?", "jinzhu%").Find(&users).Error; err != nil {
+		t.Fatalf("failed to find, got %v", err)
+	}
+
+	if len(users) != 2 || users[0].ID != 1 || users[1].Name != "jinzhu2" || users[1].Age != 20 {
+		t.Errorf("failed to scan rows, got %+v", users)
+	}
+
+	AssertEqual(t, dialector.LastStatement(), RecordedStatement{
+		SQL:  "SELECT * FROM `users` WHERE name LIKE ? AND `users`.`deleted_at` IS NULL",
+		Vars: []interface{}{"jinzhu%"},
+	})
+	AssertEqual(t, pool.Queries(), []FakeQuery{{SQL: dialector.LastStatement().SQL, Vars: []interface{}{"jinzhu%"}}})
+
+	AssertSQL(t, db, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", "jinzhu").Order("id").Find(&[]User{})
+	}, "SELECT * FROM `users`  WHERE name = \"jinzhu\"\n AND `users`.`deleted_at` IS NULL ORDER BY id")
+
+	if len(pool.Queries()) != 1 {
+		t.Errorf("DryRun statements shouldn't be executed, got %v", pool.Queries())
+	}
+
+	var user User
+	if err := db.First(&user).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("should returns ErrRecordNotFound without results, got %v", err)
+	}
+}
+
+func TestRecordingDialectorCreate(t *testing.T) {
+	pool := NewFakeConnPool(FakeResult{Columns: []string{"id"}, Rows: [][]driver.Value{{int64(10)}}})
+	dialector := &RecordingDialector{ConnPool: pool}
+	db, err := gorm.Open(dialector, &gorm.Config{SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open, got %v", err)
+	}
+
+	user := User{Name: "jinzhu", Age: 18}
+	if err := db.Select("Name", "Age").Create(&user).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+
+	if user.ID != 10 {
+		t.Errorf("primary key should be scanned from RETURNING, got %v", user.ID)
+	}
+
+	created := dialector.LastStatement()
+	AssertEqual(t, created.SQL, "INSERT INTO `users` (`created_at`,`updated_at`,`name`,`age`) VALUES (?,?,?,?) RETURNING `id`")
+	AssertEqual(t, created.Vars, []interface{}{user.CreatedAt, user.UpdatedAt, "jinzhu", uint(18)})
+
+	pool.AddResults(FakeResult{Err: errors.New("connection refused")})
+	if err := db.Model(&user).Update("name", "jinzhu2").Error; err == nil || err.Error() != "connection refused" {
+		t.Errorf("should returns error of result, got %v", err)
+	}
+
+	pool.AddResults(FakeResult{RowsAffected: 1})
+	if result := db.Delete(&user); result.Error != nil || result.RowsAffected != 1 {
+		t.Errorf("failed to delete, got %v, %v", result.Error, result.RowsAffected)
+	}
+
+	AssertEqual(t, len(dialector.Statements()), 3)
+	dialector.Reset()
+	pool.Reset()
+	if len(dialector.Statements()) != 0 || len(pool.Queries()) != 0 {
+		t.Errorf("failed to reset")
+	}
+}
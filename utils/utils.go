@@ -8,6 +8,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 )
 
@@ -99,11 +100,42 @@ func AssertEqual(src, dst interface{}) bool {
 			dst, _ = valuer.Value()
 		}
 
-		return reflect.DeepEqual(src, dst)
+		return reflect.DeepEqual(src, dst) || TimeEqual(src, dst, 0)
 	}
 	return true
 }
 
+// TimeEqual reports whether src and dst are the same instant after truncated to precision,
+// ignoring monotonic clock readings and locations, returns false if any of them isn't a time
+func TimeEqual(src, dst interface{}, precision time.Duration) bool {
+	srcTime, ok := toTime(src)
+	if !ok {
+		return false
+	}
+
+	dstTime, ok := toTime(dst)
+	if !ok {
+		return false
+	}
+
+	if precision > 0 {
+		srcTime, dstTime = srcTime.Truncate(precision), dstTime.Truncate(precision)
+	}
+	return srcTime.Equal(dstTime)
+}
+
+func toTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case *time.Time:
+		if v != nil {
+			return *v, true
+		}
+	}
+	return time.Time{}, false
+}
+
 func ToString(value interface{}) string {
 	switch v := value.(type) {
 	case string:
@@ -148,3 +180,31 @@ func SplitNestedRelationName(name string) []string {
 func JoinNestedRelationNames(relationNames []string) string {
 	return strings.Join(relationNames, nestedRelationSplit)
 }
+
+// SplitSelectItems splits select items by commas outside of parentheses and quotes, e.g: `name, COALESCE(age, 0) AS age`
+func SplitSelectItems(str string) (items []string) {
+	var (
+		depth int
+		quote rune
+		start int
+	)
+
+	for idx, c := range str {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			items = append(items, strings.TrimSpace(str[start:idx]))
+			start = idx + 1
+		}
+	}
+	return append(items, strings.TrimSpace(str[start:]))
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql/driver"
 	"errors"
 	"math"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -98,6 +99,10 @@ func TestAssertEqual(t *testing.T) {
 		{"error not equal", errors.New("1"), errors.New("2"), false},
 		{"driver.Valuer equal", ModifyAt{Time: now, Valid: true}, ModifyAt{Time: now, Valid: true}, true},
 		{"driver.Valuer not equal", ModifyAt{Time: now, Valid: true}, ModifyAt{Time: now.Add(time.Second), Valid: true}, false},
+		{"time without monotonic clock equal", now, now.Round(0), true},
+		{"time in different locations equal", now, now.UTC(), true},
+		{"time pointer equal", &now, now.Round(0), true},
+		{"time not equal", now, now.Add(time.Nanosecond), false},
 	}
 	for _, test := range assertEqualTests {
 		t.Run(test.name, func(t *testing.T) {
@@ -108,6 +113,31 @@ func TestAssertEqual(t *testing.T) {
 	}
 }
 
+func TestTimeEqual(t *testing.T) {
+	now := time.Date(2023, 1, 1, 10, 0, 0, 123456789, time.UTC)
+	timeEqualTests := []struct {
+		name      string
+		src, dst  interface{}
+		precision time.Duration
+		out       bool
+	}{
+		{"same time", now, now, 0, true},
+		{"different nanoseconds", now, now.Add(100), 0, false},
+		{"different nanoseconds in microsecond precision", now, now.Add(100), time.Microsecond, true},
+		{"different microseconds in microsecond precision", now, now.Add(time.Microsecond), time.Microsecond, false},
+		{"different microseconds in millisecond precision", now, now.Add(time.Microsecond), time.Millisecond, true},
+		{"nil time pointer", (*time.Time)(nil), now, 0, false},
+		{"not time", "2023-01-01", now, 0, false},
+	}
+	for _, test := range timeEqualTests {
+		t.Run(test.name, func(t *testing.T) {
+			if out := TimeEqual(test.src, test.dst, test.precision); test.out != out {
+				t.Errorf("TimeEqual(%v, %v, %v) want: %t, got: %t", test.src, test.dst, test.precision, test.out, out)
+			}
+		})
+	}
+}
+
 func TestToString(t *testing.T) {
 	tests := []struct {
 		name string
@@ -135,3 +165,20 @@ func TestToString(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitSelectItems(t *testing.T) {
+	tests := []struct {
+		in  string
+		out []string
+	}{
+		{"name", []string{"name"}},
+		{"users.*, Company.*", []string{"users.*", "Company.*"}},
+		{"name, COALESCE(age, 0) AS age", []string{"name", "COALESCE(age, 0) AS age"}},
+		{"CONCAT(name, ',', `a,b`), 'x,y' AS z", []string{"CONCAT(name, ',', `a,b`)", "'x,y' AS z"}},
+	}
+	for _, test := range tests {
+		if out := SplitSelectItems(test.in); !reflect.DeepEqual(out, test.out) {
+			t.Errorf("SplitSelectItems(%q) want: %q, got: %q", test.in, test.out, out)
+		}
+	}
+}
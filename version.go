@@ -0,0 +1,153 @@
+package gorm
+
+import (
+	"reflect"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Version optimistic lock version, it is initialized to 1 when creating and increased when updating, E.g:
+//
+//	type Product struct {
+//		ID      uint
+//		Price   int
+//		Version gorm.Version
+//	}
+//
+//	// UPDATE products SET price=200,version=2 WHERE products.version = 1 AND id = 1
+//	db.Model(&product).Update("price", 200)
+//
+// the record must have non-zero primary keys and version to be locked, updating returns ErrOptimisticLockConflict
+// if the record exists but its version has been changed, reload the record before updating it again
+type Version int64
+
+func (Version) CreateClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{VersionCreateClause{Field: f}}
+}
+
+type VersionCreateClause struct {
+	Field *schema.Field
+}
+
+func (v VersionCreateClause) Name() string {
+	return ""
+}
+
+func (v VersionCreateClause) Build(clause.Builder) {
+}
+
+func (v VersionCreateClause) MergeClause(*clause.Clause) {
+}
+
+func (v VersionCreateClause) ModifyStatement(stmt *Statement) {
+	if stmt.SQL.Len() > 0 {
+		return
+	}
+
+	// 创建时版本号为空则初始化为 1
+	if maps, ok := stmt.destMaps(); ok {
+		for _, m := range maps {
+			if key := stmt.mapColumnKey(m, v.Field.DBName); m[key] == nil {
+				m[key] = 1
+			}
+		}
+		return
+	}
+
+	switch stmt.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < stmt.ReflectValue.Len(); i++ {
+			if _, zero := v.Field.ValueOf(stmt.Context, stmt.ReflectValue.Index(i)); zero {
+				stmt.AddError(v.Field.Set(stmt.Context, stmt.ReflectValue.Index(i), 1))
+			}
+		}
+	case reflect.Struct:
+		if _, zero := v.Field.ValueOf(stmt.Context, stmt.ReflectValue); zero && stmt.ReflectValue.CanAddr() {
+			stmt.AddError(v.Field.Set(stmt.Context, stmt.ReflectValue, 1))
+		}
+	}
+}
+
+func (Version) UpdateClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{VersionUpdateClause{Field: f}}
+}
+
+type VersionUpdateClause struct {
+	Field *schema.Field
+}
+
+func (v VersionUpdateClause) Name() string {
+	return ""
+}
+
+func (v VersionUpdateClause) Build(clause.Builder) {
+}
+
+func (v VersionUpdateClause) MergeClause(*clause.Clause) {
+}
+
+func (v VersionUpdateClause) ModifyStatement(stmt *Statement) {
+	if stmt.SQL.Len() > 0 || stmt.ReflectValue.Kind() != reflect.Struct {
+		return
+	}
+
+	if _, ok := stmt.Clauses["SET"]; ok {
+		return
+	}
+
+	// 显式更新或者忽略版本号时，不做乐观锁
+	maps, isMap := stmt.destMaps()
+	if isMap {
+		if len(maps) != 1 {
+			return
+		}
+
+		if _, ok := maps[0][stmt.mapColumnKey(maps[0], v.Field.DBName)]; ok {
+			return
+		}
+	}
+
+	for _, omit := range stmt.Omits {
+		if omit == v.Field.Name || omit == v.Field.DBName {
+			return
+		}
+	}
+
+	current, zero := v.Field.ValueOf(stmt.Context, stmt.ReflectValue)
+	if zero {
+		return
+	}
+
+	_, queryValues := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
+	column, values := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+	if len(values) == 0 {
+		return
+	}
+
+	version := int64(current.(Version))
+	stmt.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: v.Field.DBName}, Value: version},
+	}})
+
+	if len(stmt.Selects) > 0 {
+		stmt.Selects = append(stmt.Selects, v.Field.DBName)
+	}
+
+	if isMap {
+		// 复制一份 map，避免修改用户传入的 map
+		values := make(map[string]interface{}, len(maps[0])+1)
+		for key, value := range maps[0] {
+			values[key] = value
+		}
+		values[v.Field.DBName] = version + 1
+		stmt.Dest = values
+	} else {
+		stmt.SetColumn(v.Field.DBName, version+1, true)
+	}
+
+	// 更新后影响行数为 0 时，用主键条件检查记录是否存在，以区分版本冲突
+	stmt.Clauses["version_lock_enabled"] = clause.Clause{
+		Expression: clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: values}}},
+	}
+}